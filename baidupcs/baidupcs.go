@@ -2,6 +2,7 @@
 package baidupcs
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/cookiejar"
@@ -9,6 +10,7 @@ import (
 	"strconv"
 
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires/cachemap"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/governor"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/internal/panhome"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
@@ -148,7 +150,12 @@ type (
 		isSetPanUA  bool
 		fixPCSAddr  bool
 		ph          *panhome.PanHome
-		cacheOpMap  cachemap.CacheOpMap
+		cacheOpMap  *cachemap.CacheOpMap
+		diskCache   *cachemap.DiskCache // 磁盘缓存, 为空则只在内存中缓存
+		governor    *governor.Governor  // 请求调度, 为空则不限制
+		ctx         context.Context     // 请求使用的 context, 由 WithContext 设置
+		parent      *BaiduPCS           // WithContext 派生自的对象, 共用缓存
+		endpoint    *url.URL            // 所有请求改为发往该地址, 用于测试
 	}
 
 	userInfoJSON struct {
@@ -223,10 +230,12 @@ func NewPCS(appID int, bduss string) *BaiduPCS {
 		},
 	})
 
-	return &BaiduPCS{
+	pcs := &BaiduPCS{
 		appID:  appID,
 		client: client,
 	}
+	pcs.lazyInit()
+	return pcs
 }
 
 // NewPCSWithClient 提供app_id, 自定义客户端, 返回 BaiduPCS 对象
@@ -235,6 +244,7 @@ func NewPCSWithClient(appID int, client *requester.HTTPClient) *BaiduPCS {
 		appID:  appID,
 		client: client,
 	}
+	pcs.lazyInit()
 	return pcs
 }
 
@@ -253,10 +263,13 @@ func NewPCSWithCookieStr(appID int, cookieStr string) *BaiduPCS {
 	jar, _ := cookiejar.New(nil)
 	jar.SetCookies(baiduComURL, cookies)
 	pcs.client.SetCookiejar(jar)
+	pcs.lazyInit()
 
 	return pcs
 }
 
+// lazyInit 初始化未设置的字段, 构造函数中已调用, 之后只在字段缺失时写入,
+// 使并发的请求只读取 pcs
 func (pcs *BaiduPCS) lazyInit() {
 	if pcs.client == nil {
 		pcs.client = requester.NewHTTPClient()
@@ -264,7 +277,10 @@ func (pcs *BaiduPCS) lazyInit() {
 	if pcs.ph == nil {
 		pcs.ph = panhome.NewPanHome(pcs.client)
 	}
-	if !pcs.isSetPanUA {
+	if pcs.cacheOpMap == nil {
+		pcs.cacheOpMap = &cachemap.CacheOpMap{}
+	}
+	if !pcs.isSetPanUA && pcs.panUA != NetdiskUA {
 		pcs.panUA = NetdiskUA
 	}
 }
@@ -307,6 +323,16 @@ func (pcs *BaiduPCS) GetPCSAddr() string {
 	return pcs.pcsAddr
 }
 
+// SetGovernor 设置请求调度器, 限制请求频率
+func (pcs *BaiduPCS) SetGovernor(g *governor.Governor) {
+	pcs.governor = g
+}
+
+// SetDiskCache 设置磁盘缓存, 多次运行和多个进程之间共用缓存
+func (pcs *BaiduPCS) SetDiskCache(dc *cachemap.DiskCache) {
+	pcs.diskCache = dc
+}
+
 // SetAPPID 设置app_id
 func (pcs *BaiduPCS) SetAPPID(appID int) {
 	pcs.appID = appID
@@ -367,6 +393,25 @@ func (pcs *BaiduPCS) SetPCSAddr(addr string) {
 	}
 }
 
+// SetEndpoint 将所有 pcs 和网盘的请求改为发往 endpoint, 只使用其 Scheme 和 Host,
+// 用于连接本地的测试服务器, 设为 nil 恢复默认
+func (pcs *BaiduPCS) SetEndpoint(endpoint *url.URL) {
+	pcs.endpoint = endpoint
+}
+
+// rewriteURL 设置了 endpoint 时, 替换请求地址的 Scheme 和 Host
+func (pcs *BaiduPCS) rewriteURL(urlStr string) string {
+	if pcs.endpoint == nil {
+		return urlStr
+	}
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return urlStr
+	}
+	u.Scheme, u.Host = pcs.endpoint.Scheme, pcs.endpoint.Host
+	return u.String()
+}
+
 // SetPanUserAgent 设置 Pan User-Agent
 func (pcs *BaiduPCS) SetPanUserAgent(ua string) {
 	pcs.panUA = ua
@@ -1,45 +1,246 @@
 package baidupcs
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires/cachemap"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// deleteCache 删除含有 dirs 的缓存
-func (pcs *BaiduPCS) deleteCache(dirs []string) {
-	cache := pcs.cacheOpMap.LazyInitCachePoolOp(OperationFilesDirectoriesList)
-	for _, v := range dirs {
-		key := v + "_" + defaultOrderOptionsStr
-		_, ok := cache.Load(key)
-		if ok {
+type (
+	// listCacheKey 目录列表的缓存键
+	listCacheKey struct {
+		Path  string
+		By    OrderBy
+		Order Order
+	}
+
+	// listCacheOrders 磁盘缓存中一个目录的列表, 键为排序方式, 同一目录的各种排序保存在一个缓存文件中
+	listCacheOrders map[string]FileDirectoryList
+)
+
+const (
+	// OperationDu 目录空间占用的统计结果, 只缓存到磁盘
+	OperationDu = "统计目录空间占用"
+
+	// DefaultDuCacheTTL 没有设置时空间占用统计结果的磁盘缓存时间
+	DefaultDuCacheTTL = 24 * time.Hour
+
+	// memoryCacheTTL 目录列表在内存中的缓存时间
+	memoryCacheTTL = 1 * time.Minute
+
+	// recyclePathTTL 回收站列表中 fs_id 对应的路径在内存中的保存时间, 用于还原后更新缓存
+	recyclePathTTL = 1 * time.Hour
+)
+
+var (
+	// CacheClasses 可以缓存到磁盘的操作, 用于设置缓存时间
+	CacheClasses = map[string]string{
+		"list": OperationFilesDirectoriesList,
+		"uk":   OperationGetUK,
+		"du":   OperationDu,
+	}
+)
+
+// ParseCacheTTL 解析各操作的磁盘缓存时间, 格式如 list=5m,uk=24h
+func ParseCacheTTL(s string) (map[string]time.Duration, error) {
+	ttl := map[string]time.Duration{}
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("规则格式错误: %s, 应为 类别=缓存时间", field)
+		}
+		op, ok := CacheClasses[strings.TrimSpace(kv[0])]
+		if !ok {
+			return nil, fmt.Errorf("未知的缓存类别: %s", kv[0])
+		}
+		value := strings.TrimSpace(kv[1])
+		dur, err := time.ParseDuration(value)
+		if err != nil {
+			// 不带单位时按秒计算
+			var sec int64
+			sec, err = strconv.ParseInt(value, 10, 64)
+			dur = time.Duration(sec) * time.Second
+		}
+		if err != nil || dur < 0 {
+			return nil, fmt.Errorf("缓存时间不合法: %s", kv[1])
+		}
+		ttl[op] = dur
+	}
+	return ttl, nil
+}
+
+// order 排序方式, 用作 listCacheOrders 的键
+func (k listCacheKey) order() string {
+	return string(k.By) + " " + string(k.Order)
+}
+
+func (pcs *BaiduPCS) diskCacheOps() *cachemap.DiskCache {
+	return pcs.root().diskCache
+}
+
+// deleteMemoryListCache 删除 match 返回 true 的目录列表内存缓存
+func (pcs *BaiduPCS) deleteMemoryListCache(match func(p string) bool) {
+	cache := pcs.cacheOps().LazyInitCachePoolOp(OperationFilesDirectoriesList)
+	cache.Range(func(key interface{}, _ expires.DataExpires) bool {
+		if k, ok := key.(listCacheKey); ok && match(k.Path) {
 			cache.Delete(key)
 		}
+		return true
+	})
+}
+
+// isPathUnder 判断 p 是否为 dir 或其下的路径
+func isPathUnder(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, PathSeparator)+PathSeparator)
+}
+
+// ancestorDirs 返回 p 的全部上级目录, 从直接上级到根目录
+func ancestorDirs(p string) (dirs []string) {
+	for p = path.Clean(p); p != PathSeparator && p != "."; {
+		p = path.Dir(p)
+		dirs = append(dirs, p)
+	}
+	return
+}
+
+// deleteCache 删除含有 dirs 的缓存, 磁盘缓存按路径直接删除, 不需要遍历
+func (pcs *BaiduPCS) deleteCache(dirs []string) {
+	pcs.deleteMemoryListCache(func(p string) bool {
+		for _, dir := range dirs {
+			if p == dir {
+				return true
+			}
+		}
+		return false
+	})
+	disk := pcs.diskCacheOps()
+	for _, dir := range dirs {
+		dir = path.Clean(dir)
+		disk.Delete(OperationFilesDirectoriesList, dir)
+		// 统计范围包括 dir 的缓存
+		disk.Delete(OperationDu, dir)
+		for _, v := range ancestorDirs(dir) {
+			disk.Delete(OperationDu, v)
+		}
 	}
 }
 
+// deleteCacheTree 删除 paths 及其子目录的缓存, 用于删除或移动目录.
+// 磁盘缓存按路径分层保存, 只删除 paths 之下的缓存文件
+func (pcs *BaiduPCS) deleteCacheTree(paths []string) {
+	pcs.deleteMemoryListCache(func(p string) bool {
+		for _, v := range paths {
+			if isPathUnder(p, v) {
+				return true
+			}
+		}
+		return false
+	})
+	disk := pcs.diskCacheOps()
+	for _, v := range paths {
+		v = path.Clean(v)
+		disk.DeleteTree(OperationFilesDirectoriesList, v)
+		// 统计范围包括 v, 或者在 v 之下的缓存
+		disk.DeleteTree(OperationDu, v)
+		for _, dir := range ancestorDirs(v) {
+			disk.Delete(OperationDu, dir)
+		}
+	}
+}
+
+// deleteRestoredCache 删除从回收站还原的文件的上级目录的缓存, 上级目录可能也是还原时新建的.
+// 没有列出过回收站, 原路径未知时删除全部目录列表和空间占用统计的缓存
+func (pcs *BaiduPCS) deleteRestoredCache(restored []*FsIDJSON) {
+	var (
+		recyclePaths = pcs.cacheOps().LazyInitCachePoolOp(OperationRecycleList)
+		dirs         []string
+	)
+	for _, v := range restored {
+		data, ok := recyclePaths.Load(v.FsID)
+		if !ok {
+			pcs.deleteCacheTree([]string{PathSeparator})
+			return
+		}
+		recyclePaths.Delete(v.FsID)
+		for _, dir := range ancestorDirs(data.Data().(string)) {
+			if !pcsutil.ContainsString(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	pcs.deleteCache(dirs)
+}
+
+// cacheTTL 返回磁盘缓存在内存中的缓存时间, 不超过 max
+func cacheTTL(expiresAt time.Time, max time.Duration) time.Duration {
+	if dur := time.Until(expiresAt); dur < max {
+		return dur
+	}
+	return max
+}
+
 // CacheFilesDirectoriesList 缓存获取
-func (pcs *BaiduPCS) CacheFilesDirectoriesList(path string, options *OrderOptions) (fdl FileDirectoryList, pcsError pcserror.Error) {
-	data := pcs.cacheOpMap.CacheOperation(OperationFilesDirectoriesList, path+"_"+string(options.By)+string(options.Order), func() expires.DataExpires {
-		fdl, pcsError = pcs.FilesDirectoriesList(path, options)
+func (pcs *BaiduPCS) CacheFilesDirectoriesList(pcspath string, options *OrderOptions) (fdl FileDirectoryList, pcsError pcserror.Error) {
+	if options == nil {
+		options = DefaultOrderOptions
+	}
+	key := listCacheKey{Path: path.Clean(pcspath), By: options.By, Order: options.Order}
+	data := pcs.cacheOps().CacheOperation(OperationFilesDirectoriesList, key, func() expires.DataExpires {
+		var (
+			disk   = pcs.diskCacheOps()
+			orders listCacheOrders
+		)
+		expiresAt, ok := disk.Load(OperationFilesDirectoriesList, key.Path, &orders)
+		if fdl, found := orders[key.order()]; ok && found {
+			return expires.NewDataExpires(fdl, cacheTTL(expiresAt, memoryCacheTTL))
+		}
+		fdl, pcsError = pcs.FilesDirectoriesList(pcspath, options)
 		if pcsError != nil {
 			return nil
 		}
-		return expires.NewDataExpires(fdl, 1*time.Minute)
+		if ok {
+			// 同一目录的其他排序仍然有效, 保留原来的过期时间
+			orders[key.order()] = fdl
+			disk.StoreExpiresAt(OperationFilesDirectoriesList, key.Path, orders, expiresAt)
+		} else {
+			disk.Store(OperationFilesDirectoriesList, key.Path, listCacheOrders{key.order(): fdl})
+		}
+		return expires.NewDataExpires(fdl, memoryCacheTTL)
 	})
 	if pcsError != nil {
 		return
 	}
-	return data.Data().(FileDirectoryList), nil
+	// 返回副本, 避免调用者排序等操作修改缓存
+	return append(FileDirectoryList(nil), data.Data().(FileDirectoryList)...), nil
 }
 
 // CacheUK 缓存获取
 func (pcs *BaiduPCS) CacheUK() (uk int64, pcsError pcserror.Error) {
-	data := pcs.cacheOpMap.CacheOperation(OperationGetUK, pcs.GetBDUSS(), func() expires.DataExpires {
+	// 磁盘缓存不保存 BDUSS
+	sum := md5.Sum([]byte(pcs.GetBDUSS()))
+	diskKey := hex.EncodeToString(sum[:])
+	data := pcs.cacheOps().CacheOperation(OperationGetUK, pcs.GetBDUSS(), func() expires.DataExpires {
+		disk := pcs.diskCacheOps()
+		if expiresAt, ok := disk.Load(OperationGetUK, diskKey, &uk); ok {
+			return expires.NewDataExpires(uk, cacheTTL(expiresAt, 24*time.Hour))
+		}
 		uk, pcsError = pcs.UK()
 		if pcsError != nil {
 			return nil
 		}
+		disk.Store(OperationGetUK, diskKey, uk)
 		return expires.NewDataExpires(uk, 24*time.Hour)
 	})
 	if pcsError != nil {
@@ -47,3 +248,29 @@ func (pcs *BaiduPCS) CacheUK() (uk int64, pcsError pcserror.Error) {
 	}
 	return data.Data().(int64), nil
 }
+
+// LoadDuCache 读取目录 dir 的空间占用统计缓存到 v, 缓存不存在或已过期时 ok 为 false
+func (pcs *BaiduPCS) LoadDuCache(dir string, v interface{}) (ok bool) {
+	_, ok = pcs.diskCacheOps().Load(OperationDu, path.Clean(dir), v)
+	return
+}
+
+// StoreDuCache 保存目录 dir 的空间占用统计结果, 目录下的文件被修改时缓存失效
+func (pcs *BaiduPCS) StoreDuCache(dir string, v interface{}) error {
+	return pcs.diskCacheOps().Store(OperationDu, path.Clean(dir), v)
+}
+
+// DiskCacheStats 返回磁盘缓存的统计信息, 没有设置磁盘缓存时返回空
+func (pcs *BaiduPCS) DiskCacheStats() (dir string, stats []*cachemap.DiskCacheStat, err error) {
+	disk := pcs.diskCacheOps()
+	stats, err = disk.Stats()
+	return disk.Dir(), stats, err
+}
+
+// ClearCache 清除内存和磁盘中的全部缓存, 返回删除的磁盘缓存项数量
+func (pcs *BaiduPCS) ClearCache() (n int, err error) {
+	for _, op := range CacheClasses {
+		pcs.cacheOps().RemoveCachePoolOp(op)
+	}
+	return pcs.diskCacheOps().Clear()
+}
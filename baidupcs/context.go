@@ -0,0 +1,203 @@
+package baidupcs
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires/cachemap"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+// WithContext 返回使用 ctx 发送请求的 BaiduPCS, ctx 被取消或超时时, 正在进行的请求会被中断,
+// 返回的错误可使用 errors.Is(err, context.Canceled) 判断.
+// 返回的对象与原对象共用 http 客户端, 登录信息和缓存, 之后对原对象的设置不会同步到返回的对象.
+// 不修改原对象, 可在多个协程中同时调用
+func (pcs *BaiduPCS) WithContext(ctx context.Context) *BaiduPCS {
+	if ctx == nil {
+		panic("baidupcs: nil context")
+	}
+	c := *pcs
+	c.ctx = ctx
+	c.parent = pcs.root()
+	c.lazyInit()
+	return &c
+}
+
+// Context 返回请求使用的 context, 未设置时返回 context.Background()
+func (pcs *BaiduPCS) Context() context.Context {
+	if pcs.ctx == nil {
+		return context.Background()
+	}
+	return pcs.ctx
+}
+
+// SetNetworkOptions 设置当前对象的代理和本地网卡地址, 不影响全局设置
+func (pcs *BaiduPCS) SetNetworkOptions(opt *requester.NetworkOptions) {
+	pcs.GetClient().SetNetworkOptions(opt)
+}
+
+func (pcs *BaiduPCS) root() *BaiduPCS {
+	if pcs.parent != nil {
+		return pcs.parent
+	}
+	return pcs
+}
+
+// cacheOps 返回缓存, 由 WithContext 派生的对象共用原对象的缓存
+func (pcs *BaiduPCS) cacheOps() *cachemap.CacheOpMap {
+	root := pcs.root()
+	root.lazyInit()
+	return root.cacheOpMap
+}
+
+// QuotaInfoContext 同 QuotaInfo, 使用 ctx 发送请求
+func (pcs *BaiduPCS) QuotaInfoContext(ctx context.Context) (quota, used int64, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).QuotaInfo()
+}
+
+// FilesDirectoriesMetaContext 同 FilesDirectoriesMeta, 使用 ctx 发送请求
+func (pcs *BaiduPCS) FilesDirectoriesMetaContext(ctx context.Context, path string) (data *FileDirectory, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).FilesDirectoriesMeta(path)
+}
+
+// FilesDirectoriesBatchMetaContext 同 FilesDirectoriesBatchMeta, 使用 ctx 发送请求
+func (pcs *BaiduPCS) FilesDirectoriesBatchMetaContext(ctx context.Context, paths ...string) (data FileDirectoryList, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).FilesDirectoriesBatchMeta(paths...)
+}
+
+// FilesDirectoriesListContext 同 FilesDirectoriesList, 使用 ctx 发送请求
+func (pcs *BaiduPCS) FilesDirectoriesListContext(ctx context.Context, path string, options *OrderOptions) (data FileDirectoryList, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).FilesDirectoriesList(path, options)
+}
+
+// FilesDirectoriesRecurseListContext 同 FilesDirectoriesRecurseList, ctx 被取消时停止递归
+func (pcs *BaiduPCS) FilesDirectoriesRecurseListContext(ctx context.Context, path string, options *OrderOptions, handleFileDirectoryFunc HandleFileDirectoryFunc) (data FileDirectoryList) {
+	return pcs.WithContext(ctx).FilesDirectoriesRecurseList(path, options, handleFileDirectoryFunc)
+}
+
+// SearchContext 同 Search, 使用 ctx 发送请求
+func (pcs *BaiduPCS) SearchContext(ctx context.Context, targetPath, keyword string, recursive bool) (fdl FileDirectoryList, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).Search(targetPath, keyword, recursive)
+}
+
+// RemoveContext 同 Remove, 使用 ctx 发送请求
+func (pcs *BaiduPCS) RemoveContext(ctx context.Context, paths ...string) (pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).Remove(paths...)
+}
+
+// MkdirContext 同 Mkdir, 使用 ctx 发送请求
+func (pcs *BaiduPCS) MkdirContext(ctx context.Context, pcspath string) (pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).Mkdir(pcspath)
+}
+
+// RenameContext 同 Rename, 使用 ctx 发送请求
+func (pcs *BaiduPCS) RenameContext(ctx context.Context, from, to string) (pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).Rename(from, to)
+}
+
+// CopyContext 同 Copy, 使用 ctx 发送请求
+func (pcs *BaiduPCS) CopyContext(ctx context.Context, cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).Copy(cpmvJSON...)
+}
+
+// MoveContext 同 Move, 使用 ctx 发送请求
+func (pcs *BaiduPCS) MoveContext(ctx context.Context, cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).Move(cpmvJSON...)
+}
+
+// UploadTmpFileContext 同 UploadTmpFile, uploadFunc 使用 ctx 发送上传请求
+func (pcs *BaiduPCS) UploadTmpFileContext(ctx context.Context, uploadid, targetPath string, partseq int, partOffset int64, uploadFunc UploadContextFunc) (md5 string, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).UploadTmpFile(uploadid, targetPath, partseq, partOffset, func(uploadURL string, jar http.CookieJar) (*http.Response, error) {
+		return uploadFunc(ctx, uploadURL, jar)
+	})
+}
+
+// UploadCreateSuperFileContext 同 UploadCreateSuperFile, 使用 ctx 发送请求
+func (pcs *BaiduPCS) UploadCreateSuperFileContext(ctx context.Context, uploadid, policy string, fileSize int64, targetPath string, checksumMap map[int]string) (panError pcserror.Error) {
+	return pcs.WithContext(ctx).UploadCreateSuperFile(uploadid, policy, fileSize, targetPath, checksumMap)
+}
+
+// DownloadFileContext 同 DownloadFile, downloadFunc 使用 ctx 发送下载请求
+func (pcs *BaiduPCS) DownloadFileContext(ctx context.Context, path string, downloadFunc DownloadContextFunc) (err error) {
+	return pcs.WithContext(ctx).DownloadFile(path, func(downloadURL string, jar http.CookieJar) error {
+		return downloadFunc(ctx, downloadURL, jar)
+	})
+}
+
+// DownloadStreamFileContext 同 DownloadStreamFile, downloadFunc 使用 ctx 发送下载请求
+func (pcs *BaiduPCS) DownloadStreamFileContext(ctx context.Context, path string, downloadFunc DownloadContextFunc) (err error) {
+	return pcs.WithContext(ctx).DownloadStreamFile(path, func(downloadURL string, jar http.CookieJar) error {
+		return downloadFunc(ctx, downloadURL, jar)
+	})
+}
+
+// LocateDownloadContext 同 LocateDownload, 使用 ctx 发送请求
+func (pcs *BaiduPCS) LocateDownloadContext(ctx context.Context, pcspath string) (info *URLInfo, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).LocateDownload(pcspath)
+}
+
+// ShareSetContext 同 ShareSet, 使用 ctx 发送请求
+func (pcs *BaiduPCS) ShareSetContext(ctx context.Context, paths []string, option *ShareOption) (s *Shared, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).ShareSet(paths, option)
+}
+
+// ShareCancelContext 同 ShareCancel, 使用 ctx 发送请求
+func (pcs *BaiduPCS) ShareCancelContext(ctx context.Context, shareIDs []int64) (pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).ShareCancel(shareIDs)
+}
+
+// ShareListContext 同 ShareList, 使用 ctx 发送请求
+func (pcs *BaiduPCS) ShareListContext(ctx context.Context, page int) (records ShareRecordInfoList, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).ShareList(page)
+}
+
+// RecycleListContext 同 RecycleList, 使用 ctx 发送请求
+func (pcs *BaiduPCS) RecycleListContext(ctx context.Context, page int) (fdl RecycleFDInfoList, panError pcserror.Error) {
+	return pcs.WithContext(ctx).RecycleList(page)
+}
+
+// RecycleRestoreContext 同 RecycleRestore, 使用 ctx 发送请求
+func (pcs *BaiduPCS) RecycleRestoreContext(ctx context.Context, fidList ...int64) (sussFsIDList []*FsIDJSON, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).RecycleRestore(fidList...)
+}
+
+// RecycleDeleteContext 同 RecycleDelete, 使用 ctx 发送请求
+func (pcs *BaiduPCS) RecycleDeleteContext(ctx context.Context, fidList ...int64) (panError pcserror.Error) {
+	return pcs.WithContext(ctx).RecycleDelete(fidList...)
+}
+
+// RecycleClearContext 同 RecycleClear, 使用 ctx 发送请求
+func (pcs *BaiduPCS) RecycleClearContext(ctx context.Context) (sussNum int, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).RecycleClear()
+}
+
+// CloudDlAddTaskContext 同 CloudDlAddTask, 使用 ctx 发送请求
+func (pcs *BaiduPCS) CloudDlAddTaskContext(ctx context.Context, sourceURL, savePath string) (taskID int64, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).CloudDlAddTask(sourceURL, savePath)
+}
+
+// CloudDlQueryTaskContext 同 CloudDlQueryTask, 使用 ctx 发送请求
+func (pcs *BaiduPCS) CloudDlQueryTaskContext(ctx context.Context, taskIDs []int64) (cl CloudDlTaskList, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).CloudDlQueryTask(taskIDs)
+}
+
+// CloudDlListTaskContext 同 CloudDlListTask, 使用 ctx 发送请求
+func (pcs *BaiduPCS) CloudDlListTaskContext(ctx context.Context) (cl CloudDlTaskList, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).CloudDlListTask()
+}
+
+// CloudDlCancelTaskContext 同 CloudDlCancelTask, 使用 ctx 发送请求
+func (pcs *BaiduPCS) CloudDlCancelTaskContext(ctx context.Context, taskID int64) (pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).CloudDlCancelTask(taskID)
+}
+
+// CloudDlDeleteTaskContext 同 CloudDlDeleteTask, 使用 ctx 发送请求
+func (pcs *BaiduPCS) CloudDlDeleteTaskContext(ctx context.Context, taskID int64) (pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).CloudDlDeleteTask(taskID)
+}
+
+// CloudDlClearTaskContext 同 CloudDlClearTask, 使用 ctx 发送请求
+func (pcs *BaiduPCS) CloudDlClearTaskContext(ctx context.Context) (total int, pcsError pcserror.Error) {
+	return pcs.WithContext(ctx).CloudDlClearTask()
+}
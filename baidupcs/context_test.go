@@ -0,0 +1,42 @@
+package baidupcs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcstest"
+)
+
+type ctxKey struct{}
+
+func TestContextRequests(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, pcsError := pcs.FilesDirectoriesListContext(ctx, "/", nil); pcsError == nil || !errors.Is(pcsError, context.Canceled) {
+		t.Fatalf("list with canceled context: %v", pcsError)
+	}
+	if _, pcsError := pcs.FilesDirectoriesList("/", nil); pcsError != nil {
+		t.Fatalf("list after canceled context: %s", pcsError)
+	}
+
+	// 上传和下载的处理函数收到同一个 ctx
+	ctx = context.WithValue(context.Background(), ctxKey{}, "v")
+	var uploaded, downloaded bool
+	pcs.UploadTmpFileContext(ctx, "id", "/a", 0, 0, func(c context.Context, uploadURL string, jar http.CookieJar) (*http.Response, error) {
+		uploaded = c.Value(ctxKey{}) == "v"
+		return nil, context.Canceled
+	})
+	pcs.DownloadFileContext(ctx, "/a", func(c context.Context, downloadURL string, jar http.CookieJar) error {
+		downloaded = c.Value(ctxKey{}) == "v"
+		return nil
+	})
+	if !uploaded || !downloaded {
+		t.Fatalf("context not passed: upload %v, download %v", uploaded, downloaded)
+	}
+}
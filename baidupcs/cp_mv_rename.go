@@ -38,5 +38,13 @@ func (pcs *BaiduPCS) cpmvOp(op string, cpmvJSON ...*CpMvJSON) (pcsError pcserror
 
 	// 更新缓存
 	pcs.deleteCache((*CpMvJSONList)(unsafe.Pointer(&cpmvJSON)).AllRelatedDir())
+	paths := make([]string, 0, 2*len(cpmvJSON))
+	for _, cj := range cpmvJSON {
+		paths = append(paths, cj.To)
+		if op != OperationCopy {
+			paths = append(paths, cj.From)
+		}
+	}
+	pcs.deleteCacheTree(paths)
 	return nil
 }
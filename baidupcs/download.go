@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"errors"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
@@ -19,6 +20,9 @@ type (
 	// DownloadFunc 下载文件处理函数
 	DownloadFunc func(downloadURL string, jar http.CookieJar) error
 
+	// DownloadContextFunc 下载文件处理函数, 需要使用 ctx 发送请求
+	DownloadContextFunc func(ctx context.Context, downloadURL string, jar http.CookieJar) error
+
 	// URLInfo 下载链接详情
 	URLInfo struct {
 		URLs []struct {
@@ -110,7 +114,7 @@ func (pcs *BaiduPCS) DownloadFile(path string, downloadFunc DownloadFunc) (err e
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationDownloadFile, pcsURL)
 
-	return downloadFunc(pcsURL.String(), pcs.client.Jar)
+	return downloadFunc(pcs.rewriteURL(pcsURL.String()), pcs.client.Jar)
 }
 
 // DownloadStreamFile 下载流式文件
@@ -121,7 +125,7 @@ func (pcs *BaiduPCS) DownloadStreamFile(path string, downloadFunc DownloadFunc)
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationDownloadStreamFile, pcsURL)
 
-	return downloadFunc(pcsURL.String(), pcs.client.Jar)
+	return downloadFunc(pcs.rewriteURL(pcsURL.String()), pcs.client.Jar)
 }
 
 // LocateDownloadWithUserAgent 获取下载链接
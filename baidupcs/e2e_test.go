@@ -0,0 +1,437 @@
+package baidupcs_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires/cachemap"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/governor"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcstest"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestE2EFileOperations(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	srv.WriteFile("/docs/a.txt", []byte("aaa"))
+	srv.WriteFile("/docs/b.txt", []byte("b"))
+	srv.WriteFile("/docs/sub/c.log", []byte("cc"))
+
+	if pcsError := pcs.Mkdir("/empty"); pcsError != nil {
+		t.Fatalf("Mkdir: %s", pcsError)
+	}
+	if pcsError := pcs.Mkdir("/empty"); pcsError == nil || pcsError.GetRemoteErrCode() != 31061 {
+		t.Fatalf("Mkdir existing: got %v", pcsError)
+	}
+
+	list, pcsError := pcs.FilesDirectoriesList("/docs", &baidupcs.OrderOptions{By: baidupcs.OrderBySize, Order: baidupcs.OrderDesc})
+	if pcsError != nil {
+		t.Fatalf("List: %s", pcsError)
+	}
+	if got := strings.Join(list.AllFilePaths(), ","); got != "/docs/sub,/docs/a.txt,/docs/b.txt" {
+		t.Fatalf("List: got %s", got)
+	}
+
+	fd, pcsError := pcs.FilesDirectoriesMeta("/docs/a.txt")
+	if pcsError != nil {
+		t.Fatalf("Meta: %s", pcsError)
+	}
+	if fd.Size != 3 || fd.MD5 != md5Hex([]byte("aaa")) || fd.Isdir {
+		t.Fatalf("Meta: unexpected %+v", fd)
+	}
+	if _, pcsError = pcs.FilesDirectoriesMeta("/nope"); pcsError == nil || pcsError.GetRemoteErrCode() != 31066 {
+		t.Fatalf("Meta missing: got %v", pcsError)
+	}
+
+	found, pcsError := pcs.Search("/docs", "c", true)
+	if pcsError != nil || len(found) != 1 || found[0].Path != "/docs/sub/c.log" {
+		t.Fatalf("Search: %v, %v", found, pcsError)
+	}
+
+	if pcsError = pcs.Copy(&baidupcs.CpMvJSON{From: "/docs/sub", To: "/backup/sub"}); pcsError != nil {
+		t.Fatalf("Copy: %s", pcsError)
+	}
+	if pcsError = pcs.Rename("/docs/b.txt", "/docs/b2.txt"); pcsError != nil {
+		t.Fatalf("Rename: %s", pcsError)
+	}
+	if pcsError = pcs.Move(&baidupcs.CpMvJSON{From: "/docs/a.txt", To: "/empty/a.txt"}); pcsError != nil {
+		t.Fatalf("Move: %s", pcsError)
+	}
+	for p, want := range map[string]bool{
+		"/backup/sub/c.log": true,
+		"/docs/sub/c.log":   true,
+		"/docs/b2.txt":      true,
+		"/docs/b.txt":       false,
+		"/empty/a.txt":      true,
+		"/docs/a.txt":       false,
+	} {
+		if srv.Exists(p) != want {
+			t.Fatalf("after copy/move: exists(%s) != %v", p, want)
+		}
+	}
+
+	// 删除和回收站
+	if pcsError = pcs.Remove("/docs"); pcsError != nil {
+		t.Fatalf("Remove: %s", pcsError)
+	}
+	if srv.Exists("/docs/sub/c.log") {
+		t.Fatalf("Remove: file still exists")
+	}
+	recycled, pcsError := pcs.RecycleList(1)
+	if pcsError != nil || len(recycled) != 1 || recycled[0].Path != "/docs" {
+		t.Fatalf("RecycleList: %v, %v", recycled, pcsError)
+	}
+	if _, pcsError = pcs.RecycleRestore(recycled[0].FsID); pcsError != nil {
+		t.Fatalf("RecycleRestore: %s", pcsError)
+	}
+	if data, ok := srv.ReadFile("/docs/sub/c.log"); !ok || string(data) != "cc" {
+		t.Fatalf("RecycleRestore: file not restored")
+	}
+	pcs.Remove("/backup", "/empty")
+	if n, pcsError := pcs.RecycleClear(); pcsError != nil || n != 2 {
+		t.Fatalf("RecycleClear: %d, %v", n, pcsError)
+	}
+
+	quota, used, pcsError := pcs.QuotaInfo()
+	if pcsError != nil || quota != pcstest.DefaultQuota || used != 3 {
+		t.Fatalf("QuotaInfo: %d, %d, %v", quota, used, pcsError)
+	}
+}
+
+func TestE2ELocateDownload(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	data := make([]byte, 1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	srv.WriteFile("/data.bin", data)
+
+	// locatedownload 返回的链接支持 Range
+	info, pcsError := pcs.LocateDownload("/data.bin")
+	if pcsError != nil {
+		t.Fatalf("LocateDownload: %s", pcsError)
+	}
+	u := info.SingleURL(false)
+	if u == nil {
+		t.Fatalf("LocateDownload: no url")
+	}
+	resp, err := requester.NewHTTPClient().Req(http.MethodGet, u.String(), nil, map[string]string{
+		"Range": "bytes=100-199",
+	})
+	if err != nil {
+		t.Fatalf("fetch dlink: %s", err)
+	}
+	defer resp.Body.Close()
+	ranged, _ := io.ReadAll(resp.Body)
+	if !bytes.Equal(ranged, data[100:200]) {
+		t.Fatalf("fetch dlink: range mismatch")
+	}
+}
+
+func TestE2EShareAndCloudDl(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	srv.WriteFile("/share/a.txt", []byte("a"))
+	shared, pcsError := pcs.ShareSet([]string{"/share/a.txt"}, &baidupcs.ShareOption{Password: "abcd"})
+	if pcsError != nil || shared.Link == "" {
+		t.Fatalf("ShareSet: %v, %v", shared, pcsError)
+	}
+	records, pcsError := pcs.ShareList(1)
+	if pcsError != nil || len(records) != 1 || records[0].ShareID != shared.ShareID {
+		t.Fatalf("ShareList: %v, %v", records, pcsError)
+	}
+	info, pcsError := pcs.ShareSURLInfo(shared.ShareID)
+	if pcsError != nil || info.Pwd != "abcd" {
+		t.Fatalf("ShareSURLInfo: %v, %v", info, pcsError)
+	}
+	if pcsError = pcs.ShareCancel([]int64{shared.ShareID}); pcsError != nil {
+		t.Fatalf("ShareCancel: %s", pcsError)
+	}
+	if records, _ = pcs.ShareList(1); len(records) != 0 {
+		t.Fatalf("ShareCancel: share still listed")
+	}
+
+	taskID, pcsError := pcs.CloudDlAddTask("http://example.com/file.iso", "/dl")
+	if pcsError != nil {
+		t.Fatalf("CloudDlAddTask: %s", pcsError)
+	}
+	if !srv.FinishCloudDl(taskID, []byte("iso")) {
+		t.Fatalf("FinishCloudDl failed")
+	}
+	tasks, pcsError := pcs.CloudDlQueryTask([]int64{taskID})
+	if pcsError != nil || len(tasks) != 1 || tasks[0].Status != 0 || tasks[0].FileSize != 3 {
+		t.Fatalf("CloudDlQueryTask: %v, %v", tasks, pcsError)
+	}
+	if data, ok := srv.ReadFile("/dl/file.iso"); !ok || string(data) != "iso" {
+		t.Fatalf("cloud_dl: file not saved")
+	}
+	if n, pcsError := pcs.CloudDlClearTask(); pcsError != nil || n != 1 {
+		t.Fatalf("CloudDlClearTask: %d, %v", n, pcsError)
+	}
+	if pcsError = pcs.CloudDlDeleteTask(taskID); pcsError == nil {
+		t.Fatalf("CloudDlDeleteTask: expected error for cleared task")
+	}
+}
+
+func TestE2EStorageSync(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	src := storage.NewMemory()
+	src.WriteFile("/photos/1.jpg", strings.NewReader("one"), 3)
+	src.WriteFile("/photos/2020/2.jpg", strings.NewReader("two"), 3)
+
+	n, err := storage.Sync(pcs, "/backup", src, "/photos", nil)
+	if err != nil || n != 2 {
+		t.Fatalf("Sync: %d, %v", n, err)
+	}
+	if data, ok := srv.ReadFile("/backup/2020/2.jpg"); !ok || string(data) != "two" {
+		t.Fatalf("Sync: file not uploaded")
+	}
+
+	rc, pcsError := pcs.OpenRange("/backup/1.jpg", 1, 2)
+	if pcsError != nil {
+		t.Fatalf("OpenRange: %s", pcsError)
+	}
+	defer rc.Close()
+	if data, _ := io.ReadAll(rc); string(data) != "ne" {
+		t.Fatalf("OpenRange: got %q", data)
+	}
+}
+
+func TestE2EOpenRangeNotRateLimited(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+	g := governor.New(nil, time.Minute)
+	pcs.SetGovernor(g)
+
+	// 文件内容与频率限制的响应相同, 不应触发冷却
+	content := `{"error_code":31034,"error_msg":"hit frequence limit"}`
+	srv.WriteFile("/limit.json", []byte(content))
+	rc, pcsError := pcs.OpenRange("/limit.json", 0, -1)
+	if pcsError != nil {
+		t.Fatalf("OpenRange: %s", pcsError)
+	}
+	defer rc.Close()
+	if data, _ := io.ReadAll(rc); string(data) != content {
+		t.Fatalf("OpenRange: got %q", data)
+	}
+	if _, ok := g.CoolingDown(); ok {
+		t.Fatalf("OpenRange: file content reported as rate limited")
+	}
+}
+
+func TestE2ERecycleListAll(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	n := baidupcs.RecycleListPageSize + 20
+	paths := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		p := fmt.Sprintf("/trash/%03d.txt", i)
+		srv.WriteFile(p, []byte("x"))
+		paths = append(paths, p)
+	}
+	if pcsError := pcs.Remove(paths...); pcsError != nil {
+		t.Fatalf("Remove: %s", pcsError)
+	}
+
+	all, pcsError := pcs.RecycleListAll()
+	if pcsError != nil || len(all) != n {
+		t.Fatalf("RecycleListAll: %d, %v", len(all), pcsError)
+	}
+	if got := srv.Requests("api/recycle/list"); got != 2 {
+		t.Errorf("RecycleListAll: expected 2 requests, got %d", got)
+	}
+}
+
+func TestE2EFilesDirectoriesDiff(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	n := pcstest.DiffPageSize + 20
+	for i := 0; i < n; i++ {
+		srv.WriteFile(fmt.Sprintf("/d/%03d.txt", i), []byte("x"))
+	}
+
+	// 获取全部变更
+	diffAll := func(cursor string) (entries []*baidupcs.FileDiffEntry, next string) {
+		for {
+			diff, pcsError := pcs.FilesDirectoriesDiff(cursor)
+			if pcsError != nil {
+				t.Fatalf("FilesDirectoriesDiff: %s", pcsError)
+			}
+			entries = append(entries, diff.Entries...)
+			cursor = diff.Cursor
+			if !diff.HasMore {
+				return entries, cursor
+			}
+		}
+	}
+
+	entries, cursor := diffAll("")
+	if len(entries) != n+1 {
+		t.Fatalf("expected %d entries, got %d", n+1, len(entries))
+	}
+	if got := srv.Requests("api/batch/filediff"); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+	var moved *baidupcs.FileDiffEntry
+	for _, e := range entries {
+		if e.Path == "/d/000.txt" {
+			moved = e
+		}
+	}
+	if moved == nil || moved.Isdir || moved.Size != 1 || moved.MD5 != md5Hex([]byte("x")) {
+		t.Fatalf("unexpected entry %+v", moved)
+	}
+
+	if pcsError := pcs.Rename("/d/000.txt", "/d/moved.txt"); pcsError != nil {
+		t.Fatalf("Rename: %s", pcsError)
+	}
+	if pcsError := pcs.Remove("/d/001.txt"); pcsError != nil {
+		t.Fatalf("Remove: %s", pcsError)
+	}
+	srv.WriteFile("/e/new.txt", []byte("new"))
+
+	entries, _ = diffAll(cursor)
+	got := make([]string, 0, len(entries))
+	for _, e := range entries {
+		got = append(got, fmt.Sprintf("%s %v", e.Path, e.IsDelete))
+		if e.Path == "/d/moved.txt" && e.FsID != moved.FsID {
+			t.Errorf("moved file has new fs_id %d", e.FsID)
+		}
+	}
+	expected := "[/d/001.txt true /d/moved.txt false /e false /e/new.txt false]"
+	if fmt.Sprint(got) != expected {
+		t.Errorf("got %v, expected %s", got, expected)
+	}
+}
+
+func TestE2EDiskCache(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	srv.WriteFile("/d/a.txt", []byte("a"))
+	srv.WriteFile("/d/sub/b.txt", []byte("b"))
+
+	dir := t.TempDir()
+	// 每个 BaiduPCS 对象相当于一个进程, 共用磁盘缓存
+	newPCS := func() *baidupcs.BaiduPCS {
+		pcs := srv.NewPCS()
+		pcs.SetDiskCache(cachemap.NewDiskCache(dir, map[string]time.Duration{
+			baidupcs.OperationFilesDirectoriesList: time.Minute,
+		}))
+		return pcs
+	}
+	list := func(pcs *baidupcs.BaiduPCS, p string) (names []string) {
+		fdl, pcsError := pcs.CacheFilesDirectoriesList(p, baidupcs.DefaultOrderOptions)
+		if pcsError != nil {
+			t.Fatalf("CacheFilesDirectoriesList: %s", pcsError)
+		}
+		for _, fd := range fdl {
+			names = append(names, fd.Filename)
+		}
+		return
+	}
+
+	list(newPCS(), "/d")
+	list(newPCS(), "/d/sub")
+	if got := strings.Join(list(newPCS(), "/d"), ","); got != "sub,a.txt" {
+		t.Errorf("unexpected list %s", got)
+	}
+	if got := srv.Requests("pcs/file/list"); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+
+	// 修改后其他进程不再读到旧的缓存
+	if pcsError := newPCS().Remove("/d/a.txt"); pcsError != nil {
+		t.Fatalf("Remove: %s", pcsError)
+	}
+	if got := strings.Join(list(newPCS(), "/d"), ","); got != "sub" {
+		t.Errorf("unexpected list after remove %s", got)
+	}
+	if got := strings.Join(list(newPCS(), "/d/sub"), ","); got != "b.txt" || srv.Requests("pcs/file/list") != 3 {
+		t.Errorf("unrelated cache invalidated: %s", got)
+	}
+
+	// 移动目录使子目录的缓存失效
+	pcs := newPCS()
+	if pcsError := pcs.Rename("/d", "/e"); pcsError != nil {
+		t.Fatalf("Rename: %s", pcsError)
+	}
+	if _, pcsError := pcs.CacheFilesDirectoriesList("/d/sub", baidupcs.DefaultOrderOptions); pcsError == nil {
+		t.Error("expected error listing moved directory")
+	}
+
+	// 同一目录的各种排序保存在一个缓存文件中
+	byTime := &baidupcs.OrderOptions{By: baidupcs.OrderByTime, Order: baidupcs.OrderDesc}
+	if _, pcsError := newPCS().CacheFilesDirectoriesList("/e", byTime); pcsError != nil {
+		t.Fatalf("CacheFilesDirectoriesList: %s", pcsError)
+	}
+	list(newPCS(), "/e")
+	if _, stats, _ := pcs.DiskCacheStats(); len(stats) != 1 || stats[0].Entries != 1 {
+		t.Errorf("expected 1 cache entry for /e, got %+v", stats)
+	}
+
+	// 上传时新建的上级目录也要更新
+	list(newPCS(), "/")
+	if pcsError := newPCS().WriteFile("/f/g/h.txt", strings.NewReader("h"), 1); pcsError != nil {
+		t.Fatalf("WriteFile: %s", pcsError)
+	}
+	if got := strings.Join(list(newPCS(), "/"), ","); got != "e,f" {
+		t.Errorf("unexpected list after upload %s", got)
+	}
+
+	// 从回收站还原后更新原来的上级目录
+	if pcsError := newPCS().Remove("/f/g"); pcsError != nil {
+		t.Fatalf("Remove: %s", pcsError)
+	}
+	if got := strings.Join(list(newPCS(), "/f"), ","); got != "" {
+		t.Errorf("unexpected list after remove %s", got)
+	}
+	pcs = newPCS()
+	rfdl, pcsError := pcs.RecycleList(1)
+	if pcsError != nil {
+		t.Fatalf("RecycleList: %s", pcsError)
+	}
+	for _, rfd := range rfdl {
+		if rfd.Path != "/f/g" {
+			continue
+		}
+		if _, pcsError = pcs.RecycleRestore(rfd.FsID); pcsError != nil {
+			t.Fatalf("RecycleRestore: %s", pcsError)
+		}
+	}
+	if got := strings.Join(list(newPCS(), "/f"), ","); got != "g" {
+		t.Errorf("unexpected list after restore %s", got)
+	}
+
+	n, err := pcs.ClearCache()
+	if err != nil || n != 2 {
+		t.Errorf("ClearCache: %d, %v", n, err)
+	}
+}
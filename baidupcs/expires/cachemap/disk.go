@@ -0,0 +1,285 @@
+package cachemap
+
+import (
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// DiskCache 磁盘缓存, 每个缓存项保存为一个文件, 多个进程可以共用同一目录.
+	// 写入时先写临时文件再重命名, 读取时不会读到写了一半的数据.
+	// 以 / 开头的键视为网盘路径, 按路径分层保存, 删除一个目录及其子目录的缓存时不需要遍历全部缓存.
+	// 方法对 nil 也有效, 相当于不缓存.
+	DiskCache struct {
+		dir string
+		ttl map[string]time.Duration // 各操作的缓存时间, 没有设置的操作不缓存
+	}
+
+	// DiskCacheStat 磁盘缓存的统计信息
+	DiskCacheStat struct {
+		Op      string // 操作
+		Entries int    // 有效的缓存项数量
+		Expired int    // 已过期的缓存项数量
+		Size    int64  // 占用的空间
+	}
+
+	// diskEntryHeader 缓存文件的头部, 之后为缓存的数据
+	diskEntryHeader struct {
+		Op        string
+		Key       string
+		ExpiresAt time.Time
+	}
+)
+
+const (
+	tmpPrefix = ".tmp-"
+	// treeEntryName 路径键的缓存文件名, 与各级目录名的散列长度不同, 不会冲突
+	treeEntryName = "entry"
+	treePrefix    = "tree-"
+)
+
+// NewDiskCache 返回磁盘缓存, ttl 为各操作的缓存时间
+func NewDiskCache(dir string, ttl map[string]time.Duration) *DiskCache {
+	return &DiskCache{
+		dir: dir,
+		ttl: ttl,
+	}
+}
+
+// Dir 返回缓存目录
+func (dc *DiskCache) Dir() string {
+	if dc == nil {
+		return ""
+	}
+	return dc.dir
+}
+
+// TTL 返回操作的缓存时间, 为0则不缓存
+func (dc *DiskCache) TTL(op string) time.Duration {
+	if dc == nil {
+		return 0
+	}
+	return dc.ttl[op]
+}
+
+func (dc *DiskCache) filename(op, key string) string {
+	if strings.HasPrefix(key, "/") {
+		return filepath.Join(dc.treeDir(op, key), treeEntryName)
+	}
+	sum := sha1.Sum([]byte(op + "\x00" + key))
+	return filepath.Join(dc.dir, hex.EncodeToString(sum[:]))
+}
+
+// treeDir 返回路径 p 对应的目录, 每一级使用文件名的散列,
+// 避免文件名中的特殊字符, 以及不区分大小写的文件系统上的冲突
+func (dc *DiskCache) treeDir(op, p string) string {
+	elems := []string{dc.dir, treePrefix + shortHash(op)}
+	for _, name := range strings.Split(path.Clean(p), "/") {
+		if name != "" {
+			elems = append(elems, shortHash(name))
+		}
+	}
+	return filepath.Join(elems...)
+}
+
+func shortHash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Load 读取缓存到 v, 返回过期时间, 缓存不存在或已过期时 ok 为 false
+func (dc *DiskCache) Load(op, key string, v interface{}) (expiresAt time.Time, ok bool) {
+	if dc.TTL(op) <= 0 {
+		return
+	}
+	f, err := os.Open(dc.filename(op, key))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var (
+		dec = gob.NewDecoder(f)
+		hdr diskEntryHeader
+	)
+	err = dec.Decode(&hdr)
+	if err != nil || hdr.Op != op || hdr.Key != key || !time.Now().Before(hdr.ExpiresAt) {
+		return
+	}
+	if dec.Decode(v) != nil {
+		return
+	}
+	return hdr.ExpiresAt, true
+}
+
+// Store 保存缓存, 过期时间由操作的缓存时间决定
+func (dc *DiskCache) Store(op, key string, v interface{}) error {
+	return dc.StoreExpiresAt(op, key, v, time.Now().Add(dc.TTL(op)))
+}
+
+// StoreExpiresAt 保存缓存, 在 expiresAt 过期, 用于更新缓存而不延长过期时间.
+// 没有设置缓存时间的操作不保存.
+func (dc *DiskCache) StoreExpiresAt(op, key string, v interface{}, expiresAt time.Time) error {
+	if dc.TTL(op) <= 0 || !time.Now().Before(expiresAt) {
+		return nil
+	}
+	filename := dc.filename(op, key)
+	err := os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(filename), tmpPrefix)
+	if err != nil {
+		return err
+	}
+	enc := gob.NewEncoder(f)
+	err = enc.Encode(&diskEntryHeader{
+		Op:        op,
+		Key:       key,
+		ExpiresAt: expiresAt,
+	})
+	if err == nil {
+		err = enc.Encode(v)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		// 重命名是原子操作, 其他进程读到的要么是旧数据, 要么是新数据
+		err = os.Rename(f.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Delete 删除缓存, 路径键只删除 key 本身, 不删除其子目录的缓存
+func (dc *DiskCache) Delete(op, key string) {
+	if dc == nil {
+		return
+	}
+	os.Remove(dc.filename(op, key))
+}
+
+// DeleteTree 删除操作 op 中路径 p 及其子目录的缓存, 只访问 p 之下的缓存文件
+func (dc *DiskCache) DeleteTree(op, p string) {
+	if dc == nil {
+		return
+	}
+	os.RemoveAll(dc.treeDir(op, p))
+}
+
+// readHeader 读取缓存文件的头部
+func readHeader(filename string) (hdr diskEntryHeader, err error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	err = gob.NewDecoder(f).Decode(&hdr)
+	return
+}
+
+// entries 遍历 root 下的缓存文件, 跳过正在写入的临时文件
+func (dc *DiskCache) entries(root string, fn func(filename string, info os.FileInfo)) error {
+	if dc == nil {
+		return nil
+	}
+	return filepath.WalkDir(root, func(filename string, de fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				// 已被其他进程删除
+				return nil
+			}
+			return err
+		}
+		if de.IsDir() || strings.HasPrefix(de.Name(), tmpPrefix) {
+			return nil
+		}
+		info, err := de.Info()
+		if err != nil {
+			return nil
+		}
+		fn(filename, info)
+		return nil
+	})
+}
+
+// Clear 删除全部缓存, 包括残留的临时文件, 返回删除的缓存项数量
+func (dc *DiskCache) Clear() (n int, err error) {
+	if dc == nil {
+		return 0, nil
+	}
+	des, err := os.ReadDir(dc.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	for _, de := range des {
+		filename := filepath.Join(dc.dir, de.Name())
+		if de.IsDir() {
+			if !strings.HasPrefix(de.Name(), treePrefix) {
+				continue
+			}
+			dc.entries(filename, func(string, os.FileInfo) {
+				n++
+			})
+			rmErr := os.RemoveAll(filename)
+			if rmErr != nil {
+				err = rmErr
+			}
+			continue
+		}
+		rmErr := os.Remove(filename)
+		if rmErr != nil && !os.IsNotExist(rmErr) {
+			err = rmErr
+			continue
+		}
+		if !strings.HasPrefix(de.Name(), tmpPrefix) {
+			n++
+		}
+	}
+	return n, err
+}
+
+// Stats 按操作统计缓存, 按操作名排序
+func (dc *DiskCache) Stats() (stats []*DiskCacheStat, err error) {
+	var (
+		now  = time.Now()
+		byOp = map[string]*DiskCacheStat{}
+	)
+	err = dc.entries(dc.Dir(), func(filename string, info os.FileInfo) {
+		hdr, err := readHeader(filename)
+		if err != nil {
+			return
+		}
+		stat := byOp[hdr.Op]
+		if stat == nil {
+			stat = &DiskCacheStat{Op: hdr.Op}
+			byOp[hdr.Op] = stat
+			stats = append(stats, stat)
+		}
+		if now.Before(hdr.ExpiresAt) {
+			stat.Entries++
+		} else {
+			stat.Expired++
+		}
+		stat.Size += info.Size()
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Op < stats[j].Op
+	})
+	return
+}
@@ -0,0 +1,116 @@
+package cachemap_test
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires/cachemap"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	dc := cachemap.NewDiskCache(dir, map[string]time.Duration{
+		"list": time.Minute,
+		"uk":   time.Millisecond,
+	})
+
+	if err := dc.Store("list", "/a", []string{"x", "y"}); err != nil {
+		t.Fatal(err)
+	}
+	dc.Store("list", "/a/b", []string{"z"})
+	dc.Store("list", "/ab", []string{})
+	dc.Store("list", "/c", []string{})
+	dc.Store("uk", "bduss", int64(1))
+	dc.Store("other", "key", 1) // 没有设置缓存时间, 不缓存
+
+	// 另一个进程打开同一目录
+	other := cachemap.NewDiskCache(dir, map[string]time.Duration{"list": time.Minute})
+	var list []string
+	if _, ok := other.Load("list", "/a", &list); !ok || strings.Join(list, ",") != "x,y" {
+		t.Errorf("unexpected list %v, %v", list, ok)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	var uk int64
+	if _, ok := dc.Load("uk", "bduss", &uk); ok {
+		t.Error("expired entry loaded")
+	}
+
+	stats, err := dc.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || stats[0].Op != "list" || stats[0].Entries != 4 || stats[1].Expired != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// 删除 /a 及其子目录的缓存
+	dc.DeleteTree("list", "/a")
+	if _, ok := dc.Load("list", "/a/b", &list); ok {
+		t.Error("deleted entry loaded")
+	}
+	for _, key := range []string{"/ab", "/c"} {
+		if _, ok := dc.Load("list", key, &list); !ok {
+			t.Errorf("unrelated entry %s deleted", key)
+		}
+	}
+	if stats, _ = dc.Stats(); len(stats) != 2 || stats[0].Entries != 2 || stats[1].Expired != 1 {
+		t.Errorf("unexpected stats after delete %+v", stats)
+	}
+
+	// 只删除 /c 本身
+	dc.Store("list", "/c/d", []string{})
+	dc.Delete("list", "/c")
+	if _, ok := dc.Load("list", "/c/d", &list); !ok {
+		t.Error("child entry deleted")
+	}
+
+	n, err := dc.Clear()
+	if err != nil || n != 3 {
+		t.Errorf("unexpected clear result %d, %v", n, err)
+	}
+
+	var nilCache *cachemap.DiskCache
+	nilCache.Store("list", "/a", list)
+	if _, ok := nilCache.Load("list", "/a", &list); ok {
+		t.Error("nil cache loaded")
+	}
+}
+
+func TestDiskCacheConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// 模拟多个进程同时读写同一缓存项
+			dc := cachemap.NewDiskCache(dir, map[string]time.Duration{"list": time.Minute})
+			for j := 0; j < 50; j++ {
+				dc.Store("list", "/a", fmt.Sprintf("value %d %d", i, j))
+				var v string
+				if _, ok := dc.Load("list", "/a", &v); ok && !strings.HasPrefix(v, "value ") {
+					t.Errorf("corrupted value %q", v)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// 不应残留临时文件
+	n := 0
+	err := filepath.WalkDir(dir, func(_ string, de fs.DirEntry, err error) error {
+		if err == nil && !de.IsDir() {
+			n++
+		}
+		return err
+	})
+	if err != nil || n != 1 {
+		t.Errorf("expected one cache file, got %d, %v", n, err)
+	}
+}
@@ -7,19 +7,16 @@ import (
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/cachepool"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
-	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/escaper"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester/downloader"
 	"io"
 	"mime"
 	"net/http"
-	"path"
 	"strconv"
-	"strings"
 )
 
 const (
 	// ShellPatternCharacters 通配符字符串
-	ShellPatternCharacters = "*?[]"
+	ShellPatternCharacters = "*?[]{}"
 )
 
 var (
@@ -139,7 +136,7 @@ func (pcs *BaiduPCS) GetRapidUploadInfoByLink(link string, compareRInfo *RapidUp
 		header["Range"] = "bytes=0-" + strconv.FormatInt(SliceMD5Size-1, 10)
 	}
 
-	resp, err := pcs.client.Req(http.MethodGet, link, nil, header)
+	resp, err := pcs.client.ReqWithContext(pcs.Context(), http.MethodGet, link, nil, header)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -327,51 +324,3 @@ func (pcs *BaiduPCS) FixMD5(pcspath string) (pcsError pcserror.Error) {
 
 	return pcs.FixMD5ByFileInfo(finfo)
 }
-
-func (pcs *BaiduPCS) recurseMatchPathByShellPattern(index int, patternSlice *[]string, ps *[]string, pcspaths *[]string) {
-	if index == len(*patternSlice) {
-		*pcspaths = append(*pcspaths, strings.Join(*ps, PathSeparator))
-		return
-	}
-
-	if !strings.ContainsAny((*patternSlice)[index], ShellPatternCharacters) {
-		(*ps)[index] = (*patternSlice)[index]
-		pcs.recurseMatchPathByShellPattern(index+1, patternSlice, ps, pcspaths)
-		return
-	}
-
-	fds, pcsError := pcs.FilesDirectoriesList(strings.Join((*ps)[:index], PathSeparator), DefaultOrderOptions)
-	if pcsError != nil {
-		panic(pcsError) // 抛出异常
-	}
-
-	for k := range fds {
-		if matched, _ := path.Match((*patternSlice)[index], fds[k].Filename); matched {
-			(*ps)[index] = fds[k].Filename
-			pcs.recurseMatchPathByShellPattern(index+1, patternSlice, ps, pcspaths)
-		}
-	}
-	return
-}
-
-// MatchPathByShellPattern 通配符匹配文件路径, pattern 为绝对路径
-func (pcs *BaiduPCS) MatchPathByShellPattern(pattern string) (pcspaths []string, pcsError pcserror.Error) {
-	errInfo := pcserror.NewPCSErrorInfo(OperationMatchPathByShellPattern)
-	errInfo.ErrType = pcserror.ErrTypeOthers
-
-	patternSlice := strings.Split(escaper.Escape(path.Clean(pattern), []rune{'['}), PathSeparator) // 转义中括号
-	if patternSlice[0] != "" {
-		errInfo.Err = ErrMatchPathByShellPatternNotAbsPath
-		return nil, errInfo
-	}
-
-	ps := make([]string, len(patternSlice))
-	defer func() { // 捕获异常
-		if err := recover(); err != nil {
-			pcspaths = nil
-			pcsError = err.(pcserror.Error)
-		}
-	}()
-	pcs.recurseMatchPathByShellPattern(1, &patternSlice, &ps, &pcspaths)
-	return pcspaths, nil
-}
@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/olekukonko/tablewriter"
@@ -74,7 +75,9 @@ type (
 		IsdirInt       int8  `json:"isdir"`
 		IfhassubdirInt int8  `json:"ifhassubdir"`
 
-		// 对齐
+		// 对齐, 与 FileDirectory 的 PreBase, Parent, Children 对应.
+		// fdJSON 会被转换为 FileDirectory 使用, 缺少 PreBase 时写入 Children 会越界
+		_ string
 		_ *fdJSON
 		_ []*fdJSON
 	}
@@ -210,8 +213,16 @@ func (pcs *BaiduPCS) Search(targetPath, keyword string, recursive bool) (fdl Fil
 	return
 }
 
-func (pcs *BaiduPCS) recurseList(path string, depth int, options *OrderOptions, prebase string, handleFileDirectoryFunc HandleFileDirectoryFunc) (fdl FileDirectoryList, ok bool) {
-	fdl, pcsError := pcs.FilesDirectoriesList(path, options)
+func recurseList(s Storage, path string, depth int, options *OrderOptions, prebase string, handleFileDirectoryFunc HandleFileDirectoryFunc) (fdl FileDirectoryList, ok bool) {
+	if err := storageContext(s).Err(); err != nil {
+		// 已取消, 停止递归
+		errInfo := pcserror.NewPCSErrorInfo(OperationFilesDirectoriesList)
+		errInfo.SetNetError(err)
+		handleFileDirectoryFunc(depth, path, nil, errInfo)
+		return nil, false
+	}
+
+	fdl, pcsError := s.FilesDirectoriesList(path, options)
 	if pcsError != nil {
 		ok := handleFileDirectoryFunc(depth, path, nil, pcsError) // 传递错误
 		return nil, ok
@@ -228,7 +239,7 @@ func (pcs *BaiduPCS) recurseList(path string, depth int, options *OrderOptions,
 			continue
 		}
 
-		fdl[k].Children, ok = pcs.recurseList(fdl[k].Path, depth+1, options, filepath.Join(prebase, filepath.Base(fdl[k].Path)), handleFileDirectoryFunc)
+		fdl[k].Children, ok = recurseList(s, fdl[k].Path, depth+1, options, filepath.Join(prebase, filepath.Base(fdl[k].Path)), handleFileDirectoryFunc)
 		if !ok {
 			return
 		}
@@ -239,7 +250,12 @@ func (pcs *BaiduPCS) recurseList(path string, depth int, options *OrderOptions,
 
 // FilesDirectoriesRecurseList 递归获取目录下的文件和目录列表
 func (pcs *BaiduPCS) FilesDirectoriesRecurseList(path string, options *OrderOptions, handleFileDirectoryFunc HandleFileDirectoryFunc) (data FileDirectoryList) {
-	fd, pcsError := pcs.FilesDirectoriesMeta(path)
+	return StorageRecurseList(pcs, path, options, handleFileDirectoryFunc)
+}
+
+// StorageRecurseList 递归获取存储 s 中目录下的文件和目录列表
+func StorageRecurseList(s Storage, path string, options *OrderOptions, handleFileDirectoryFunc HandleFileDirectoryFunc) (data FileDirectoryList) {
+	fd, pcsError := s.FilesDirectoriesMeta(path)
 	if pcsError != nil {
 		handleFileDirectoryFunc(0, path, nil, pcsError) // 传递错误
 		return nil
@@ -252,7 +268,7 @@ func (pcs *BaiduPCS) FilesDirectoriesRecurseList(path string, options *OrderOpti
 		handleFileDirectoryFunc(0, path, fd, nil)
 	}
 
-	data, _ = pcs.recurseList(path, 0, options, filepath.Base(path), handleFileDirectoryFunc)
+	data, _ = recurseList(s, path, 0, options, filepath.Base(path), handleFileDirectoryFunc)
 	return data
 }
 
@@ -266,6 +282,27 @@ func (f *FileDirectory) fixMD5() {
 	f.MD5 = f.BlockList[0]
 }
 
+// MD5Reliable 判断服务器返回的 md5 是否可信: 解密后为有效的 md5, 且不是分片上传的文件
+func (f *FileDirectory) MD5Reliable() bool {
+	return MD5Reliable(f.MD5, f.BlockList)
+}
+
+// MD5Reliable 判断 md5 是否可信. 分片上传的文件 (block_list 有多个分片,
+// 或唯一的分片与 md5 不同), 网盘记录的 md5 可能不正确
+func MD5Reliable(md5 string, blockList []string) bool {
+	md5 = DecryptMD5(md5)
+	if _, err := hex.DecodeString(md5); err != nil || len(md5) != 32 {
+		return false
+	}
+	switch len(blockList) {
+	case 0:
+		return true
+	case 1:
+		return strings.EqualFold(DecryptMD5(blockList[0]), md5)
+	}
+	return false
+}
+
 func (f *FileDirectory) String() string {
 	builder := &strings.Builder{}
 	tb := pcstable.NewTable(builder)
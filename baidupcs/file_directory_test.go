@@ -0,0 +1,26 @@
+package baidupcs
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fdJSON 通过 unsafe.Pointer 转换为 FileDirectory 使用, 两者的内存布局必须一致
+func TestFdJSONLayout(t *testing.T) {
+	var (
+		jt = reflect.TypeOf(fdJSON{})
+		ft = reflect.TypeOf(FileDirectory{})
+	)
+	if jt.Size() != ft.Size() {
+		t.Fatalf("fdJSON size %d, FileDirectory size %d", jt.Size(), ft.Size())
+	}
+	if jt.NumField() != ft.NumField() {
+		t.Fatalf("fdJSON has %d fields, FileDirectory has %d", jt.NumField(), ft.NumField())
+	}
+	for i := 0; i < jt.NumField(); i++ {
+		jf, ff := jt.Field(i), ft.Field(i)
+		if jf.Offset != ff.Offset || jf.Type.Size() != ff.Type.Size() {
+			t.Errorf("field %d: fdJSON.%s (offset %d, size %d), FileDirectory.%s (offset %d, size %d)", i, jf.Name, jf.Offset, jf.Type.Size(), ff.Name, ff.Offset, ff.Type.Size())
+		}
+	}
+}
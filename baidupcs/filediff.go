@@ -0,0 +1,93 @@
+package baidupcs
+
+import (
+	"sort"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// FileDiffEntry 增量变更中的一个文件或目录
+	FileDiffEntry struct {
+		*FileDirectory
+		IsDelete bool // 是否已删除
+	}
+
+	// FileDiff 从 cursor 开始的增量变更
+	FileDiff struct {
+		Entries []*FileDiffEntry
+		Cursor  string // 下一次请求使用的 cursor
+		HasMore bool   // 是否还有更多变更
+		Reset   bool   // 服务器要求重新获取全部文件
+	}
+
+	fileDiffEntryJSON struct {
+		FsID     int64  `json:"fs_id"`
+		Path     string `json:"path"`
+		Filename string `json:"server_filename"`
+		Ctime    int64  `json:"server_ctime"`
+		Mtime    int64  `json:"server_mtime"`
+		MD5      string `json:"md5"`
+		BlockListJSON
+		Size     int64 `json:"size"`
+		IsdirInt int8  `json:"isdir"`
+		IsDelete int8  `json:"isdelete"`
+	}
+
+	fileDiffJSON struct {
+		*pcserror.PanErrorInfo
+		Entries map[string]*fileDiffEntryJSON `json:"entries"`
+		Cursor  string                        `json:"cursor"`
+		HasMore bool                          `json:"has_more"`
+		Reset   bool                          `json:"reset"`
+	}
+)
+
+// FilesDirectoriesDiff 获取 cursor 之后的文件变更, cursor 为空时返回全部文件.
+// 变更按路径排序, 需要根据 HasMore 继续请求.
+func (pcs *BaiduPCS) FilesDirectoriesDiff(cursor string) (diff *FileDiff, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareFilesDirectoriesDiff(cursor)
+	if pcsError != nil {
+		return
+	}
+
+	defer dataReadCloser.Close()
+
+	jsonData := fileDiffJSON{
+		PanErrorInfo: pcserror.NewPanErrorInfo(OperationGetCursorDiff),
+	}
+	pcsError = pcserror.HandleJSONParse(OperationGetCursorDiff, dataReadCloser, &jsonData)
+	if pcsError != nil {
+		return
+	}
+
+	diff = &FileDiff{
+		Entries: make([]*FileDiffEntry, 0, len(jsonData.Entries)),
+		Cursor:  jsonData.Cursor,
+		HasMore: jsonData.HasMore,
+		Reset:   jsonData.Reset,
+	}
+	for _, e := range jsonData.Entries {
+		fd := &FileDirectory{
+			FsID:          e.FsID,
+			Path:          e.Path,
+			Filename:      e.Filename,
+			Ctime:         e.Ctime,
+			Mtime:         e.Mtime,
+			MD5:           e.MD5,
+			BlockListJSON: e.BlockListJSON,
+			Size:          e.Size,
+			Isdir:         e.IsdirInt != 0,
+		}
+		fd.fixMD5()
+		fd.MD5 = DecryptMD5(fd.MD5)
+		diff.Entries = append(diff.Entries, &FileDiffEntry{
+			FileDirectory: fd,
+			IsDelete:      e.IsDelete != 0,
+		})
+	}
+	sort.Slice(diff.Entries, func(i, j int) bool {
+		return diff.Entries[i].Path < diff.Entries[j].Path
+	})
+	return
+}
@@ -0,0 +1,233 @@
+// Package governor 百度 API 请求调度, 按接口类别限制请求频率,
+// 遇到服务器频率限制时暂停所有请求一段时间 (熔断)
+package governor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Class 接口类别
+	Class string
+
+	// Limits 各类接口每秒最多请求的次数, 0 代表不限制
+	Limits map[Class]float64
+
+	// Governor 请求调度器
+	Governor struct {
+		// Notify 进入和结束冷却时的状态提示, 为空则不提示
+		Notify func(msg string)
+
+		limits   Limits
+		cooldown time.Duration
+
+		mu        sync.Mutex
+		next      map[Class]time.Time // 各类接口下一次允许请求的时间
+		coolUntil time.Time           // 冷却结束的时间
+		strikes   int                 // 连续触发频率限制的次数
+	}
+)
+
+const (
+	// ClassList 获取文件列表, 元信息, 搜索
+	ClassList Class = "list"
+	// ClassManage 文件管理, 删除, 创建目录, 复制, 移动, 重命名
+	ClassManage Class = "manage"
+	// ClassLocate 获取下载链接
+	ClassLocate Class = "locate"
+	// ClassUpload 上传, 秒传, 创建文件
+	ClassUpload Class = "upload"
+	// ClassShare 分享, 转存
+	ClassShare Class = "share"
+	// ClassDefault 其他接口
+	ClassDefault Class = "default"
+
+	// maxCooldownFactor 连续触发频率限制时, 冷却时间最多翻倍到的倍数
+	maxCooldownFactor = 16
+)
+
+var (
+	// Classes 所有的接口类别
+	Classes = []Class{ClassList, ClassManage, ClassLocate, ClassUpload, ClassShare, ClassDefault}
+)
+
+// ParseLimits 解析限制规则, 格式为 类别=每秒请求次数, 多个规则以逗号分隔,
+// 例如 list=5,manage=2, 未指定的类别使用 default 的值
+func ParseLimits(s string) (Limits, error) {
+	limits := Limits{}
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("规则格式错误: %s, 应为 类别=每秒请求次数", field)
+		}
+		class := Class(strings.TrimSpace(kv[0]))
+		if !class.valid() {
+			return nil, fmt.Errorf("未知的接口类别: %s", class)
+		}
+		qps, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil || qps < 0 {
+			return nil, fmt.Errorf("每秒请求次数不合法: %s", kv[1])
+		}
+		limits[class] = qps
+	}
+	return limits, nil
+}
+
+func (c Class) valid() bool {
+	for _, class := range Classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// QPS 返回类别的限制, 未指定的使用 default 的值
+func (l Limits) QPS(class Class) float64 {
+	if qps, ok := l[class]; ok {
+		return qps
+	}
+	return l[ClassDefault]
+}
+
+func (l Limits) String() string {
+	fields := make([]string, 0, len(l))
+	for class, qps := range l {
+		fields = append(fields, string(class)+"="+strconv.FormatFloat(qps, 'f', -1, 64))
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ",")
+}
+
+// New 初始化调度器, cooldown 为第一次触发频率限制后暂停的时间
+func New(limits Limits, cooldown time.Duration) *Governor {
+	return &Governor{
+		limits:   limits,
+		cooldown: cooldown,
+		next:     map[Class]time.Time{},
+	}
+}
+
+// Wait 等待直到允许发送 class 类别的请求
+func (g *Governor) Wait(class Class) {
+	g.WaitContext(context.Background(), class)
+}
+
+// WaitContext 同 Wait, ctx 被取消时返回 ctx 的错误
+func (g *Governor) WaitContext(ctx context.Context, class Class) error {
+	if g == nil {
+		return ctx.Err()
+	}
+	for {
+		g.mu.Lock()
+		now := time.Now()
+		if now.Before(g.coolUntil) {
+			// 冷却中, 暂停所有请求
+			wait := g.coolUntil.Sub(now)
+			g.mu.Unlock()
+			if err := sleepContext(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+
+		qps := g.limits.QPS(class)
+		if qps <= 0 {
+			g.mu.Unlock()
+			return ctx.Err()
+		}
+
+		// 预约下一个请求的时间
+		at := g.next[class]
+		if at.Before(now) {
+			at = now
+		}
+		g.next[class] = at.Add(time.Duration(float64(time.Second) / qps))
+		g.mu.Unlock()
+
+		return sleepContext(ctx, at.Sub(now))
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReportRateLimited 报告触发了服务器的频率限制, 暂停所有请求,
+// 连续触发时冷却时间翻倍
+func (g *Governor) ReportRateLimited() {
+	if g == nil || g.cooldown <= 0 {
+		return
+	}
+	g.mu.Lock()
+	now := time.Now()
+	if now.Before(g.coolUntil) {
+		// 已在冷却中, 冷却前发出的请求返回的结果
+		g.mu.Unlock()
+		return
+	}
+	factor := 1 << uint(g.strikes)
+	if factor > maxCooldownFactor {
+		factor = maxCooldownFactor
+	} else {
+		g.strikes++
+	}
+	d := g.cooldown * time.Duration(factor)
+	g.coolUntil = now.Add(d)
+	notify := g.Notify
+	g.mu.Unlock()
+
+	if notify != nil {
+		notify(fmt.Sprintf("触发百度服务器频率限制, 暂停请求, 冷却 %s", d))
+	}
+}
+
+// ReportSuccess 报告请求未触发频率限制, 重置连续触发次数
+func (g *Governor) ReportSuccess() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	if g.strikes == 0 || time.Now().Before(g.coolUntil) {
+		g.mu.Unlock()
+		return
+	}
+	g.strikes = 0
+	notify := g.Notify
+	g.mu.Unlock()
+
+	if notify != nil {
+		notify("冷却结束, 恢复请求")
+	}
+}
+
+// CoolingDown 是否在冷却中, 返回剩余的冷却时间
+func (g *Governor) CoolingDown() (left time.Duration, ok bool) {
+	if g == nil {
+		return 0, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	left = time.Until(g.coolUntil)
+	return left, left > 0
+}
@@ -0,0 +1,78 @@
+package governor_test
+
+import (
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/governor"
+	"testing"
+	"time"
+)
+
+func TestParseLimits(t *testing.T) {
+	limits, err := governor.ParseLimits(" list=5, manage=0.5,default=10 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits.QPS(governor.ClassList) != 5 || limits.QPS(governor.ClassManage) != 0.5 || limits.QPS(governor.ClassShare) != 10 {
+		t.Fatalf("unexpected limits: %v", limits)
+	}
+	if limits.String() != "default=10,list=5,manage=0.5" {
+		t.Fatalf("unexpected string: %s", limits)
+	}
+
+	for _, s := range []string{"list", "foo=1", "list=-1", "list=abc"} {
+		if _, err := governor.ParseLimits(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestGovernorWait(t *testing.T) {
+	g := governor.New(governor.Limits{governor.ClassList: 20}, 0)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		g.Wait(governor.ClassList)
+	}
+	// 第一个请求立即发出, 之后每个间隔 50ms
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("requests not limited, elapsed %s", elapsed)
+	}
+
+	// 不限制的类别不等待
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		g.Wait(governor.ClassManage)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("unlimited class waited %s", elapsed)
+	}
+}
+
+func TestGovernorCooldown(t *testing.T) {
+	var msgs []string
+	g := governor.New(nil, 100*time.Millisecond)
+	g.Notify = func(msg string) {
+		msgs = append(msgs, msg)
+	}
+
+	g.ReportRateLimited()
+	g.ReportRateLimited() // 冷却中重复报告, 忽略
+	if _, ok := g.CoolingDown(); !ok {
+		t.Fatal("expected cooling down")
+	}
+
+	start := time.Now()
+	g.Wait(governor.ClassList)
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("request not paused, elapsed %s", elapsed)
+	}
+
+	// 连续触发, 冷却时间翻倍
+	g.ReportRateLimited()
+	if left, _ := g.CoolingDown(); left <= 100*time.Millisecond {
+		t.Fatalf("cooldown not doubled: %s", left)
+	}
+	time.Sleep(250 * time.Millisecond)
+	g.ReportSuccess()
+	if len(msgs) != 3 {
+		t.Fatalf("unexpected notify messages: %v", msgs)
+	}
+}
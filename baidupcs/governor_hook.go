@@ -0,0 +1,102 @@
+package baidupcs
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/governor"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// rateLimitPeekSize 检测频率限制时读取的响应数据长度
+	rateLimitPeekSize = 4096
+)
+
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// requestClass 根据请求地址判断接口类别
+func requestClass(urlStr string) governor.Class {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return governor.ClassDefault
+	}
+
+	p := u.Path
+	if strings.Contains(p, "/share") {
+		return governor.ClassShare
+	}
+	switch {
+	case strings.HasSuffix(p, "/recycle/list"), strings.HasSuffix(p, "/filediff"):
+		return governor.ClassList
+	case strings.HasSuffix(p, "/recycle/delete"), strings.Contains(p, "filemanager"):
+		return governor.ClassManage
+	case strings.HasSuffix(p, "/precreate"), strings.HasSuffix(p, "/create"):
+		return governor.ClassUpload
+	case strings.HasSuffix(p, "/download"):
+		return governor.ClassLocate
+	}
+
+	switch u.Query().Get("method") {
+	case "list", "listall", "meta", "filemetas", "search", "diff":
+		return governor.ClassList
+	case "delete", "mkdir", "copy", "move", "rename", "restore":
+		return governor.ClassManage
+	case "locatedownload", "download":
+		return governor.ClassLocate
+	case "upload", "locateupload", "precreate", "create", "createsuperfile", "rapidupload":
+		return governor.ClassUpload
+	}
+	return governor.ClassDefault
+}
+
+// isContentOperation 判断操作的响应是否为文件内容, 文件内容不是接口返回的数据, 不检查
+func isContentOperation(op string) bool {
+	return op == OperationOpenRange
+}
+
+// checkRateLimited 检测响应是否为频率限制错误, 只检查响应数据的开头部分, 不影响后续读取
+func (pcs *BaiduPCS) checkRateLimited(op string, resp *http.Response) {
+	if pcs.governor == nil || resp == nil {
+		return
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		pcs.governor.ReportRateLimited()
+		return
+	}
+	if isContentOperation(op) && resp.StatusCode/100 == 2 {
+		pcs.governor.ReportSuccess()
+		return
+	}
+
+	br := bufio.NewReaderSize(resp.Body, rateLimitPeekSize)
+	resp.Body = &peekedBody{Reader: br, Closer: resp.Body}
+	data, _ := br.Peek(rateLimitPeekSize)
+	if isRateLimitedData(data) {
+		pcs.governor.ReportRateLimited()
+		return
+	}
+	pcs.governor.ReportSuccess()
+}
+
+func isRateLimitedData(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return false
+	}
+	if code := gjson.GetBytes(data, "error_code"); code.Exists() {
+		return pcserror.Classify(&pcserror.PCSErrInfo{ErrType: pcserror.ErrTypeRemoteError, ErrCode: int(code.Int())}) == pcserror.ErrClassRateLimited
+	}
+	if errno := gjson.GetBytes(data, "errno"); errno.Exists() {
+		return pcserror.Classify(&pcserror.PanErrorInfo{ErrType: pcserror.ErrTypeRemoteError, ErrNo: int(errno.Int())}) == pcserror.ErrClassRateLimited
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package pcserror
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+type (
+	// ErrClass 错误分类, 用于决定是否重试
+	ErrClass int
+)
+
+const (
+	// ErrClassRetryable 可重试的错误, 如网络错误, 服务器临时错误
+	ErrClassRetryable ErrClass = iota
+	// ErrClassNonRetryable 重试也不会成功的错误, 如文件不存在, 空间配额已满
+	ErrClassNonRetryable
+	// ErrClassNeedRelogin 登录状态失效, 需要重新登录
+	ErrClassNeedRelogin
+	// ErrClassRateLimited 触发服务器频率限制, 需要等待更长时间再重试
+	ErrClassRateLimited
+)
+
+func (c ErrClass) String() string {
+	switch c {
+	case ErrClassRetryable:
+		return "可重试"
+	case ErrClassNonRetryable:
+		return "不可重试"
+	case ErrClassNeedRelogin:
+		return "需要重新登录"
+	case ErrClassRateLimited:
+		return "频率限制"
+	}
+	return "未知"
+}
+
+// Retryable 是否值得重试
+func (c ErrClass) Retryable() bool {
+	return c == ErrClassRetryable || c == ErrClassRateLimited
+}
+
+// Classify 对错误进行分类, 未知的错误视为可重试
+func Classify(err error) ErrClass {
+	if err == nil {
+		return ErrClassRetryable
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrClassNonRetryable
+	}
+
+	switch value := err.(type) {
+	case *PCSErrInfo:
+		if value.ErrType == ErrTypeRemoteError {
+			return classifyPCSErrCode(value.ErrCode)
+		}
+		return classifyErrType(value.ErrType)
+	case *PanErrorInfo:
+		if value.ErrType == ErrTypeRemoteError {
+			return classifyPanErrNo(value.ErrNo)
+		}
+		return classifyErrType(value.ErrType)
+	case *XPanErrorInfo:
+		if value.ErrType == ErrTypeRemoteError {
+			return classifyPanErrNo(value.ErrNo)
+		}
+		return classifyErrType(value.ErrType)
+	case Error:
+		if value.GetErrType() == ErrTypeRemoteError {
+			return classifyPCSErrCode(value.GetRemoteErrCode())
+		}
+		return classifyErrType(value.GetErrType())
+	}
+
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		// 系统级别的错误, 可能是权限问题
+		return ErrClassNonRetryable
+	}
+	return ErrClassRetryable
+}
+
+func classifyErrType(errType ErrType) ErrClass {
+	switch errType {
+	case ErrTypeInternalError:
+		return ErrClassNonRetryable
+	}
+	// 网络错误, json 解析错误等
+	return ErrClassRetryable
+}
+
+// classifyPCSErrCode 根据 PCS 错误代码分类
+func classifyPCSErrCode(code int) ErrClass {
+	switch code {
+	case 110, // access token invalid
+		111,   // access token expired
+		31045: // user not exists, 登录状态过期
+		return ErrClassNeedRelogin
+	case 31034: // hit frequence limit
+		return ErrClassRateLimited
+	case 31061, // file already exists
+		31066, // file does not exist
+		31079, // file md5 not found
+		31112, // exceed quota
+		31297: // file does not exist
+		return ErrClassNonRetryable
+	}
+	return ErrClassRetryable
+}
+
+// classifyPanErrNo 根据网盘错误代码分类
+func classifyPanErrNo(errno int) ErrClass {
+	switch errno {
+	case -4, // 登录信息有误
+		-6,   // 请重新登录
+		-11,  // 验证cookie无效
+		3,    // 未登录或帐号无效
+		9019: // accesstoken 过期
+		return ErrClassNeedRelogin
+	case 31034: // 接口频率限制
+		return ErrClassRateLimited
+	case -3, -9, // 文件不存在
+		-8, -30, // 文件已存在
+		-32, // 空间不足
+		-70, // 包含病毒
+		108, // 文件名有敏感词
+		115: // 文件禁止分享
+		return ErrClassNonRetryable
+	}
+	return ErrClassRetryable
+}
@@ -64,6 +64,11 @@ func (pane *PanErrorInfo) GetError() error {
 	return pane.Err
 }
 
+// Unwrap 返回原始错误, 用于 errors.Is, errors.As
+func (pane *PanErrorInfo) Unwrap() error {
+	return pane.Err
+}
+
 func (pane *PanErrorInfo) Error() string {
 	if pane.Operation == "" {
 		if pane.Err != nil {
@@ -66,6 +66,11 @@ func (pcse *PCSErrInfo) GetError() error {
 	return pcse.Err
 }
 
+// Unwrap 返回原始错误, 用于 errors.Is, errors.As
+func (pcse *PCSErrInfo) Unwrap() error {
+	return pcse.Err
+}
+
 func (pcse *PCSErrInfo) Error() string {
 	if pcse.Operation == "" {
 		if pcse.Err != nil {
@@ -65,6 +65,11 @@ func (pane *XPanErrorInfo) GetError() error {
 	return pane.Err
 }
 
+// Unwrap 返回原始错误, 用于 errors.Is, errors.As
+func (pane *XPanErrorInfo) Unwrap() error {
+	return pane.Err
+}
+
 func (pane *XPanErrorInfo) Error() string {
 	if pane.Operation == "" {
 		if pane.Err != nil {
@@ -0,0 +1,192 @@
+package pcstest
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cloudDlStatusSuccess  = 0
+	cloudDlStatusRunning  = 1
+	cloudDlStatusCanceled = 7
+)
+
+type (
+	// cloudDlTask 离线下载任务, 添加后一直处于下载中, 可使用 FinishCloudDl 完成
+	cloudDlTask struct {
+		id         int64
+		status     int
+		sourceURL  string
+		savePath   string
+		fileSize   int64
+		createTime int64
+		finishTime int64
+	}
+)
+
+func (t *cloudDlTask) json() map[string]interface{} {
+	name := path.Base(t.sourceURL)
+	return map[string]interface{}{
+		"task_id":       strconv.FormatInt(t.id, 10),
+		"status":        strconv.Itoa(t.status),
+		"file_size":     strconv.FormatInt(t.fileSize, 10),
+		"finished_size": strconv.FormatInt(t.finishedSize(), 10),
+		"create_time":   strconv.FormatInt(t.createTime, 10),
+		"start_time":    strconv.FormatInt(t.createTime, 10),
+		"finish_time":   strconv.FormatInt(t.finishTime, 10),
+		"save_path":     t.savePath,
+		"source_url":    t.sourceURL,
+		"task_name":     name,
+		"od_type":       "0",
+		"file_list": []map[string]string{
+			{"file_name": name, "file_size": strconv.FormatInt(t.fileSize, 10)},
+		},
+		"result": 0,
+	}
+}
+
+func (t *cloudDlTask) finishedSize() int64 {
+	if t.status == cloudDlStatusSuccess {
+		return t.fileSize
+	}
+	return 0
+}
+
+// FinishCloudDl 完成离线下载任务, 将 data 保存到任务的保存目录
+func (s *Server) FinishCloudDl(taskID int64, data []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tasks[taskID]
+	if t == nil || t.status != cloudDlStatusRunning {
+		return false
+	}
+	if s.put(path.Join(t.savePath, path.Base(t.sourceURL)), append([]byte(nil), data...), nil) == nil {
+		return false
+	}
+	t.status = cloudDlStatusSuccess
+	t.fileSize = int64(len(data))
+	t.finishTime = time.Now().Unix()
+	return true
+}
+
+func (s *Server) handleCloudDlAdd(w http.ResponseWriter, r *http.Request) {
+	var (
+		sourceURL = r.FormValue("source_url")
+		savePath  = cleanPath(r.FormValue("save_path"))
+	)
+	if sourceURL == "" {
+		writePCSError(w, errCodeParamError, "source_url is empty")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.mkdirAll(savePath) {
+		writePCSError(w, errCodeParamError, "save_path is not a directory")
+		return
+	}
+	t := &cloudDlTask{
+		id:         s.newID(),
+		status:     cloudDlStatusRunning,
+		sourceURL:  sourceURL,
+		savePath:   savePath,
+		createTime: time.Now().Unix(),
+	}
+	s.tasks[t.id] = t
+	writeJSON(w, map[string]interface{}{
+		"task_id":        t.id,
+		"rapid_download": 0,
+		"request_id":     1,
+	})
+}
+
+func (s *Server) handleCloudDlQuery(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := map[string]interface{}{}
+	for _, idStr := range strings.Split(r.FormValue("task_ids"), ",") {
+		id, _ := strconv.ParseInt(idStr, 10, 64)
+		if t := s.tasks[id]; t != nil {
+			info[idStr] = t.json()
+		} else {
+			info[idStr] = map[string]interface{}{"result": 1}
+		}
+	}
+	writeJSON(w, map[string]interface{}{
+		"task_info":  info,
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleCloudDlList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int64, 0, len(s.tasks))
+	for id := range s.tasks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] > ids[j]
+	})
+	list := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, s.tasks[id].json())
+	}
+	writeJSON(w, map[string]interface{}{
+		"task_info":  list,
+		"total":      len(list),
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleCloudDlCancel(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tasks[formInt64(r, "task_id")]
+	if t == nil {
+		writePCSError(w, errCodeTaskNotExists, "task not exist")
+		return
+	}
+	if t.status == cloudDlStatusRunning {
+		t.status = cloudDlStatusCanceled
+		t.finishTime = time.Now().Unix()
+	}
+	writeJSON(w, map[string]interface{}{
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleCloudDlDelete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := formInt64(r, "task_id")
+	if s.tasks[id] == nil {
+		writePCSError(w, errCodeTaskNotExists, "task not exist")
+		return
+	}
+	delete(s.tasks, id)
+	writeJSON(w, map[string]interface{}{
+		"request_id": 1,
+	})
+}
+
+// handleCloudDlClear 清除已结束的任务记录
+func (s *Server) handleCloudDlClear(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := 0
+	for id, t := range s.tasks {
+		if t.status != cloudDlStatusRunning {
+			delete(s.tasks, id)
+			total++
+		}
+	}
+	writeJSON(w, map[string]interface{}{
+		"total":      total,
+		"request_id": 1,
+	})
+}
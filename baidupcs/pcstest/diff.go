@@ -0,0 +1,134 @@
+package pcstest
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+const (
+	// DiffPageSize 增量变更每次返回的最大条数
+	DiffPageSize = 100
+)
+
+type (
+	// tombstone 已删除的文件或目录
+	tombstone struct {
+		node
+	}
+
+	fileDiffEntryJSON struct {
+		FsID      int64    `json:"fs_id"`
+		Path      string   `json:"path"`
+		Filename  string   `json:"server_filename"`
+		Ctime     int64    `json:"server_ctime"`
+		Mtime     int64    `json:"server_mtime"`
+		MD5       string   `json:"md5,omitempty"`
+		BlockList []string `json:"block_list,omitempty"`
+		Size      int64    `json:"size"`
+		Isdir     int      `json:"isdir"`
+		IsDelete  int      `json:"isdelete"`
+	}
+)
+
+// setNode 写入文件或目录, 并更新版本
+func (s *Server) setNode(n *node) {
+	s.seq++
+	n.ver = s.seq
+	s.files[n.path] = n
+}
+
+// removeNode 删除文件或目录, 并记录删除
+func (s *Server) removeNode(n *node) {
+	s.seq++
+	t := &tombstone{node: *n}
+	t.ver = s.seq
+	s.removed = append(s.removed, t)
+	delete(s.files, n.path)
+}
+
+func diffEntry(n *node, isDelete bool) *fileDiffEntryJSON {
+	e := &fileDiffEntryJSON{
+		FsID:     n.fsID,
+		Path:     n.path,
+		Filename: path.Base(n.path),
+		Ctime:    n.ctime,
+		Mtime:    n.mtime,
+		Isdir:    boolInt(n.isdir),
+		IsDelete: boolInt(isDelete),
+	}
+	if !n.isdir && !isDelete {
+		e.MD5 = n.md5
+		e.BlockList = n.blockList
+		e.Size = int64(len(n.data))
+	}
+	return e
+}
+
+// handleFileDiff 返回 cursor 之后的变更, cursor 为版本号, null 时返回全部文件.
+// 同一个 fs_id 只返回最后一次变更, 移动后只返回新路径.
+func (s *Server) handleFileDiff(w http.ResponseWriter, r *http.Request) {
+	var (
+		cursor = r.URL.Query().Get("cursor")
+		since  int64
+		full   = cursor == "" || cursor == "null"
+	)
+	if !full {
+		var err error
+		since, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			writePanError(w, errnoParamError)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type change struct {
+		n        *node
+		isDelete bool
+	}
+	latest := map[int64]change{}
+	for _, n := range s.files {
+		if n.ver > since && n.path != baidupcs.PathSeparator {
+			latest[n.fsID] = change{n: n}
+		}
+	}
+	if !full {
+		for _, t := range s.removed {
+			if c, ok := latest[t.fsID]; t.ver > since && (!ok || c.n.ver < t.ver) {
+				latest[t.fsID] = change{n: &t.node, isDelete: true}
+			}
+		}
+	}
+
+	changes := make([]change, 0, len(latest))
+	for _, c := range latest {
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].n.ver < changes[j].n.ver
+	})
+
+	hasMore := len(changes) > DiffPageSize
+	next := s.seq
+	if hasMore {
+		changes = changes[:DiffPageSize]
+		next = changes[len(changes)-1].n.ver
+	}
+	entries := make(map[string]*fileDiffEntryJSON, len(changes))
+	for _, c := range changes {
+		entries[strconv.FormatInt(c.n.fsID, 10)] = diffEntry(c.n, c.isDelete)
+	}
+	writeJSON(w, map[string]interface{}{
+		"errno":    0,
+		"entries":  entries,
+		"cursor":   strconv.FormatInt(next, 10),
+		"has_more": hasMore,
+		"reset":    false,
+	})
+}
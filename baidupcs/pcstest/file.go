@@ -0,0 +1,300 @@
+package pcstest
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	pathsJSON struct {
+		List []struct {
+			Path string `json:"path"`
+		} `json:"list"`
+	}
+
+	cpmvJSON struct {
+		List []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"list"`
+	}
+)
+
+// sortList 按 pcs 的排序方式排序, 目录在前
+func sortList(list []*fdJSON, by, order string) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Isdir != b.Isdir {
+			return a.Isdir > b.Isdir
+		}
+		var less bool
+		switch by {
+		case "time":
+			less = a.Mtime < b.Mtime
+		case "size":
+			less = a.Size < b.Size
+		default:
+			less = a.Filename < b.Filename
+		}
+		if order == "desc" {
+			return !less
+		}
+		return less
+	})
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	p := cleanPath(r.FormValue("path"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.files[p]
+	if n == nil {
+		writePCSError(w, errCodeFileNotExists, "file does not exist")
+		return
+	}
+
+	list := []*fdJSON{}
+	if !n.isdir {
+		list = append(list, s.fd(n))
+	}
+	for _, child := range s.children(p) {
+		list = append(list, s.fd(child))
+	}
+	sortList(list, r.FormValue("by"), r.FormValue("order"))
+	writeJSON(w, map[string]interface{}{
+		"list":       list,
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleMeta(w http.ResponseWriter, r *http.Request) {
+	var param pathsJSON
+	if err := formParam(r, &param); err != nil {
+		writePCSError(w, errCodeParamError, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]*fdJSON, 0, len(param.List))
+	for _, item := range param.List {
+		n := s.files[cleanPath(item.Path)]
+		if n == nil {
+			writePCSError(w, errCodeFileNotExists, "file does not exist")
+			return
+		}
+		list = append(list, s.fd(n))
+	}
+	writeJSON(w, map[string]interface{}{
+		"list":       list,
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var (
+		p       = cleanPath(r.FormValue("path"))
+		keyword = r.FormValue("wd")
+		re      = r.FormValue("re") == "1"
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := s.files[p]; n == nil || !n.isdir {
+		writePCSError(w, errCodeFileNotExists, "file does not exist")
+		return
+	}
+
+	list := []*fdJSON{}
+	for _, n := range s.subtree(p) {
+		if n.isdir || !strings.Contains(path.Base(n.path), keyword) {
+			continue
+		}
+		if !re && path.Dir(n.path) != p {
+			continue
+		}
+		list = append(list, s.fd(n))
+	}
+	writeJSON(w, map[string]interface{}{
+		"list":       list,
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("type") == "recycle" {
+		s.handleRecycleClear(w, r)
+		return
+	}
+
+	var param pathsJSON
+	if err := formParam(r, &param); err != nil {
+		writePCSError(w, errCodeParamError, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(param.List))
+	for _, item := range param.List {
+		p := cleanPath(item.Path)
+		if s.files[p] == nil || p == "/" {
+			writePCSError(w, errCodeFileNotExists, "file does not exist")
+			return
+		}
+		paths = append(paths, p)
+	}
+	for _, p := range paths {
+		s.toRecycle(p)
+	}
+	writeJSON(w, map[string]interface{}{
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleMkdir(w http.ResponseWriter, r *http.Request) {
+	p := cleanPath(r.FormValue("path"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.files[p] != nil {
+		writePCSError(w, errCodeFileExists, "file already exists")
+		return
+	}
+	if !s.mkdirAll(p) {
+		writePCSError(w, errCodeParamError, "parent is not a directory")
+		return
+	}
+	writeJSON(w, s.fd(s.files[p]))
+}
+
+func (s *Server) handleCopy(w http.ResponseWriter, r *http.Request) {
+	s.cpmv(w, r, false)
+}
+
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
+	s.cpmv(w, r, true)
+}
+
+func (s *Server) cpmv(w http.ResponseWriter, r *http.Request, isMove bool) {
+	var param cpmvJSON
+	if err := formParam(r, &param); err != nil {
+		writePCSError(w, errCodeParamError, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range param.List {
+		from, to := cleanPath(item.From), cleanPath(item.To)
+		switch {
+		case s.files[from] == nil:
+			writePCSError(w, errCodeFileNotExists, "file does not exist")
+			return
+		case s.files[to] != nil:
+			writePCSError(w, errCodeFileExists, "file already exists")
+			return
+		case isSubPath(to, from):
+			writePCSError(w, errCodeParamError, "can not move or copy into itself")
+			return
+		case !s.mkdirAll(path.Dir(to)):
+			writePCSError(w, errCodeParamError, "parent is not a directory")
+			return
+		}
+
+		now := time.Now().Unix()
+		for _, n := range s.subtree(from) {
+			newNode := *n
+			newNode.path = to + strings.TrimPrefix(n.path, from)
+			if isMove {
+				s.removeNode(n)
+			} else {
+				newNode.fsID = s.newID()
+				newNode.ctime, newNode.mtime = now, now
+			}
+			s.setNode(&newNode)
+		}
+	}
+	writeJSON(w, map[string]interface{}{
+		"extra": map[string]interface{}{
+			"list": param.List,
+		},
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	p := cleanPath(r.FormValue("path"))
+
+	s.mu.Lock()
+	n := s.files[p]
+	s.mu.Unlock()
+	if n == nil || n.isdir {
+		w.WriteHeader(http.StatusNotFound)
+		writePCSError(w, errCodeFileNotExists, "file does not exist")
+		return
+	}
+	w.Header().Set("Content-MD5", n.md5)
+	w.Header().Set("x-bs-file-size", strconv.Itoa(len(n.data)))
+	http.ServeContent(w, r, path.Base(p), time.Unix(n.mtime, 0), bytes.NewReader(n.data))
+}
+
+func (s *Server) handleLocateDownload(w http.ResponseWriter, r *http.Request) {
+	p := cleanPath(r.FormValue("path"))
+
+	s.mu.Lock()
+	n := s.files[p]
+	s.mu.Unlock()
+	if n == nil || n.isdir {
+		writePCSError(w, errCodeFileNotExists, "file does not exist")
+		return
+	}
+
+	dlink := &url.URL{
+		Scheme:   "http",
+		Host:     r.Host,
+		Path:     "/rest/2.0/pcs/file",
+		RawQuery: url.Values{"method": {"download"}, "path": {p}}.Encode(),
+	}
+	writeJSON(w, map[string]interface{}{
+		"urls": []map[string]interface{}{
+			{"url": dlink.String(), "encrypt": 0},
+		},
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleQuota(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, map[string]interface{}{
+		"quota":      s.Quota,
+		"used":       s.used(),
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"errno": 0,
+		"records": []map[string]interface{}{
+			{"uk": s.UK},
+		},
+	})
+}
+
+func (s *Server) handleTemplateVariable(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"errno": 0,
+		"result": map[string]interface{}{
+			"bdstoken": "pcstest",
+		},
+	})
+}
@@ -0,0 +1,182 @@
+package pcstest
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// recycleKeepDays 回收站保留天数
+	recycleKeepDays = 10
+)
+
+type (
+	// recycled 回收站中的文件或目录, 保存删除时的整个子树
+	recycled struct {
+		fd        *fdJSON
+		nodes     []*node
+		deletedAt int64
+	}
+
+	recycleFDJSON struct {
+		FsID     int64  `json:"fs_id"`
+		Isdir    int    `json:"isdir"`
+		LeftTime int    `json:"leftTime"`
+		Path     string `json:"path"`
+		Filename string `json:"server_filename"`
+		Ctime    int64  `json:"server_ctime"`
+		Mtime    int64  `json:"server_mtime"`
+		MD5      string `json:"md5"`
+		Size     int64  `json:"size"`
+	}
+
+	fsIDJSON struct {
+		FsID int64 `json:"fs_id"`
+	}
+)
+
+// toRecycle 将文件或目录移入回收站
+func (s *Server) toRecycle(p string) {
+	root := s.files[p]
+	if root == nil {
+		return
+	}
+	rc := &recycled{
+		fd:        s.fd(root),
+		nodes:     s.subtree(p),
+		deletedAt: time.Now().Unix(),
+	}
+	for _, n := range rc.nodes {
+		s.removeNode(n)
+	}
+	s.recycle[root.fsID] = rc
+}
+
+// sortRecycle 按 fs_id 排序, 保证分页结果稳定
+func sortRecycle(list []*recycleFDJSON) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].FsID < list[j].FsID
+	})
+}
+
+func (rc *recycled) json() *recycleFDJSON {
+	left := recycleKeepDays - int(time.Now().Unix()-rc.deletedAt)/86400
+	return &recycleFDJSON{
+		FsID:     rc.fd.FsID,
+		Isdir:    rc.fd.Isdir,
+		LeftTime: left,
+		Path:     rc.fd.Path,
+		Filename: rc.fd.Filename,
+		Ctime:    rc.fd.Ctime,
+		Mtime:    rc.fd.Mtime,
+		MD5:      rc.fd.MD5,
+		Size:     rc.fd.Size,
+	}
+}
+
+func (s *Server) handleRecycleList(w http.ResponseWriter, r *http.Request) {
+	var (
+		page, _ = strconv.Atoi(r.FormValue("page"))
+		num, _  = strconv.Atoi(r.FormValue("num"))
+	)
+	if page < 1 {
+		page = 1
+	}
+	if num < 1 {
+		num = 100
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := make([]*recycleFDJSON, 0, len(s.recycle))
+	for _, rc := range s.recycle {
+		all = append(all, rc.json())
+	}
+	sortRecycle(all)
+
+	list := []*recycleFDJSON{}
+	if start := (page - 1) * num; start < len(all) {
+		end := start + num
+		if end > len(all) {
+			end = len(all)
+		}
+		list = all[start:end]
+	}
+	writeJSON(w, map[string]interface{}{
+		"errno": 0,
+		"list":  list,
+	})
+}
+
+func (s *Server) handleRecycleRestore(w http.ResponseWriter, r *http.Request) {
+	var param struct {
+		List []*fsIDJSON `json:"list"`
+	}
+	if err := formParam(r, &param); err != nil {
+		writePCSError(w, errCodeParamError, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	succ := []*fsIDJSON{}
+	for _, item := range param.List {
+		rc := s.recycle[item.FsID]
+		if rc == nil || s.files[rc.fd.Path] != nil || !s.mkdirAll(path.Dir(rc.fd.Path)) {
+			continue
+		}
+		for _, n := range rc.nodes {
+			s.setNode(n)
+		}
+		delete(s.recycle, item.FsID)
+		succ = append(succ, item)
+	}
+	if len(succ) == 0 {
+		writePCSError(w, errCodeFileNotExists, "file does not exist")
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"extra": map[string]interface{}{
+			"list": succ,
+		},
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleRecycleDelete(w http.ResponseWriter, r *http.Request) {
+	fidList, err := formInt64List(r, "fidlist")
+	if err != nil {
+		writePanError(w, errnoParamError)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, fid := range fidList {
+		if s.recycle[fid] == nil {
+			writePanError(w, errnoFileNotExists)
+			return
+		}
+	}
+	for _, fid := range fidList {
+		delete(s.recycle, fid)
+	}
+	writePanError(w, 0)
+}
+
+func (s *Server) handleRecycleClear(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.recycle)
+	s.recycle = map[int64]*recycled{}
+	writeJSON(w, map[string]interface{}{
+		"extra": map[string]interface{}{
+			"succNum": n,
+			"list":    []interface{}{},
+		},
+		"request_id": 1,
+	})
+}
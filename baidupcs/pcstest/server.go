@@ -0,0 +1,252 @@
+// Package pcstest 本地模拟的百度网盘服务器, 用于离线测试
+package pcstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+const (
+	// DefaultQuota 默认的网盘容量
+	DefaultQuota = 2 << 40
+	// DefaultUK 默认的用户 uk
+	DefaultUK = 1000000001
+
+	errCodeFileNotExists   = 31066
+	errCodeFileExists      = 31061
+	errCodeParamError      = 31023
+	errCodeUploadIDInvalid = 31299
+	errCodeBlockMiss       = 31363
+	errCodeTaskNotExists   = 36016
+	errnoFileNotExists     = -9
+	errnoFileExists        = -8
+	errnoParamError        = 2
+)
+
+type (
+	// Server 模拟的百度网盘服务器, 数据保存在内存中.
+	// 使用 NewPCS 获取连接到该服务器的 BaiduPCS.
+	Server struct {
+		*httptest.Server
+
+		Quota int64 // 网盘容量
+		UK    int64 // 用户 uk
+
+		mu       sync.Mutex
+		nextID   int64
+		files    map[string]*node
+		seq      int64        // 当前版本
+		removed  []*tombstone // 已删除的文件和目录
+		recycle  map[int64]*recycled
+		uploads  map[string]*uploadSession
+		shares   map[int64]*share
+		tasks    map[int64]*cloudDlTask
+		requests map[string]int
+		failures map[string]*failure
+		routes   map[string]route
+	}
+
+	route struct {
+		handler func(w http.ResponseWriter, r *http.Request)
+		pan     bool // 使用网盘首页 api 的错误格式 (errno)
+	}
+
+	failure struct {
+		code int
+		n    int
+	}
+)
+
+// NewServer 启动模拟服务器, 使用完毕需要调用 Close
+func NewServer() *Server {
+	s := &Server{
+		Quota:    DefaultQuota,
+		UK:       DefaultUK,
+		nextID:   1,
+		files:    map[string]*node{},
+		recycle:  map[int64]*recycled{},
+		uploads:  map[string]*uploadSession{},
+		shares:   map[int64]*share{},
+		tasks:    map[int64]*cloudDlTask{},
+		requests: map[string]int{},
+		failures: map[string]*failure{},
+	}
+	s.files[baidupcs.PathSeparator] = &node{
+		fsID:  s.newID(),
+		path:  baidupcs.PathSeparator,
+		isdir: true,
+	}
+	s.registerRoutes()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) registerRoutes() {
+	s.routes = map[string]route{
+		"pcs/file/list":           {handler: s.handleList},
+		"pcs/file/meta":           {handler: s.handleMeta},
+		"pcs/file/search":         {handler: s.handleSearch},
+		"pcs/file/delete":         {handler: s.handleDelete},
+		"pcs/file/mkdir":          {handler: s.handleMkdir},
+		"pcs/file/copy":           {handler: s.handleCopy},
+		"pcs/file/move":           {handler: s.handleMove},
+		"pcs/file/download":       {handler: s.handleDownload},
+		"pcs/stream/download":     {handler: s.handleDownload},
+		"pcs/file/locatedownload": {handler: s.handleLocateDownload},
+		"pcs/file/locateupload":   {handler: s.handleLocateUpload},
+		"pcs/file/upload":         {handler: s.handleUpload},
+		"pcs/file/restore":        {handler: s.handleRecycleRestore},
+		"pcs/superfile2/upload":   {handler: s.handleSuperfile2},
+		"pcs/quota/info":          {handler: s.handleQuota},
+		"xpan/file/create":        {handler: s.handleRapidUpload},
+		"api/precreate":           {handler: s.handlePrecreate, pan: true},
+		"api/create":              {handler: s.handleCreate, pan: true},
+		"api/user/getinfo":        {handler: s.handleUserInfo, pan: true},
+		"api/gettemplatevariable": {handler: s.handleTemplateVariable, pan: true},
+		"api/recycle/list":        {handler: s.handleRecycleList, pan: true},
+		"api/recycle/delete":      {handler: s.handleRecycleDelete, pan: true},
+		"api/batch/filediff":      {handler: s.handleFileDiff, pan: true},
+		"share/pset":              {handler: s.handleSharePSet, pan: true},
+		"share/cancel":            {handler: s.handleShareCancel, pan: true},
+		"share/record":            {handler: s.handleShareRecord, pan: true},
+		"share/surlinfoinrecord":  {handler: s.handleShareSURLInfo, pan: true},
+		"cloud_dl/add_task":       {handler: s.handleCloudDlAdd},
+		"cloud_dl/query_task":     {handler: s.handleCloudDlQuery},
+		"cloud_dl/list_task":      {handler: s.handleCloudDlList},
+		"cloud_dl/cancel_task":    {handler: s.handleCloudDlCancel},
+		"cloud_dl/delete_task":    {handler: s.handleCloudDlDelete},
+		"cloud_dl/clear_task":     {handler: s.handleCloudDlClear},
+	}
+}
+
+// Endpoint 返回服务器地址, 用于 BaiduPCS.SetEndpoint
+func (s *Server) Endpoint() *url.URL {
+	u, _ := url.Parse(s.URL)
+	return u
+}
+
+// NewPCS 返回连接到该服务器的 BaiduPCS
+func (s *Server) NewPCS() *baidupcs.BaiduPCS {
+	pcs := baidupcs.NewPCS(266719, "pcstest")
+	pcs.SetEndpoint(s.Endpoint())
+	pcs.SetUID(uint64(s.UK))
+	pcs.SetStaticPCSAddr(true)
+	return pcs
+}
+
+// Requests 返回接口 api 被请求的次数.
+// api 的格式为 "pcs/file/list", "pcs/superfile2/upload", "api/precreate", "share/pset", "cloud_dl/add_task" 等
+func (s *Server) Requests(api string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[api]
+}
+
+// Fail 使接下来 n 次对接口 api 的请求返回错误码 code, api 的格式同 Requests
+func (s *Server) Fail(api string, code, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[api] = &failure{
+		code: code,
+		n:    n,
+	}
+}
+
+// apiName 根据请求地址返回接口名
+func apiName(r *http.Request) string {
+	p := strings.Trim(r.URL.Path, "/")
+	method := r.URL.Query().Get("method")
+	switch {
+	case strings.HasPrefix(p, "rest/2.0/pcs/"):
+		return "pcs/" + strings.TrimPrefix(p, "rest/2.0/pcs/") + "/" + method
+	case p == "rest/2.0/xpan/file":
+		return "xpan/file/" + method
+	case p == "rest/2.0/services/cloud_dl":
+		return "cloud_dl/" + method
+	}
+	return p
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	api := apiName(r)
+
+	s.mu.Lock()
+	s.requests[api]++
+	rt, ok := s.routes[api]
+	var code int
+	if f := s.failures[api]; f != nil && f.n > 0 {
+		f.n--
+		code = f.code
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		writePCSError(w, errCodeParamError, "unknown api "+api)
+		return
+	}
+	if code != 0 {
+		if rt.pan {
+			writePanError(w, code)
+		} else {
+			writePCSError(w, code, "injected error")
+		}
+		return
+	}
+	rt.handler(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writePCSError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, map[string]interface{}{
+		"error_code": code,
+		"error_msg":  msg,
+		"request_id": 1,
+	})
+}
+
+func writePanError(w http.ResponseWriter, errno int) {
+	writeJSON(w, map[string]interface{}{
+		"errno":      errno,
+		"request_id": 1,
+	})
+}
+
+// formParam 解析 pcs 接口 multipart 表单中的 param 字段
+func formParam(r *http.Request, v interface{}) error {
+	return json.Unmarshal([]byte(r.FormValue("param")), v)
+}
+
+// formStringList 解析形如 ["a","b"] 的表单字段
+func formStringList(r *http.Request, key string) (list []string, err error) {
+	err = json.Unmarshal([]byte(r.FormValue(key)), &list)
+	return
+}
+
+// formInt64List 解析形如 [1,2] 的表单字段
+func formInt64List(r *http.Request, key string) (list []int64, err error) {
+	err = json.Unmarshal([]byte(r.FormValue(key)), &list)
+	return
+}
+
+func formInt64(r *http.Request, key string) int64 {
+	i, _ := strconv.ParseInt(r.FormValue(key), 10, 64)
+	return i
+}
+
+func (s *Server) newID() int64 {
+	id := s.nextID
+	s.nextID++
+	return id
+}
@@ -0,0 +1,142 @@
+package pcstest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type (
+	// share 分享记录
+	share struct {
+		id         int64
+		fsIDs      []int64
+		paths      []string
+		pwd        string
+		period     int
+		createTime int64
+	}
+)
+
+func (sh *share) shortURL() string {
+	return "pcstest" + strconv.FormatInt(sh.id, 10)
+}
+
+func (sh *share) link() string {
+	return "https://pan.baidu.com/s/1" + sh.shortURL()
+}
+
+func (s *Server) handleSharePSet(w http.ResponseWriter, r *http.Request) {
+	paths, err := formStringList(r, "path_list")
+	if err != nil || len(paths) == 0 {
+		writePanError(w, errnoParamError)
+		return
+	}
+	period, _ := strconv.Atoi(r.FormValue("period"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sh := &share{
+		id:         s.newID(),
+		pwd:        r.FormValue("pwd"),
+		period:     period,
+		createTime: time.Now().Unix(),
+	}
+	for _, p := range paths {
+		n := s.files[cleanPath(p)]
+		if n == nil {
+			writePanError(w, errnoFileNotExists)
+			return
+		}
+		sh.fsIDs = append(sh.fsIDs, n.fsID)
+		sh.paths = append(sh.paths, n.path)
+	}
+	s.shares[sh.id] = sh
+	writeJSON(w, map[string]interface{}{
+		"errno":    0,
+		"shareid":  sh.id,
+		"link":     sh.link(),
+		"shorturl": sh.link(),
+	})
+}
+
+func (s *Server) handleShareCancel(w http.ResponseWriter, r *http.Request) {
+	ids, err := formInt64List(r, "shareid_list")
+	if err != nil {
+		writePanError(w, errnoParamError)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if s.shares[id] == nil {
+			writePanError(w, errnoFileNotExists)
+			return
+		}
+	}
+	for _, id := range ids {
+		delete(s.shares, id)
+	}
+	writePanError(w, 0)
+}
+
+func (s *Server) handleShareRecord(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.FormValue("page"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []map[string]interface{}{}
+	if page <= 1 {
+		for _, sh := range s.sortedShares() {
+			var expireTime int64
+			if sh.period > 0 {
+				expireTime = sh.createTime + int64(sh.period)*86400
+			}
+			list = append(list, map[string]interface{}{
+				"shareId":     sh.id,
+				"fsIds":       sh.fsIDs,
+				"shortlink":   sh.link(),
+				"status":      0,
+				"public":      0,
+				"typicalPath": sh.paths[0],
+				"expiredType": sh.period,
+				"expiredTime": expireTime,
+				"vCnt":        0,
+			})
+		}
+	}
+	writeJSON(w, map[string]interface{}{
+		"errno": 0,
+		"list":  list,
+	})
+}
+
+func (s *Server) handleShareSURLInfo(w http.ResponseWriter, r *http.Request) {
+	id := formInt64(r, "shareid")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sh := s.shares[id]
+	if sh == nil {
+		writePanError(w, errnoFileNotExists)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"errno":    0,
+		"pwd":      sh.pwd,
+		"shorturl": sh.shortURL(),
+	})
+}
+
+func (s *Server) sortedShares() []*share {
+	list := make([]*share, 0, len(s.shares))
+	for _, sh := range s.shares {
+		list = append(list, sh)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].id > list[j].id
+	})
+	return list
+}
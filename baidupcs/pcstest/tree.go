@@ -0,0 +1,208 @@
+package pcstest
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// node 文件或目录
+	node struct {
+		fsID      int64
+		path      string
+		isdir     bool
+		data      []byte
+		md5       string
+		blockList []string
+		ctime     int64
+		mtime     int64
+		ver       int64 // 最后修改时的版本, 用于增量变更
+	}
+
+	// fdJSON 文件/目录信息, 与网盘返回的格式一致
+	fdJSON struct {
+		FsID        int64    `json:"fs_id"`
+		Path        string   `json:"path"`
+		Filename    string   `json:"server_filename"`
+		Ctime       int64    `json:"ctime"`
+		Mtime       int64    `json:"mtime"`
+		MD5         string   `json:"md5,omitempty"`
+		BlockList   []string `json:"block_list,omitempty"`
+		Size        int64    `json:"size"`
+		Isdir       int      `json:"isdir"`
+		Ifhassubdir int      `json:"ifhassubdir"`
+	}
+)
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func cleanPath(p string) string {
+	return path.Clean(baidupcs.PathSeparator + p)
+}
+
+// isSubPath 判断 p 是否为 dir 或其子路径
+func isSubPath(p, dir string) bool {
+	return p == dir || dir == baidupcs.PathSeparator || strings.HasPrefix(p, dir+baidupcs.PathSeparator)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *Server) fd(n *node) *fdJSON {
+	fd := &fdJSON{
+		FsID:     n.fsID,
+		Path:     n.path,
+		Filename: path.Base(n.path),
+		Ctime:    n.ctime,
+		Mtime:    n.mtime,
+		Isdir:    boolInt(n.isdir),
+	}
+	if n.isdir {
+		for _, child := range s.children(n.path) {
+			if child.isdir {
+				fd.Ifhassubdir = 1
+				break
+			}
+		}
+		return fd
+	}
+	fd.MD5 = n.md5
+	fd.BlockList = n.blockList
+	fd.Size = int64(len(n.data))
+	return fd
+}
+
+// children 返回目录下的文件和目录, 按路径排序
+func (s *Server) children(dir string) (list []*node) {
+	for p, n := range s.files {
+		if p != dir && path.Dir(p) == dir {
+			list = append(list, n)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].path < list[j].path
+	})
+	return
+}
+
+// subtree 返回 p 及其所有子文件和子目录
+func (s *Server) subtree(p string) (list []*node) {
+	for k, n := range s.files {
+		if isSubPath(k, p) {
+			list = append(list, n)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].path < list[j].path
+	})
+	return
+}
+
+// mkdirAll 创建目录及其上级目录, 路径上存在文件时返回 false
+func (s *Server) mkdirAll(p string) bool {
+	if n := s.files[p]; n != nil {
+		return n.isdir
+	}
+	if !s.mkdirAll(path.Dir(p)) {
+		return false
+	}
+	now := time.Now().Unix()
+	s.setNode(&node{
+		fsID:  s.newID(),
+		path:  p,
+		isdir: true,
+		ctime: now,
+		mtime: now,
+	})
+	return true
+}
+
+// put 写入文件, 覆盖已存在的文件
+func (s *Server) put(p string, data []byte, blockList []string) *node {
+	if !s.mkdirAll(path.Dir(p)) {
+		return nil
+	}
+	if blockList == nil {
+		blockList = []string{md5Hex(data)}
+	}
+	now := time.Now().Unix()
+	n := &node{
+		fsID:      s.newID(),
+		path:      p,
+		data:      data,
+		md5:       md5Hex(data),
+		blockList: blockList,
+		ctime:     now,
+		mtime:     now,
+	}
+	if old := s.files[p]; old != nil {
+		if old.isdir {
+			return nil
+		}
+		n.fsID, n.ctime = old.fsID, old.ctime
+	}
+	s.setNode(n)
+	return n
+}
+
+// findByMD5 查找内容 md5 和大小相同的文件, 用于秒传
+func (s *Server) findByMD5(md5 string, size int64) *node {
+	for _, n := range s.files {
+		if !n.isdir && n.md5 == md5 && int64(len(n.data)) == size {
+			return n
+		}
+	}
+	return nil
+}
+
+func (s *Server) used() (used int64) {
+	for _, n := range s.files {
+		used += int64(len(n.data))
+	}
+	return
+}
+
+// WriteFile 直接在服务器写入文件, 自动创建上级目录
+func (s *Server) WriteFile(p string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(cleanPath(p), append([]byte(nil), data...), nil)
+}
+
+// ReadFile 直接读取服务器中的文件
+func (s *Server) ReadFile(p string) (data []byte, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.files[cleanPath(p)]
+	if n == nil || n.isdir {
+		return nil, false
+	}
+	return append([]byte(nil), n.data...), true
+}
+
+// Mkdir 直接在服务器创建目录, 自动创建上级目录
+func (s *Server) Mkdir(p string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mkdirAll(cleanPath(p))
+}
+
+// Exists 判断服务器中是否存在文件或目录
+func (s *Server) Exists(p string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.files[cleanPath(p)] != nil
+}
@@ -0,0 +1,264 @@
+package pcstest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+type (
+	// uploadSession 分片上传的会话, 保存已上传的分片
+	uploadSession struct {
+		path  string
+		parts map[int][]byte
+	}
+)
+
+// readFormFile 读取 multipart 表单中上传的文件, 文件名为空时文件内容会被解析为普通字段
+func readFormFile(r *http.Request) ([]byte, error) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"uploadedfile", "file"} {
+		if fhs := r.MultipartForm.File[key]; len(fhs) > 0 {
+			f, err := fhs[0].Open()
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return io.ReadAll(f)
+		}
+		if values := r.MultipartForm.Value[key]; len(values) > 0 {
+			return []byte(values[0]), nil
+		}
+	}
+	return nil, io.ErrUnexpectedEOF
+}
+
+// missingParts 返回会话中还未上传的分片序号
+func (us *uploadSession) missingParts(total int) []int {
+	missing := []int{}
+	for i := 0; i < total; i++ {
+		if _, ok := us.parts[i]; !ok {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+func (s *Server) handlePrecreate(w http.ResponseWriter, r *http.Request) {
+	var (
+		p          = cleanPath(r.FormValue("path"))
+		size       = formInt64(r, "size")
+		uploadID   = r.FormValue("uploadid")
+		contentMD5 = r.FormValue("content-md5")
+	)
+	blockList, err := formStringList(r, "block_list")
+	if err != nil {
+		writePanError(w, errnoParamError)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := s.files[p]; n != nil && n.isdir {
+		writePanError(w, errnoFileExists)
+		return
+	}
+
+	// 秒传
+	if contentMD5 != "" {
+		if src := s.findByMD5(contentMD5, size); src != nil {
+			n := s.put(p, src.data, src.blockList)
+			if n == nil {
+				writePanError(w, errnoFileExists)
+				return
+			}
+			writeJSON(w, map[string]interface{}{
+				"errno":       0,
+				"return_type": 2,
+				"info":        s.fd(n),
+			})
+			return
+		}
+	}
+
+	// 续传
+	us := s.uploads[uploadID]
+	if us == nil || us.path != p {
+		uploadID = "P" + strconv.FormatInt(s.newID(), 10)
+		us = &uploadSession{
+			path:  p,
+			parts: map[int][]byte{},
+		}
+		s.uploads[uploadID] = us
+	}
+	writeJSON(w, map[string]interface{}{
+		"errno":       0,
+		"return_type": 1,
+		"uploadid":    uploadID,
+		"block_list":  us.missingParts(len(blockList)),
+	})
+}
+
+func (s *Server) handleSuperfile2(w http.ResponseWriter, r *http.Request) {
+	data, err := readFormFile(r)
+	if err != nil {
+		writePCSError(w, errCodeParamError, err.Error())
+		return
+	}
+	var (
+		uploadID   = r.FormValue("uploadid")
+		partseq, _ = strconv.Atoi(r.FormValue("partseq"))
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	us := s.uploads[uploadID]
+	if us == nil {
+		writePCSError(w, errCodeUploadIDInvalid, "uploadid does not exist")
+		return
+	}
+	us.parts[partseq] = data
+	writeJSON(w, map[string]interface{}{
+		"md5":        md5Hex(data),
+		"partseq":    strconv.Itoa(partseq),
+		"uploadid":   uploadID,
+		"request_id": 1,
+	})
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var (
+		p        = cleanPath(r.FormValue("path"))
+		size     = formInt64(r, "size")
+		uploadID = r.FormValue("uploadid")
+		rtype    = r.FormValue("rtype")
+	)
+	blockList, err := formStringList(r, "block_list")
+	if err != nil {
+		writePanError(w, errnoParamError)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	us := s.uploads[uploadID]
+	if us == nil {
+		writePanError(w, errCodeUploadIDInvalid)
+		return
+	}
+
+	// 按分片顺序合并, 并校验分片的 md5
+	seqs := make([]int, 0, len(us.parts))
+	for seq := range us.parts {
+		seqs = append(seqs, seq)
+	}
+	sort.Ints(seqs)
+	if len(seqs) != len(blockList) {
+		writePanError(w, errCodeBlockMiss)
+		return
+	}
+	buf := &bytes.Buffer{}
+	for i, seq := range seqs {
+		if md5Hex(us.parts[seq]) != blockList[i] {
+			writePanError(w, errCodeBlockMiss)
+			return
+		}
+		buf.Write(us.parts[seq])
+	}
+	if int64(buf.Len()) != size {
+		writePanError(w, errCodeBlockMiss)
+		return
+	}
+
+	if old := s.files[p]; old != nil && (old.isdir || rtype != "3") {
+		writePanError(w, errnoFileExists)
+		return
+	}
+	n := s.put(p, buf.Bytes(), blockList)
+	if n == nil {
+		writePanError(w, errnoFileExists)
+		return
+	}
+	delete(s.uploads, uploadID)
+
+	fd := s.fd(n)
+	writeJSON(w, map[string]interface{}{
+		"errno":           0,
+		"fs_id":           fd.FsID,
+		"path":            fd.Path,
+		"server_filename": fd.Filename,
+		"md5":             fd.MD5,
+		"size":            fd.Size,
+		"isdir":           0,
+	})
+}
+
+// handleUpload 单文件上传, type=tmpfile 时只返回分片的 md5
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	data, err := readFormFile(r)
+	if err != nil {
+		writePCSError(w, errCodeParamError, err.Error())
+		return
+	}
+	if r.FormValue("type") == "tmpfile" {
+		writeJSON(w, map[string]interface{}{
+			"md5":        md5Hex(data),
+			"request_id": 1,
+		})
+		return
+	}
+
+	p := cleanPath(r.FormValue("path"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old := s.files[p]; old != nil && (old.isdir || r.FormValue("ondup") != "overwrite") {
+		writePCSError(w, errCodeFileExists, "file already exists")
+		return
+	}
+	n := s.put(p, data, nil)
+	if n == nil {
+		writePCSError(w, errCodeParamError, "parent is not a directory")
+		return
+	}
+	writeJSON(w, s.fd(n))
+}
+
+// handleRapidUpload 旧的秒传接口
+func (s *Server) handleRapidUpload(w http.ResponseWriter, r *http.Request) {
+	var (
+		p    = cleanPath(r.FormValue("path"))
+		size = formInt64(r, "size")
+	)
+	blockList, err := formStringList(r, "block_list")
+	if err != nil || len(blockList) == 0 {
+		writePCSError(w, errCodeParamError, "block_list invalid")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src := s.findByMD5(blockList[0], size)
+	if src == nil {
+		writePCSError(w, errCodeBlockMiss, "file md5 not found")
+		return
+	}
+	n := s.put(p, src.data, src.blockList)
+	if n == nil {
+		writePCSError(w, errCodeFileExists, "file already exists")
+		return
+	}
+	writeJSON(w, s.fd(n))
+}
+
+func (s *Server) handleLocateUpload(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"host":       r.Host,
+		"server":     []string{r.Host},
+		"request_id": 1,
+	})
+}
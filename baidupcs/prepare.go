@@ -67,7 +67,12 @@ func (pcs *BaiduPCS) sendReqReturnResp(rt reqType, op, method, urlStr string, po
 		}
 	}
 
-	resp, err := pcs.client.Req(method, urlStr, post, header)
+	urlStr = pcs.rewriteURL(urlStr)
+	ctx := pcs.Context()
+	err := pcs.governor.WaitContext(ctx, requestClass(urlStr))
+	if err == nil {
+		resp, err = pcs.client.ReqWithContext(ctx, method, urlStr, post, header)
+	}
 	if err != nil {
 		handleRespClose(resp)
 		switch rt {
@@ -86,6 +91,7 @@ func (pcs *BaiduPCS) sendReqReturnResp(rt reqType, op, method, urlStr string, po
 		}
 		panic("unreachable")
 	}
+	pcs.checkRateLimited(op, resp)
 	return resp, nil
 }
 
@@ -515,7 +521,7 @@ func (pcs *BaiduPCS) PrepareUpload(policy string, targetPath string, uploadFunc
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationUpload, pcsURL)
 
-	resp, err := uploadFunc(pcsURL.String(), pcs.client.Jar)
+	resp, err := uploadFunc(pcs.rewriteURL(pcsURL.String()), pcs.client.Jar)
 	if err != nil {
 		handleRespClose(resp)
 		return nil, &pcserror.PCSErrInfo{
@@ -541,7 +547,7 @@ func (pcs *BaiduPCS) PrepareUploadTmpFile(uploadFunc UploadFunc) (dataReadCloser
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationUploadTmpFile, pcsURL)
 
-	resp, err := uploadFunc(pcsURL.String(), pcs.client.Jar)
+	resp, err := uploadFunc(pcs.rewriteURL(pcsURL.String()), pcs.client.Jar)
 	if err != nil {
 		handleRespClose(resp)
 		return nil, &pcserror.PCSErrInfo{
@@ -623,7 +629,7 @@ func (pcs *BaiduPCS) PrepareUploadSuperfile2(uploadid, targetPath string, partse
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationUploadSuperfile2, pcsURL)
 
-	resp, err := uploadFunc(pcsURL.String(), pcs.client.Jar)
+	resp, err := uploadFunc(pcs.rewriteURL(pcsURL.String()), pcs.client.Jar)
 	if err != nil {
 		handleRespClose(resp)
 		return nil, &pcserror.PCSErrInfo{
@@ -808,7 +814,7 @@ func (pcs *BaiduPCS) PrepareRecycleList(page int) (dataReadCloser io.ReadCloser,
 	pcs.lazyInit()
 
 	panURL := pcs.generatePanURL("recycle/list", map[string]string{
-		"num":  "100",
+		"num":  strconv.Itoa(RecycleListPageSize),
 		"page": strconv.Itoa(page),
 	})
 
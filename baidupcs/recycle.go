@@ -1,9 +1,15 @@
 package baidupcs
 
 import (
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 )
 
+const (
+	// RecycleListPageSize 回收站文件列表每页的数量
+	RecycleListPageSize = 100
+)
+
 type (
 	// RecycleFDInfo 回收站中文件/目录信息
 	RecycleFDInfo struct {
@@ -62,9 +68,28 @@ func (pcs *BaiduPCS) RecycleList(page int) (fdl RecycleFDInfoList, panError pcse
 		return
 	}
 
+	// 记录原路径, 还原后更新缓存
+	cache := pcs.cacheOps().LazyInitCachePoolOp(OperationRecycleList)
+	for _, fd := range jsonData.List {
+		cache.Store(fd.FsID, expires.NewDataExpires(fd.Path, recyclePathTTL))
+	}
 	return jsonData.List, nil
 }
 
+// RecycleListAll 列出回收站全部文件, 逐页获取直到最后一页
+func (pcs *BaiduPCS) RecycleListAll() (fdl RecycleFDInfoList, panError pcserror.Error) {
+	for page := 1; ; page++ {
+		list, panError := pcs.RecycleList(page)
+		if panError != nil {
+			return fdl, panError
+		}
+		fdl = append(fdl, list...)
+		if len(list) < RecycleListPageSize {
+			return fdl, nil
+		}
+	}
+}
+
 // RecycleRestore 还原回收站文件或目录
 func (pcs *BaiduPCS) RecycleRestore(fidList ...int64) (sussFsIDList []*FsIDJSON, pcsError pcserror.Error) {
 	dataReadCloser, pcsError := pcs.PrepareRecycleRestore(fidList...)
@@ -80,7 +105,12 @@ func (pcs *BaiduPCS) RecycleRestore(fidList ...int64) (sussFsIDList []*FsIDJSON,
 	}
 
 	pcsError = pcserror.HandleJSONParse(OperationRecycleRestore, dataReadCloser, &jsonData)
-	return jsonData.Extra.List, pcsError
+	if pcsError != nil {
+		return
+	}
+
+	pcs.deleteRestoredCache(jsonData.Extra.List)
+	return jsonData.Extra.List, nil
 }
 
 // RecycleDelete 删除回收站文件或目录
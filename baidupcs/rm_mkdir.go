@@ -2,7 +2,6 @@ package baidupcs
 
 import (
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
-	"path"
 )
 
 // Remove 批量删除文件/目录
@@ -21,6 +20,7 @@ func (pcs *BaiduPCS) Remove(paths ...string) (pcsError pcserror.Error) {
 
 	// 更新缓存
 	pcs.deleteCache(allRelatedDir(paths))
+	pcs.deleteCacheTree(paths)
 	return nil
 }
 
@@ -39,6 +39,6 @@ func (pcs *BaiduPCS) Mkdir(pcspath string) (pcsError pcserror.Error) {
 	}
 
 	// 更新缓存
-	pcs.deleteCache([]string{path.Dir(pcspath)})
+	pcs.deleteCache(ancestorDirs(pcspath))
 	return
 }
@@ -0,0 +1,364 @@
+package baidupcs
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/escaper"
+)
+
+const (
+	// ShellPatternGlobstar 匹配任意层级目录的通配符
+	ShellPatternGlobstar = "**"
+
+	// shellPatternParallel 通配符匹配时同时列出目录的最大数量
+	shellPatternParallel = 4
+)
+
+type (
+	// ShellPatternPlan 通配符的展开计划
+	ShellPatternPlan struct {
+		Pattern  string   // 花括号展开后的通配符
+		Base     string   // 首个通配符之前的目录, 从该目录开始逐级匹配
+		Segments []string // 需要逐级匹配的部分, 为空时不需要列出目录
+	}
+
+	shellPatternMatcher struct {
+		s     Storage
+		sem   chan struct{}
+		mu    sync.Mutex
+		cache map[string]FileDirectoryList
+		err   pcserror.Error
+	}
+
+	// shellPatternItem 匹配到的路径及其剩余需要匹配的部分
+	shellPatternItem struct {
+		path     string
+		segments []string
+	}
+)
+
+// expandBraces 展开花括号, 如 /a/{b,c}.* 展开为 /a/b.* 和 /a/c.*, 支持嵌套.
+// 不含逗号的花括号按原样保留. 只有 pattern 同时含有其他通配符时才展开,
+// 避免文件名中的花括号被误当作通配符.
+func expandBraces(pattern string) []string {
+	if !hasShellMeta(pattern) {
+		return []string{pattern}
+	}
+	return expandAllBraces(pattern)
+}
+
+func expandAllBraces(pattern string) []string {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			end, alts := braceAlternatives(pattern, i)
+			if end < 0 || len(alts) < 2 {
+				continue
+			}
+			var (
+				prefix, suffix = pattern[:i], pattern[end+1:]
+				patterns       []string
+			)
+			for _, alt := range alts {
+				patterns = append(patterns, expandAllBraces(prefix+alt+suffix)...)
+			}
+			return patterns
+		}
+	}
+	return []string{pattern}
+}
+
+// braceAlternatives 返回 start 处花括号的结束位置和其中按逗号分隔的各部分, 花括号未闭合时返回 -1
+func braceAlternatives(pattern string, start int) (end int, alts []string) {
+	depth, last := 0, start+1
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i, append(alts, pattern[last:i])
+			}
+		case ',':
+			if depth == 1 {
+				alts = append(alts, pattern[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return -1, nil
+}
+
+// hasShellMeta 判断 segment 是否含有未转义的通配符
+func hasShellMeta(segment string) bool {
+	for i := 0; i < len(segment); i++ {
+		switch segment[i] {
+		case '\\':
+			i++
+		case '*', '?', '[':
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeShellPattern 去除转义用的反斜杠
+func unescapeShellPattern(segment string) string {
+	if !strings.Contains(segment, `\`) {
+		return segment
+	}
+	builder := &strings.Builder{}
+	for i := 0; i < len(segment); i++ {
+		if segment[i] == '\\' && i+1 < len(segment) {
+			i++
+		}
+		builder.WriteByte(segment[i])
+	}
+	return builder.String()
+}
+
+// EscapeShellPattern 转义路径中的通配符, 使其只匹配该路径本身
+func EscapeShellPattern(p string) string {
+	builder := &strings.Builder{}
+	for _, r := range p {
+		if strings.ContainsRune(`\*?[{`, r) {
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// matchShellSegment 判断文件名 name 是否匹配 segment, [!a-z] 与 [^a-z] 同义.
+// 为兼容文件名中含有中括号的情况, 把中括号当作普通字符能匹配的也视为匹配.
+func matchShellSegment(segment, name string) bool {
+	if segment == name {
+		return true
+	}
+	if matched, _ := path.Match(strings.Replace(segment, "[!", "[^", -1), name); matched {
+		return true
+	}
+	matched, _ := path.Match(escaper.Escape(segment, []rune{'['}), name)
+	return matched
+}
+
+// ParseShellPattern 展开花括号, 并拆分出每个通配符的展开计划, pattern 为绝对路径
+func ParseShellPattern(pattern string) (plans []*ShellPatternPlan, err error) {
+	for _, p := range expandBraces(pattern) {
+		p = path.Clean(p)
+		if !path.IsAbs(p) {
+			return nil, ErrMatchPathByShellPatternNotAbsPath
+		}
+
+		var (
+			base     = []string{""}
+			segments = strings.Split(p, PathSeparator)[1:]
+		)
+		if p == PathSeparator {
+			segments = nil
+		}
+		for len(segments) > 0 && segments[0] != ShellPatternGlobstar && !hasShellMeta(segments[0]) {
+			base = append(base, unescapeShellPattern(segments[0]))
+			segments = segments[1:]
+		}
+
+		plan := &ShellPatternPlan{
+			Pattern: p,
+			Base:    path.Clean(PathSeparator + strings.Join(base, PathSeparator)),
+		}
+		if len(segments) > 0 {
+			plan.Segments = segments
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+func (m *shellPatternMatcher) setErr(pcsError pcserror.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err == nil {
+		m.err = pcsError
+	}
+}
+
+func (m *shellPatternMatcher) failed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err != nil
+}
+
+// list 列出目录, 限制同时列出目录的数量, 结果会被缓存
+func (m *shellPatternMatcher) list(dir string) (fds FileDirectoryList, ok bool) {
+	m.mu.Lock()
+	fds, ok = m.cache[dir]
+	m.mu.Unlock()
+	if ok {
+		return fds, true
+	}
+
+	m.sem <- struct{}{}
+	fds, pcsError := m.s.FilesDirectoriesList(dir, DefaultOrderOptions)
+	<-m.sem
+	if pcsError != nil {
+		m.setErr(pcsError)
+		return nil, false
+	}
+
+	m.mu.Lock()
+	m.cache[dir] = fds
+	m.mu.Unlock()
+	return fds, true
+}
+
+// match 在目录 dir 中逐级匹配 segments, 按列出目录的顺序返回匹配到的路径
+func (m *shellPatternMatcher) match(dir string, segments []string) []string {
+	if len(segments) == 0 {
+		return []string{dir}
+	}
+	if m.failed() {
+		return nil
+	}
+
+	fds, ok := m.list(dir)
+	if !ok {
+		return nil
+	}
+
+	var (
+		segment = segments[0]
+		rest    = segments[1:]
+		items   []*shellPatternItem
+	)
+	if segment == ShellPatternGlobstar {
+		// 匹配零层目录
+		items = append(items, &shellPatternItem{path: dir, segments: rest})
+	}
+	for _, fd := range fds {
+		p := path.Join(dir, fd.Filename)
+		switch {
+		case segment == ShellPatternGlobstar && fd.Isdir:
+			// 继续匹配更深的目录
+			items = append(items, &shellPatternItem{path: p, segments: segments})
+		case segment == ShellPatternGlobstar:
+			if len(rest) == 0 {
+				items = append(items, &shellPatternItem{path: p})
+			}
+		case matchShellSegment(segment, fd.Filename) && (fd.Isdir || len(rest) == 0):
+			items = append(items, &shellPatternItem{path: p, segments: rest})
+		}
+	}
+
+	var (
+		results = make([][]string, len(items))
+		wg      sync.WaitGroup
+	)
+	for k := range items {
+		if len(items[k].segments) == 0 {
+			results[k] = []string{items[k].path}
+			continue
+		}
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			results[k] = m.match(items[k].path, items[k].segments)
+		}(k)
+	}
+	wg.Wait()
+
+	var pcspaths []string
+	for k := range results {
+		pcspaths = append(pcspaths, results[k]...)
+	}
+	return pcspaths
+}
+
+// MatchShellPattern 判断路径 pcspath 是否匹配通配符 pattern, 不需要访问网盘,
+// 支持的语法与 MatchStoragePathByShellPattern 相同
+func MatchShellPattern(pattern, pcspath string) (bool, error) {
+	plans, err := ParseShellPattern(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	names := strings.Split(path.Clean(pcspath), PathSeparator)[1:]
+	if pcspath == PathSeparator {
+		names = nil
+	}
+	for _, plan := range plans {
+		segments := strings.Split(plan.Pattern, PathSeparator)[1:]
+		if plan.Pattern == PathSeparator {
+			segments = nil
+		}
+		if matchShellSegments(segments, names) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchShellSegments 逐级匹配, ** 匹配零层或任意层目录
+func matchShellSegments(segments, names []string) bool {
+	for len(segments) > 0 {
+		if segments[0] == ShellPatternGlobstar {
+			for i := 0; i <= len(names); i++ {
+				if matchShellSegments(segments[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 || !matchShellSegment(segments[0], names[0]) {
+			return false
+		}
+		segments, names = segments[1:], names[1:]
+	}
+	return len(names) == 0
+}
+
+// MatchPathByShellPattern 通配符匹配文件路径, pattern 为绝对路径
+func (pcs *BaiduPCS) MatchPathByShellPattern(pattern string) (pcspaths []string, pcsError pcserror.Error) {
+	return MatchStoragePathByShellPattern(pcs, pattern)
+}
+
+// MatchStoragePathByShellPattern 在存储 s 中通配符匹配文件路径, pattern 为绝对路径.
+// 支持 * ? [a-z] [!a-z], 匹配任意层级目录的 **, 花括号展开 {a,b}, 以及使用反斜杠转义.
+// 花括号只在 pattern 同时含有其他通配符时展开, 否则按普通字符匹配.
+func MatchStoragePathByShellPattern(s Storage, pattern string) (pcspaths []string, pcsError pcserror.Error) {
+	errInfo := pcserror.NewPCSErrorInfo(OperationMatchPathByShellPattern)
+	errInfo.ErrType = pcserror.ErrTypeOthers
+
+	plans, err := ParseShellPattern(pattern)
+	if err != nil {
+		errInfo.Err = err
+		return nil, errInfo
+	}
+
+	m := &shellPatternMatcher{
+		s:     s,
+		sem:   make(chan struct{}, shellPatternParallel),
+		cache: map[string]FileDirectoryList{},
+	}
+	seen := map[string]struct{}{}
+	for _, plan := range plans {
+		for _, p := range m.match(plan.Base, plan.Segments) {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			pcspaths = append(pcspaths, p)
+		}
+		if m.err != nil {
+			return nil, m.err
+		}
+	}
+	return pcspaths, nil
+}
@@ -0,0 +1,125 @@
+package baidupcs_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+func TestParseShellPattern(t *testing.T) {
+	plans, err := baidupcs.ParseShellPattern("/logs/{2023,20{24,25}}-*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var patterns []string
+	for _, plan := range plans {
+		patterns = append(patterns, plan.Pattern)
+		if plan.Base != "/logs" || len(plan.Segments) != 1 {
+			t.Fatalf("unexpected plan %+v", plan)
+		}
+	}
+	expected := []string{"/logs/2023-*.log", "/logs/2024-*.log", "/logs/2025-*.log"}
+	if !reflect.DeepEqual(patterns, expected) {
+		t.Fatalf("got %v, expected %v", patterns, expected)
+	}
+
+	plans, err = baidupcs.ParseShellPattern(`/a/\*b/{c}/**/d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plans) != 1 || plans[0].Base != "/a/*b/{c}" || !reflect.DeepEqual(plans[0].Segments, []string{"**", "d"}) {
+		t.Fatalf("unexpected plan %+v", plans[0])
+	}
+
+	if _, err = baidupcs.ParseShellPattern("a/*"); err == nil {
+		t.Fatal("expected error for relative pattern")
+	}
+}
+
+func TestMatchStoragePathByShellPattern(t *testing.T) {
+	s := storage.NewMemory()
+	for _, p := range []string{
+		"/photos/a.jpg",
+		"/photos/b.png",
+		"/photos/2023/c.jpg",
+		"/photos/2023/trip/d.jpg",
+		"/photos/2024/e.JPG",
+		"/photos/[电影]f.jpg",
+		"/photos/*.jpg",
+		"/logs/2023-01.log",
+		"/logs/2024-01.log",
+		"/logs/2025-01.log",
+		"/logs/x/2023-02.log",
+	} {
+		if pcsError := s.WriteFile(p, strings.NewReader(p), int64(len(p))); pcsError != nil {
+			t.Fatal(pcsError)
+		}
+	}
+
+	cases := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"/photos/**/*.jpg", []string{"/photos/*.jpg", "/photos/[电影]f.jpg", "/photos/a.jpg", "/photos/2023/c.jpg", "/photos/2023/trip/d.jpg"}},
+		{"/photos/**", []string{"/photos", "/photos/2023", "/photos/2023/trip", "/photos/2023/trip/d.jpg", "/photos/2023/c.jpg", "/photos/2024", "/photos/2024/e.JPG", "/photos/*.jpg", "/photos/[电影]f.jpg", "/photos/a.jpg", "/photos/b.png"}},
+		{"/logs/{2023,2024}-*.log", []string{"/logs/2023-01.log", "/logs/2024-01.log"}},
+		{"/logs/202[!3]-*.log", []string{"/logs/2024-01.log", "/logs/2025-01.log"}},
+		{"/logs/202[3-4]-*.log", []string{"/logs/2023-01.log", "/logs/2024-01.log"}},
+		{`/photos/\*.jpg`, []string{"/photos/*.jpg"}},
+		{"/photos/[电影]*", []string{"/photos/[电影]f.jpg"}},
+		{"/*/2023/c.jpg", []string{"/photos/2023/c.jpg"}},
+		{"/photos/a.jpg", []string{"/photos/a.jpg"}},
+		{"/photos/*.gif", nil},
+	}
+	for _, c := range cases {
+		paths, pcsError := baidupcs.MatchStoragePathByShellPattern(s, c.pattern)
+		if pcsError != nil {
+			t.Fatalf("%s: %s", c.pattern, pcsError)
+		}
+		if !reflect.DeepEqual(paths, c.expected) {
+			t.Errorf("%s: got %v, expected %v", c.pattern, paths, c.expected)
+		}
+	}
+
+	if _, pcsError := baidupcs.MatchStoragePathByShellPattern(s, "/notexist/*"); pcsError == nil {
+		t.Fatal("expected error for nonexistent base")
+	}
+}
+
+func TestMatchShellPattern(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		matched       bool
+	}{
+		{"/projects/**", "/projects", true},
+		{"/projects/**", "/projects/a/b/c.txt", true},
+		{"/projects/**", "/project/a", false},
+		{"/projects/**/*.go", "/projects/main.go", true},
+		{"/projects/**/*.go", "/projects/a/b/main.go", true},
+		{"/projects/**/*.go", "/projects/a/b/main.c", false},
+		{"/{a,b}/*.txt", "/b/1.txt", true},
+		{"/{a,b}/*.txt", "/c/1.txt", false},
+		{"/a{1,2}.txt", "/a{1,2}.txt", true},
+		{"/a{1,2}.txt", "/a1.txt", false},
+		{`/\{a,b}/*.txt`, "/{a,b}/1.txt", true},
+		{`/\{a,b}/*.txt`, "/a/1.txt", false},
+		{"/a/[!0-9]*", "/a/x1", true},
+		{"/a/[!0-9]*", "/a/1x", false},
+		{`/a/\*`, "/a/*", true},
+		{`/a/\*`, "/a/b", false},
+		{"/[电影]/*", "/[电影]/a.mp4", true},
+		{"/*", "/a/b", false},
+	}
+	for _, c := range cases {
+		matched, err := baidupcs.MatchShellPattern(c.pattern, c.path)
+		if err != nil {
+			t.Fatalf("%s: %s", c.pattern, err)
+		}
+		if matched != c.matched {
+			t.Errorf("MatchShellPattern(%q, %q) = %v, expected %v", c.pattern, c.path, matched, c.matched)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package baidupcs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/multipartreader"
+)
+
+const (
+	// OperationOpenRange 读取文件内容
+	OperationOpenRange = "读取文件内容"
+	// OperationWriteFile 写入文件
+	OperationWriteFile = "写入文件"
+)
+
+type (
+	// Storage 网盘存储接口, BaiduPCS 实现了此接口,
+	// 命令可以使用其他实现在本地目录或内存中运行, 便于离线测试和复用.
+	// 路径均为以 / 开头的绝对路径, 文件或目录不存在时返回远端错误, 代码为 31066
+	Storage interface {
+		// FilesDirectoriesMeta 获取文件/目录的元信息
+		FilesDirectoriesMeta(path string) (data *FileDirectory, pcsError pcserror.Error)
+		// FilesDirectoriesList 获取目录下的文件和目录列表
+		FilesDirectoriesList(path string, options *OrderOptions) (data FileDirectoryList, pcsError pcserror.Error)
+		// OpenRange 读取文件从 offset 开始的 length 字节, length 小于 0 表示读到文件末尾
+		OpenRange(path string, offset, length int64) (readCloser io.ReadCloser, pcsError pcserror.Error)
+		// WriteFile 写入文件, 已存在的文件会被覆盖, 上级目录不存在时自动创建
+		WriteFile(path string, r io.Reader, size int64) (pcsError pcserror.Error)
+		// Remove 批量删除文件/目录
+		Remove(paths ...string) (pcsError pcserror.Error)
+		// Mkdir 创建目录
+		Mkdir(pcspath string) (pcsError pcserror.Error)
+		// Rename 重命名文件/目录
+		Rename(from, to string) (pcsError pcserror.Error)
+		// Copy 批量拷贝文件/目录, To 为目标的完整路径
+		Copy(cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error)
+		// Move 批量移动文件/目录, To 为目标的完整路径
+		Move(cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error)
+	}
+
+	// readerLen64 给 io.Reader 加上长度
+	readerLen64 struct {
+		io.Reader
+		n int64
+	}
+)
+
+var _ Storage = (*BaiduPCS)(nil)
+
+func (r *readerLen64) Len() int64 {
+	return r.n
+}
+
+// storageContext 返回存储使用的 context
+func storageContext(s Storage) context.Context {
+	if c, ok := s.(interface{ Context() context.Context }); ok {
+		return c.Context()
+	}
+	return context.Background()
+}
+
+// OpenRange 读取网盘文件从 offset 开始的 length 字节, length 小于 0 表示读到文件末尾
+func (pcs *BaiduPCS) OpenRange(path string, offset, length int64) (readCloser io.ReadCloser, pcsError pcserror.Error) {
+	pcs.lazyInit()
+	pcsURL := pcs.generatePCSURL("file", "download", map[string]string{
+		"path": path,
+	})
+	baiduPCSVerbose.Infof("%s URL: %s\n", OperationOpenRange, pcsURL)
+
+	header := map[string]string{}
+	if offset > 0 || length >= 0 {
+		rangeStr := "bytes=" + strconv.FormatInt(offset, 10) + "-"
+		if length >= 0 {
+			rangeStr += strconv.FormatInt(offset+length-1, 10)
+		}
+		header["Range"] = rangeStr
+	}
+
+	resp, pcsError := pcs.sendReqReturnResp(reqTypePCS, OperationOpenRange, http.MethodGet, pcsURL.String(), nil, header)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	pcsError = handleRespStatusError(OperationOpenRange, resp)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return resp.Body, nil
+}
+
+// WriteFile 上传单个文件到网盘, 已存在的文件会被覆盖
+func (pcs *BaiduPCS) WriteFile(path string, r io.Reader, size int64) (pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareUpload(OverWritePolicy, path, func(uploadURL string, jar http.CookieJar) (*http.Response, error) {
+		mr := multipartreader.NewMultipartReader()
+		mr.AddFormFile("file", "file", &readerLen64{Reader: r, n: size})
+		mr.CloseMultipart()
+		return pcs.client.ReqWithContext(pcs.Context(), http.MethodPost, uploadURL, mr, map[string]string{
+			"User-Agent": pcs.pcsUA,
+		})
+	})
+	if pcsError != nil {
+		return
+	}
+	defer dataReadCloser.Close()
+
+	errInfo := pcserror.NewPCSErrorInfo(OperationWriteFile)
+	pcsError = pcserror.HandleJSONParse(OperationWriteFile, dataReadCloser, errInfo)
+	if pcsError != nil {
+		return
+	}
+
+	pcs.deleteCache(ancestorDirs(path))
+	return nil
+}
@@ -0,0 +1,295 @@
+package storage
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// Local 本地目录存储, 网盘路径 / 对应本地目录 Root
+	Local struct {
+		Root string
+	}
+)
+
+var _ baidupcs.Storage = (*Local)(nil)
+
+// NewLocal 返回以 root 为根目录的本地存储
+func NewLocal(root string) *Local {
+	return &Local{
+		Root: root,
+	}
+}
+
+// localPath 网盘路径对应的本地路径
+func (l *Local) localPath(p string) string {
+	return filepath.Join(l.Root, filepath.FromSlash(cleanPath(p)))
+}
+
+func (l *Local) handleError(op string, err error) pcserror.Error {
+	if os.IsNotExist(err) {
+		return remoteError(op, errCodeFileNotExists)
+	}
+	if os.IsExist(err) {
+		return remoteError(op, errCodeFileExists)
+	}
+	return otherError(op, err)
+}
+
+func (l *Local) fileDirectory(p string, info os.FileInfo) *baidupcs.FileDirectory {
+	fd := &baidupcs.FileDirectory{
+		Path:     p,
+		Filename: path.Base(p),
+		Ctime:    info.ModTime().Unix(),
+		Mtime:    info.ModTime().Unix(),
+		Isdir:    info.IsDir(),
+	}
+	if p == baidupcs.PathSeparator {
+		fd.Filename = baidupcs.PathSeparator
+	}
+	if !fd.Isdir {
+		fd.Size = info.Size()
+		return fd
+	}
+
+	entries, err := os.ReadDir(l.localPath(p))
+	if err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				fd.Ifhassubdir = true
+				break
+			}
+		}
+	}
+	return fd
+}
+
+// FilesDirectoriesMeta 获取文件/目录的元信息, 文件的 md5 会在读取时计算
+func (l *Local) FilesDirectoriesMeta(p string) (data *baidupcs.FileDirectory, pcsError pcserror.Error) {
+	p = cleanPath(p)
+	info, err := os.Stat(l.localPath(p))
+	if err != nil {
+		return nil, l.handleError(baidupcs.OperationFilesDirectoriesMeta, err)
+	}
+	data = l.fileDirectory(p, info)
+	if !data.Isdir {
+		data.MD5 = l.md5(p)
+		data.BlockList = []string{data.MD5}
+	}
+	return data, nil
+}
+
+func (l *Local) md5(p string) string {
+	f, err := os.Open(l.localPath(p))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	m := md5.New()
+	if _, err = io.Copy(m, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// FilesDirectoriesList 获取目录下的文件和目录列表, 为节省时间不计算 md5
+func (l *Local) FilesDirectoriesList(p string, options *baidupcs.OrderOptions) (data baidupcs.FileDirectoryList, pcsError pcserror.Error) {
+	p = cleanPath(p)
+	info, err := os.Stat(l.localPath(p))
+	if err != nil {
+		return nil, l.handleError(baidupcs.OperationFilesDirectoriesList, err)
+	}
+	if !info.IsDir() {
+		return baidupcs.FileDirectoryList{l.fileDirectory(p, info)}, nil
+	}
+
+	entries, err := os.ReadDir(l.localPath(p))
+	if err != nil {
+		return nil, l.handleError(baidupcs.OperationFilesDirectoriesList, err)
+	}
+	data = make(baidupcs.FileDirectoryList, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		data = append(data, l.fileDirectory(path.Join(p, entry.Name()), info))
+	}
+	SortList(data, options)
+	return data, nil
+}
+
+// OpenRange 读取文件从 offset 开始的 length 字节
+func (l *Local) OpenRange(p string, offset, length int64) (readCloser io.ReadCloser, pcsError pcserror.Error) {
+	f, err := os.Open(l.localPath(p))
+	if err != nil {
+		return nil, l.handleError(baidupcs.OperationOpenRange, err)
+	}
+	info, err := f.Stat()
+	if err == nil && info.IsDir() {
+		err = ErrIsDir
+	}
+	if err == nil && offset > 0 {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, otherError(baidupcs.OperationOpenRange, err)
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return &limitReadCloser{
+		Reader: io.LimitReader(f, length),
+		Closer: f,
+	}, nil
+}
+
+// WriteFile 写入文件, 先写入临时文件, 完成后再替换
+func (l *Local) WriteFile(p string, r io.Reader, size int64) (pcsError pcserror.Error) {
+	localPath := l.localPath(p)
+	err := os.MkdirAll(filepath.Dir(localPath), 0755)
+	if err != nil {
+		return l.handleError(baidupcs.OperationWriteFile, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), ".write-*")
+	if err != nil {
+		return l.handleError(baidupcs.OperationWriteFile, err)
+	}
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), localPath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return l.handleError(baidupcs.OperationWriteFile, err)
+	}
+	return nil
+}
+
+// Remove 批量删除文件/目录
+func (l *Local) Remove(paths ...string) (pcsError pcserror.Error) {
+	for _, p := range paths {
+		if cleanPath(p) == baidupcs.PathSeparator {
+			return otherError(baidupcs.OperationRemove, ErrRoot)
+		}
+		localPath := l.localPath(p)
+		if _, err := os.Lstat(localPath); err != nil {
+			return l.handleError(baidupcs.OperationRemove, err)
+		}
+		if err := os.RemoveAll(localPath); err != nil {
+			return l.handleError(baidupcs.OperationRemove, err)
+		}
+	}
+	return nil
+}
+
+// Mkdir 创建目录
+func (l *Local) Mkdir(p string) (pcsError pcserror.Error) {
+	localPath := l.localPath(p)
+	if _, err := os.Lstat(localPath); err == nil {
+		return remoteError(baidupcs.OperationMkdir, errCodeFileExists)
+	}
+	if err := os.MkdirAll(localPath, 0755); err != nil {
+		return l.handleError(baidupcs.OperationMkdir, err)
+	}
+	return nil
+}
+
+// Rename 重命名文件/目录
+func (l *Local) Rename(from, to string) (pcsError pcserror.Error) {
+	return l.Move(&baidupcs.CpMvJSON{From: from, To: to})
+}
+
+// Copy 批量拷贝文件/目录
+func (l *Local) Copy(cpmvJSON ...*baidupcs.CpMvJSON) (pcsError pcserror.Error) {
+	for _, cj := range cpmvJSON {
+		from, to, pcsError := l.checkCpMv(baidupcs.OperationCopy, cj)
+		if pcsError != nil {
+			return pcsError
+		}
+		err := filepath.Walk(from, func(walkPath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(from, walkPath)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(to, rel)
+			if info.IsDir() {
+				return os.MkdirAll(target, info.Mode().Perm()|0700)
+			}
+			return copyLocalFile(target, walkPath, info.Mode().Perm())
+		})
+		if err != nil {
+			return l.handleError(baidupcs.OperationCopy, err)
+		}
+	}
+	return nil
+}
+
+// Move 批量移动文件/目录
+func (l *Local) Move(cpmvJSON ...*baidupcs.CpMvJSON) (pcsError pcserror.Error) {
+	for _, cj := range cpmvJSON {
+		from, to, pcsError := l.checkCpMv(baidupcs.OperationMove, cj)
+		if pcsError != nil {
+			return pcsError
+		}
+		if err := os.Rename(from, to); err != nil {
+			return l.handleError(baidupcs.OperationMove, err)
+		}
+	}
+	return nil
+}
+
+// checkCpMv 检查拷贝或移动的源和目标, 创建目标的上级目录, 返回本地路径
+func (l *Local) checkCpMv(op string, cj *baidupcs.CpMvJSON) (from, to string, pcsError pcserror.Error) {
+	fromPath, toPath := cleanPath(cj.From), cleanPath(cj.To)
+	if fromPath == baidupcs.PathSeparator {
+		return "", "", otherError(op, ErrRoot)
+	}
+	if isSubPath(toPath, fromPath) {
+		return "", "", otherError(op, ErrIntoItself)
+	}
+	from, to = l.localPath(fromPath), l.localPath(toPath)
+	if _, err := os.Lstat(from); err != nil {
+		return "", "", l.handleError(op, err)
+	}
+	if _, err := os.Lstat(to); err == nil {
+		return "", "", remoteError(op, errCodeFileExists)
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return "", "", l.handleError(op, err)
+	}
+	return from, to, nil
+}
+
+func copyLocalFile(dst, src string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
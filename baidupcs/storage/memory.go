@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// Memory 内存存储, 用于测试
+	Memory struct {
+		mu    sync.RWMutex
+		nodes map[string]*memNode
+		fsID  int64
+	}
+
+	memNode struct {
+		fd   baidupcs.FileDirectory
+		data []byte
+	}
+)
+
+var _ baidupcs.Storage = (*Memory)(nil)
+
+// NewMemory 返回空的内存存储, 只包含根目录
+func NewMemory() *Memory {
+	m := &Memory{
+		nodes: map[string]*memNode{},
+	}
+	m.nodes[baidupcs.PathSeparator] = m.newNode(baidupcs.PathSeparator, true, nil)
+	return m
+}
+
+func (m *Memory) newNode(p string, isdir bool, data []byte) *memNode {
+	m.fsID++
+	now := time.Now().Unix()
+	node := &memNode{
+		fd: baidupcs.FileDirectory{
+			FsID:     m.fsID,
+			Path:     p,
+			Filename: path.Base(p),
+			Ctime:    now,
+			Mtime:    now,
+			Size:     int64(len(data)),
+			Isdir:    isdir,
+		},
+		data: data,
+	}
+	if !isdir {
+		sum := md5.Sum(data)
+		node.fd.MD5 = hex.EncodeToString(sum[:])
+		node.fd.BlockList = []string{node.fd.MD5}
+	}
+	return node
+}
+
+func (m *Memory) info(node *memNode) *baidupcs.FileDirectory {
+	fd := node.fd
+	if fd.Isdir {
+		for p, n := range m.nodes {
+			if n.fd.Isdir && p != fd.Path && path.Dir(p) == fd.Path {
+				fd.Ifhassubdir = true
+				break
+			}
+		}
+	}
+	return &fd
+}
+
+// mkdirAll 创建目录及其上级目录, 调用时需持有锁
+func (m *Memory) mkdirAll(op, p string) pcserror.Error {
+	if node, ok := m.nodes[p]; ok {
+		if !node.fd.Isdir {
+			return remoteError(op, errCodeFileExists)
+		}
+		return nil
+	}
+	if pcsError := m.mkdirAll(op, path.Dir(p)); pcsError != nil {
+		return pcsError
+	}
+	m.nodes[p] = m.newNode(p, true, nil)
+	return nil
+}
+
+// FilesDirectoriesMeta 获取文件/目录的元信息
+func (m *Memory) FilesDirectoriesMeta(p string) (data *baidupcs.FileDirectory, pcsError pcserror.Error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	node, ok := m.nodes[cleanPath(p)]
+	if !ok {
+		return nil, remoteError(baidupcs.OperationFilesDirectoriesMeta, errCodeFileNotExists)
+	}
+	return m.info(node), nil
+}
+
+// FilesDirectoriesList 获取目录下的文件和目录列表
+func (m *Memory) FilesDirectoriesList(p string, options *baidupcs.OrderOptions) (data baidupcs.FileDirectoryList, pcsError pcserror.Error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p = cleanPath(p)
+	node, ok := m.nodes[p]
+	if !ok {
+		return nil, remoteError(baidupcs.OperationFilesDirectoriesList, errCodeFileNotExists)
+	}
+	if !node.fd.Isdir {
+		return baidupcs.FileDirectoryList{m.info(node)}, nil
+	}
+
+	data = baidupcs.FileDirectoryList{}
+	for childPath, child := range m.nodes {
+		if childPath != p && path.Dir(childPath) == p {
+			data = append(data, m.info(child))
+		}
+	}
+	SortList(data, options)
+	return data, nil
+}
+
+// OpenRange 读取文件从 offset 开始的 length 字节
+func (m *Memory) OpenRange(p string, offset, length int64) (readCloser io.ReadCloser, pcsError pcserror.Error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	node, ok := m.nodes[cleanPath(p)]
+	if !ok {
+		return nil, remoteError(baidupcs.OperationOpenRange, errCodeFileNotExists)
+	}
+	if node.fd.Isdir {
+		return nil, otherError(baidupcs.OperationOpenRange, ErrIsDir)
+	}
+
+	data := node.data
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	data = data[offset:]
+	if length >= 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// WriteFile 写入文件
+func (m *Memory) WriteFile(p string, r io.Reader, size int64) (pcsError pcserror.Error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return otherError(baidupcs.OperationWriteFile, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p = cleanPath(p)
+	if node, ok := m.nodes[p]; ok && node.fd.Isdir {
+		return otherError(baidupcs.OperationWriteFile, ErrIsDir)
+	}
+	if pcsError = m.mkdirAll(baidupcs.OperationWriteFile, path.Dir(p)); pcsError != nil {
+		return pcsError
+	}
+	m.nodes[p] = m.newNode(p, false, data)
+	return nil
+}
+
+// Remove 批量删除文件/目录
+func (m *Memory) Remove(paths ...string) (pcsError pcserror.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range paths {
+		p = cleanPath(p)
+		if p == baidupcs.PathSeparator {
+			return otherError(baidupcs.OperationRemove, ErrRoot)
+		}
+		if _, ok := m.nodes[p]; !ok {
+			return remoteError(baidupcs.OperationRemove, errCodeFileNotExists)
+		}
+		for childPath := range m.nodes {
+			if isSubPath(childPath, p) {
+				delete(m.nodes, childPath)
+			}
+		}
+	}
+	return nil
+}
+
+// Mkdir 创建目录
+func (m *Memory) Mkdir(p string) (pcsError pcserror.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p = cleanPath(p)
+	if _, ok := m.nodes[p]; ok {
+		return remoteError(baidupcs.OperationMkdir, errCodeFileExists)
+	}
+	return m.mkdirAll(baidupcs.OperationMkdir, p)
+}
+
+// Rename 重命名文件/目录
+func (m *Memory) Rename(from, to string) (pcsError pcserror.Error) {
+	return m.cpmv(baidupcs.OperationRename, true, &baidupcs.CpMvJSON{From: from, To: to})
+}
+
+// Copy 批量拷贝文件/目录
+func (m *Memory) Copy(cpmvJSON ...*baidupcs.CpMvJSON) (pcsError pcserror.Error) {
+	return m.cpmv(baidupcs.OperationCopy, false, cpmvJSON...)
+}
+
+// Move 批量移动文件/目录
+func (m *Memory) Move(cpmvJSON ...*baidupcs.CpMvJSON) (pcsError pcserror.Error) {
+	return m.cpmv(baidupcs.OperationMove, true, cpmvJSON...)
+}
+
+func (m *Memory) cpmv(op string, isMove bool, cpmvJSON ...*baidupcs.CpMvJSON) pcserror.Error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, cj := range cpmvJSON {
+		from, to := cleanPath(cj.From), cleanPath(cj.To)
+		if from == baidupcs.PathSeparator {
+			return otherError(op, ErrRoot)
+		}
+		if _, ok := m.nodes[from]; !ok {
+			return remoteError(op, errCodeFileNotExists)
+		}
+		if _, ok := m.nodes[to]; ok {
+			return remoteError(op, errCodeFileExists)
+		}
+		if isSubPath(to, from) {
+			return otherError(op, ErrIntoItself)
+		}
+		if pcsError := m.mkdirAll(op, path.Dir(to)); pcsError != nil {
+			return pcsError
+		}
+
+		for childPath, child := range m.nodes {
+			if !isSubPath(childPath, from) {
+				continue
+			}
+			newPath := to + childPath[len(from):]
+			node := m.newNode(newPath, child.fd.Isdir, child.data)
+			if isMove {
+				node.fd.FsID = child.fd.FsID
+				delete(m.nodes, childPath)
+			}
+			node.fd.Ctime, node.fd.Mtime = child.fd.Ctime, child.fd.Mtime
+			m.nodes[newPath] = node
+		}
+	}
+	return nil
+}
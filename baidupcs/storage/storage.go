@@ -0,0 +1,205 @@
+// Package storage 提供 baidupcs.Storage 的本地目录和内存实现, 以及存储之间的同步
+package storage
+
+import (
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+const (
+	// errCodeFileExists 文件已存在, 与百度网盘的错误代码一致
+	errCodeFileExists = 31061
+	// errCodeFileNotExists 文件或目录不存在, 与百度网盘的错误代码一致
+	errCodeFileNotExists = 31066
+)
+
+var (
+	// ErrIsDir 目标是目录
+	ErrIsDir = errors.New("is a directory")
+	// ErrNotDir 目标不是目录
+	ErrNotDir = errors.New("not a directory")
+	// ErrRoot 不能操作根目录
+	ErrRoot = errors.New("can not operate root directory")
+	// ErrIntoItself 不能将目录拷贝或移动到自身之下
+	ErrIntoItself = errors.New("can not copy or move a directory into itself")
+)
+
+// cleanPath 规范化路径, 返回以 / 开头的绝对路径
+func cleanPath(p string) string {
+	return path.Clean(baidupcs.PathSeparator + p)
+}
+
+func remoteError(op string, code int) pcserror.Error {
+	return &pcserror.PCSErrInfo{
+		Operation: op,
+		ErrType:   pcserror.ErrTypeRemoteError,
+		ErrCode:   code,
+	}
+}
+
+func otherError(op string, err error) pcserror.Error {
+	return &pcserror.PCSErrInfo{
+		Operation: op,
+		ErrType:   pcserror.ErrTypeOthers,
+		Err:       err,
+	}
+}
+
+// IsNotExist 错误是否为文件或目录不存在
+func IsNotExist(err error) bool {
+	pcsError, ok := err.(pcserror.Error)
+	return ok && pcsError.GetErrType() == pcserror.ErrTypeRemoteError && pcsError.GetRemoteErrCode() == errCodeFileNotExists
+}
+
+// isSubPath p 是否为 dir 或 dir 下的路径
+func isSubPath(p, dir string) bool {
+	return p == dir || dir == baidupcs.PathSeparator || strings.HasPrefix(p, dir+baidupcs.PathSeparator)
+}
+
+// SortList 按 options 排序, 目录排在文件前面, 与网盘返回的顺序一致
+func SortList(fdl baidupcs.FileDirectoryList, options *baidupcs.OrderOptions) {
+	if options == nil {
+		options = baidupcs.DefaultOrderOptions
+	}
+	sort.SliceStable(fdl, func(i, j int) bool {
+		a, b := fdl[i], fdl[j]
+		if a.Isdir != b.Isdir {
+			return a.Isdir
+		}
+		var less, equal bool
+		switch options.By {
+		case baidupcs.OrderByTime:
+			less, equal = a.Mtime < b.Mtime, a.Mtime == b.Mtime
+		case baidupcs.OrderBySize:
+			less, equal = a.Size < b.Size, a.Size == b.Size
+		default:
+			less, equal = a.Filename < b.Filename, a.Filename == b.Filename
+		}
+		if equal {
+			return false
+		}
+		if options.Order == baidupcs.OrderDesc {
+			return !less
+		}
+		return less
+	})
+}
+
+// limitReadCloser 限制读取长度
+type limitReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// SyncOptions 同步可选项
+type SyncOptions struct {
+	DryRun bool                              // 只返回需要同步的文件, 不执行
+	Delete bool                              // 删除目标中源不存在的文件和目录
+	OnSync func(op, srcPath, dstPath string) // 每个同步操作执行前调用, op 为 copy 或 delete
+}
+
+// Sync 将 src 中 srcDir 目录同步到 dst 中的 dstDir 目录, 只复制目标不存在或大小不同的文件,
+// 返回复制的文件数量
+func Sync(dst baidupcs.Storage, dstDir string, src baidupcs.Storage, srcDir string, opt *SyncOptions) (n int, err error) {
+	if opt == nil {
+		opt = &SyncOptions{}
+	}
+	srcDir, dstDir = cleanPath(srcDir), cleanPath(dstDir)
+
+	srcList, pcsError := src.FilesDirectoriesList(srcDir, baidupcs.DefaultOrderOptions)
+	if pcsError != nil {
+		return 0, pcsError
+	}
+	dstList, pcsError := dst.FilesDirectoriesList(dstDir, baidupcs.DefaultOrderOptions)
+	if pcsError != nil && !IsNotExist(pcsError) {
+		return 0, pcsError
+	}
+	if pcsError != nil && !opt.DryRun {
+		if pcsError = dst.Mkdir(dstDir); pcsError != nil {
+			return 0, pcsError
+		}
+	}
+
+	dstMap := make(map[string]*baidupcs.FileDirectory, len(dstList))
+	for _, fd := range dstList {
+		dstMap[fd.Filename] = fd
+	}
+
+	for _, fd := range srcList {
+		dstPath := path.Join(dstDir, fd.Filename)
+		target := dstMap[fd.Filename]
+		delete(dstMap, fd.Filename)
+
+		if target != nil && target.Isdir != fd.Isdir {
+			// 类型不同, 先删除目标
+			if opt.OnSync != nil {
+				opt.OnSync("delete", "", dstPath)
+			}
+			if !opt.DryRun {
+				if pcsError = dst.Remove(dstPath); pcsError != nil {
+					return n, pcsError
+				}
+			}
+			target = nil
+		}
+
+		if fd.Isdir {
+			m, err := Sync(dst, dstPath, src, fd.Path, opt)
+			n += m
+			if err != nil {
+				return n, err
+			}
+			continue
+		}
+
+		if target != nil && target.Size == fd.Size {
+			continue
+		}
+
+		if opt.OnSync != nil {
+			opt.OnSync("copy", fd.Path, dstPath)
+		}
+		n++
+		if opt.DryRun {
+			continue
+		}
+		err = copyFile(dst, dstPath, src, fd)
+		if err != nil {
+			return n, err
+		}
+	}
+
+	if opt.Delete {
+		for _, fd := range dstMap {
+			if opt.OnSync != nil {
+				opt.OnSync("delete", "", fd.Path)
+			}
+			if !opt.DryRun {
+				if pcsError = dst.Remove(fd.Path); pcsError != nil {
+					return n, pcsError
+				}
+			}
+		}
+	}
+	return n, nil
+}
+
+func copyFile(dst baidupcs.Storage, dstPath string, src baidupcs.Storage, fd *baidupcs.FileDirectory) error {
+	rc, pcsError := src.OpenRange(fd.Path, 0, -1)
+	if pcsError != nil {
+		return pcsError
+	}
+	defer rc.Close()
+
+	pcsError = dst.WriteFile(dstPath, rc, fd.Size)
+	if pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package storage_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+func testStorage(t *testing.T, s baidupcs.Storage) {
+	pcsError := s.WriteFile("/a/b/c.txt", strings.NewReader("hello world"), 11)
+	if pcsError != nil {
+		t.Fatalf("WriteFile: %s", pcsError)
+	}
+
+	fd, pcsError := s.FilesDirectoriesMeta("/a/b/c.txt")
+	if pcsError != nil {
+		t.Fatalf("Meta: %s", pcsError)
+	}
+	if fd.Isdir || fd.Size != 11 || fd.Filename != "c.txt" || fd.MD5 != "5eb63bbbe01eeed093cb22bb8f5acdc3" {
+		t.Fatalf("Meta: unexpected %+v", fd)
+	}
+
+	rc, pcsError := s.OpenRange("/a/b/c.txt", 6, 3)
+	if pcsError != nil {
+		t.Fatalf("OpenRange: %s", pcsError)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "wor" {
+		t.Fatalf("OpenRange: got %q", data)
+	}
+
+	if pcsError = s.Mkdir("/a/d"); pcsError != nil {
+		t.Fatalf("Mkdir: %s", pcsError)
+	}
+	if pcsError = s.Mkdir("/a/d"); pcsError == nil {
+		t.Fatalf("Mkdir: expected error on existing dir")
+	}
+
+	list, pcsError := s.FilesDirectoriesList("/a", baidupcs.DefaultOrderOptions)
+	if pcsError != nil {
+		t.Fatalf("List: %s", pcsError)
+	}
+	if len(list) != 2 || list[0].Filename != "b" || list[1].Filename != "d" {
+		t.Fatalf("List: unexpected %v", list.AllFilePaths())
+	}
+
+	pcsError = s.Copy(&baidupcs.CpMvJSON{From: "/a/b", To: "/a/d/b"})
+	if pcsError != nil {
+		t.Fatalf("Copy: %s", pcsError)
+	}
+	if _, pcsError = s.FilesDirectoriesMeta("/a/d/b/c.txt"); pcsError != nil {
+		t.Fatalf("Copy: %s", pcsError)
+	}
+	if pcsError = s.Copy(&baidupcs.CpMvJSON{From: "/a", To: "/a/x"}); pcsError == nil {
+		t.Fatalf("Copy: expected error copying into itself")
+	}
+
+	if pcsError = s.Rename("/a/b/c.txt", "/a/b/e.txt"); pcsError != nil {
+		t.Fatalf("Rename: %s", pcsError)
+	}
+	if _, pcsError = s.FilesDirectoriesMeta("/a/b/c.txt"); !storage.IsNotExist(pcsError) {
+		t.Fatalf("Rename: old path still exists")
+	}
+
+	if pcsError = s.Remove("/a/d"); pcsError != nil {
+		t.Fatalf("Remove: %s", pcsError)
+	}
+	if _, pcsError = s.FilesDirectoriesMeta("/a/d/b/c.txt"); !storage.IsNotExist(pcsError) {
+		t.Fatalf("Remove: path still exists")
+	}
+	if pcsError = s.Remove("/a/d"); !storage.IsNotExist(pcsError) {
+		t.Fatalf("Remove: expected not exist error, got %v", pcsError)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testStorage(t, storage.NewMemory())
+}
+
+func TestLocal(t *testing.T) {
+	testStorage(t, storage.NewLocal(t.TempDir()))
+}
+
+func TestSync(t *testing.T) {
+	src, dst := storage.NewMemory(), storage.NewLocal(t.TempDir())
+	for p, content := range map[string]string{
+		"/src/1.txt":     "1",
+		"/src/a/2.txt":   "22",
+		"/src/a/b/3.txt": "333",
+	} {
+		if pcsError := src.WriteFile(p, strings.NewReader(content), int64(len(content))); pcsError != nil {
+			t.Fatalf("WriteFile: %s", pcsError)
+		}
+	}
+	dst.WriteFile("/dst/a/2.txt", strings.NewReader("xx"), 2)
+	dst.WriteFile("/dst/old.txt", strings.NewReader("old"), 3)
+
+	n, err := storage.Sync(dst, "/dst", src, "/src", &storage.SyncOptions{DryRun: true})
+	if err != nil || n != 2 {
+		t.Fatalf("Sync dry run: n=%d, err=%v", n, err)
+	}
+	if _, pcsError := dst.FilesDirectoriesMeta("/dst/1.txt"); !storage.IsNotExist(pcsError) {
+		t.Fatalf("Sync dry run: file copied")
+	}
+
+	var deleted []string
+	n, err = storage.Sync(dst, "/dst", src, "/src", &storage.SyncOptions{
+		Delete: true,
+		OnSync: func(op, srcPath, dstPath string) {
+			if op == "delete" {
+				deleted = append(deleted, dstPath)
+			}
+		},
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("Sync: n=%d, err=%v", n, err)
+	}
+	if len(deleted) != 1 || deleted[0] != "/dst/old.txt" {
+		t.Fatalf("Sync: deleted %v", deleted)
+	}
+
+	rc, pcsError := dst.OpenRange("/dst/a/b/3.txt", 0, -1)
+	if pcsError != nil {
+		t.Fatalf("OpenRange: %s", pcsError)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	if !bytes.Equal(data, []byte("333")) {
+		t.Fatalf("Sync: got %q", data)
+	}
+}
@@ -1,12 +1,12 @@
 package baidupcs
 
 import (
+	"context"
 	"errors"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
 	"net/http"
 	"net/url"
-	"path"
 	"strings"
 	"time"
 )
@@ -54,6 +54,9 @@ type (
 	// UploadFunc 上传文件处理函数
 	UploadFunc func(uploadURL string, jar http.CookieJar) (resp *http.Response, err error)
 
+	// UploadContextFunc 上传文件处理函数, 需要使用 ctx 发送请求
+	UploadContextFunc func(ctx context.Context, uploadURL string, jar http.CookieJar) (resp *http.Response, err error)
+
 	// RapidUploadInfo 文件秒传信息
 	RapidUploadInfo struct {
 		Filename      string
@@ -106,7 +109,7 @@ func (pcs *BaiduPCS) RapidUpload(targetPath, policy, uploadid, contentMD5, slice
 	defer func() {
 		if pcsError == nil {
 			// 更新缓存
-			pcs.deleteCache([]string{path.Dir(targetPath)})
+			pcs.deleteCache(ancestorDirs(targetPath))
 		}
 	}()
 	pcsError, jsonData = pcs.rapidUploadV2(targetPath, policy, uploadid, strings.ToLower(contentMD5), strings.ToLower(sliceMD5), dataContent, crc32, offset, length, totalSize, dataTime, blockListMD5)
@@ -118,7 +121,7 @@ func (pcs *BaiduPCS) FakeRapidUpload(targetPath, policy string, length int64) (p
 	defer func() {
 		if pcsError == nil {
 			// 更新缓存
-			pcs.deleteCache([]string{path.Dir(targetPath)})
+			pcs.deleteCache(ancestorDirs(targetPath))
 		}
 	}()
 	if length <= MinUploadBlockSize {
@@ -217,8 +220,8 @@ func (pcs *BaiduPCS) UploadCreateSuperFile(uploadid, policy string, fileSize int
 		return errInfo
 	}
 
-	// 更新缓存, targetPath取了dir所以不受重命名策略影响
-	pcs.deleteCache([]string{path.Dir(targetPath)})
+	// 更新缓存, 上级目录可能是新建的, 全部更新; targetPath取了dir所以不受重命名策略影响
+	pcs.deleteCache(ancestorDirs(targetPath))
 	return nil
 }
 
@@ -17,6 +17,7 @@ require (
 	github.com/qjfoidnh/baidu-tools v1.2.0 //dfa5778abeed
 	github.com/tidwall/gjson v1.18.0
 	github.com/urfave/cli v1.22.5
+	go.etcd.io/bbolt v1.3.11
 	golang.org/x/crypto v0.0.0-20191011191535-87dc89f01550
 	golang.org/x/sys v0.25.0
 )
@@ -24,6 +25,7 @@ require (
 require (
 	github.com/rs/dnscache v0.0.0-20230804202142-fc85eb664529
 	golang.org/x/net v0.0.0-20190620200207-3b0461eec859
+	golang.org/x/text v0.18.0
 )
 
 require (
@@ -41,6 +43,6 @@ require (
 	github.com/shurcooL/sanitized_anchor_name v1.0.0 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
-	golang.org/x/sync v0.0.0-20190911185100-cd5d95a43a6e // indirect
+	golang.org/x/sync v0.8.0 // indirect
 	google.golang.org/protobuf v1.33.0 // indirect
 )
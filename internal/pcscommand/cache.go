@@ -0,0 +1,49 @@
+package pcscommand
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+)
+
+// RunCacheStats 输出当前帐号磁盘缓存的统计信息
+func RunCacheStats() {
+	dir, stats, err := GetBaiduPCS().DiskCacheStats()
+	if dir == "" {
+		fmt.Println("未启用磁盘缓存, 可通过 config set -disk_cache_ttl 设置")
+		return
+	}
+	if err != nil {
+		fmt.Printf("读取磁盘缓存失败, %s\n", err)
+		return
+	}
+
+	fmt.Printf("缓存目录: %s\n缓存时间: %s\n", dir, pcsconfig.Config.DiskCacheTTL)
+	var (
+		tb                      = pcstable.NewTable(os.Stdout)
+		entries, expired, total int64
+	)
+	tb.SetHeader([]string{"操作", "缓存项", "已过期", "大小"})
+	for _, stat := range stats {
+		tb.Append([]string{stat.Op, strconv.Itoa(stat.Entries), strconv.Itoa(stat.Expired), converter.ConvertFileSize(stat.Size, 2)})
+		entries += int64(stat.Entries)
+		expired += int64(stat.Expired)
+		total += stat.Size
+	}
+	tb.Append([]string{"总计", strconv.FormatInt(entries, 10), strconv.FormatInt(expired, 10), converter.ConvertFileSize(total, 2)})
+	tb.Render()
+}
+
+// RunCacheClear 清除当前帐号的内存和磁盘缓存
+func RunCacheClear() {
+	n, err := GetBaiduPCS().ClearCache()
+	if err != nil {
+		fmt.Printf("清除缓存失败, %s\n", err)
+		return
+	}
+	fmt.Printf("已清除 %d 项磁盘缓存\n", n)
+}
@@ -0,0 +1,228 @@
+package pcscommand
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscat"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+)
+
+type (
+	// CatOptions cat, head, tail 可选项
+	CatOptions struct {
+		Bytes    int64         // 输出的字节数, 不为0时忽略 Lines
+		Lines    int           // 输出的行数
+		Gunzip   bool          // 解压 .gz 文件
+		Follow   bool          // tail: 持续输出文件新增的内容
+		Interval time.Duration // tail: 检查文件是否增长的间隔
+	}
+
+	catMode int
+)
+
+const (
+	catAll catMode = iota
+	catHead
+	catTail
+
+	// DefaultFollowInterval 默认检查文件是否增长的间隔
+	DefaultFollowInterval = 5 * time.Second
+)
+
+var (
+	// ErrCatIsDir 不能输出目录
+	ErrCatIsDir = errors.New("是目录")
+)
+
+// openRangeReader 返回按范围读取网盘文件的 RangeReader 和文件大小,
+// 网盘文件通过下载链接读取, 与下载使用相同的链接检测
+func openRangeReader(pcspath string) (r pcscat.RangeReader, size int64, err error) {
+	fd, pcsError := GetStorage().FilesDirectoriesMeta(pcspath)
+	if pcsError != nil {
+		return nil, 0, pcsError
+	}
+	if fd.Isdir {
+		return nil, 0, ErrCatIsDir
+	}
+	if storage != nil || Offline {
+		return &pcscat.StorageReader{Storage: GetStorage(), Path: pcspath}, fd.Size, nil
+	}
+
+	client := pcsconfig.Config.PanHTTPClient()
+	client.SetKeepAlive(true)
+	dr, err := pcscat.NewDlinkReader(GetBaiduPCS(), client, pcspath, pcsdownload.BaiduPCSURLCheckFunc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if dr.Size < 0 {
+		dr.Size = fd.Size
+	}
+	return dr, dr.Size, nil
+}
+
+// catFile 按 mode 输出一个文件
+func catFile(w io.Writer, mode catMode, pcspath string, opt *CatOptions) (size int64, err error) {
+	r, size, err := openRangeReader(pcspath)
+	if err != nil {
+		return 0, err
+	}
+
+	if opt.Gunzip && strings.HasSuffix(strings.ToLower(pcspath), ".gz") {
+		rc, err := r.ReadRange(0, -1)
+		if err != nil {
+			return 0, err
+		}
+		defer rc.Close()
+		zr, err := gzip.NewReader(rc)
+		if err != nil {
+			return 0, err
+		}
+		defer zr.Close()
+
+		switch {
+		case mode == catAll:
+			_, err = io.Copy(w, zr)
+		case mode == catHead && opt.Bytes > 0:
+			err = pcscat.HeadBytes(w, zr, opt.Bytes)
+		case mode == catHead:
+			err = pcscat.HeadLines(w, zr, opt.Lines)
+		case opt.Bytes > 0:
+			err = pcscat.TailBytes(w, zr, opt.Bytes)
+		default:
+			err = pcscat.TailLines(w, zr, opt.Lines)
+		}
+		return size, err
+	}
+
+	switch {
+	case mode == catAll:
+		_, err = pcscat.Copy(w, r, 0, -1)
+	case mode == catHead && opt.Bytes > 0:
+		_, err = pcscat.Copy(w, r, 0, opt.Bytes)
+	case mode == catHead:
+		var rc io.ReadCloser
+		rc, err = r.ReadRange(0, -1)
+		if err != nil {
+			return size, err
+		}
+		// 读够行数后关闭连接, 不再下载剩余的数据
+		err = pcscat.HeadLines(w, rc, opt.Lines)
+		rc.Close()
+	case opt.Bytes > 0:
+		offset := size - opt.Bytes
+		if offset < 0 {
+			offset = 0
+		}
+		_, err = pcscat.Copy(w, r, offset, -1)
+	default:
+		var data []byte
+		_, data, err = pcscat.TailOffset(r, size, opt.Lines)
+		if err == nil {
+			_, err = w.Write(data)
+		}
+	}
+	return size, err
+}
+
+func runCat(mode catMode, paths []string, opt *CatOptions) {
+	if opt == nil {
+		opt = &CatOptions{}
+	}
+	paths, err := matchPathByShellPattern(paths...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	var (
+		w          = os.Stdout // 错误输出到 os.Stderr, 不混入文件内容
+		sizes      = make(map[string]int64, len(paths))
+		showHeader = mode != catAll && len(paths) > 1
+		lastHeader string
+		header     = func(pcspath string) {
+			if !showHeader || pcspath == lastHeader {
+				return
+			}
+			if lastHeader != "" {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "==> %s <==\n", pcspath)
+			lastHeader = pcspath
+		}
+	)
+	for _, pcspath := range paths {
+		header(pcspath)
+		size, err := catFile(w, mode, pcspath, opt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", pcspath, err)
+			continue
+		}
+		sizes[pcspath] = size
+	}
+
+	if mode != catTail || !opt.Follow || len(sizes) == 0 {
+		return
+	}
+	if opt.Gunzip {
+		fmt.Fprintln(os.Stderr, "解压 .gz 文件时不支持持续输出")
+		return
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = DefaultFollowInterval
+	}
+	for {
+		time.Sleep(opt.Interval)
+		for _, pcspath := range paths {
+			offset, ok := sizes[pcspath]
+			if !ok {
+				continue
+			}
+			fd, pcsError := GetStorage().FilesDirectoriesMeta(pcspath)
+			if pcsError != nil || fd.Size == offset {
+				continue
+			}
+			if fd.Size < offset {
+				fmt.Fprintf(os.Stderr, "%s: 文件被截断\n", pcspath)
+				offset = 0
+				sizes[pcspath] = 0
+			}
+			// 文件改变后下载链接可能失效, 重新获取
+			r, size, err := openRangeReader(pcspath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", pcspath, err)
+				continue
+			}
+			if size <= offset {
+				continue
+			}
+			header(pcspath)
+			n, err := pcscat.Copy(w, r, offset, size-offset)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", pcspath, err)
+			}
+			sizes[pcspath] = offset + n
+		}
+	}
+}
+
+// RunCat 输出网盘文件的全部内容
+func RunCat(paths []string, opt *CatOptions) {
+	runCat(catAll, paths, opt)
+}
+
+// RunHead 输出网盘文件开头的几行或几个字节, 只下载需要的部分
+func RunHead(paths []string, opt *CatOptions) {
+	runCat(catHead, paths, opt)
+}
+
+// RunTail 输出网盘文件结尾的几行或几个字节, 从文件末尾按范围读取
+func RunTail(paths []string, opt *CatOptions) {
+	runCat(catTail, paths, opt)
+}
@@ -9,7 +9,7 @@ import (
 // RunChangeDirectory 执行更改工作目录
 func RunChangeDirectory(targetPath string, isList bool) {
 	pcs := GetBaiduPCS()
-	err := matchPathByShellPatternOnce(&targetPath)
+	err := matchListPathByShellPatternOnce(&targetPath)
 	if err != nil {
 		fmt.Println(err)
 		return
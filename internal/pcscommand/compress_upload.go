@@ -154,7 +154,7 @@ func RunCompressUpload(localPaths []string, savePath string, opt *CompressUpload
 		executor.SetParallel(executor.Count())
 	}
 
-	executor.Execute()
+	executeTasks(executor)
 
 	fmt.Printf("\n")
 	fmt.Printf("压缩上传结束, 时间: %s\n", statistic.Elapsed()/1e6*1e6)
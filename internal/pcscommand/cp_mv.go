@@ -4,21 +4,22 @@ import (
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsjournal"
 	"path"
 	"strings"
 )
 
-// RunCopy 执行 批量拷贝文件/目录
-func RunCopy(paths ...string) {
-	runCpMvOp("copy", paths...)
+// RunCopy 执行 批量拷贝文件/目录, overwrite 为 true 时先把已存在的目标移到回收站
+func RunCopy(overwrite bool, paths ...string) {
+	runCpMvOp("copy", overwrite, paths...)
 }
 
 // RunMove 执行 批量 重命名/移动 文件/目录
 func RunMove(paths ...string) {
-	runCpMvOp("move", paths...)
+	runCpMvOp("move", false, paths...)
 }
 
-func runCpMvOp(op string, paths ...string) {
+func runCpMvOp(op string, overwrite bool, paths ...string) {
 	err := cpmvPathValid(paths...) // 检查路径的有效性, 目前只是判断数量
 	if err != nil {
 		fmt.Printf("%s path error, %s\n", op, err)
@@ -49,7 +50,7 @@ func runCpMvOp(op string, paths ...string) {
 		}
 	}
 
-	pcs := GetBaiduPCS()
+	pcs := GetStorage()
 	toInfo, pcsError := pcs.FilesDirectoriesMeta(to)
 	switch {
 	case toInfo != nil && toInfo.Path != path.Clean(to):
@@ -75,6 +76,7 @@ func runCpMvOp(op string, paths ...string) {
 				fmt.Printf("%s <-> %s\n", from[0], to)
 				return
 			}
+			recordJournal(pcsjournal.OpCopy, []*pcsjournal.Item{{Path: from[0], To: to}})
 			fmt.Println("文件/目录拷贝成功: ")
 			fmt.Printf("%s <-> %s\n", from[0], to)
 		} else { // 重命名
@@ -85,6 +87,7 @@ func runCpMvOp(op string, paths ...string) {
 				fmt.Printf("%s -> %s\n", from[0], to)
 				return
 			}
+			recordJournal(pcsjournal.OpRename, []*pcsjournal.Item{{Path: from[0], To: path.Clean(to)}})
 			fmt.Println("重命名成功: ")
 			fmt.Printf("%s -> %s\n", from[0], to)
 		}
@@ -94,33 +97,64 @@ func runCpMvOp(op string, paths ...string) {
 		return
 	}
 
-	if !toInfo.Isdir {
-		fmt.Printf("目标 %s 不是一个目录, 操作失败\n", toInfo.Path)
-		return
-	}
-
 	cj := new(baidupcs.CpMvListJSON)
-	cj.List = make([]*baidupcs.CpMvJSON, len(from))
-	for k := range from {
-		cj.List[k] = &baidupcs.CpMvJSON{
-			From: from[k],
-			To:   path.Clean(to + baidupcs.PathSeparator + path.Base(from[k])),
+	switch {
+	case toInfo.Isdir:
+		cj.List = make([]*baidupcs.CpMvJSON, len(from))
+		for k := range from {
+			cj.List[k] = &baidupcs.CpMvJSON{
+				From: from[k],
+				To:   path.Clean(to + baidupcs.PathSeparator + path.Base(from[k])),
+			}
 		}
+	case op == "copy" && overwrite && len(from) == 1:
+		// 覆盖已存在的文件
+		cj.List = []*baidupcs.CpMvJSON{{
+			From: from[0],
+			To:   toInfo.Path,
+		}}
+	default:
+		fmt.Printf("目标 %s 不是一个目录, 操作失败\n", toInfo.Path)
+		return
 	}
 
 	switch op {
 	case "copy":
+		var replaced map[string]int64
+		if overwrite {
+			replaced, err = cpOverwriteRemove(pcs, cj.List)
+			if err != nil {
+				fmt.Println(err)
+				fmt.Println("操作失败, 删除已存在的目标文件/目录失败")
+				return
+			}
+		}
+
 		err = pcs.Copy(cj.List...)
 		if err != nil {
 			fmt.Println(err)
 			fmt.Println("操作失败, 以下文件/目录拷贝失败: ")
 			fmt.Println(cj)
+			if len(replaced) > 0 {
+				// 被覆盖的目标已经删除, 记录下来以便撤销
+				items := make([]*pcsjournal.Item, 0, len(replaced))
+				for p, fsID := range replaced {
+					items = append(items, &pcsjournal.Item{Path: p, FsID: fsID})
+				}
+				recordJournal(pcsjournal.OpRemove, items)
+				fmt.Println("已存在的目标文件/目录已移到回收站, 可使用 undo 还原")
+			}
 			return
 		}
+		items := cpmvJournalItems(cj.List)
+		for _, item := range items {
+			item.Replaced = replaced[item.To]
+		}
+		recordJournal(pcsjournal.OpCopy, items)
 		fmt.Println("操作成功, 以下文件/目录拷贝成功: ")
 		fmt.Println(cj)
 	case "move":
-		err = pcs.Move(cj.List...)
+		err = moveWithJournal(pcs, cj.List...)
 		if err != nil {
 			fmt.Println(err)
 			fmt.Println("操作失败, 以下文件/目录移动失败: ")
@@ -135,6 +169,31 @@ func runCpMvOp(op string, paths ...string) {
 	return
 }
 
+// cpOverwriteRemove 把已存在的拷贝目标移到回收站, 返回被删除的目标的 fs_id
+func cpOverwriteRemove(pcs baidupcs.Storage, list []*baidupcs.CpMvJSON) (replaced map[string]int64, err error) {
+	tos := make([]string, 0, len(list))
+	for _, cpmv := range list {
+		tos = append(tos, cpmv.To)
+	}
+	replaced = fsIDs(pcs, tos)
+	if len(replaced) == 0 {
+		return nil, nil
+	}
+
+	existed := make([]string, 0, len(replaced))
+	for _, to := range tos {
+		if _, ok := replaced[to]; ok {
+			existed = append(existed, to)
+		}
+	}
+	pcsError := pcs.Remove(existed...)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	fmt.Printf("已把 %d 个已存在的目标文件/目录移到回收站\n", len(existed))
+	return replaced, nil
+}
+
 // cpmvPathValid 检查路径的有效性
 func cpmvPathValid(paths ...string) (err error) {
 	if len(paths) <= 1 {
@@ -0,0 +1,198 @@
+package pcscommand
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdedupe"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+)
+
+type (
+	// DedupeOptions 查找重复文件可选项
+	DedupeOptions struct {
+		Keep       string // 保留策略, 为空时只输出重复文件
+		Quarantine string // 把多余的文件移动到该目录
+		Delete     bool   // 删除多余的文件
+		DryRun     bool   // 只输出将要执行的操作
+		FixMD5     bool   // 修复可能不正确的 md5, 否则忽略这些文件
+		MinSize    int64  // 忽略小于 MinSize 的文件
+	}
+)
+
+// RunDedupe 执行查找重复文件
+func RunDedupe(dirs []string, opt *DedupeOptions) {
+	if opt == nil {
+		opt = &DedupeOptions{}
+	}
+	if opt.Delete && opt.Quarantine != "" {
+		fmt.Println("不能同时删除和移动多余的文件")
+		return
+	}
+	if (opt.Delete || opt.Quarantine != "") && opt.Keep == "" {
+		fmt.Println("请使用 --keep 指定保留策略")
+		return
+	}
+
+	var policy *pcsdedupe.KeepPolicy
+	if opt.Keep != "" {
+		var err error
+		policy, err = pcsdedupe.ParseKeepPolicy(opt.Keep)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if policy.Name == pcsdedupe.KeepFirstInDir {
+			policy.Dir = GetActiveUser().PathJoin(policy.Dir)
+		}
+	}
+
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	dirs, err := matchPathByShellPattern(dirs...)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	quarantine := ""
+	if opt.Quarantine != "" {
+		quarantine = GetActiveUser().PathJoin(opt.Quarantine)
+	}
+
+	// 列出所有文件, 跳过重复列出的路径和隔离目录
+	var (
+		s    = GetStorage()
+		fdl  baidupcs.FileDirectoryList
+		seen = map[string]struct{}{}
+	)
+	for _, dir := range dirs {
+		fmt.Printf("正在列出 %s ...\n", dir)
+		baidupcs.StorageRecurseList(s, dir, baidupcs.DefaultOrderOptions, func(depth int, fdPath string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+			if pcsError != nil {
+				fmt.Printf("列出 %s 失败, %s\n", fdPath, pcsError)
+				return true
+			}
+			if _, ok := seen[fd.Path]; ok {
+				return true
+			}
+			seen[fd.Path] = struct{}{}
+			if quarantine == "" || !hasPathPrefix(fd.Path, quarantine) {
+				fdl = append(fdl, fd)
+			}
+			return true
+		})
+	}
+
+	findOpt := &pcsdedupe.Options{
+		MinSize: opt.MinSize,
+		OnSkip: func(fd *baidupcs.FileDirectory, err error) {
+			if err == pcsdedupe.ErrMD5Unreliable {
+				fmt.Printf("%s MD5 不可靠, 跳过\n", fd.Path)
+				return
+			}
+			fmt.Printf("跳过 %s, %s\n", fd.Path, err)
+		},
+	}
+	// 修复 md5 会修改网盘中的文件, 只在明确指定且不是 dry-run 时进行
+	if pcs, ok := s.(*baidupcs.BaiduPCS); ok && opt.FixMD5 && !opt.DryRun {
+		findOpt.FixMD5 = func(fd *baidupcs.FileDirectory) (string, error) {
+			return fixFileMD5(pcs, fd)
+		}
+	}
+	groups := pcsdedupe.Find(fdl, findOpt)
+	if len(groups) == 0 {
+		fmt.Println("未找到重复文件")
+		return
+	}
+
+	var (
+		reclaimable int64
+		extras      baidupcs.FileDirectoryList
+	)
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "", "文件大小", "创建日期", "md5", "路径"})
+	for k, g := range groups {
+		reclaimable += g.Reclaimable()
+
+		keep := g.Files[0]
+		if policy != nil {
+			var groupExtras baidupcs.FileDirectoryList
+			keep, groupExtras = g.Split(policy)
+			extras = append(extras, groupExtras...)
+		}
+		for _, fd := range g.Files {
+			mark := ""
+			if policy != nil && fd == keep {
+				mark = "保留"
+			}
+			tb.Append([]string{strconv.Itoa(k), mark, converter.ConvertFileSize(fd.Size, 2), pcstime.FormatTime(fd.Ctime), g.MD5, fd.Path})
+		}
+	}
+	tb.Render()
+	fmt.Printf("\n重复文件: %d 组, 可释放空间: %s\n", len(groups), converter.ConvertFileSize(reclaimable, 2))
+
+	switch {
+	case opt.Delete:
+		dedupeDelete(s, extras, opt.DryRun)
+	case quarantine != "":
+		dedupeMove(s, extras, quarantine, opt.DryRun)
+	}
+}
+
+func hasPathPrefix(p, dir string) bool {
+	return p == dir || dir == baidupcs.PathSeparator || strings.HasPrefix(p, dir+baidupcs.PathSeparator)
+}
+
+func dedupeDelete(s baidupcs.Storage, extras baidupcs.FileDirectoryList, dryRun bool) {
+	paths := make([]string, 0, len(extras))
+	for _, fd := range extras {
+		paths = append(paths, fd.Path)
+		if dryRun {
+			fmt.Printf("[dry-run] 删除 %s\n", fd.Path)
+		}
+	}
+	if dryRun {
+		return
+	}
+
+	err := removeWithJournal(s, paths...)
+	if err != nil {
+		fmt.Printf("删除失败, %s\n", err)
+		return
+	}
+	fmt.Printf("已删除 %d 个多余的文件, 可在网盘文件回收站找回\n", len(paths))
+}
+
+// dedupeMove 把多余的文件移动到隔离目录, 保留原来的路径结构
+func dedupeMove(s baidupcs.Storage, extras baidupcs.FileDirectoryList, quarantine string, dryRun bool) {
+	cpmvJSONs := make([]*baidupcs.CpMvJSON, 0, len(extras))
+	for _, fd := range extras {
+		to := path.Join(quarantine, fd.Path)
+		cpmvJSONs = append(cpmvJSONs, &baidupcs.CpMvJSON{
+			From: fd.Path,
+			To:   to,
+		})
+		if dryRun {
+			fmt.Printf("[dry-run] 移动 %s -> %s\n", fd.Path, to)
+		}
+	}
+	if dryRun {
+		return
+	}
+
+	err := moveWithJournal(s, cpmvJSONs...)
+	if err != nil {
+		fmt.Printf("移动失败, %s\n", err)
+		return
+	}
+	fmt.Printf("已移动 %d 个多余的文件到 %s\n", len(cpmvJSONs), quarantine)
+}
@@ -0,0 +1,131 @@
+package pcscommand
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdiff"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+)
+
+type (
+	// DiffOptions 比较目录可选项
+	DiffOptions struct {
+		Manifest string // 与保存的清单比较, 代替目录 B
+		Save     string // 把目录 A 保存为清单
+		JSON     bool   // 输出 json 格式
+	}
+
+	diffJSON struct {
+		A string `json:"a"`
+		B string `json:"b"`
+		*pcsdiff.Result
+	}
+)
+
+var (
+	diffTypeNames = map[pcsdiff.ChangeType]string{
+		pcsdiff.OnlyInA:  "仅 A",
+		pcsdiff.OnlyInB:  "仅 B",
+		pcsdiff.Mismatch: "不同",
+		pcsdiff.Moved:    "移动",
+		pcsdiff.Unknown:  "未知",
+	}
+)
+
+// RunDiff 比较网盘目录 a 和 b, 或目录 a 和清单
+func RunDiff(a, b string, opt *DiffOptions) {
+	if opt == nil {
+		opt = &DiffOptions{}
+	}
+
+	var (
+		s      = GetStorage()
+		acUser = GetActiveUser()
+	)
+	a = acUser.PathJoin(a)
+	treeA, pcsError := pcsdiff.Walk(s, a)
+	if pcsError != nil {
+		fmt.Printf("列出 %s 失败, %s\n", a, pcsError)
+		return
+	}
+
+	if opt.Save != "" {
+		err := pcsdiff.NewManifest(a, treeA).Save(opt.Save)
+		if err != nil {
+			fmt.Printf("保存清单失败, %s\n", err)
+			return
+		}
+		if b == "" && opt.Manifest == "" {
+			fmt.Printf("已保存 %s 的清单到 %s\n", a, opt.Save)
+			return
+		}
+	}
+
+	var treeB pcsdiff.Tree
+	if opt.Manifest != "" {
+		m, err := pcsdiff.LoadManifest(opt.Manifest)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		b, treeB = opt.Manifest, m.Tree()
+	} else {
+		b = acUser.PathJoin(b)
+		treeB, pcsError = pcsdiff.Walk(s, b)
+		if pcsError != nil {
+			fmt.Printf("列出 %s 失败, %s\n", b, pcsError)
+			return
+		}
+	}
+
+	res := pcsdiff.Compare(treeA, treeB)
+	if opt.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", " ")
+		err := encoder.Encode(&diffJSON{A: a, B: b, Result: res})
+		if err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	fmt.Printf("A: %s\nB: %s\n\n", a, b)
+	if len(res.Changes) == 0 {
+		fmt.Printf("没有差异, 相同的文件: %d\n", res.Same)
+		return
+	}
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "差异", "路径", "说明"})
+	for k, c := range res.Changes {
+		tb.Append([]string{strconv.Itoa(k), diffTypeNames[c.Type], c.Path, diffDetail(c)})
+	}
+	tb.Render()
+
+	count := res.Count()
+	fmt.Printf("\n相同: %d, 仅 A: %d, 仅 B: %d, 不同: %d, 移动: %d, 未知: %d\n", res.Same, count[pcsdiff.OnlyInA], count[pcsdiff.OnlyInB], count[pcsdiff.Mismatch], count[pcsdiff.Moved], count[pcsdiff.Unknown])
+}
+
+func diffDetail(c *pcsdiff.Change) string {
+	switch c.Type {
+	case pcsdiff.Moved:
+		return "移动到 " + c.To
+	case pcsdiff.Mismatch, pcsdiff.Unknown:
+		if c.A.Size >= 0 && c.B.Size >= 0 && c.A.Size != c.B.Size && !c.A.Isdir && !c.B.Isdir {
+			return fmt.Sprintf("%s, %s -> %s", c.Reason, converter.ConvertFileSize(c.A.Size, 2), converter.ConvertFileSize(c.B.Size, 2))
+		}
+		return c.Reason
+	case pcsdiff.OnlyInA:
+		return converter.ConvertFileSize(c.A.Size, 2)
+	case pcsdiff.OnlyInB:
+		if c.B.Size < 0 {
+			return ""
+		}
+		return converter.ConvertFileSize(c.B.Size, 2)
+	}
+	return ""
+}
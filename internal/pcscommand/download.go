@@ -14,7 +14,6 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"sort"
 )
 
 type (
@@ -33,11 +32,17 @@ type (
 		ModifyMTime          bool
 		FullPath             bool
 		LinkPrefer           int
+		SaveTemplate         string // 保存路径模板
+		ConflictPolicy       string // 模板生成的保存路径冲突时的处理策略
+		Force                bool   // 忽略本地剩余空间检查
+		Order                string // 下载顺序
 	}
 
 	// LocateDownloadOption 获取下载链接可选参数
 	LocateDownloadOption struct {
 		FromPan bool
+		Format  string // 导出格式, aria2, curl, wget, json
+		Output  string // 导出到文件
 	}
 )
 
@@ -93,6 +98,37 @@ func RunDownload(paths []string, options *DownloadOptions) {
 		return
 	}
 
+	// 检查下载顺序
+	err = sortDownloadList(nil, options.Order)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// 保存路径模板
+	if _, err = pcsdownload.ParseConflictPolicy(options.ConflictPolicy); err != nil {
+		fmt.Println(err)
+		return
+	}
+	var resolver *templateSavePathResolver
+	if options.SaveTemplate != "" {
+		tmpl, err := pcsdownload.ParseSaveTemplate(options.SaveTemplate)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		base := options.SaveTo
+		if base == "" {
+			// 与默认的保存路径相同, 按帐号区分
+			base = GetActiveUser().GetSavePath("")
+		}
+		resolver, err = newTemplateSavePathResolver(tmpl, base, options.ConflictPolicy, GetActiveUser().Name)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
 	fmt.Print("\n")
 	fmt.Printf("[0] 提示: 当前下载最大并发量为: %d, 下载缓存为: %d\n", options.Parallel, cfg.CacheSize)
 
@@ -104,7 +140,7 @@ func RunDownload(paths []string, options *DownloadOptions) {
 	// 预测要下载的文件数量
 	file_dir_list := make([]*baidupcs.FileDirectory,0,10)
 	for k := range paths {
-		pcs.FilesDirectoriesRecurseList(paths[k], baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+		baidupcs.StorageRecurseList(GetStorage(), paths[k], baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
 			if pcsError != nil {
 				pcsCommandVerbose.Warnf("%s\n", pcsError)
 				return true
@@ -134,17 +170,23 @@ func RunDownload(paths []string, options *DownloadOptions) {
 			IsFailedDeque: true, // 统计失败的列表
 		}
 		statistic = &pcsdownload.DownloadStatistic{}
+		// 保存路径 => 需要的本地空间
+		spaceNeeds = map[string]int64{}
 	)
 
-	// 处理队列, 小文件优先下载
-	sort.Slice(file_dir_list, func(i, j int) bool {
-		return file_dir_list[i].Size < file_dir_list[j].Size
-	})
+	// 处理队列, 默认小文件优先下载
+	sortDownloadList(file_dir_list, options.Order)
 	for _,v := range file_dir_list {
+		// 使用模板时, 目录结构由模板决定
+		if resolver != nil && v.Isdir {
+			continue
+		}
+
 		newCfg := *cfg
 		unit := pcsdownload.DownloadTaskUnit{
 			Cfg:                  &newCfg, // 复制一份新的cfg
 			PCS:                  pcs,
+			Storage:              storage,
 			VerbosePrinter:       pcsCommandVerbose,
 			PrintFormat:          downloadPrintFormat(options.Load),
 			ParentTaskExecutor:   &executor,
@@ -166,21 +208,46 @@ func RunDownload(paths []string, options *DownloadOptions) {
 		if !options.FullPath {
 			vPath = filepath.Join(v.PreBase, filepath.Base(v.Path))
 		}
-		if options.SaveTo != "" {
-			unit.SavePath = filepath.Join(options.SaveTo, vPath)
+		if resolver != nil {
+			savePath, overwrite := resolver.Resolve(v)
+			if savePath == "" {
+				fmt.Printf("[0] 保存路径冲突或无效, 跳过: %s\n", v.Path)
+				continue
+			}
+			unit.SavePath = savePath
+			unit.IsOverwrite = unit.IsOverwrite || overwrite
 		} else {
-			// 使用默认的保存路径
-			unit.SavePath = GetActiveUser().GetSavePath(vPath)
+			base := options.SaveTo
+			if base == "" {
+				// 使用默认的保存路径
+				base = GetActiveUser().GetSavePath("")
+			}
+			unit.SavePath, err = pcsdownload.MapSavePath(base, vPath)
+			if err != nil {
+				fmt.Printf("[0] 映射保存路径失败, 跳过: %s, %s\n", v.Path, err)
+				continue
+			}
+		}
+		if !v.Isdir {
+			spaceNeeds[unit.SavePath] = downloadSpaceNeeded(unit.SavePath, v.Size, unit.IsOverwrite)
 		}
 		info := executor.Append(&unit, options.MaxRetry)
 		fmt.Printf("[%s] 加入下载队列: %s\n", info.Id(), v.Path)
 	}
 
+	// 检查本地剩余空间
+	if !options.Force && !options.IsTest && !checkLocalSpace(spaceNeeds) {
+		return
+	}
+
 	// 开始计时
 	statistic.StartTimer()
 
-	// 开始执行
-	executor.Execute()
+	// 开始执行, 同时接受提前下载请求
+	bumpDone := make(chan struct{})
+	go watchDownloadBump(&executor, bumpDone)
+	executeTasks(&executor)
+	close(bumpDone)
 
 	fmt.Printf("\n下载结束, 时间: %s, 数据总量: %s\n", statistic.Elapsed()/1e6*1e6, converter.ConvertFileSize(statistic.TotalSize()))
 
@@ -0,0 +1,225 @@
+package pcscommand
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/filelock"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DownloadOrderSize 按文件大小升序, 小文件优先
+	DownloadOrderSize = "size"
+	// DownloadOrderSizeDesc 按文件大小降序
+	DownloadOrderSizeDesc = "-size"
+	// DownloadOrderName 按文件名
+	DownloadOrderName = "name"
+	// DownloadOrderMtime 按修改时间升序
+	DownloadOrderMtime = "mtime"
+	// DownloadOrderMtimeDesc 按修改时间降序
+	DownloadOrderMtimeDesc = "-mtime"
+	// DownloadOrderPath 按网盘路径
+	DownloadOrderPath = "path"
+
+	// downloadBumpFilename 提前下载请求文件, 每行一个网盘路径
+	downloadBumpFilename = "download_bump.txt"
+	// downloadBumpInterval 检查提前下载请求的间隔
+	downloadBumpInterval = 2 * time.Second
+	// downloadBumpExpires 提前下载请求的有效期, 过期未匹配任何任务的请求被丢弃
+	downloadBumpExpires = 10 * time.Minute
+)
+
+// sortDownloadList 按指定的顺序排列下载队列
+func sortDownloadList(list []*baidupcs.FileDirectory, order string) error {
+	var less func(a, b *baidupcs.FileDirectory) bool
+	switch order {
+	case "", DownloadOrderSize:
+		less = func(a, b *baidupcs.FileDirectory) bool { return a.Size < b.Size }
+	case DownloadOrderSizeDesc:
+		less = func(a, b *baidupcs.FileDirectory) bool { return a.Size > b.Size }
+	case DownloadOrderName:
+		less = func(a, b *baidupcs.FileDirectory) bool { return a.Filename < b.Filename }
+	case DownloadOrderMtime:
+		less = func(a, b *baidupcs.FileDirectory) bool { return a.Mtime < b.Mtime }
+	case DownloadOrderMtimeDesc:
+		less = func(a, b *baidupcs.FileDirectory) bool { return a.Mtime > b.Mtime }
+	case DownloadOrderPath:
+		less = func(a, b *baidupcs.FileDirectory) bool { return a.Path < b.Path }
+	default:
+		return fmt.Errorf("未知的下载顺序: %s, 可选值: size, -size, name, mtime, -mtime, path", order)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return less(list[i], list[j])
+	})
+	return nil
+}
+
+func downloadBumpFilePath() string {
+	return filepath.Join(pcsconfig.GetConfigDir(), downloadBumpFilename)
+}
+
+// lockDownloadBump 锁定提前下载请求文件, 多个进程同时读写时不丢失请求
+func lockDownloadBump() (*filelock.Lock, error) {
+	return filelock.Acquire(downloadBumpFilePath() + ".lock")
+}
+
+type downloadBumpRequest struct {
+	pattern string
+	time    time.Time
+}
+
+// readDownloadBump 读取提前下载请求, 每行为 请求时间(unix) + 制表符 + 网盘路径
+func readDownloadBump() (reqs []downloadBumpRequest) {
+	f, err := os.Open(downloadBumpFilePath())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		req := downloadBumpRequest{pattern: line}
+		if i := strings.IndexByte(line, '\t'); i > 0 {
+			if unix, err := strconv.ParseInt(line[:i], 10, 64); err == nil {
+				req.pattern, req.time = line[i+1:], time.Unix(unix, 0)
+			}
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+// writeDownloadBump 保存提前下载请求, 没有请求时删除文件
+func writeDownloadBump(reqs []downloadBumpRequest) error {
+	if len(reqs) == 0 {
+		err := os.Remove(downloadBumpFilePath())
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	builder := &strings.Builder{}
+	for _, req := range reqs {
+		fmt.Fprintf(builder, "%d\t%s\n", req.time.Unix(), req.pattern)
+	}
+	return os.WriteFile(downloadBumpFilePath(), []byte(builder.String()), 0600)
+}
+
+// RunDownloadBump 请求正在进行的下载任务优先下载指定的文件
+func RunDownloadBump(paths []string) {
+	paths, err := matchPathByShellPattern(paths...)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	lock, err := lockDownloadBump()
+	if err != nil {
+		fmt.Printf("写入提前下载请求错误: %s\n", err)
+		return
+	}
+	defer lock.Release()
+
+	var (
+		reqs = readDownloadBump()
+		now  = time.Now()
+	)
+	for _, p := range paths {
+		reqs = append(reqs, downloadBumpRequest{pattern: p, time: now})
+	}
+	if err = writeDownloadBump(reqs); err != nil {
+		fmt.Printf("写入提前下载请求错误: %s\n", err)
+		return
+	}
+	for _, p := range paths {
+		fmt.Printf("已请求提前下载: %s\n", p)
+	}
+}
+
+// matchBumpPath 网盘路径是否匹配请求的路径, 请求的路径为目录时匹配目录下的所有文件
+func matchBumpPath(pattern, pcspath string) bool {
+	if pattern == pcspath || strings.HasPrefix(pcspath, strings.TrimSuffix(pattern, baidupcs.PathSeparator)+baidupcs.PathSeparator) {
+		return true
+	}
+	ok, _ := path.Match(pattern, pcspath)
+	return ok
+}
+
+// consumeDownloadBump 从请求中找出匹配的等待中的任务, 返回任务id和剩余的请求.
+// 匹配到任务的请求被消费, 超过有效期仍未匹配的请求被丢弃, 其余的保留给其他下载进程
+func consumeDownloadBump(reqs []downloadBumpRequest, pending []*taskframework.TaskInfoItem, now time.Time) (ids []string, left []downloadBumpRequest) {
+	for _, req := range reqs {
+		matched := false
+		for _, item := range pending {
+			unit, ok := item.Unit.(*pcsdownload.DownloadTaskUnit)
+			if !ok || !matchBumpPath(req.pattern, unit.PcsPath) {
+				continue
+			}
+			matched = true
+			ids = append(ids, item.Info.Id())
+		}
+		if !matched && now.Sub(req.time) < downloadBumpExpires {
+			left = append(left, req)
+		}
+	}
+	return
+}
+
+// handleDownloadBump 读取提前下载请求, 将匹配的任务移到队列最前
+func handleDownloadBump(executor *taskframework.TaskExecutor) {
+	if _, err := os.Stat(downloadBumpFilePath()); err != nil {
+		return
+	}
+
+	lock, err := lockDownloadBump()
+	if err != nil {
+		pcsCommandVerbose.Warnf("读取提前下载请求错误: %s\n", err)
+		return
+	}
+	defer lock.Release()
+
+	reqs := readDownloadBump()
+	if len(reqs) == 0 {
+		return
+	}
+
+	ids, left := consumeDownloadBump(reqs, executor.PendingTasks(), time.Now())
+	if n := executor.BumpToFront(ids...); n > 0 {
+		fmt.Printf("[0] 已将 %d 个文件移到下载队列最前\n", n)
+	}
+	if len(left) != len(reqs) {
+		if err = writeDownloadBump(left); err != nil {
+			pcsCommandVerbose.Warnf("写入提前下载请求错误: %s\n", err)
+		}
+	}
+}
+
+// watchDownloadBump 定期检查提前下载请求, 关闭 done 后停止
+func watchDownloadBump(executor *taskframework.TaskExecutor, done <-chan struct{}) {
+	ticker := time.NewTicker(downloadBumpInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			handleDownloadBump(executor)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package pcscommand
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+)
+
+func TestConsumeDownloadBump(t *testing.T) {
+	var (
+		executor = &taskframework.TaskExecutor{}
+		a        = executor.Append(&pcsdownload.DownloadTaskUnit{PcsPath: "/a/1.mp4"}, 0)
+		b        = executor.Append(&pcsdownload.DownloadTaskUnit{PcsPath: "/b/2.mp4"}, 0)
+		now      = time.Now()
+	)
+	reqs := []downloadBumpRequest{
+		{pattern: "/a", time: now},                                         // 匹配, 被消费
+		{pattern: "/b/*.mp4", time: now.Add(-time.Hour)},                   // 已过期但匹配, 被消费
+		{pattern: "/c", time: now.Add(-time.Minute)},                       // 未匹配, 保留
+		{pattern: "/d", time: now.Add(-downloadBumpExpires - time.Second)}, // 未匹配且过期, 丢弃
+	}
+	ids, left := consumeDownloadBump(reqs, executor.PendingTasks(), now)
+	if len(ids) != 2 || ids[0] != a.Id() || ids[1] != b.Id() {
+		t.Errorf("unexpected ids %v", ids)
+	}
+	if len(left) != 1 || left[0].pattern != "/c" {
+		t.Errorf("unexpected left %v", left)
+	}
+}
+
+func TestDownloadBumpFile(t *testing.T) {
+	t.Setenv(pcsconfig.EnvConfigDir, t.TempDir())
+	reqs := []downloadBumpRequest{{pattern: "/a b/c", time: time.Unix(1700000000, 0)}}
+	if err := writeDownloadBump(reqs); err != nil {
+		t.Fatal(err)
+	}
+	got := readDownloadBump()
+	if len(got) != 1 || got[0] != reqs[0] {
+		t.Errorf("unexpected requests %v", got)
+	}
+	if err := writeDownloadBump(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got = readDownloadBump(); len(got) != 0 {
+		t.Errorf("unexpected requests %v", got)
+	}
+}
@@ -0,0 +1,74 @@
+package pcscommand
+
+import (
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"path/filepath"
+)
+
+type (
+	// templateSavePathResolver 根据模板生成保存路径, 并处理冲突
+	templateSavePathResolver struct {
+		tmpl   *pcsdownload.SaveTemplate
+		ctx    *pcsdownload.SaveTemplateContext
+		base   string
+		policy string
+		used   map[string]struct{} // 已分配的保存路径
+	}
+)
+
+// newTemplateSavePathResolver 模板生成的相对路径保存到 base 目录, 冲突策略 policy 无效时返回错误
+func newTemplateSavePathResolver(tmpl *pcsdownload.SaveTemplate, base, policy, account string) (*templateSavePathResolver, error) {
+	policy, err := pcsdownload.ParseConflictPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	return &templateSavePathResolver{
+		tmpl:   tmpl,
+		ctx:    &pcsdownload.SaveTemplateContext{Account: account},
+		base:   base,
+		policy: policy,
+		used:   map[string]struct{}{},
+	}, nil
+}
+
+func (r *templateSavePathResolver) inUse(savePath string) bool {
+	_, ok := r.used[savePath]
+	return ok
+}
+
+// Resolve 返回保存路径, 是否覆盖本地文件, 为空则表示跳过
+func (r *templateSavePathResolver) Resolve(fd *baidupcs.FileDirectory) (savePath string, overwrite bool) {
+	savePath = r.tmpl.Render(fd, r.ctx)
+	if !filepath.IsAbs(savePath) {
+		var err error
+		savePath, err = pcsdownload.MapSavePath(r.base, savePath)
+		if err != nil {
+			return "", false
+		}
+	}
+	if absPath, err := filepath.Abs(savePath); err == nil {
+		savePath = absPath
+	}
+
+	switch {
+	case !r.inUse(savePath) && !pcsdownload.FileExist(savePath):
+		// 无冲突
+	case r.policy == pcsdownload.ConflictPolicySuffix:
+		for i := 1; ; i++ {
+			newPath := pcsdownload.SuffixSavePath(savePath, i)
+			if !r.inUse(newPath) && !pcsdownload.FileExist(newPath) {
+				savePath = newPath
+				break
+			}
+		}
+	case r.policy == pcsdownload.ConflictPolicyOverwrite && !r.inUse(savePath):
+		// 只覆盖本地已存在的文件, 队列中的重复路径仍然跳过, 避免同时写入同一个文件
+		overwrite = true
+	default:
+		return "", false
+	}
+
+	r.used[savePath] = struct{}{}
+	return savePath, overwrite
+}
@@ -0,0 +1,63 @@
+package pcscommand
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+)
+
+func TestTemplateSavePathResolver(t *testing.T) {
+	base := t.TempDir()
+	tmpl, err := pcsdownload.ParseSaveTemplate("{account}/{filename}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(base, "user", "a.txt")
+	if err = os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(existing, []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		a1 = &baidupcs.FileDirectory{Path: "/x/a.txt"}
+		a2 = &baidupcs.FileDirectory{Path: "/y/a.txt"}
+		b  = &baidupcs.FileDirectory{Path: "/x/b.txt"}
+	)
+	type result struct {
+		path      string
+		overwrite bool
+	}
+	cases := map[string][]result{
+		// 本地已存在和队列中重复的路径都跳过
+		pcsdownload.ConflictPolicySkip: {{"", false}, {"", false}, {"user/b.txt", false}},
+		// 只覆盖本地已存在的文件, 队列中重复的路径跳过
+		pcsdownload.ConflictPolicyOverwrite: {{"user/a.txt", true}, {"", false}, {"user/b.txt", false}},
+		// 依次添加序号
+		pcsdownload.ConflictPolicySuffix: {{"user/a (1).txt", false}, {"user/a (2).txt", false}, {"user/b.txt", false}},
+	}
+	for policy, expected := range cases {
+		r, err := newTemplateSavePathResolver(tmpl, base, policy, "user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, fd := range []*baidupcs.FileDirectory{a1, a2, b} {
+			savePath, overwrite := r.Resolve(fd)
+			want := expected[k]
+			if want.path != "" {
+				want.path = filepath.Join(base, filepath.FromSlash(want.path))
+			}
+			if savePath != want.path || overwrite != want.overwrite {
+				t.Errorf("%s #%d: got (%q, %v), expected (%q, %v)", policy, k, savePath, overwrite, want.path, want.overwrite)
+			}
+		}
+	}
+
+	if _, err = newTemplateSavePathResolver(tmpl, base, "rename", "user"); err == nil {
+		t.Error("expected error for unknown conflict policy")
+	}
+}
@@ -0,0 +1,329 @@
+package pcscommand
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdu"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsliner"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+)
+
+type (
+	// DuOptions 统计空间占用可选项
+	DuOptions struct {
+		Depth       int    // 显示的目录深度
+		Top         int    // 只显示占用最大的 Top 条
+		By          string // 按扩展名 (ext) 或文件类型 (category) 分组
+		Interactive bool   // 交互式浏览
+		Refresh     bool   // 忽略缓存, 重新统计
+	}
+
+	// duBrowser 交互式浏览空间占用
+	duBrowser struct {
+		result *pcsdu.Result
+		cur    *pcsdu.Node
+		sortBy pcsdu.SortBy
+		marked map[*pcsdu.Node]struct{}
+	}
+)
+
+// RunDu 执行统计空间占用
+func RunDu(dir string, opt *DuOptions) {
+	if opt == nil {
+		opt = &DuOptions{}
+	}
+	switch pcsdu.GroupBy(opt.By) {
+	case "", pcsdu.GroupByExt, pcsdu.GroupByCategory:
+	default:
+		fmt.Printf("未知的分组方式: %s, 支持: ext, category\n", opt.By)
+		return
+	}
+
+	err := matchListPathByShellPatternOnce(&dir)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var (
+		pcs    = GetBaiduPCS()
+		result *pcsdu.Result
+	)
+	if !opt.Refresh && duCacheEnabled() {
+		result, err = pcsdu.LoadCache(pcs, dir)
+		if err == nil {
+			fmt.Printf("使用 %s 的统计缓存, 使用 --refresh 重新统计\n", pcstime.FormatTime(result.ScanTime.Unix()))
+		}
+	}
+	if result == nil {
+		fmt.Printf("正在统计 %s, 文件较多时需要较长时间...\n", dir)
+		result, err = pcsdu.Scan(listStorage(), dir)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if duCacheEnabled() {
+			err = result.SaveCache(pcs)
+			if err != nil {
+				fmt.Printf("警告: 保存统计缓存失败, %s\n", err)
+			}
+		}
+	}
+
+	switch {
+	case opt.Interactive:
+		b := &duBrowser{
+			result: result,
+			cur:    result.Root,
+			sortBy: pcsdu.SortBySize,
+			marked: map[*pcsdu.Node]struct{}{},
+		}
+		b.run()
+	case opt.By != "":
+		printDuGroups(result, pcsdu.GroupBy(opt.By), opt.Top)
+	default:
+		printDu(result, opt.Depth, opt.Top)
+	}
+}
+
+// duCacheEnabled 是否使用统计缓存, 缓存属于当前帐号的网盘, 使用其他存储后端时不缓存
+func duCacheEnabled() bool {
+	return storage == nil
+}
+
+func printDu(result *pcsdu.Result, depth, top int) {
+	var nodes []*pcsdu.Node
+	result.Root.Walk(depth, func(n *pcsdu.Node, depth int) {
+		nodes = append(nodes, n)
+	})
+	if top > 0 {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].Size > nodes[j].Size
+		})
+		if len(nodes) > top {
+			nodes = nodes[:top]
+		}
+	}
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "大小", "文件数", "目录数", "目录"})
+	tb.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_LEFT})
+	for k, n := range nodes {
+		tb.Append([]string{strconv.Itoa(k), converter.ConvertFileSize(n.Size, 2), strconv.FormatInt(n.Files, 10), strconv.FormatInt(n.Dirs, 10), n.Path(result.Path)})
+	}
+	tb.Render()
+}
+
+func printDuGroups(result *pcsdu.Result, by pcsdu.GroupBy, top int) {
+	groups := result.Root.Groups(by)
+	if top > 0 && len(groups) > top {
+		groups = groups[:top]
+	}
+
+	name := "扩展名"
+	if by == pcsdu.GroupByCategory {
+		name = "类型"
+	}
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", name, "大小", "文件数", "占比"})
+	tb.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	for k, g := range groups {
+		tb.Append([]string{strconv.Itoa(k), g.Name, converter.ConvertFileSize(g.Size, 2), strconv.FormatInt(g.Files, 10), duPercent(g.Size, result.Root.Size)})
+	}
+	tb.Append([]string{"", "总计", converter.ConvertFileSize(result.Root.Size, 2), strconv.FormatInt(result.Root.Files, 10), ""})
+	tb.Render()
+}
+
+func duPercent(size, total int64) string {
+	if total <= 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(float64(size)*100/float64(total), 'f', 1, 64) + "%"
+}
+
+// duBar 返回占比的图形
+func duBar(size, total int64) string {
+	const width = 10
+	n := 0
+	if total > 0 {
+		n = int(size * width / total)
+	}
+	return "[" + strings.Repeat("#", n) + strings.Repeat(" ", width-n) + "]"
+}
+
+const duBrowserHelp = `
+	<序号>          进入目录
+	..              返回上级目录
+	s <方式>        排序, 方式: size, name, count, mtime
+	m <序号...>     标记/取消标记, 用于删除
+	d               删除已标记的文件/目录
+	h               显示帮助
+	q               退出
+`
+
+func (b *duBrowser) render() {
+	b.cur.Sort(b.sortBy)
+	fmt.Printf("\n当前目录: %s, 大小: %s, 文件数: %d, 已标记: %d\n----\n", b.cur.Path(b.result.Path), converter.ConvertFileSize(b.cur.Size, 2), b.cur.Files, len(b.marked))
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "", "大小", "占比", "", "文件数", "修改日期", "文件(目录)"})
+	tb.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT})
+	for k, n := range b.cur.Children {
+		var mark, name = "", n.Name
+		if _, ok := b.marked[n]; ok {
+			mark = "*"
+		}
+		if n.Isdir {
+			name += baidupcs.PathSeparator
+		}
+		tb.Append([]string{strconv.Itoa(k), mark, converter.ConvertFileSize(n.Size, 2), duPercent(n.Size, b.cur.Size), duBar(n.Size, b.cur.Size), strconv.FormatInt(n.Files, 10), pcstime.FormatTime(n.Mtime), name})
+	}
+	tb.Render()
+}
+
+// child 根据序号返回子文件或子目录
+func (b *duBrowser) child(s string) *pcsdu.Node {
+	k, err := strconv.Atoi(s)
+	if err != nil || k < 0 || k >= len(b.cur.Children) {
+		fmt.Printf("序号错误: %s\n", s)
+		return nil
+	}
+	return b.cur.Children[k]
+}
+
+func (b *duBrowser) run() {
+	line := pcsliner.NewLiner()
+	defer line.Close()
+
+	fmt.Print(duBrowserHelp)
+	b.render()
+	for {
+		input, err := line.State.Prompt("du> ")
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(input)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "q", "quit", "exit":
+			return
+		case "h", "help":
+			fmt.Print(duBrowserHelp)
+			continue
+		case "..":
+			if b.cur.Parent() != nil {
+				b.cur = b.cur.Parent()
+			}
+		case "s":
+			if len(fields) != 2 {
+				fmt.Println("请指定排序方式: size, name, count, mtime")
+				continue
+			}
+			switch by := pcsdu.SortBy(fields[1]); by {
+			case pcsdu.SortBySize, pcsdu.SortByName, pcsdu.SortByCount, pcsdu.SortByMtime:
+				b.sortBy = by
+			default:
+				fmt.Printf("未知的排序方式: %s\n", fields[1])
+				fmt.Print(duBrowserHelp)
+				continue
+			}
+		case "m":
+			for _, s := range fields[1:] {
+				n := b.child(s)
+				if n == nil {
+					continue
+				}
+				if _, ok := b.marked[n]; ok {
+					delete(b.marked, n)
+				} else {
+					b.marked[n] = struct{}{}
+				}
+			}
+		case "d":
+			b.deleteMarked(line)
+		default:
+			n := b.child(fields[0])
+			if n == nil {
+				continue
+			}
+			if !n.Isdir {
+				fmt.Printf("%s 不是目录\n", n.Name)
+				continue
+			}
+			b.cur = n
+		}
+		b.render()
+	}
+}
+
+// ancestorMarked 判断 n 的上级目录是否已标记
+func (b *duBrowser) ancestorMarked(n *pcsdu.Node) bool {
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		if _, ok := b.marked[p]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *duBrowser) deleteMarked(line *pcsliner.PCSLiner) {
+	if len(b.marked) == 0 {
+		fmt.Println("没有已标记的文件/目录")
+		return
+	}
+
+	var (
+		nodes = make([]*pcsdu.Node, 0, len(b.marked))
+		paths = make([]string, 0, len(b.marked))
+		size  int64
+	)
+	for n := range b.marked {
+		if !b.ancestorMarked(n) {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Path(b.result.Path) < nodes[j].Path(b.result.Path)
+	})
+	for _, n := range nodes {
+		paths = append(paths, n.Path(b.result.Path))
+		size += n.Size
+		fmt.Println(paths[len(paths)-1])
+	}
+
+	y, err := line.State.Prompt(fmt.Sprintf("确认删除以上 %d 个文件/目录, 共 %s (y/n): ", len(nodes), converter.ConvertFileSize(size, 2)))
+	if err != nil || (y != "y" && y != "Y") {
+		fmt.Println("已取消删除")
+		return
+	}
+
+	err = removeWithJournal(GetStorage(), paths...)
+	if err != nil {
+		fmt.Printf("删除失败, %s\n", err)
+		return
+	}
+	fmt.Println("删除成功, 可在网盘文件回收站找回")
+
+	for _, n := range nodes {
+		n.Remove()
+	}
+	b.marked = map[*pcsdu.Node]struct{}{}
+	if !duCacheEnabled() {
+		return
+	}
+	err = b.result.SaveCache(GetBaiduPCS())
+	if err != nil {
+		fmt.Printf("警告: 更新统计缓存失败, %s\n", err)
+	}
+}
@@ -0,0 +1,158 @@
+package pcscommand
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsfind"
+)
+
+type (
+	// findJSON -json 输出的格式
+	findJSON struct {
+		FsID  int64  `json:"fs_id"`
+		Path  string `json:"path"`
+		Size  int64  `json:"size"`
+		Isdir bool   `json:"isdir"`
+		MD5   string `json:"md5,omitempty"`
+		Ctime int64  `json:"ctime"`
+		Mtime int64  `json:"mtime"`
+	}
+)
+
+// RunFind 执行查找, args 为查找表达式
+func RunFind(dir string, args []string) {
+	q, err := pcsfind.Parse(args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// 删除和移动按实时的文件列表执行, 不使用缓存和本地索引
+	var (
+		s     = listStorage()
+		match = matchListPathByShellPatternOnce
+	)
+	if q.Modifies() {
+		s, match = GetStorage(), matchPathByShellPatternOnce
+	}
+
+	err = match(&dir)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fdl, err := pcsfind.Find(s, dir, q)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, action := range q.Actions {
+		switch action.Type {
+		case pcsfind.ActionPrint:
+			for _, fd := range fdl {
+				fmt.Println(fd.Path)
+			}
+		case pcsfind.ActionPrint0:
+			for _, fd := range fdl {
+				fmt.Printf("%s\x00", fd.Path)
+			}
+		case pcsfind.ActionJSON:
+			for _, fd := range fdl {
+				data, _ := json.Marshal(&findJSON{
+					FsID:  fd.FsID,
+					Path:  fd.Path,
+					Size:  fd.Size,
+					Isdir: fd.Isdir,
+					MD5:   fd.MD5,
+					Ctime: fd.Ctime,
+					Mtime: fd.Mtime,
+				})
+				fmt.Printf("%s\n", data)
+			}
+		case pcsfind.ActionDelete:
+			findDelete(outermostPaths(fdl))
+		case pcsfind.ActionDownload:
+			paths := outermostPaths(fdl)
+			if len(paths) == 0 {
+				continue
+			}
+			for k := range paths {
+				paths[k] = baidupcs.EscapeShellPattern(paths[k])
+			}
+			RunDownload(paths, &DownloadOptions{
+				SaveTo:   action.Arg,
+				MaxRetry: pcsdownload.DefaultDownloadMaxRetry,
+			})
+		case pcsfind.ActionMove:
+			findMove(outermostPaths(fdl), GetActiveUser().PathJoin(action.Arg))
+		}
+	}
+}
+
+// outermostPaths 返回路径, 去除已包含在其他结果目录中的路径
+func outermostPaths(fdl baidupcs.FileDirectoryList) (paths []string) {
+	dirs := map[string]struct{}{}
+	for _, fd := range fdl {
+		if fd.Isdir {
+			dirs[fd.Path] = struct{}{}
+		}
+	}
+	for _, fd := range fdl {
+		if !hasAncestorDir(fd.Path, dirs) {
+			paths = append(paths, fd.Path)
+		}
+	}
+	return
+}
+
+func hasAncestorDir(p string, dirs map[string]struct{}) bool {
+	for dir := path.Dir(p); dir != p; p, dir = dir, path.Dir(dir) {
+		if _, ok := dirs[dir]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func findDelete(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	err := removeWithJournal(GetStorage(), paths...)
+	if err != nil {
+		fmt.Printf("删除失败, %s\n", err)
+		return
+	}
+	fmt.Printf("已删除 %d 个文件/目录, 可在网盘文件回收站找回\n", len(paths))
+}
+
+func findMove(paths []string, to string) {
+	cpmvJSONs := make([]*baidupcs.CpMvJSON, 0, len(paths))
+	for _, p := range paths {
+		// 跳过目标目录本身及其中的文件
+		if p == to || strings.HasPrefix(to, p+baidupcs.PathSeparator) || strings.HasPrefix(p, to+baidupcs.PathSeparator) {
+			continue
+		}
+		cpmvJSONs = append(cpmvJSONs, &baidupcs.CpMvJSON{
+			From: p,
+			To:   path.Join(to, path.Base(p)),
+		})
+	}
+	if len(cpmvJSONs) == 0 {
+		return
+	}
+
+	err := moveWithJournal(GetStorage(), cpmvJSONs...)
+	if err != nil {
+		fmt.Printf("移动失败, %s\n", err)
+		return
+	}
+	fmt.Printf("已移动 %d 个文件/目录到 %s\n", len(cpmvJSONs), to)
+}
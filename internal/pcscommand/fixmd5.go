@@ -1,12 +1,78 @@
 package pcscommand
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsfixmd5"
+)
+
+type (
+	// FixMD5Options 修复md5可选项
+	FixMD5Options struct {
+		Recurse  bool   // 递归修复目录中的文件
+		Parallel int    // 同时修复的文件数
+		Report   string // 保存修复报告到本地文件
+		Restart  bool   // 忽略上次的进度, 重新开始
+	}
 )
 
-// RunFixMD5 执行修复md5
-func RunFixMD5(pcspaths ...string) {
+// fixMD5StateFile 返回修复进度文件的路径, 相同的帐号和参数使用相同的进度文件
+func fixMD5StateFile(absPaths []string, recurse bool) string {
+	paths := append([]string{}, absPaths...)
+	sort.Strings(paths)
+	key := md5.Sum([]byte(strconv.FormatBool(recurse) + "\n" + strings.Join(paths, "\n")))
+	return filepath.Join(pcsconfig.GetConfigDir(), "fixmd5", strconv.FormatUint(GetActiveUser().UID, 10)+"-"+hex.EncodeToString(key[:8])+".jsonl")
+}
+
+// fixMD5Files 列出需要检查的文件, recurse 为 true 时递归列出目录中的文件
+func fixMD5Files(pcs *baidupcs.BaiduPCS, absPaths []string, recurse bool) (fdl baidupcs.FileDirectoryList) {
+	if recurse {
+		for _, p := range absPaths {
+			baidupcs.StorageRecurseList(pcs, p, baidupcs.DefaultOrderOptions, func(depth int, fdPath string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+				if pcsError != nil {
+					fmt.Printf("列出 %s 失败, %s\n", fdPath, pcsError)
+					return true
+				}
+				if !fd.Isdir {
+					fdl = append(fdl, fd)
+				}
+				return true
+			})
+		}
+		return
+	}
+
+	finfoList, pcsError := pcs.FilesDirectoriesBatchMeta(absPaths...)
+	if pcsError != nil {
+		fmt.Println(pcsError)
+		return nil
+	}
+	for _, finfo := range finfoList {
+		if finfo.Isdir {
+			fmt.Printf("[%s] 是目录, 使用 -r 修复目录中的文件\n", finfo.Path)
+			continue
+		}
+		fdl = append(fdl, finfo)
+	}
+	return
+}
+
+// RunFixMD5 执行修复md5, 只修复 md5 可能不正确的文件, 同时修复多个文件.
+// 每修复一个文件记录进度, 中断后再次运行相同的命令, 跳过已完成的文件, 重试失败的文件
+func RunFixMD5(pcspaths []string, opt *FixMD5Options) {
+	if opt == nil {
+		opt = &FixMD5Options{}
+	}
 	absPaths, err := matchPathByShellPattern(pcspaths...)
 	if err != nil {
 		fmt.Println(err)
@@ -14,23 +80,60 @@ func RunFixMD5(pcspaths ...string) {
 	}
 
 	pcs := GetBaiduPCS()
-	finfoList, err := pcs.FilesDirectoriesBatchMeta(absPaths...)
+	fdl := fixMD5Files(pcs, absPaths, opt.Recurse)
+	if len(fdl) == 0 {
+		return
+	}
+
+	stateFile := fixMD5StateFile(absPaths, opt.Recurse)
+	if opt.Restart {
+		os.Remove(stateFile)
+	}
+	state, err := pcsfixmd5.OpenState(stateFile)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Printf("打开修复进度文件失败, %s\n", err)
 		return
 	}
 
-	for k, finfo := range finfoList {
-		err := pcs.FixMD5ByFileInfo(finfo)
-		if err == nil {
-			fmt.Printf("[%d] - [%s] 修复md5成功\n", k, finfo.Path)
-			continue
+	var (
+		k      int
+		report = pcsfixmd5.Run(fdl, func(fd *baidupcs.FileDirectory) (string, error) {
+			return fixFileMD5(pcs, fd)
+		}, nil, opt.Parallel, state, func(res *pcsfixmd5.Result) {
+			k++
+			switch res.Status {
+			case pcsfixmd5.StatusFixed:
+				fmt.Printf("[%d] - [%s] 修复md5成功\n", k, res.Path)
+			case pcsfixmd5.StatusFailed:
+				fmt.Printf("[%d] - [%s] 修复md5失败, 错误信息: %s\n", k, res.Path, res.Err)
+			}
+		})
+	)
+
+	fmt.Printf("\n修复成功: %d, 修复失败: %d, 不需要修复: %d", len(report.Fixed), len(report.Failed), len(report.Unchanged))
+	if report.Skipped > 0 {
+		fmt.Printf(", 上次已完成: %d", report.Skipped)
+	}
+	fmt.Println()
+	for _, res := range report.Failed {
+		if strings.Contains(res.Err, baidupcs.ErrFixMD5Failed.Error()) {
+			fmt.Println("修复md5失败可能是服务器未刷新, 可过几天后再尝试")
+			break
 		}
+	}
 
-		if err.GetError() == baidupcs.ErrFixMD5Failed {
-			fmt.Printf("[%d] - [%s] 修复md5失败, 可能是服务器未刷新\n", k, finfo.Path)
-			continue
+	if opt.Report != "" {
+		if err = report.Save(opt.Report); err != nil {
+			fmt.Printf("保存修复报告失败, %s\n", err)
+		} else {
+			fmt.Printf("修复报告已保存到 %s\n", opt.Report)
 		}
-		fmt.Printf("[%d] - [%s] 修复md5失败, 错误信息: %s\n", k, finfo.Path, err)
 	}
+
+	if len(report.Failed) == 0 {
+		state.Remove()
+		return
+	}
+	state.Close()
+	fmt.Println("再次运行相同的命令, 将跳过已完成的文件, 重试失败的文件")
 }
@@ -0,0 +1,143 @@
+package pcscommand
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscat"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+)
+
+type (
+	// HashOptions 计算网盘文件摘要可选项
+	HashOptions struct {
+		Algorithms []checksum.Algorithm // 摘要算法, 默认 sha256
+		Parallel   int                  // 同时下载的连接数, 默认使用 max_parallel
+		BlockSize  int64                // 每个连接每次下载的大小
+		Output     string               // 输出到本地文件, 为空时输出到标准输出
+	}
+
+	// hashTarget 要计算摘要的文件
+	hashTarget struct {
+		Path string // 网盘路径
+		Name string // 输出的路径, 相对于参数中的目录, 参数为文件时为文件名
+	}
+)
+
+// hashFiles 列出 paths 中的文件, 目录递归列出其中的文件.
+// 输出的路径相对于参数中的目录, 在本地对应的目录中可以直接使用 sha256sum -c 校验
+func hashFiles(s baidupcs.Storage, paths []string) (files []*hashTarget) {
+	for _, p := range paths {
+		base := path.Dir(p)
+		baidupcs.StorageRecurseList(s, p, baidupcs.DefaultOrderOptions, func(depth int, fdPath string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+			if pcsError != nil {
+				fmt.Fprintf(os.Stderr, "列出 %s 失败, %s\n", fdPath, pcsError)
+				return true
+			}
+			if depth == 0 && fd.Isdir {
+				base = fd.Path
+			}
+			if !fd.Isdir {
+				files = append(files, &hashTarget{
+					Path: fd.Path,
+					Name: strings.TrimPrefix(strings.TrimPrefix(fd.Path, base), baidupcs.PathSeparator),
+				})
+			}
+			return true
+		})
+	}
+	return
+}
+
+// hashFile 下载文件并计算摘要, 不保存到本地
+func hashFile(pcspath string, opt *HashOptions) (sums []string, err error) {
+	r, size, err := openRangeReader(pcspath)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cws     = make([]checksum.ChecksumWriter, 0, len(opt.Algorithms))
+		writers = make([]io.Writer, 0, len(opt.Algorithms))
+	)
+	for _, algo := range opt.Algorithms {
+		cw := algo.NewChecksumWriter()
+		cws = append(cws, cw)
+		writers = append(writers, cw)
+	}
+	n, err := pcscat.CopyParallel(io.MultiWriter(writers...), r, size, opt.BlockSize, opt.Parallel)
+	if err != nil {
+		return nil, err
+	}
+	if n != size {
+		return nil, fmt.Errorf("读取的数据大小 %d 与文件大小 %d 不一致", n, size)
+	}
+
+	sums = make([]string, 0, len(cws))
+	for _, cw := range cws {
+		sums = append(sums, checksum.FormatSum(cw.Sum()))
+	}
+	return sums, nil
+}
+
+// RunHash 流式下载网盘文件, 计算 sha256 等摘要, 输出与 sha256sum 兼容的格式.
+// 只使用一种算法时输出 "摘要  路径", 多种算法时输出 BSD 风格 "SHA256 (路径) = 摘要",
+// 路径相对于参数中的目录. crc32 没有可以校验的标准工具, 只用于显示
+func RunHash(paths []string, opt *HashOptions) {
+	if opt == nil {
+		opt = &HashOptions{}
+	}
+	if len(opt.Algorithms) == 0 {
+		opt.Algorithms = []checksum.Algorithm{checksum.AlgorithmSHA256}
+	}
+	if opt.Parallel <= 0 {
+		opt.Parallel = pcsconfig.Config.MaxParallel
+	}
+
+	paths, err := matchPathByShellPattern(paths...)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var out io.Writer = os.Stdout
+	if opt.Output != "" {
+		f, err := os.Create(opt.Output)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+
+	var (
+		files  = hashFiles(listStorage(), paths)
+		tag    = len(opt.Algorithms) > 1
+		failed int
+	)
+	for k, file := range files {
+		sums, err := hashFile(file.Path, opt)
+		if err != nil {
+			failed++
+			// 错误输出到标准错误, 不影响摘要的输出
+			fmt.Fprintf(os.Stderr, "%s: %s\n", file.Path, err)
+			continue
+		}
+		for i, algo := range opt.Algorithms {
+			fmt.Fprintln(out, checksum.FormatSumLine(algo, sums[i], file.Name, tag))
+		}
+		if opt.Output != "" {
+			fmt.Printf("[%d/%d] %s\n", k+1, len(files), file.Path)
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d 个文件计算失败\n", failed)
+	}
+}
@@ -0,0 +1,26 @@
+package pcscommand
+
+import (
+	"strings"
+	"testing"
+
+	pcsstorage "github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+func TestHashFiles(t *testing.T) {
+	s := pcsstorage.NewMemory()
+	for _, p := range []string{"/a/1.txt", "/a/sub/2.txt", "/b.txt"} {
+		if pcsError := s.WriteFile(p, strings.NewReader("x"), 1); pcsError != nil {
+			t.Fatal(pcsError)
+		}
+	}
+
+	var got []string
+	for _, file := range hashFiles(s, []string{"/a", "/b.txt", "/"}) {
+		got = append(got, file.Path+"="+file.Name)
+	}
+	expected := "/a/sub/2.txt=sub/2.txt /a/1.txt=1.txt /b.txt=b.txt /a/sub/2.txt=a/sub/2.txt /a/1.txt=a/1.txt /b.txt=b.txt"
+	if strings.Join(got, " ") != expected {
+		t.Errorf("got %v, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,274 @@
+package pcscommand
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsindex"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+)
+
+type (
+	// IndexQueryOptions 查询本地索引可选项
+	IndexQueryOptions struct {
+		Path    string // 搜索的目录
+		MD5     string // 按 md5 查找文件
+		FsID    int64  // 按 fs_id 查找
+		Total   bool   // 输出详细信息
+		Recurse bool   // 递归搜索
+	}
+
+	// indexFallback 网络不可用时从本地索引列出文件, 启用磁盘缓存时使用缓存的目录列表
+	indexFallback struct {
+		*baidupcs.BaiduPCS
+	}
+)
+
+var (
+	// Offline 离线模式, 从本地索引列出文件, 不访问网络
+	Offline bool
+
+	// ErrIndexNotBuilt 没有本地索引
+	ErrIndexNotBuilt = errors.New("没有本地索引, 请先运行 index build")
+
+	loadedIndex    *pcsindex.Index
+	loadedIndexUID uint64
+	loadedIndexMu  sync.Mutex
+	offlineWarning sync.Once
+	fallbackNotice sync.Once
+)
+
+// indexFilename 返回帐号的本地索引文件
+func indexFilename(uid uint64) string {
+	return filepath.Join(pcsconfig.GetConfigDir(), "index", strconv.FormatUint(uid, 10)+".db")
+}
+
+// loadIndex 只读打开当前帐号的本地索引, 打开后缓存
+func loadIndex() (*pcsindex.Index, error) {
+	loadedIndexMu.Lock()
+	defer loadedIndexMu.Unlock()
+
+	uid := GetActiveUser().UID
+	if loadedIndex != nil && loadedIndexUID == uid {
+		return loadedIndex, nil
+	}
+	idx, err := pcsindex.OpenReadOnly(indexFilename(uid))
+	if os.IsNotExist(err) {
+		return nil, ErrIndexNotBuilt
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取本地索引失败, %s", err)
+	}
+	if loadedIndex != nil {
+		loadedIndex.Close()
+	}
+	loadedIndex, loadedIndexUID = idx, uid
+	return idx, nil
+}
+
+// closeIndex 关闭已打开的本地索引, 建立或更新索引前调用
+func closeIndex() {
+	loadedIndexMu.Lock()
+	defer loadedIndexMu.Unlock()
+	if loadedIndex != nil {
+		loadedIndex.Close()
+		loadedIndex = nil
+	}
+}
+
+// offlineStorage 返回离线模式使用的本地索引, 没有索引时返回空索引
+func offlineStorage() baidupcs.Storage {
+	idx, err := loadIndex()
+	if err != nil {
+		offlineWarning.Do(func() {
+			fmt.Println(err)
+		})
+		return pcsindex.New()
+	}
+	return idx
+}
+
+// listStorage 返回列出文件使用的存储后端, 离线模式或网络不可用时使用本地索引.
+// 只用于不修改网盘文件的命令, 修改文件的命令使用 GetStorage, 不经过磁盘缓存和本地索引
+func listStorage() baidupcs.Storage {
+	if storage != nil || Offline {
+		return GetStorage()
+	}
+	return &indexFallback{BaiduPCS: GetBaiduPCS()}
+}
+
+// index 发生网络错误时返回本地索引
+func (f *indexFallback) index(pcsError pcserror.Error) *pcsindex.Index {
+	if pcsError == nil || pcsError.GetErrType() != pcserror.ErrTypeNetError {
+		return nil
+	}
+	idx, err := loadIndex()
+	if err != nil {
+		return nil
+	}
+	fallbackNotice.Do(func() {
+		fmt.Printf("网络不可用, 使用本地索引 (更新于 %s)\n", idx.Updated.Format("2006-01-02 15:04:05"))
+	})
+	return idx
+}
+
+// FilesDirectoriesMeta 获取文件/目录的元信息, 网络不可用时从本地索引获取
+func (f *indexFallback) FilesDirectoriesMeta(path string) (data *baidupcs.FileDirectory, pcsError pcserror.Error) {
+	data, pcsError = f.BaiduPCS.FilesDirectoriesMeta(path)
+	if idx := f.index(pcsError); idx != nil {
+		return idx.FilesDirectoriesMeta(path)
+	}
+	return
+}
+
+// FilesDirectoriesList 获取目录下的文件和目录列表, 网络不可用时从本地索引获取
+func (f *indexFallback) FilesDirectoriesList(path string, options *baidupcs.OrderOptions) (data baidupcs.FileDirectoryList, pcsError pcserror.Error) {
+	if pcsconfig.Config.DiskCacheTTL != "" {
+		data, pcsError = f.BaiduPCS.CacheFilesDirectoriesList(path, options)
+	} else {
+		data, pcsError = f.BaiduPCS.FilesDirectoriesList(path, options)
+	}
+	if idx := f.index(pcsError); idx != nil {
+		return idx.FilesDirectoriesList(path, options)
+	}
+	return
+}
+
+// searchIndex 在本地索引中搜索
+func searchIndex(targetPath, keyword string, recurse bool) (baidupcs.FileDirectoryList, error) {
+	idx, err := loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	return idx.FileDirectories(idx.Search(targetPath, keyword, recurse)), nil
+}
+
+// CompleteList 列出目录用于 tab 自动补全, 离线模式或网络不可用时使用本地索引
+func CompleteList(dir string) (baidupcs.FileDirectoryList, error) {
+	if Offline {
+		idx, err := loadIndex()
+		if err != nil {
+			return nil, err
+		}
+		return idx.FilesDirectoriesList(dir, baidupcs.DefaultOrderOptions)
+	}
+	fdl, pcsError := GetBaiduPCS().CacheFilesDirectoriesList(dir, baidupcs.DefaultOrderOptions)
+	if pcsError != nil && pcsError.GetErrType() == pcserror.ErrTypeNetError {
+		if idx, err := loadIndex(); err == nil {
+			return idx.FilesDirectoriesList(dir, baidupcs.DefaultOrderOptions)
+		}
+	}
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return fdl, nil
+}
+
+func indexProgress(n int) {
+	fmt.Printf("\r已获取 %d 项变更", n)
+}
+
+// RunIndexBuild 获取全部文件, 重新建立本地索引
+func RunIndexBuild() {
+	if Offline {
+		fmt.Println("离线模式下不能建立索引")
+		return
+	}
+	closeIndex()
+	idx, err := pcsindex.Build(indexFilename(GetActiveUser().UID), GetBaiduPCS(), indexProgress)
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("建立索引失败, %s\n", err)
+		return
+	}
+	defer idx.Close()
+	fmt.Printf("索引建立完成, 共 %d 个文件和目录\n", idx.Len())
+}
+
+// RunIndexUpdate 增量更新本地索引, 没有索引时建立索引
+func RunIndexUpdate() {
+	if Offline {
+		fmt.Println("离线模式下不能更新索引")
+		return
+	}
+	filename := indexFilename(GetActiveUser().UID)
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		RunIndexBuild()
+		return
+	}
+	closeIndex()
+	idx, err := pcsindex.Open(filename)
+	if err != nil {
+		fmt.Printf("打开本地索引失败, %s\n", err)
+		return
+	}
+	defer idx.Close()
+
+	// 每页变更获取后立即写入, 出错时下次从中断处继续
+	n, pcsError := idx.Update(GetBaiduPCS(), indexProgress)
+	fmt.Println()
+	if pcsError != nil {
+		fmt.Printf("更新索引失败, %s\n", pcsError)
+		return
+	}
+	fmt.Printf("索引更新完成, %d 项变更, 共 %d 个文件和目录\n", n, idx.Len())
+}
+
+// RunIndexQuery 查询本地索引, 没有条件时输出索引的信息
+func RunIndexQuery(keyword string, opt *IndexQueryOptions) {
+	if opt == nil {
+		opt = &IndexQueryOptions{}
+	}
+	idx, err := loadIndex()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var (
+		root    = GetActiveUser().PathJoin(opt.Path)
+		records []*pcsindex.Record
+	)
+	switch {
+	case opt.FsID != 0:
+		if r, ok := idx.GetByFsID(opt.FsID); ok {
+			records = append(records, r)
+		}
+	case opt.MD5 != "":
+		md5 := strings.ToLower(opt.MD5)
+		idx.Walk(root, func(r *pcsindex.Record) bool {
+			if !r.Isdir && r.MD5 == md5 {
+				records = append(records, r)
+			}
+			return true
+		})
+	case keyword != "":
+		records = idx.Search(root, keyword, opt.Recurse)
+	default:
+		var files, dirs, size int64
+		idx.Walk(baidupcs.PathSeparator, func(r *pcsindex.Record) bool {
+			if r.Isdir {
+				dirs++
+			} else {
+				files++
+				size += r.Size
+			}
+			return true
+		})
+		fmt.Printf("索引文件: %s\n更新时间: %s\n文件总数: %d, 目录总数: %d, 总大小: %s\n",
+			indexFilename(GetActiveUser().UID), pcstime.FormatTime(idx.Updated.Unix()), files, dirs-1, converter.ConvertFileSize(size, 2))
+		return
+	}
+
+	fmt.Printf("索引更新于 %s\n", pcstime.FormatTime(idx.Updated.Unix()))
+	renderTable(opSearch, opt.Total, root, idx.FileDirectories(records))
+}
@@ -0,0 +1,198 @@
+package pcscommand
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsjournal"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+)
+
+// openJournal 打开当前帐号的操作日志
+func openJournal() (*pcsjournal.Journal, error) {
+	return pcsjournal.Open(filepath.Join(pcsconfig.GetConfigDir(), "journal", strconv.FormatUint(GetActiveUser().UID, 10)+".jsonl"))
+}
+
+// journalEnabled 是否记录操作日志, 操作日志只记录当前帐号的网盘,
+// 使用其他存储后端时不记录, 以免撤销时修改网盘上的文件
+func journalEnabled() bool {
+	return storage == nil
+}
+
+// recordJournal 记录操作, 失败或不记录时返回 nil
+func recordJournal(op pcsjournal.Op, items []*pcsjournal.Item) *pcsjournal.Entry {
+	if !journalEnabled() {
+		return nil
+	}
+	j, err := openJournal()
+	if err == nil {
+		var e *pcsjournal.Entry
+		e, err = j.Add(op, items)
+		if err == nil {
+			return e
+		}
+	}
+	fmt.Printf("记录操作日志失败, %s\n", err)
+	return nil
+}
+
+// cpmvJournalItems 把 CpMvJSON 转换为操作日志
+func cpmvJournalItems(cpmvJSONs []*baidupcs.CpMvJSON) []*pcsjournal.Item {
+	items := make([]*pcsjournal.Item, 0, len(cpmvJSONs))
+	for _, cpmv := range cpmvJSONs {
+		items = append(items, &pcsjournal.Item{
+			Path: cpmv.From,
+			To:   cpmv.To,
+		})
+	}
+	return items
+}
+
+// fsIDs 获取 paths 的 fs_id, 用于删除后从回收站还原, 获取失败的为 0
+func fsIDs(s baidupcs.Storage, paths []string) map[string]int64 {
+	ids := make(map[string]int64, len(paths))
+	if pcs, ok := s.(*baidupcs.BaiduPCS); ok {
+		fdl, pcsError := pcs.FilesDirectoriesBatchMeta(paths...)
+		if pcsError == nil {
+			for _, fd := range fdl {
+				ids[fd.Path] = fd.FsID
+			}
+			return ids
+		}
+	}
+	for _, p := range paths {
+		fd, pcsError := s.FilesDirectoriesMeta(p)
+		if pcsError == nil {
+			ids[p] = fd.FsID
+		}
+	}
+	return ids
+}
+
+// removeWithJournal 删除文件/目录, 并记录 fs_id
+func removeWithJournal(s baidupcs.Storage, paths ...string) pcserror.Error {
+	if !journalEnabled() {
+		return s.Remove(paths...)
+	}
+	ids := fsIDs(s, paths)
+	pcsError := s.Remove(paths...)
+	if pcsError != nil {
+		return pcsError
+	}
+
+	items := make([]*pcsjournal.Item, 0, len(paths))
+	for _, p := range paths {
+		items = append(items, &pcsjournal.Item{
+			Path: p,
+			FsID: ids[p],
+		})
+	}
+	recordJournal(pcsjournal.OpRemove, items)
+	return nil
+}
+
+// moveWithJournal 移动文件/目录, 并记录
+func moveWithJournal(s baidupcs.Storage, cpmvJSONs ...*baidupcs.CpMvJSON) pcserror.Error {
+	pcsError := s.Move(cpmvJSONs...)
+	if pcsError != nil {
+		return pcsError
+	}
+	recordJournal(pcsjournal.OpMove, cpmvJournalItems(cpmvJSONs))
+	return nil
+}
+
+// RunHistory 列出最近 n 次修改网盘文件的操作
+func RunHistory(n int) {
+	j, err := openJournal()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	entries := j.Entries()
+	if len(entries) == 0 {
+		fmt.Println("没有操作记录")
+		return
+	}
+	if n > 0 && len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"id", "时间", "操作", "数量", "状态", "文件/目录"})
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		status := ""
+		switch {
+		case e.Undone:
+			status = "已撤销"
+		case !e.Op.Reversible():
+			status = "不可撤销"
+		}
+		tb.Append([]string{strconv.Itoa(e.ID), pcstime.FormatTime(e.Time.Unix()), string(e.Op), strconv.Itoa(len(e.Items)), status, historySummary(e)})
+	}
+	tb.Render()
+}
+
+// historySummary 操作涉及的第一个文件/目录
+func historySummary(e *pcsjournal.Entry) string {
+	if len(e.Items) == 0 {
+		return ""
+	}
+
+	item := e.Items[0]
+	s := item.Path
+	switch {
+	case item.To != "":
+		s += " -> " + item.To
+	case s == "":
+		s = "fs_id " + strconv.FormatInt(item.FsID, 10)
+	}
+	if len(e.Items) > 1 {
+		s += fmt.Sprintf(" 等 %d 个", len(e.Items))
+	}
+	return s
+}
+
+// RunUndo 撤销 id 对应的操作
+func RunUndo(id int) {
+	if !journalEnabled() {
+		fmt.Println("操作日志只记录网盘上的操作, 使用其他存储后端时不能撤销")
+		return
+	}
+	j, err := openJournal()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	e := j.Get(id)
+	if e == nil {
+		fmt.Printf("操作 %d 不存在, 请使用 history 查看操作记录\n", id)
+		return
+	}
+
+	u, ok := GetStorage().(pcsjournal.Undoer)
+	if !ok {
+		fmt.Println("当前存储不支持撤销操作")
+		return
+	}
+
+	err = pcsjournal.Undo(u, e)
+	if err != nil {
+		fmt.Printf("撤销操作 %d 失败, %s\n", id, err)
+		return
+	}
+
+	err = j.MarkUndone(e)
+	if err != nil {
+		fmt.Printf("记录操作日志失败, %s\n", err)
+	}
+	fmt.Printf("已撤销操作 %d: %s %s\n", id, e.Op, historySummary(e))
+}
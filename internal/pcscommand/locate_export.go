@@ -0,0 +1,334 @@
+package pcscommand
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LocateFormatAria2 aria2 输入文件
+	LocateFormatAria2 = "aria2"
+	// LocateFormatCurl curl 脚本
+	LocateFormatCurl = "curl"
+	// LocateFormatWget wget 脚本
+	LocateFormatWget = "wget"
+	// LocateFormatJSON json
+	LocateFormatJSON = "json"
+
+	// defaultDlinkLifetime 下载链接默认有效期
+	defaultDlinkLifetime = 8 * time.Hour
+	// locatePanBatchSize 网盘 API 每次获取下载链接的文件数
+	locatePanBatchSize = 100
+)
+
+type (
+	// LocateExportItem 导出的下载链接信息
+	LocateExportItem struct {
+		Path    string            `json:"path"`    // 网盘路径
+		Out     string            `json:"out"`     // 相对的保存路径
+		URL     string            `json:"url"`     // 下载链接
+		Size    int64             `json:"size"`    // 文件大小
+		MD5     string            `json:"md5"`     // md5 值
+		Expires int64             `json:"expires"` // 链接过期时间, unix 时间戳
+		Headers map[string]string `json:"headers"` // 下载时需要的请求头
+	}
+)
+
+// checkLocateFormat 检查导出格式
+func checkLocateFormat(format string) error {
+	switch format {
+	case LocateFormatAria2, LocateFormatCurl, LocateFormatWget, LocateFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("未知的导出格式: %s, 支持: aria2, curl, wget, json", format)
+}
+
+// locateExportFile 需要导出下载链接的文件
+type locateExportFile struct {
+	fd  *baidupcs.FileDirectory
+	out string // 相对的保存路径
+}
+
+// runLocateExport 递归获取下载链接, 并导出为下载工具可用的格式
+func runLocateExport(absPaths []string, opt *LocateDownloadOption) {
+	err := checkLocateFormat(opt.Format)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var (
+		pcs     = GetBaiduPCS()
+		headers = locateExportHeaders(pcs)
+		files   = make([]*locateExportFile, 0, len(absPaths))
+	)
+
+	for _, pcspath := range absPaths {
+		pcs.FilesDirectoriesRecurseList(pcspath, baidupcs.DefaultOrderOptions, func(depth int, fdPath string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+			if pcsError != nil {
+				fmt.Fprintf(os.Stderr, "%s, 路径: %s\n", pcsError, fdPath)
+				return true
+			}
+			if fd.Isdir {
+				return true
+			}
+
+			// 与 download 保持一致的相对路径
+			out := fd.Filename
+			if depth > 0 {
+				out = path.Join(filepath.ToSlash(fd.PreBase), fd.Filename)
+			}
+			files = append(files, &locateExportFile{fd: fd, out: out})
+			return true
+		})
+	}
+
+	var dlinks map[string]*url.URL
+	if opt.FromPan {
+		dlinks = locatePanExportLinks(pcs, files)
+	} else {
+		dlinks = locateExportLinks(pcs, files)
+	}
+
+	items := make([]*LocateExportItem, 0, len(files))
+	for _, file := range files {
+		dlink, ok := dlinks[file.fd.Path]
+		if !ok {
+			continue
+		}
+		items = append(items, &LocateExportItem{
+			Path:    file.fd.Path,
+			Out:     file.out,
+			URL:     dlink.String(),
+			Size:    file.fd.Size,
+			MD5:     file.fd.MD5,
+			Expires: dlinkExpires(dlink).Unix(),
+			Headers: headers,
+		})
+	}
+
+	if len(items) == 0 {
+		fmt.Println("未获取到任何下载链接")
+		return
+	}
+
+	if opt.Output == "" {
+		err = writeLocateExport(os.Stdout, opt.Format, items)
+		if err != nil {
+			fmt.Printf("导出错误: %s\n", err)
+		}
+		return
+	}
+
+	err = saveLocateExport(opt.Output, opt.Format, items)
+	if err != nil {
+		fmt.Printf("导出错误: %s\n", err)
+		return
+	}
+	fmt.Printf("已导出 %d 条下载链接到: %s\n", len(items), opt.Output)
+}
+
+// saveLocateExport 导出到文件, 写入或关闭文件失败 (如磁盘已满) 时返回错误
+func saveLocateExport(filename, format string, items []*LocateExportItem) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("创建导出文件错误: %s", err)
+	}
+	err = writeLocateExport(f, format, items)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// locateExportLinks 逐个获取文件的下载链接, 与 download 使用相同的链接
+func locateExportLinks(pcs *baidupcs.BaiduPCS, files []*locateExportFile) map[string]*url.URL {
+	dlinks := make(map[string]*url.URL, len(files))
+	for _, file := range files {
+		links, err := pcsdownload.GetLocateDownloadLinks(pcs, file.fd.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "获取下载链接失败, %s, 路径: %s\n", err, file.fd.Path)
+			continue
+		}
+		dlink := links[0]
+		pcsdownload.FixHTTPLinkURL(dlink)
+		dlinks[file.fd.Path] = dlink
+	}
+	return dlinks
+}
+
+// locatePanExportLinks 使用网盘 API 按 fs_id 批量获取下载链接
+func locatePanExportLinks(pcs *baidupcs.BaiduPCS, files []*locateExportFile) map[string]*url.URL {
+	dlinks := make(map[string]*url.URL, len(files))
+	for start := 0; start < len(files); start += locatePanBatchSize {
+		end := start + locatePanBatchSize
+		if end > len(files) {
+			end = len(files)
+		}
+
+		var (
+			batch   = files[start:end]
+			fidList = make([]int64, 0, len(batch))
+			byFsID  = make(map[string]*locateExportFile, len(batch))
+		)
+		for _, file := range batch {
+			fidList = append(fidList, file.fd.FsID)
+			byFsID[strconv.FormatInt(file.fd.FsID, 10)] = file
+		}
+
+		list, pcsError := pcs.LocatePanAPIDownload(fidList...)
+		if pcsError != nil {
+			fmt.Fprintf(os.Stderr, "获取下载链接失败, %s\n", pcsError)
+			continue
+		}
+		for _, info := range list {
+			file, ok := byFsID[info.FsID]
+			if !ok {
+				continue
+			}
+			dlink, err := url.Parse(info.Dlink)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "解析下载链接失败, %s, 路径: %s\n", err, file.fd.Path)
+				continue
+			}
+			dlinks[file.fd.Path] = dlink
+		}
+		for _, file := range batch {
+			if _, ok := dlinks[file.fd.Path]; !ok {
+				fmt.Fprintf(os.Stderr, "未获取到下载链接, 路径: %s\n", file.fd.Path)
+			}
+		}
+	}
+	return dlinks
+}
+
+// locateExportHeaders 下载链接需要的请求头
+func locateExportHeaders(pcs *baidupcs.BaiduPCS) map[string]string {
+	headers := map[string]string{
+		"User-Agent": pcsconfig.Config.PanUA,
+	}
+
+	jar := pcs.GetClient().Jar
+	if jar == nil {
+		return headers
+	}
+
+	cookies := jar.Cookies(&url.URL{Scheme: "https", Host: pcsconfig.Config.PCSAddr, Path: "/"})
+	if len(cookies) == 0 {
+		return headers
+	}
+
+	cookieStrs := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		cookieStrs = append(cookieStrs, c.Name+"="+c.Value)
+	}
+	headers["Cookie"] = strings.Join(cookieStrs, "; ")
+	return headers
+}
+
+// dlinkExpires 解析下载链接的过期时间, 解析失败则按默认有效期估算
+func dlinkExpires(dlink *url.URL) time.Time {
+	var (
+		query = dlink.Query()
+		start = time.Now()
+	)
+
+	if t, err := strconv.ParseInt(query.Get("time"), 10, 64); err == nil && t > 0 {
+		start = time.Unix(t, 0)
+	}
+
+	if d, err := time.ParseDuration(query.Get("expires")); err == nil && d > 0 {
+		return start.Add(d)
+	}
+	return start.Add(defaultDlinkLifetime)
+}
+
+// writeLocateExport 按格式写出下载链接, 返回第一个写入错误
+func writeLocateExport(out io.Writer, format string, items []*LocateExportItem) error {
+	w := &exportWriter{w: out}
+	switch format {
+	case LocateFormatJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case LocateFormatAria2:
+		for _, item := range items {
+			w.printf("# %s, size: %d, expires: %s\n", item.Path, item.Size, time.Unix(item.Expires, 0).Format(time.RFC3339))
+			w.printf("%s\n", item.URL)
+			w.printf("  out=%s\n", item.Out)
+			for _, k := range sortedHeaderKeys(item.Headers) {
+				w.printf("  header=%s: %s\n", k, item.Headers[k])
+			}
+			if item.MD5 != "" {
+				w.printf("  checksum=md5=%s\n", item.MD5)
+			}
+		}
+		return w.err
+	case LocateFormatCurl, LocateFormatWget:
+		w.printf("#!/bin/sh\n# 由 BaiduPCS-Go 导出, 链接有效期有限, 请尽快下载\n\n")
+		for _, item := range items {
+			w.printf("# %s, size: %d, md5: %s, expires: %s\n", item.Path, item.Size, item.MD5, time.Unix(item.Expires, 0).Format(time.RFC3339))
+			if format == LocateFormatCurl {
+				w.printf("curl -L -C - --create-dirs")
+				for _, k := range sortedHeaderKeys(item.Headers) {
+					w.printf(" -H %s", shellQuote(k+": "+item.Headers[k]))
+				}
+				w.printf(" -o %s %s\n", shellQuote(item.Out), shellQuote(item.URL))
+				continue
+			}
+
+			if dir := path.Dir(item.Out); dir != "." {
+				w.printf("mkdir -p %s && ", shellQuote(dir))
+			}
+			w.printf("wget -c")
+			for _, k := range sortedHeaderKeys(item.Headers) {
+				w.printf(" --header=%s", shellQuote(k+": "+item.Headers[k]))
+			}
+			w.printf(" -O %s %s\n", shellQuote(item.Out), shellQuote(item.URL))
+		}
+		return w.err
+	}
+	return errors.New("unknown format")
+}
+
+// exportWriter 记录第一个写入错误, 之后的写入忽略
+type exportWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *exportWriter) printf(format string, a ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, a...)
+}
+
+// sortedHeaderKeys 固定请求头的输出顺序
+func sortedHeaderKeys(headers map[string]string) []string {
+	keys := make([]string, 0, len(headers))
+	for _, k := range []string{"User-Agent", "Cookie"} {
+		if _, ok := headers[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// shellQuote 转义为 shell 单引号字符串
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
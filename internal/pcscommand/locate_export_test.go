@@ -0,0 +1,115 @@
+package pcscommand
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failingWriter struct {
+	n int // 成功写入的次数
+}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	if fw.n <= 0 {
+		return 0, errors.New("no space left on device")
+	}
+	fw.n--
+	return len(p), nil
+}
+
+func testExportItems() []*LocateExportItem {
+	return []*LocateExportItem{
+		{
+			Path:    "/a/it's.mp4",
+			Out:     "a/it's.mp4",
+			URL:     "https://d.pcs.baidu.com/file/1?time=1&expires=8h",
+			Size:    3,
+			MD5:     "0cc175b9c0f1b6a831c399e269772661",
+			Expires: 1700000000,
+			Headers: map[string]string{"User-Agent": "ua", "Cookie": "BDUSS=x"},
+		},
+	}
+}
+
+func TestWriteLocateExport(t *testing.T) {
+	items := testExportItems()
+	expected := map[string][]string{
+		LocateFormatAria2: {
+			"https://d.pcs.baidu.com/file/1?time=1&expires=8h\n",
+			"  out=a/it's.mp4\n",
+			"  header=User-Agent: ua\n  header=Cookie: BDUSS=x\n",
+			"  checksum=md5=0cc175b9c0f1b6a831c399e269772661\n",
+		},
+		LocateFormatCurl: {
+			"curl -L -C - --create-dirs -H 'User-Agent: ua' -H 'Cookie: BDUSS=x' -o 'a/it'\\''s.mp4' 'https://d.pcs.baidu.com/file/1?time=1&expires=8h'\n",
+		},
+		LocateFormatWget: {
+			"mkdir -p 'a' && wget -c --header='User-Agent: ua' --header='Cookie: BDUSS=x' -O 'a/it'\\''s.mp4' ",
+		},
+	}
+	for format, parts := range expected {
+		buf := &bytes.Buffer{}
+		if err := writeLocateExport(buf, format, items); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		for _, part := range parts {
+			if !strings.Contains(buf.String(), part) {
+				t.Errorf("%s: output missing %q:\n%s", format, part, buf.String())
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeLocateExport(buf, LocateFormatJSON, items); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []*LocateExportItem
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0].URL != items[0].URL {
+		t.Errorf("unexpected json %s, err: %v", buf.String(), err)
+	}
+}
+
+func TestWriteLocateExportError(t *testing.T) {
+	// json 一次写入, 其他格式在第二次写入时失败
+	for format, n := range map[string]int{LocateFormatAria2: 1, LocateFormatCurl: 1, LocateFormatWget: 1, LocateFormatJSON: 0} {
+		if err := writeLocateExport(&failingWriter{n: n}, format, testExportItems()); err == nil {
+			t.Errorf("%s: expected write error", format)
+		}
+	}
+}
+
+func TestSaveLocateExport(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "links.txt")
+	if err := saveLocateExport(filename, LocateFormatAria2, testExportItems()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "  out=a/it's.mp4\n") {
+		t.Errorf("unexpected file content %s", data)
+	}
+
+	if err = saveLocateExport(filepath.Join(t.TempDir(), "missing", "links.txt"), LocateFormatAria2, testExportItems()); err == nil {
+		t.Error("expected create error")
+	}
+}
+
+func TestDlinkExpires(t *testing.T) {
+	u, _ := url.Parse("https://d.pcs.baidu.com/file/1?time=1000&expires=1h")
+	if got := dlinkExpires(u); !got.Equal(time.Unix(1000, 0).Add(time.Hour)) {
+		t.Errorf("unexpected expires %s", got)
+	}
+	u, _ = url.Parse("https://d.pcs.baidu.com/file/1")
+	if got := time.Until(dlinkExpires(u)); got < defaultDlinkLifetime-time.Minute || got > defaultDlinkLifetime {
+		t.Errorf("unexpected default lifetime %s", got)
+	}
+}
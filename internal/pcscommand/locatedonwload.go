@@ -21,6 +21,11 @@ func RunLocateDownload(pcspaths []string, opt *LocateDownloadOption) {
 		return
 	}
 
+	if opt.Format != "" {
+		runLocateExport(absPaths, opt)
+		return
+	}
+
 	pcs := GetBaiduPCS()
 
 	if opt.FromPan {
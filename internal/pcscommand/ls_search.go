@@ -3,6 +3,7 @@ package pcscommand
 import (
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
@@ -31,13 +32,13 @@ const (
 
 // RunLs 执行列目录
 func RunLs(pcspath string, lsOptions *LsOptions, orderOptions *baidupcs.OrderOptions) {
-	err := matchPathByShellPatternOnce(&pcspath)
+	err := matchListPathByShellPatternOnce(&pcspath)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	files, err := GetBaiduPCS().FilesDirectoriesList(pcspath, orderOptions)
+	files, err := listStorage().FilesDirectoriesList(pcspath, orderOptions)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -55,7 +56,7 @@ func RunLs(pcspath string, lsOptions *LsOptions, orderOptions *baidupcs.OrderOpt
 
 // RunSearch 执行搜索
 func RunSearch(targetPath, keyword string, opt *SearchOptions) {
-	err := matchPathByShellPatternOnce(&targetPath)
+	err := matchListPathByShellPatternOnce(&targetPath)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -65,7 +66,19 @@ func RunSearch(targetPath, keyword string, opt *SearchOptions) {
 		opt = &SearchOptions{}
 	}
 
-	files, err := GetBaiduPCS().Search(targetPath, keyword, opt.Recurse)
+	var files baidupcs.FileDirectoryList
+	if Offline {
+		files, err = searchIndex(targetPath, keyword, opt.Recurse)
+	} else {
+		var pcsError pcserror.Error
+		files, pcsError = GetBaiduPCS().Search(targetPath, keyword, opt.Recurse)
+		if pcsError != nil && pcsError.GetErrType() == pcserror.ErrTypeNetError {
+			fmt.Println("网络不可用, 使用本地索引")
+			files, err = searchIndex(targetPath, keyword, opt.Recurse)
+		} else if pcsError != nil {
+			err = pcsError
+		}
+	}
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -0,0 +1,200 @@
+package pcscommand
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdiff"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+)
+
+type (
+	// ManifestOptions 生成和校验清单可选项
+	ManifestOptions struct {
+		Algorithms []checksum.Algorithm // create: 摘要算法, 默认 md5
+		Compute    bool                 // 下载文件计算 md5, 不使用服务器记录的 md5
+		Output     string               // create: 输出到本地文件, 为空时输出到标准输出
+		Parallel   int                  // 校验本地目录时的协程数, 网盘文件同时下载的连接数
+		Remote     bool                 // verify: 目标为网盘目录, 不检查本地目录
+		JSON       bool                 // verify: 输出 json 格式
+	}
+)
+
+// remoteSumFunc 返回下载网盘目录 root 中的文件计算摘要的 SumFunc
+func remoteSumFunc(root string, parallel int) pcsdiff.SumFunc {
+	return func(rel string, algos []checksum.Algorithm) ([]string, error) {
+		return hashFile(path.Join(root, rel), &HashOptions{
+			Algorithms: algos,
+			Parallel:   parallel,
+		})
+	}
+}
+
+// RunManifestCreate 生成网盘目录 dir 的校验文件, 格式与 md5sum, sha256sum 等工具兼容, 路径相对于 dir.
+// 只使用 md5 时默认使用服务器记录的 md5, 不需要下载文件; 服务器记录的 md5 可能不正确时下载文件计算
+func RunManifestCreate(dir string, opt *ManifestOptions) {
+	if opt == nil {
+		opt = &ManifestOptions{}
+	}
+	if len(opt.Algorithms) == 0 {
+		opt.Algorithms = []checksum.Algorithm{checksum.AlgorithmMD5}
+	}
+	if opt.Parallel <= 0 {
+		opt.Parallel = pcsconfig.Config.MaxParallel
+	}
+
+	dir = GetActiveUser().PathJoin(dir)
+	tree, pcsError := pcsdiff.Walk(GetStorage(), dir)
+	if pcsError != nil {
+		fmt.Printf("列出 %s 失败, %s\n", dir, pcsError)
+		return
+	}
+	if fd, pcsError := GetStorage().FilesDirectoriesMeta(dir); pcsError == nil && !fd.Isdir {
+		// 单个文件, 路径相对于所在目录
+		dir = path.Dir(dir)
+	}
+	files := tree.Files()
+
+	var out io.Writer = os.Stdout
+	if opt.Output != "" {
+		f, err := os.Create(opt.Output)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+
+	var (
+		serverMD5 = !opt.Compute && len(opt.Algorithms) == 1 && opt.Algorithms[0] == checksum.AlgorithmMD5
+		sumFunc   = remoteSumFunc(dir, opt.Parallel)
+		tag       = len(opt.Algorithms) > 1
+		failed    int
+	)
+	for k, e := range files {
+		var (
+			sums    []string
+			err     error
+			compute = !serverMD5 || !e.MD5Reliable()
+		)
+		if compute {
+			sums, err = sumFunc(e.Path, opt.Algorithms)
+		} else {
+			sums = []string{e.MD5}
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s: %s\n", e.Path, err)
+			continue
+		}
+		for i, algo := range opt.Algorithms {
+			fmt.Fprintln(out, checksum.FormatSumLine(algo, sums[i], e.Path, tag))
+		}
+		if opt.Output != "" && compute {
+			fmt.Printf("[%d/%d] %s\n", k+1, len(files), e.Path)
+		}
+	}
+
+	if opt.Output != "" {
+		fmt.Printf("已保存 %s 的 %d 个文件的校验信息到 %s\n", dir, len(files)-failed, opt.Output)
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d 个文件计算失败\n", failed)
+	}
+}
+
+// RunManifestVerify 使用校验文件 sumFile 校验本地目录或网盘目录 dir, 报告缺少, 多余和不一致的文件.
+// dir 在本地存在时校验本地目录, 否则校验网盘目录
+func RunManifestVerify(sumFile, dir string, opt *ManifestOptions) {
+	if opt == nil {
+		opt = &ManifestOptions{}
+	}
+
+	entries, err := pcsdiff.LoadSumFile(sumFile)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var (
+		tree     pcsdiff.Tree
+		sumFunc  pcsdiff.SumFunc
+		parallel = opt.Parallel
+	)
+	if info, statErr := os.Stat(dir); !opt.Remote && statErr == nil && info.IsDir() {
+		tree, err = pcsdiff.WalkLocal(dir)
+		if err != nil {
+			fmt.Printf("列出本地目录 %s 失败, %s\n", dir, err)
+			return
+		}
+		sumFunc = pcsdiff.LocalSumFunc(dir)
+		if parallel <= 0 {
+			parallel = runtime.NumCPU()
+		}
+	} else {
+		dir = GetActiveUser().PathJoin(dir)
+		var pcsError pcserror.Error
+		tree, pcsError = pcsdiff.Walk(GetStorage(), dir)
+		if pcsError != nil {
+			fmt.Printf("列出 %s 失败, %s\n", dir, pcsError)
+			return
+		}
+		if opt.Compute {
+			for _, e := range tree {
+				e.MD5 = ""
+			}
+		}
+		if parallel <= 0 {
+			parallel = pcsconfig.Config.MaxParallel
+		}
+		// 逐个文件下载, 每个文件使用 parallel 个连接
+		sumFunc, parallel = remoteSumFunc(dir, parallel), 1
+	}
+
+	res := pcsdiff.Verify(entries, tree, sumFunc, parallel)
+	if opt.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", " ")
+		err = encoder.Encode(res)
+		if err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	fmt.Printf("校验文件: %s\n目录: %s\n\n", sumFile, dir)
+	var rows [][]string
+	for _, p := range res.Missing {
+		rows = append(rows, []string{"缺少", p, ""})
+	}
+	for _, p := range res.Extra {
+		rows = append(rows, []string{"多余", p, ""})
+	}
+	for _, m := range res.Mismatch {
+		rows = append(rows, []string{"不一致", m.Path, m.Reason})
+	}
+	for _, f := range res.Failed {
+		rows = append(rows, []string{"失败", f.Path, f.Err})
+	}
+	if len(rows) == 0 {
+		fmt.Printf("校验通过, 一致的文件: %d\n", res.OK)
+		return
+	}
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "结果", "路径", "说明"})
+	for k, row := range rows {
+		tb.Append(append([]string{strconv.Itoa(k)}, row...))
+	}
+	tb.Render()
+	fmt.Printf("\n一致: %d, 缺少: %d, 多余: %d, 不一致: %d, 失败: %d\n", res.OK, len(res.Missing), len(res.Extra), len(res.Mismatch), len(res.Failed))
+}
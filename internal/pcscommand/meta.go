@@ -6,7 +6,7 @@ import (
 
 // RunGetMeta 执行 获取文件/目录的元信息
 func RunGetMeta(targetPaths ...string) {
-	targetPaths, err := matchPathByShellPattern(targetPaths...)
+	targetPaths, err := matchListPathByShellPattern(targetPaths...)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -2,13 +2,21 @@
 package pcscommand
 
 import (
+	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	pcsstorage "github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
+	"strings"
 )
 
 var (
 	pcsCommandVerbose = pcsverbose.New("PCSCOMMAND")
+
+	// storage 命令使用的存储后端, 为空时使用当前帐号的网盘
+	storage baidupcs.Storage
+	// storageSpec 设置 storage 使用的参数, 见 UseStorage
+	storageSpec string
 )
 
 // GetActiveUser 获取当前登录的百度帐号
@@ -20,3 +28,40 @@ func GetActiveUser() *pcsconfig.Baidu {
 func GetBaiduPCS() *baidupcs.BaiduPCS {
 	return pcsconfig.Config.ActiveUserBaiduPCS()
 }
+
+// SetStorage 设置命令使用的存储后端, 设为 nil 时恢复使用当前帐号的网盘
+func SetStorage(s baidupcs.Storage) {
+	storage = s
+}
+
+// UseStorage 按参数设置命令使用的存储后端:
+// memory 为内存中的存储, local:<目录> 为本地目录, 为空时使用当前帐号的网盘.
+// 参数与上次相同时保留原有的存储, 交互模式下内存中的文件不会丢失
+func UseStorage(spec string) error {
+	if spec == storageSpec {
+		return nil
+	}
+	switch {
+	case spec == "":
+		SetStorage(nil)
+	case spec == "memory":
+		SetStorage(pcsstorage.NewMemory())
+	case strings.HasPrefix(spec, "local:") && len(spec) > len("local:"):
+		SetStorage(pcsstorage.NewLocal(strings.TrimPrefix(spec, "local:")))
+	default:
+		return fmt.Errorf("未知的存储后端: %s, 可选值: memory, local:<目录>", spec)
+	}
+	storageSpec = spec
+	return nil
+}
+
+// GetStorage 获取命令使用的存储后端, 离线模式下为只读的本地索引
+func GetStorage() baidupcs.Storage {
+	if storage != nil {
+		return storage
+	}
+	if Offline {
+		return offlineStorage()
+	}
+	return GetBaiduPCS()
+}
@@ -0,0 +1,48 @@
+package pcscommand
+
+import (
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsjournal"
+)
+
+func TestUseStorage(t *testing.T) {
+	defer UseStorage("")
+
+	if err := UseStorage("memory"); err != nil {
+		t.Fatal(err)
+	}
+	s := GetStorage()
+	if err := UseStorage("memory"); err != nil || GetStorage() != s {
+		t.Fatal("same spec should keep the storage")
+	}
+	if err := UseStorage("local:" + t.TempDir()); err != nil || GetStorage() == s {
+		t.Fatalf("local storage: %v", err)
+	}
+	for _, spec := range []string{"local:", "s3"} {
+		if err := UseStorage(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestJournalSkippedForStorage(t *testing.T) {
+	defer UseStorage("")
+
+	if err := UseStorage("memory"); err != nil {
+		t.Fatal(err)
+	}
+	s := GetStorage()
+	if pcsError := s.Mkdir("/photos"); pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	if pcsError := removeWithJournal(s, "/photos"); pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	if _, pcsError := s.FilesDirectoriesMeta("/photos"); pcsError == nil {
+		t.Fatal("/photos not removed")
+	}
+	if e := recordJournal(pcsjournal.OpMkdir, []*pcsjournal.Item{{Path: "/photos"}}); e != nil {
+		t.Fatalf("journal recorded for memory storage: %+v", e)
+	}
+}
@@ -0,0 +1,154 @@
+package pcscommand
+
+import (
+	"fmt"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/diskfree"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// downloadSpaceNeeded 计算下载单个文件还需要的本地空间
+func downloadSpaceNeeded(savePath string, size int64, overwrite bool) int64 {
+	if !overwrite && pcsdownload.FileExist(savePath) {
+		// 已存在的文件会被跳过
+		return 0
+	}
+
+	need := size - pcsdownload.DownloadedSize(savePath)
+	if need < 0 {
+		return 0
+	}
+	return need
+}
+
+// checkLocalSpace 检查本地剩余空间, needs 为保存路径 => 需要的空间,
+// 空间不足时输出报告并返回 false
+func checkLocalSpace(needs map[string]int64) bool {
+	// 按最近的已存在的目录汇总
+	groups := map[string]int64{}
+	for savePath, need := range needs {
+		if need <= 0 {
+			continue
+		}
+		groups[diskfree.ExistingDir(filepath.Dir(savePath))] += need
+	}
+
+	dirs := make([]string, 0, len(groups))
+	for dir := range groups {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	// 按所在的文件系统汇总, 挂载在子目录的磁盘单独计算
+	var (
+		roots   []string
+		merged  = map[string]int64{}
+		volumes = map[string]string{} // 文件系统 => 汇总到的目录
+	)
+	for _, dir := range dirs {
+		root := dir
+		if vol, err := diskfree.Volume(dir); err == nil {
+			if r, ok := volumes[vol]; ok {
+				root = r
+			} else {
+				volumes[vol] = dir
+			}
+		} else {
+			// 无法获取文件系统时, 子目录视为与上级目录在同一磁盘
+			for _, r := range roots {
+				if strings.HasPrefix(dir, strings.TrimSuffix(r, string(os.PathSeparator))+string(os.PathSeparator)) {
+					root = r
+					break
+				}
+			}
+		}
+		if root == dir {
+			roots = append(roots, dir)
+		}
+		merged[root] += groups[dir]
+	}
+
+	var (
+		ok = true
+		tb = pcstable.NewTable(os.Stdout)
+	)
+	tb.SetHeader([]string{"目录", "需要空间", "可用空间"})
+	for _, root := range roots {
+		free, err := diskfree.Free(root)
+		if err != nil {
+			pcsCommandVerbose.Warnf("获取磁盘剩余空间失败, %s\n", err)
+			continue
+		}
+		tb.Append([]string{root, converter.ConvertFileSize(merged[root], 2), converter.ConvertFileSize(free, 2)})
+		if merged[root] > free {
+			ok = false
+		}
+	}
+
+	if !ok {
+		fmt.Printf("本地剩余空间不足: \n")
+		tb.Render()
+		fmt.Printf("使用 --force 忽略此检查\n")
+	}
+	return ok
+}
+
+// uploadQuotaNeeded 计算上传需要的网盘空间, sizes 为网盘保存路径 => 文件大小,
+// 按 policy 会被跳过的已存在文件不计算, 覆盖的文件只计算增加的大小
+func uploadQuotaNeeded(s baidupcs.Storage, sizes map[string]int64, policy string) (need int64) {
+	byDir := map[string][]string{}
+	for savePath := range sizes {
+		dir := path.Dir(savePath)
+		byDir[dir] = append(byDir[dir], savePath)
+	}
+
+	for dir, savePaths := range byDir {
+		existing := map[string]*baidupcs.FileDirectory{}
+		// 目录不存在时全部需要上传
+		if fdl, pcsError := s.FilesDirectoriesList(dir, baidupcs.DefaultOrderOptions); pcsError == nil {
+			for _, fd := range fdl {
+				existing[fd.Path] = fd
+			}
+		}
+		for _, savePath := range savePaths {
+			size := sizes[savePath]
+			fd, ok := existing[savePath]
+			switch {
+			case !ok || fd.Isdir:
+			case policy == baidupcs.SkipPolicy:
+				size = 0
+			case policy == baidupcs.RsyncPolicy && fd.Size == size:
+				size = 0
+			default:
+				size -= fd.Size
+			}
+			if size > 0 {
+				need += size
+			}
+		}
+	}
+	return need
+}
+
+// checkRemoteQuota 检查网盘剩余空间, 空间不足时输出报告并返回 false
+func checkRemoteQuota(pcs *baidupcs.BaiduPCS, need int64) bool {
+	free, pcsError := pcs.SpaceLeftInfo()
+	if pcsError != nil {
+		fmt.Printf("警告: 获取网盘剩余空间失败, %s\n", pcsError)
+		return true
+	}
+
+	if need > free {
+		fmt.Printf("网盘剩余空间不足, 需要: %s, 剩余: %s\n", converter.ConvertFileSize(need, 2), converter.ConvertFileSize(free, 2))
+		fmt.Printf("使用 --force 忽略此检查\n")
+		return false
+	}
+	return true
+}
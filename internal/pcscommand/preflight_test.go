@@ -0,0 +1,29 @@
+package pcscommand
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	pcsstorage "github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+func TestUploadQuotaNeeded(t *testing.T) {
+	s := pcsstorage.NewMemory()
+	for p, data := range map[string]string{"/a/same": "1234", "/a/small": "12"} {
+		if err := s.WriteFile(p, strings.NewReader(data), int64(len(data))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sizes := map[string]int64{"/a/same": 4, "/a/small": 10, "/a/new": 5, "/b/new": 7}
+	for policy, expected := range map[string]int64{
+		baidupcs.SkipPolicy:      12,
+		baidupcs.RsyncPolicy:     20,
+		baidupcs.OverWritePolicy: 20,
+	} {
+		if got := uploadQuotaNeeded(s, sizes, policy); got != expected {
+			t.Errorf("%s: got %d, expected %d", policy, got, expected)
+		}
+	}
+}
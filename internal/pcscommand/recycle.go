@@ -3,6 +3,9 @@ package pcscommand
 import (
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsjournal"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsrecycle"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsliner"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
@@ -24,6 +27,11 @@ func RunRecycleList(page int) {
 		return
 	}
 
+	printRecycleList(fdl)
+}
+
+// printRecycleList 输出回收站文件列表
+func printRecycleList(fdl baidupcs.RecycleFDInfoList) {
 	tb := pcstable.NewTable(os.Stdout)
 	tb.SetHeader([]string{"#", "fs_id", "文件大小", "创建日期", "修改日期", "md5(截图请打码)", "剩余时间", "路径"})
 	tb.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_LEFT})
@@ -43,8 +51,18 @@ func RunRecycleRestore(fidStrList ...string) {
 	var (
 		fidList = converter.SliceStringToInt64(fidStrList)
 		pcs     = GetBaiduPCS()
-		ex, err = pcs.RecycleRestore(fidList...)
+		paths   = map[int64]string{}
 	)
+
+	// 尽量记录被还原的路径, 只查找回收站第一页
+	if fdl, err := pcs.RecycleList(1); err == nil {
+		for _, file := range fdl {
+			paths[file.FsID] = file.Path
+		}
+	}
+
+	ex, err := pcs.RecycleRestore(fidList...)
+	recordRecycleRestore(ex, paths)
 	if err != nil {
 		fmt.Println(err)
 		if len(ex) > 0 {
@@ -64,13 +82,28 @@ func RunRecycleDelete(fidStrList ...string) {
 	var (
 		fidList = converter.SliceStringToInt64(fidStrList)
 		pcs     = GetBaiduPCS()
-		err     = pcs.RecycleDelete(fidList...)
+		paths   = map[int64]string{}
 	)
+
+	// 尽量记录被删除的路径, 只查找回收站第一页
+	if fdl, err := pcs.RecycleList(1); err == nil {
+		for _, file := range fdl {
+			paths[file.FsID] = file.Path
+		}
+	}
+
+	err := pcs.RecycleDelete(fidList...)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
+	items := make([]*pcsjournal.Item, 0, len(fidList))
+	for _, fid := range fidList {
+		items = append(items, &pcsjournal.Item{Path: paths[fid], FsID: fid})
+	}
+	recordJournal(pcsjournal.OpRecycleDelete, items)
+
 	fmt.Printf("删除成功\n")
 }
 
@@ -82,5 +115,187 @@ func RunRecycleClear() {
 		fmt.Println(err)
 		return
 	}
+	recordJournal(pcsjournal.OpRecycleClear, nil)
 	fmt.Printf("清空回收站成功, 数量: %d\n", sussNum)
 }
+
+// recordRecycleRestore 记录已还原的文件/目录, 跳过路径未知的
+func recordRecycleRestore(ex []*baidupcs.FsIDJSON, paths map[int64]string) {
+	items := make([]*pcsjournal.Item, 0, len(ex))
+	for _, fsID := range ex {
+		if p := paths[fsID.FsID]; p != "" {
+			items = append(items, &pcsjournal.Item{Path: p, FsID: fsID.FsID})
+		}
+	}
+	if len(items) > 0 {
+		recordJournal(pcsjournal.OpRecycleRestore, items)
+	}
+}
+
+// RecycleOptions 回收站批量还原/删除可选项
+type RecycleOptions struct {
+	pcsrecycle.Filter
+	DryRun bool // 只输出匹配的文件, 不执行
+	Yes    bool // 删除时不询问, 直接执行
+}
+
+// NewRecycleFilter 解析回收站筛选条件, 空字符串表示不限制.
+// pattern 为原路径的通配符, 相对路径基于当前工作目录; since, until 格式为 2006-01-02, 均包含当天.
+func NewRecycleFilter(pattern, since, until, minSize, maxSize, fileType string) (f *pcsrecycle.Filter, err error) {
+	f = &pcsrecycle.Filter{
+		Type: fileType,
+	}
+	if pattern != "" {
+		f.Pattern = GetActiveUser().PathJoin(pattern)
+	}
+	if since != "" {
+		if f.Since, err = pcsrecycle.ParseDate(since, false); err != nil {
+			return nil, err
+		}
+	}
+	if until != "" {
+		if f.Until, err = pcsrecycle.ParseDate(until, true); err != nil {
+			return nil, err
+		}
+	}
+	if minSize != "" {
+		if f.MinSize, err = converter.ParseFileSizeStr(minSize); err != nil {
+			return nil, fmt.Errorf("文件大小格式错误, %s", err)
+		}
+	}
+	if maxSize != "" {
+		if f.MaxSize, err = converter.ParseFileSizeStr(maxSize); err != nil {
+			return nil, fmt.Errorf("文件大小格式错误, %s", err)
+		}
+	}
+	return f, f.Check()
+}
+
+// recycleSelect 列出回收站全部文件, 并按条件筛选
+func recycleSelect(f *pcsrecycle.Filter) (baidupcs.RecycleFDInfoList, bool) {
+	fdl, err := GetBaiduPCS().RecycleListAll()
+	if err != nil {
+		fmt.Println(err)
+		return nil, false
+	}
+	return pcsrecycle.Select(fdl, f), true
+}
+
+// RunRecycleSearch 列出回收站中所有符合条件的文件
+func RunRecycleSearch(f *pcsrecycle.Filter) {
+	fdl, ok := recycleSelect(f)
+	if !ok {
+		return
+	}
+	printRecycleList(fdl)
+	fmt.Printf("\n匹配: %d\n", len(fdl))
+}
+
+// RunRecycleRestoreByFilter 按条件批量还原回收站文件, 原路径已被占用的跳过
+func RunRecycleRestoreByFilter(opt *RecycleOptions) {
+	fdl, ok := recycleSelect(&opt.Filter)
+	if !ok {
+		return
+	}
+	if len(fdl) == 0 {
+		fmt.Println("回收站中没有符合条件的文件/目录")
+		return
+	}
+
+	paths := make([]string, 0, len(fdl))
+	for _, fd := range fdl {
+		paths = append(paths, fd.Path)
+	}
+	existed := fsIDs(GetBaiduPCS(), paths)
+	restorable, conflicts := pcsrecycle.SplitConflicts(fdl, func(p string) bool {
+		_, ok := existed[p]
+		return ok
+	})
+	if len(conflicts) > 0 {
+		fmt.Printf("以下 %d 个文件/目录的原路径冲突, 跳过还原:\n", len(conflicts))
+		tb := pcstable.NewTable(os.Stdout)
+		tb.SetHeader([]string{"#", "fs_id", "删除日期", "原因", "路径"})
+		for k, c := range conflicts {
+			tb.Append([]string{strconv.Itoa(k), strconv.FormatInt(c.FsID, 10), pcstime.FormatTime(c.Mtime), c.Reason, c.Path})
+		}
+		tb.Render()
+	}
+
+	if opt.DryRun {
+		for _, fd := range restorable {
+			fmt.Printf("[dry-run] 还原 %s\n", fd.Path)
+		}
+		return
+	}
+
+	var (
+		pcs           = GetBaiduPCS()
+		restored      []*baidupcs.FsIDJSON
+		restoredPaths = make(map[int64]string, len(restorable))
+	)
+	for _, fd := range restorable {
+		restoredPaths[fd.FsID] = fd.Path
+	}
+	for _, batch := range pcsrecycle.Batches(pcsrecycle.FsIDs(restorable), baidupcs.RecycleListPageSize) {
+		ex, err := pcs.RecycleRestore(batch...)
+		restored = append(restored, ex...)
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+	}
+	recordRecycleRestore(restored, restoredPaths)
+	fmt.Printf("还原成功, 数量: %d, 冲突跳过: %d\n", len(restored), len(conflicts))
+}
+
+// RunRecycleDeleteByFilter 按条件批量删除回收站文件, 删除后不能恢复
+func RunRecycleDeleteByFilter(opt *RecycleOptions) {
+	if opt.IsEmpty() {
+		fmt.Println("请指定筛选条件, 清空回收站请使用 -all")
+		return
+	}
+
+	fdl, ok := recycleSelect(&opt.Filter)
+	if !ok {
+		return
+	}
+	if len(fdl) == 0 {
+		fmt.Println("回收站中没有符合条件的文件/目录")
+		return
+	}
+
+	printRecycleList(fdl)
+	if opt.DryRun {
+		return
+	}
+	if !opt.Yes {
+		line := pcsliner.NewLiner()
+		y, err := line.State.Prompt(fmt.Sprintf("确认从回收站彻底删除以上 %d 个文件/目录, 删除后不能恢复 (y/n): ", len(fdl)))
+		line.Close()
+		if err != nil || (y != "y" && y != "Y") {
+			fmt.Println("已取消删除")
+			return
+		}
+	}
+
+	var (
+		pcs   = GetBaiduPCS()
+		items = make([]*pcsjournal.Item, 0, len(fdl))
+		start int
+	)
+	for _, batch := range pcsrecycle.Batches(pcsrecycle.FsIDs(fdl), baidupcs.RecycleListPageSize) {
+		err := pcs.RecycleDelete(batch...)
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		for _, fd := range fdl[start : start+len(batch)] {
+			items = append(items, &pcsjournal.Item{Path: fd.Path, FsID: fd.FsID})
+		}
+		start += len(batch)
+	}
+	if len(items) > 0 {
+		recordJournal(pcsjournal.OpRecycleDelete, items)
+	}
+	fmt.Printf("删除成功, 数量: %d\n", len(items))
+}
@@ -0,0 +1,159 @@
+package pcscommand
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsjournal"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsrename"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsliner"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+)
+
+const (
+	// renameBatchSize 每次 filemanager 请求移动的文件数
+	renameBatchSize = 100
+)
+
+type (
+	// RenameOptions 批量重命名可选项
+	RenameOptions struct {
+		DryRun bool // 只预览, 不执行
+		Yes    bool // 不询问, 直接执行
+	}
+)
+
+// RunRename 执行批量重命名, expr 为正则替换或模板, 只重命名文件名, 不改变所在目录
+func RunRename(expr string, patterns []string, opt *RenameOptions) {
+	if opt == nil {
+		opt = &RenameOptions{}
+	}
+
+	r, err := pcsrename.ParseRenamer(expr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	paths, err := matchPathByShellPattern(patterns...)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// 按目录列出, 同时取得文件信息和目录中已有的文件名
+	var (
+		s        = GetStorage()
+		fdl      = make(baidupcs.FileDirectoryList, 0, len(paths))
+		siblings = map[string][]string{}
+		listed   = map[string]baidupcs.FileDirectoryList{}
+	)
+	for _, p := range paths {
+		dir := path.Dir(p)
+		dirList, ok := listed[dir]
+		if !ok {
+			var pcsError pcserror.Error
+			dirList, pcsError = s.FilesDirectoriesList(dir, baidupcs.DefaultOrderOptions)
+			if pcsError != nil {
+				fmt.Printf("列出 %s 失败, %s\n", dir, pcsError)
+				return
+			}
+			listed[dir] = dirList
+			for _, fd := range dirList {
+				siblings[dir] = append(siblings[dir], fd.Filename)
+			}
+		}
+		for _, fd := range dirList {
+			if fd.Path == p {
+				fdl = append(fdl, fd)
+				break
+			}
+		}
+	}
+
+	plan, err := pcsrename.NewPlan(fdl, r, siblings)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(plan.Items) == 0 {
+		fmt.Println("没有需要重命名的文件")
+		return
+	}
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "原路径", "新文件名", "冲突"})
+	for k, item := range plan.Items {
+		tb.Append([]string{strconv.Itoa(k), item.From, path.Base(item.To), item.Conflict})
+	}
+	tb.Render()
+	fmt.Printf("\n重命名: %d, 文件名不变: %d\n", len(plan.Items), plan.Unchanged)
+
+	if n := plan.Conflicts(); n > 0 {
+		fmt.Printf("有 %d 个冲突, 已取消重命名\n", n)
+		return
+	}
+	if opt.DryRun {
+		return
+	}
+	if !opt.Yes {
+		line := pcsliner.NewLiner()
+		y, err := line.State.Prompt(fmt.Sprintf("确认重命名以上 %d 个文件/目录 (y/n): ", len(plan.Items)))
+		line.Close()
+		if err != nil || (y != "y" && y != "Y") {
+			fmt.Println("已取消重命名")
+			return
+		}
+	}
+
+	var renamed baidupcs.CpMvJSONList
+	for _, batch := range pcsrename.Batches(plan.CpMvJSONList(), renameBatchSize) {
+		pcsError := s.Move(batch...)
+		if pcsError != nil {
+			fmt.Printf("重命名失败, %s\n", pcsError)
+			break
+		}
+		renamed = append(renamed, batch...)
+	}
+	if len(renamed) == 0 {
+		return
+	}
+
+	e := recordJournal(pcsjournal.OpRename, cpmvJournalItems(renamed))
+	if e == nil {
+		fmt.Printf("已重命名 %d 个文件/目录\n", len(renamed))
+		return
+	}
+	fmt.Printf("已重命名 %d 个文件/目录, 撤销请运行: rename --undo %d\n", len(renamed), e.ID)
+}
+
+// RunRenameUndo 撤销操作记录中 id 对应的批量重命名
+func RunRenameUndo(id int, dryRun bool) {
+	j, err := openJournal()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	e := j.Get(id)
+	if e == nil || e.Op != pcsjournal.OpRename {
+		fmt.Printf("重命名操作 %d 不存在, 请使用 history 查看操作记录\n", id)
+		return
+	}
+	if e.Undone {
+		fmt.Printf("撤销操作 %d 失败, %s\n", id, pcsjournal.ErrUndone)
+		return
+	}
+
+	if dryRun {
+		for i := len(e.Items) - 1; i >= 0; i-- {
+			fmt.Printf("[dry-run] 重命名 %s -> %s\n", e.Items[i].To, e.Items[i].Path)
+		}
+		return
+	}
+	RunUndo(id)
+}
@@ -2,6 +2,7 @@ package pcscommand
 
 import (
 	"fmt"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsjournal"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
 	"os"
 	"strconv"
@@ -24,7 +25,7 @@ func RunRemove(paths ...string) {
 		tb.Render()
 	}
 
-	err = GetBaiduPCS().Remove(paths...)
+	err = removeWithJournal(GetStorage(), paths...)
 	if err != nil {
 		fmt.Println(err)
 		fmt.Println("操作失败, 以下文件/目录删除失败: ")
@@ -39,11 +40,12 @@ func RunRemove(paths ...string) {
 // RunMkdir 执行 创建目录
 func RunMkdir(path string) {
 	activeUser := GetActiveUser()
-	err := GetBaiduPCS().Mkdir(activeUser.PathJoin(path))
+	err := GetStorage().Mkdir(activeUser.PathJoin(path))
 	if err != nil {
 		fmt.Printf("创建目录 %s 失败, %s\n", path, err)
 		return
 	}
+	recordJournal(pcsjournal.OpMkdir, []*pcsjournal.Item{{Path: activeUser.PathJoin(path)}})
 
 	fmt.Println("创建目录成功:", path)
 }
@@ -0,0 +1,48 @@
+package pcscommand
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+)
+
+// printTaskEvent 输出任务的信息和进度状态行
+func printTaskEvent(e *taskframework.Event) {
+	switch e.Type {
+	case taskframework.EventMessage, taskframework.EventProgress:
+		fmt.Print(e.Message)
+	}
+}
+
+// cancelOnInterrupt 按 Ctrl+C 时取消所有任务, 再次按下时直接退出, 返回停止监听的函数
+func cancelOnInterrupt(executor *taskframework.TaskExecutor) (stop func()) {
+	var (
+		sigCh = make(chan os.Signal, 1)
+		done  = make(chan struct{})
+	)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		select {
+		case <-sigCh:
+			// 恢复默认的处理, 再次按下时退出
+			signal.Stop(sigCh)
+			fmt.Printf("\n[0] 正在取消所有任务, 再次按 Ctrl+C 强制退出...\n")
+			executor.CancelAll()
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
+
+// executeTasks 执行任务, 由订阅的任务事件输出任务信息, 按 Ctrl+C 取消所有任务
+func executeTasks(executor *taskframework.TaskExecutor) {
+	executor.Subscribe(taskframework.ObserverFunc(printTaskEvent))
+	stop := cancelOnInterrupt(executor)
+	defer stop()
+	executor.Execute()
+}
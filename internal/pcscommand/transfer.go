@@ -3,6 +3,7 @@ package pcscommand
 import (
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsjournal"
 	"net/url"
 	"path"
 	"strconv"
@@ -101,6 +102,17 @@ func RunShareTransfer(params []string, opt *baidupcs.TransferOption) {
 		//}
 		return
 	}
+	// 记录转存的文件/目录, 合并保存时只记录新建的目录
+	var items []*pcsjournal.Item
+	if transMetas["item_num"] != "1" && opt.Collect {
+		items = append(items, &pcsjournal.Item{Path: transMetas["path"]})
+	} else {
+		for _, name := range strings.Split(resp["filenames"], ",") {
+			items = append(items, &pcsjournal.Item{Path: path.Join(transMetas["path"], name)})
+		}
+	}
+	recordJournal(pcsjournal.OpTransfer, items)
+
 	if opt.Collect {
 		resp["filename"] = transMetas["filename"]
 	}
@@ -25,14 +25,14 @@ func getTree(pcspath string, depth int, option *TreeOptions) {
 		files baidupcs.FileDirectoryList
 	)
 	if depth == 0 {
-		err := matchPathByShellPatternOnce(&pcspath)
+		err := matchListPathByShellPatternOnce(&pcspath)
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
 	}
 
-	files, err = GetBaiduPCS().FilesDirectoriesList(pcspath, baidupcs.DefaultOrderOptions)
+	files, err = listStorage().FilesDirectoriesList(pcspath, baidupcs.DefaultOrderOptions)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsjournal"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsupload"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
@@ -14,6 +15,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 const (
@@ -31,6 +33,7 @@ type (
 		NoSplitFile     bool   // 禁用分片上传
 		Policy          string // 同名文件处理策略
 		NoFilenameCheck bool   // 禁用文件名合法性检查
+		Force           bool   // 忽略网盘剩余空间检查
 	}
 )
 
@@ -92,6 +95,8 @@ func RunUpload(localPaths []string, savePath string, opt *UploadOptions) {
 			IsFailedDeque: true, // 失败统计
 		}
 		subSavePath string
+		nameMapper  = pcsconfig.Config.FilenameMapper()
+		uploadSizes = map[string]int64{} // 网盘保存路径 => 文件大小, 用于检查网盘剩余空间
 		// 统计
 		statistic = &pcsupload.UploadStatistic{}
 	)
@@ -126,16 +131,22 @@ func RunUpload(localPaths []string, savePath string, opt *UploadOptions) {
 			if len(localPaths) == 1 && len(walkedFiles) == 1 {
 				opt.Load = 1
 			}
-			subSavePath = strings.TrimPrefix(walkedFiles[k3], localPathDir)
-			if !opt.NoFilenameCheck && !pcsutil.ChPathLegal(walkedFiles[k3]) {
+			// 还原下载时映射的文件名
+			subSavePath = nameMapper.UnmapPath(strings.TrimPrefix(walkedFiles[k3], localPathDir))
+			if !opt.NoFilenameCheck && (!pcsutil.ChPathLegal(walkedFiles[k3]) || !pcsutil.ChPathLegal(subSavePath)) {
 				fmt.Printf("[0] %s 文件路径含有非法字符，已跳过!\n", walkedFiles[k3])
 				continue
 			}
+			uploadPath := path.Clean(savePath + baidupcs.PathSeparator + subSavePath)
+			if fileInfo, err := os.Stat(walkedFiles[k3]); err == nil {
+				uploadSizes[uploadPath] = fileInfo.Size()
+			}
 			LoadCount++
 			info := executor.Append(&pcsupload.UploadTaskUnit{
 				LocalFileChecksum: checksum.NewLocalFileChecksum(walkedFiles[k3], int(baidupcs.SliceMD5Size)),
-				SavePath:          path.Clean(savePath + baidupcs.PathSeparator + subSavePath),
+				SavePath:          uploadPath,
 				PCS:               pcs,
+				Storage:           storage,
 				UploadingDatabase: uploadDatabase,
 				Parallel:          opt.Parallel,
 				PrintFormat:       uploadPrintFormat(opt.Load),
@@ -157,10 +168,36 @@ func RunUpload(localPaths []string, savePath string, opt *UploadOptions) {
 		return
 	}
 
+	// 检查网盘剩余空间
+	if !opt.Force && storage == nil && !checkRemoteQuota(pcs, uploadQuotaNeeded(pcs, uploadSizes, opt.Policy)) {
+		return
+	}
+
+	// 记录上传成功的文件, 跳过的文件不记录
+	var (
+		uploadedMu sync.Mutex
+		uploaded   []*pcsjournal.Item
+	)
+	executor.Subscribe(taskframework.ObserverFunc(func(e *taskframework.Event) {
+		if e.Type != taskframework.EventSucceeded || (e.Result != nil && e.Result.Extra != nil) {
+			return
+		}
+		unit, ok := e.Unit.(*pcsupload.UploadTaskUnit)
+		if !ok {
+			return
+		}
+		uploadedMu.Lock()
+		uploaded = append(uploaded, &pcsjournal.Item{Path: unit.SavePath})
+		uploadedMu.Unlock()
+	}))
+
 	// 设置上传文件并发数
 	executor.SetParallel(LoadCount)
 	// 执行上传任务
-	executor.Execute()
+	executeTasks(executor)
+	if len(uploaded) > 0 {
+		recordJournal(pcsjournal.OpUpload, uploaded)
+	}
 
 	fmt.Printf("\n")
 	fmt.Printf("上传结束, 时间: %s, 总大小: %s\n", statistic.Elapsed()/1e6*1e6, converter.ConvertFileSize(statistic.TotalSize()))
@@ -3,6 +3,8 @@ package pcscommand
 import (
 	"errors"
 	"fmt"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsfixmd5"
 	"math/rand"
 	"path"
 	"time"
@@ -28,10 +30,35 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-// RunTestShellPattern 执行测试通配符
-func RunTestShellPattern(pattern string) {
-	pcs := GetBaiduPCS()
-	paths, err := pcs.MatchPathByShellPattern(GetActiveUser().PathJoin(pattern))
+// RunTestShellPattern 执行测试通配符, showPlan 为 true 时输出通配符的展开计划
+func RunTestShellPattern(pattern string, showPlan bool) {
+	pattern = GetActiveUser().PathJoin(pattern)
+	if showPlan {
+		plans, err := baidupcs.ParseShellPattern(pattern)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("展开计划:\n")
+		for k, plan := range plans {
+			fmt.Printf("[%d] %s\n", k+1, plan.Pattern)
+			fmt.Printf("    起始目录: %s\n", plan.Base)
+			if len(plan.Segments) == 0 {
+				fmt.Printf("    不含通配符, 无需列出目录\n")
+				continue
+			}
+			for _, segment := range plan.Segments {
+				if segment == baidupcs.ShellPatternGlobstar {
+					fmt.Printf("    -> %s (任意层级目录)\n", segment)
+					continue
+				}
+				fmt.Printf("    -> %s\n", segment)
+			}
+		}
+		fmt.Printf("\n匹配结果:\n")
+	}
+
+	paths, err := baidupcs.MatchStoragePathByShellPattern(listStorage(), pattern)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -43,7 +70,25 @@ func RunTestShellPattern(pattern string) {
 }
 
 func matchPathByShellPatternOnce(pattern *string) error {
-	paths, err := GetBaiduPCS().MatchPathByShellPattern(GetActiveUser().PathJoin(*pattern))
+	return matchStoragePathByShellPatternOnce(GetStorage(), pattern)
+}
+
+func matchPathByShellPattern(patterns ...string) (pcspaths []string, err error) {
+	return matchStoragePathByShellPattern(GetStorage(), patterns...)
+}
+
+// matchListPathByShellPatternOnce 同 matchPathByShellPatternOnce, 网络不可用时匹配本地索引, 只用于不修改网盘文件的命令
+func matchListPathByShellPatternOnce(pattern *string) error {
+	return matchStoragePathByShellPatternOnce(listStorage(), pattern)
+}
+
+// matchListPathByShellPattern 同 matchPathByShellPattern, 网络不可用时匹配本地索引, 只用于不修改网盘文件的命令
+func matchListPathByShellPattern(patterns ...string) (pcspaths []string, err error) {
+	return matchStoragePathByShellPattern(listStorage(), patterns...)
+}
+
+func matchStoragePathByShellPatternOnce(s baidupcs.Storage, pattern *string) error {
+	paths, err := baidupcs.MatchStoragePathByShellPattern(s, GetActiveUser().PathJoin(*pattern))
 	if err != nil {
 		return err
 	}
@@ -59,10 +104,10 @@ func matchPathByShellPatternOnce(pattern *string) error {
 	return nil
 }
 
-func matchPathByShellPattern(patterns ...string) (pcspaths []string, err error) {
-	acUser, pcs := GetActiveUser(), GetBaiduPCS()
+func matchStoragePathByShellPattern(s baidupcs.Storage, patterns ...string) (pcspaths []string, err error) {
+	acUser := GetActiveUser()
 	for k := range patterns {
-		ps, err := pcs.MatchPathByShellPattern(acUser.PathJoin(patterns[k]))
+		ps, err := baidupcs.MatchStoragePathByShellPattern(s, acUser.PathJoin(patterns[k]))
 		if err != nil {
 			return nil, err
 		}
@@ -72,7 +117,24 @@ func matchPathByShellPattern(patterns ...string) (pcspaths []string, err error)
 	return pcspaths, nil
 }
 
-
+// fixFileMD5 修复文件的 md5, 并返回修复后的 md5, md5 已经正确时直接返回
+func fixFileMD5(pcs *baidupcs.BaiduPCS, fd *baidupcs.FileDirectory) (string, error) {
+	if !pcsfixmd5.NeedFix(fd) {
+		return fd.MD5, nil
+	}
+	pcsError := pcs.FixMD5ByFileInfo(fd)
+	if pcsError != nil {
+		return "", pcsError
+	}
+	finfo, pcsError := pcs.FilesDirectoriesMeta(fd.Path)
+	if pcsError != nil {
+		return "", pcsError
+	}
+	if pcsfixmd5.NeedFix(finfo) {
+		return "", baidupcs.ErrFixMD5Failed
+	}
+	return finfo.MD5, nil
+}
 
 func randReplaceStr(s string, rname bool) string {
 	if !rname {
@@ -62,6 +62,8 @@ func (baidu *Baidu) BaiduPCS() *baidupcs.BaiduPCS {
 	pcs.SetPanUserAgent(Config.PanUA)
 	pcs.SetUID(baidu.UID)
 	pcs.SetaccessToken(baidu.AccessToken)
+	pcs.SetGovernor(Config.Governor())
+	pcs.SetDiskCache(Config.DiskCache(baidu.UID))
 	return pcs
 }
 
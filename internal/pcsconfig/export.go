@@ -80,6 +80,15 @@ func (c *PCSConfig) PrintTable() {
 		[]string{"ignore_illegal", fmt.Sprint(c.IgnoreIllegal), "false", "关闭上传文件的文件名非法字符检查"},
 		[]string{"upload_policy", fmt.Sprint(c.UPolicy), baidupcs.SkipPolicy, fmt.Sprintf("上传遇到重名文件时的处理策略, %s(默认，跳过)、%s(覆盖)、%s(仅跳过大小未变化的文件其余覆盖)",
 			baidupcs.SkipPolicy, baidupcs.OverWritePolicy, baidupcs.RsyncPolicy)},
+		[]string{"filename_profile", c.FilenameProfile, "留空, posix, windows, exfat", "下载时按目标文件系统的规则映射文件名中的非法字符, 上传时还原, 留空表示不映射"},
+		[]string{"filename_normalize", c.FilenameNormalize, "留空, nfc, nfd", "文件名 Unicode 规范化形式, 避免 macOS 上传出现看起来重复的文件名"},
+		[]string{"max_path_length", strconv.Itoa(c.MaxPathLength), "0, 260", "下载保存路径最大长度, 超出时截断文件名, 0代表不限制"},
+		[]string{"retry_initial_wait", strconv.Itoa(c.RetryInitialWait), "1 ~ 10", "失败重试第一次的等待时间, 单位: 秒, 之后每次翻倍"},
+		[]string{"retry_max_wait", strconv.Itoa(c.RetryMaxWait), "10 ~ 120", "失败重试的最大等待时间, 单位: 秒"},
+		[]string{"retry_max_elapsed", strconv.Itoa(c.RetryMaxElapsed), "0", "任务从开始执行起超过此时间不再重试, 单位: 秒, 0代表不限制"},
+		[]string{"api_rate_limit", c.APIRateLimit, DefaultAPIRateLimit, "各类接口每秒最多请求的次数, 类别: list, manage, locate, upload, share, default, 0代表不限制"},
+		[]string{"api_cooldown", strconv.Itoa(c.APICooldown), "30", "触发百度服务器频率限制后暂停所有请求的时间, 单位: 秒, 连续触发时翻倍, 0代表不暂停"},
+		[]string{"disk_cache_ttl", c.DiskCacheTTL, "", "各操作的磁盘缓存时间, 多次运行之间共用, 如 list=5m,uk=24h, 类别: list, uk, du, 留空表示目录列表只在内存中缓存, du 默认缓存 24h"},
 		[]string{"user_agent", c.UserAgent, requester.DefaultUserAgent, "浏览器标识"},
 		[]string{"pcs_ua", c.PCSUA, "", "PCS 浏览器标识"},
 		[]string{"pcs_addr", c.PCSAddr, "pcs.baidu.com", "PCS 服务器地址"},
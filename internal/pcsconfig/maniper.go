@@ -4,7 +4,10 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/governor"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/namemapper"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester"
 )
 
@@ -264,6 +267,74 @@ func (c *PCSConfig) SetIgnoreIllegal(ignore bool) {
 	c.IgnoreIllegal = ignore
 }
 
+// SetFilenameProfile 设置下载文件名映射规则
+func (c *PCSConfig) SetFilenameProfile(profile string) bool {
+	if !namemapper.CheckProfile(profile) {
+		return false
+	}
+	c.FilenameProfile = profile
+	return true
+}
+
+// SetFilenameNormalize 设置文件名 Unicode 规范化形式
+func (c *PCSConfig) SetFilenameNormalize(normalize string) bool {
+	if !namemapper.CheckNormalization(normalize) {
+		return false
+	}
+	c.FilenameNormalize = normalize
+	return true
+}
+
+// SetMaxPathLength 设置下载保存路径最大长度
+func (c *PCSConfig) SetMaxPathLength(length int) {
+	c.MaxPathLength = length
+}
+
+// SetRetryInitialWait 设置第一次重试的等待时间, 单位: 秒
+func (c *PCSConfig) SetRetryInitialWait(seconds int) {
+	c.RetryInitialWait = seconds
+}
+
+// SetRetryMaxWait 设置重试的最大等待时间, 单位: 秒
+func (c *PCSConfig) SetRetryMaxWait(seconds int) {
+	c.RetryMaxWait = seconds
+}
+
+// SetRetryMaxElapsed 设置任务重试的最长时间, 单位: 秒
+func (c *PCSConfig) SetRetryMaxElapsed(seconds int) {
+	c.RetryMaxElapsed = seconds
+}
+
+// SetAPIRateLimit 设置各类接口每秒最多请求的次数, 格式如 list=5,manage=2
+func (c *PCSConfig) SetAPIRateLimit(limit string) error {
+	_, err := governor.ParseLimits(limit)
+	if err != nil {
+		return err
+	}
+	c.APIRateLimit = limit
+	c.governor = nil
+	return nil
+}
+
+// SetAPICooldown 设置触发服务器频率限制后暂停请求的时间, 单位: 秒
+func (c *PCSConfig) SetAPICooldown(seconds int) {
+	c.APICooldown = seconds
+	c.governor = nil
+}
+
+// SetDiskCacheTTL 设置各操作的磁盘缓存时间, 格式如 list=5m,uk=24h
+func (c *PCSConfig) SetDiskCacheTTL(ttl string) error {
+	_, err := baidupcs.ParseCacheTTL(ttl)
+	if err != nil {
+		return err
+	}
+	c.DiskCacheTTL = ttl
+	if c.pcs != nil {
+		c.pcs.SetDiskCache(c.DiskCache(c.activeUser.UID))
+	}
+	return nil
+}
+
 // SetForceLogin 设置强制登录
 func (c *PCSConfig) SetForceLogin(username string) {
 	c.ForceLogin = username
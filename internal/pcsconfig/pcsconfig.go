@@ -4,8 +4,10 @@ package pcsconfig
 import (
 	"github.com/json-iterator/go"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/governor"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/namemapper"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester"
 	"os"
@@ -20,6 +22,8 @@ const (
 	EnvConfigDir = "BAIDUPCS_GO_CONFIG_DIR"
 	// ConfigName 配置文件名
 	ConfigName = "pcs_config.json"
+	// DefaultAPIRateLimit 默认的各类接口每秒最多请求的次数
+	DefaultAPIRateLimit = "list=5,manage=2,locate=3,upload=10,share=1,default=10"
 )
 
 var (
@@ -61,11 +65,25 @@ type PCSConfig struct {
 	IgnoreIllegal  bool   `json:"ignore_illegal"`       // 禁用上传文件名非法字符检查
 	UPolicy        string `json:"u_policy"`             // 上传重名文件处理策略
 
+	FilenameProfile   string `json:"filename_profile"`   // 下载文件名映射规则
+	FilenameNormalize string `json:"filename_normalize"` // 文件名 Unicode 规范化形式
+	MaxPathLength     int    `json:"max_path_length"`    // 下载保存路径最大长度
+
+	RetryInitialWait int `json:"retry_initial_wait"` // 第一次重试的等待时间, 单位: 秒
+	RetryMaxWait     int `json:"retry_max_wait"`     // 重试的最大等待时间, 单位: 秒
+	RetryMaxElapsed  int `json:"retry_max_elapsed"`  // 任务从开始执行起, 超过此时间不再重试, 单位: 秒
+
+	APIRateLimit string `json:"api_rate_limit"` // 各类接口每秒最多请求的次数
+	APICooldown  int    `json:"api_cooldown"`   // 触发服务器频率限制后暂停请求的时间, 单位: 秒
+
+	DiskCacheTTL string `json:"disk_cache_ttl"` // 各操作的磁盘缓存时间, 留空表示只在内存中缓存
+
 	configFilePath string
 	configFile     *os.File
 	fileMu         sync.Mutex
 	activeUser     *Baidu
 	pcs            *baidupcs.BaiduPCS
+	governor       *governor.Governor
 }
 
 // NewConfig 返回 PCSConfig 指针对象
@@ -247,6 +265,11 @@ func (c *PCSConfig) InitDefaultConfig() {
 	c.IgnoreIllegal = true
 	c.ForceLogin = ""
 	c.EnableHTTPS = true
+	c.RetryInitialWait = 2
+	c.RetryMaxWait = 30
+	c.RetryMaxElapsed = 0
+	c.APIRateLimit = DefaultAPIRateLimit
+	c.APICooldown = 30
 
 	// 设置默认的下载路径
 	switch runtime.GOOS {
@@ -331,4 +354,31 @@ func (c *PCSConfig) fix() {
 	if c.UPolicy != baidupcs.SkipPolicy && c.UPolicy != baidupcs.OverWritePolicy && c.UPolicy != baidupcs.RsyncPolicy {
 		c.UPolicy = baidupcs.SkipPolicy
 	}
+	if !namemapper.CheckProfile(c.FilenameProfile) {
+		c.FilenameProfile = ""
+	}
+	if !namemapper.CheckNormalization(c.FilenameNormalize) {
+		c.FilenameNormalize = ""
+	}
+	if c.MaxPathLength < 0 {
+		c.MaxPathLength = 0
+	}
+	if c.RetryInitialWait < 1 {
+		c.RetryInitialWait = 1
+	}
+	if c.RetryMaxWait < c.RetryInitialWait {
+		c.RetryMaxWait = c.RetryInitialWait
+	}
+	if c.RetryMaxElapsed < 0 {
+		c.RetryMaxElapsed = 0
+	}
+	if _, err := governor.ParseLimits(c.APIRateLimit); err != nil {
+		c.APIRateLimit = DefaultAPIRateLimit
+	}
+	if c.APICooldown < 0 {
+		c.APICooldown = 0
+	}
+	if _, err := baidupcs.ParseCacheTTL(c.DiskCacheTTL); err != nil {
+		c.DiskCacheTTL = ""
+	}
 }
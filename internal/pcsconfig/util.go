@@ -1,8 +1,17 @@
 package pcsconfig
 
 import (
+	"fmt"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires/cachemap"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/governor"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/delay"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/namemapper"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // AverageParallel 返回平均的下载最大并发量
@@ -32,3 +41,51 @@ func showMaxRate(size int64) string {
 	}
 	return converter.ConvertFileSize(size, 2) + "/s"
 }
+
+// FilenameMapper 根据配置返回文件名映射
+func (c *PCSConfig) FilenameMapper() *namemapper.Mapper {
+	return &namemapper.Mapper{
+		Profile:       namemapper.Profile(c.FilenameProfile),
+		Normalization: namemapper.Normalization(c.FilenameNormalize),
+		MaxPathLength: c.MaxPathLength,
+	}
+}
+
+// RetryBackoff 根据配置返回失败重试的退避策略
+func (c *PCSConfig) RetryBackoff() *delay.Backoff {
+	return &delay.Backoff{
+		Initial:    time.Duration(c.RetryInitialWait) * time.Second,
+		Max:        time.Duration(c.RetryMaxWait) * time.Second,
+		Multiplier: 2,
+		Jitter:     0.2,
+		MaxElapsed: time.Duration(c.RetryMaxElapsed) * time.Second,
+	}
+}
+
+// Governor 根据配置返回请求调度器, 所有帐号共用
+func (c *PCSConfig) Governor() *governor.Governor {
+	if c.governor != nil {
+		return c.governor
+	}
+	limits, err := governor.ParseLimits(c.APIRateLimit)
+	if err != nil {
+		limits, _ = governor.ParseLimits(DefaultAPIRateLimit)
+	}
+	c.governor = governor.New(limits, time.Duration(c.APICooldown)*time.Second)
+	c.governor.Notify = func(msg string) {
+		fmt.Printf("\n[限流] %s\n", msg)
+	}
+	return c.governor
+}
+
+// DiskCache 根据配置返回帐号的磁盘缓存, 没有设置 du 的缓存时间时使用默认值
+func (c *PCSConfig) DiskCache(uid uint64) *cachemap.DiskCache {
+	ttl, err := baidupcs.ParseCacheTTL(c.DiskCacheTTL)
+	if err != nil {
+		return nil
+	}
+	if _, ok := ttl[baidupcs.OperationDu]; !ok {
+		ttl[baidupcs.OperationDu] = baidupcs.DefaultDuCacheTTL
+	}
+	return cachemap.NewDiskCache(filepath.Join(GetConfigDir(), "cache", strconv.FormatUint(uid, 10)), ttl)
+}
@@ -1,13 +1,30 @@
 package pcsfunctions
 
 import (
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
 	"time"
 )
 
-// RetryWait 失败重试等待事件
-func RetryWait(retry int) time.Duration {
-	if retry < 3 {
-		return 2 * time.Duration(retry) * time.Second
+const (
+	// rateLimitFactor 触发频率限制时, 等待时间的倍数
+	rateLimitFactor = 4
+)
+
+// RetryWait 失败重试等待时间, 按配置的退避策略计算, 触发频率限制时等待更长时间
+func RetryWait(info *taskframework.TaskInfo) time.Duration {
+	d := pcsconfig.Config.RetryBackoff().Delay(info.Retry())
+	if pcserror.Classify(info.LastError()) == pcserror.ErrClassRateLimited {
+		d *= rateLimitFactor
+	}
+	return d
+}
+
+// NeedRetry 根据错误分类和任务已执行的时间, 判断是否需要重试
+func NeedRetry(info *taskframework.TaskInfo, err error) bool {
+	if !pcserror.Classify(err).Retryable() {
+		return false
 	}
-	return 6 * time.Second
+	return !pcsconfig.Config.RetryBackoff().Exceeded(info.Elapsed())
 }
@@ -0,0 +1,144 @@
+package pcscat
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/downloader"
+)
+
+type (
+	// DlinkReader 通过下载链接按范围读取网盘文件
+	DlinkReader struct {
+		client *requester.HTTPClient
+		dlink  string
+		Size   int64 // 文件大小, 由下载链接检测函数获得, -1 表示未知
+	}
+)
+
+var (
+	// ErrRangeNotSatisfiable 请求的范围超出文件大小
+	ErrRangeNotSatisfiable = errors.New("请求的范围超出文件大小")
+)
+
+// NewDlinkReader 获取文件的下载链接, 使用 check 检测链接, 返回第一个可用的链接.
+// 与下载相同, client 使用 pcs 的 cookie 访问链接. check 为空时使用 downloader.DefaultDURLCheckFunc
+func NewDlinkReader(pcs *baidupcs.BaiduPCS, client *requester.HTTPClient, pcspath string, check downloader.DURLCheckFunc) (dr *DlinkReader, err error) {
+	if check == nil {
+		check = downloader.DefaultDURLCheckFunc
+	}
+	dlinks, err := pcsdownload.GetLocateDownloadLinks(pcs, pcspath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range dlinks {
+		// 跳过nb.cache这种还没有证书的
+		if strings.HasPrefix(u.Host, "nb.cache") && len(dlinks) > 1 {
+			continue
+		}
+		pcsdownload.FixHTTPLinkURL(u)
+		if jar, jarErr := pcsdownload.CloneJarWithDomain(pcs.GetClient().Jar, u.String()); jarErr == nil {
+			client.SetCookiejar(jar)
+		}
+
+		var (
+			size int64
+			resp *http.Response
+		)
+		size, resp, err = check(client, u.String())
+		if err != nil {
+			continue
+		}
+		err = checkStatus(resp)
+		resp.Body.Close()
+		if err == ErrRangeNotSatisfiable {
+			// 空文件
+			size, err = 0, nil
+		} else if err != nil {
+			continue
+		} else if size <= 0 {
+			size = responseSize(resp)
+		}
+		return &DlinkReader{
+			client: client,
+			dlink:  u.String(),
+			Size:   size,
+		}, nil
+	}
+	if err == nil {
+		err = pcsdownload.ErrDlinkNotFound
+	}
+	return nil, err
+}
+
+// checkStatus 检查下载链接的响应, 返回服务器的错误
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		return ErrRangeNotSatisfiable
+	}
+	// 返回的错误可能是pcs的json
+	if pcsError := pcserror.DecodePCSJSONError(baidupcs.OperationDownloadFile, resp.Body); pcsError != nil {
+		return pcsError
+	}
+	return fmt.Errorf("下载链接返回错误: %s", resp.Status)
+}
+
+// responseSize 从响应头获取文件大小, 未知时返回 -1
+func responseSize(resp *http.Response) int64 {
+	if resp.StatusCode == http.StatusPartialContent {
+		// Content-Range: bytes 0-99/1234
+		cr := resp.Header.Get("Content-Range")
+		if i := strings.LastIndex(cr, "/"); i >= 0 {
+			if size, err := strconv.ParseInt(cr[i+1:], 10, 64); err == nil {
+				return size
+			}
+		}
+		return -1
+	}
+	return resp.ContentLength
+}
+
+// ReadRange 读取文件的范围, 服务器不支持 Range 时跳过 offset 之前的数据
+func (dr *DlinkReader) ReadRange(offset, length int64) (io.ReadCloser, error) {
+	if dr.Size >= 0 && offset >= dr.Size {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+
+	rangeStr := "bytes=" + strconv.FormatInt(offset, 10) + "-"
+	if length >= 0 {
+		rangeStr += strconv.FormatInt(offset+length-1, 10)
+	}
+	resp, err := dr.client.Req(http.MethodGet, dr.dlink, nil, map[string]string{
+		"Range": rangeStr,
+	})
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, err
+	}
+	if err = checkStatus(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusOK && offset > 0 {
+		if _, err = io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,84 @@
+package pcscat
+
+import (
+	"bytes"
+	"io"
+)
+
+const (
+	// DefaultBlockSize CopyParallel 每个连接每次下载的大小
+	DefaultBlockSize = 4 * 1024 * 1024
+	// blockRetry 下载一个块失败后的重试次数
+	blockRetry = 3
+)
+
+type (
+	blockResult struct {
+		data []byte
+		err  error
+	}
+)
+
+// readBlock 读取一个块, 数据不完整时重试
+func readBlock(r RangeReader, offset, length int64) (data []byte, err error) {
+	for i := 0; i <= blockRetry; i++ {
+		buf := bytes.NewBuffer(make([]byte, 0, length))
+		var n int64
+		n, err = Copy(buf, r, offset, length)
+		if err == nil && n != length {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil {
+			return buf.Bytes(), nil
+		}
+	}
+	return nil, err
+}
+
+// CopyParallel 把大小为 size 的文件分成 blockSize 大小的块, 使用 parallel 个连接同时下载,
+// 按顺序写入 w, 最多同时在内存中保存 parallel 个块. 返回写入的字节数.
+func CopyParallel(w io.Writer, r RangeReader, size, blockSize int64, parallel int) (written int64, err error) {
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	var (
+		blocks  = (size + blockSize - 1) / blockSize
+		pending = make(map[int64]chan blockResult, parallel)
+		start   = func(i int64) {
+			length := blockSize
+			if (i+1)*blockSize > size {
+				length = size - i*blockSize
+			}
+			// 带缓冲, 出错提前返回时不会阻塞
+			ch := make(chan blockResult, 1)
+			pending[i] = ch
+			go func() {
+				data, err := readBlock(r, i*blockSize, length)
+				ch <- blockResult{data: data, err: err}
+			}()
+		}
+	)
+	for i := int64(0); i < int64(parallel) && i < blocks; i++ {
+		start(i)
+	}
+	for i := int64(0); i < blocks; i++ {
+		res := <-pending[i]
+		delete(pending, i)
+		if res.err != nil {
+			return written, res.err
+		}
+		if next := i + int64(parallel); next < blocks {
+			start(next)
+		}
+		n, err := w.Write(res.data)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,187 @@
+// Package pcscat 按范围读取网盘文件的开头或结尾, 用于 cat, head, tail
+package pcscat
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+const (
+	// TailChunkSize tail 从文件末尾向前每次读取的大小
+	TailChunkSize = 64 * 1024
+)
+
+type (
+	// RangeReader 按范围读取文件, length 为 -1 时读取到文件末尾
+	RangeReader interface {
+		ReadRange(offset, length int64) (io.ReadCloser, error)
+	}
+
+	// StorageReader 从存储后端按范围读取文件
+	StorageReader struct {
+		Storage baidupcs.Storage
+		Path    string
+	}
+)
+
+// ReadRange 读取文件的范围
+func (sr *StorageReader) ReadRange(offset, length int64) (io.ReadCloser, error) {
+	rc, pcsError := sr.Storage.OpenRange(sr.Path, offset, length)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return rc, nil
+}
+
+// Copy 把文件从 offset 开始, 长度为 length 的数据写入 w, length 为 -1 时写到文件末尾
+func Copy(w io.Writer, r RangeReader, offset, length int64) (n int64, err error) {
+	if length == 0 {
+		return 0, nil
+	}
+	rc, err := r.ReadRange(offset, length)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	if length < 0 {
+		return io.Copy(w, rc)
+	}
+	n, err = io.CopyN(w, rc, length)
+	if err == io.EOF {
+		err = nil
+	}
+	return
+}
+
+// HeadLines 从 rd 读取并写入前 lines 行, 读够后即停止
+func HeadLines(w io.Writer, rd io.Reader, lines int) error {
+	if lines <= 0 {
+		return nil
+	}
+	br := bufio.NewReader(rd)
+	for i := 0; i < lines; i++ {
+		line, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// 超长的行, 继续读取本行剩余部分
+			i--
+		} else if err == io.EOF {
+			_, err = w.Write(line)
+			return err
+		} else if err != nil {
+			return err
+		}
+		if _, err = w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HeadBytes 从 rd 读取并写入前 n 个字节
+func HeadBytes(w io.Writer, rd io.Reader, n int64) error {
+	_, err := io.CopyN(w, rd, n)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// TailLines 从 rd 读取全部数据, 写入最后 lines 行, 用于不能按范围读取的数据, 如解压后的数据
+func TailLines(w io.Writer, rd io.Reader, lines int) error {
+	if lines <= 0 {
+		_, err := io.Copy(io.Discard, rd)
+		return err
+	}
+	var (
+		br   = bufio.NewReader(rd)
+		ring = make([][]byte, lines)
+		next int
+		full bool
+	)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			ring[next] = line
+			next = (next + 1) % lines
+			full = full || next == 0
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if full {
+		ring = append(ring[next:], ring[:next]...)
+	} else {
+		ring = ring[:next]
+	}
+	for _, line := range ring {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TailBytes 从 rd 读取全部数据, 写入最后 n 个字节
+func TailBytes(w io.Writer, rd io.Reader, n int64) error {
+	buf := make([]byte, 0, n)
+	chunk := make([]byte, 32*1024)
+	for {
+		m, err := rd.Read(chunk)
+		buf = append(buf, chunk[:m]...)
+		if int64(len(buf)) > n {
+			buf = append(buf[:0], buf[int64(len(buf))-n:]...)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// TailOffset 从文件末尾向前按块读取, 返回最后 lines 行的起始位置.
+// 文件末尾的换行符不计入行数. data 为已读取的从起始位置到文件末尾的数据.
+func TailOffset(r RangeReader, size int64, lines int) (offset int64, data []byte, err error) {
+	if lines <= 0 {
+		return size, nil, nil
+	}
+	var (
+		end   = size
+		count = 0
+	)
+	for end > 0 {
+		start := end - TailChunkSize
+		if start < 0 {
+			start = 0
+		}
+		chunk := &bytes.Buffer{}
+		_, err = Copy(chunk, r, start, end-start)
+		if err != nil {
+			return 0, nil, err
+		}
+		b := chunk.Bytes()
+		data = append(b, data...)
+		for i := len(b) - 1; i >= 0; i-- {
+			if b[i] != '\n' || start+int64(i) == size-1 {
+				continue
+			}
+			count++
+			if count == lines {
+				k := start + int64(i) + 1
+				return k, data[k-start:], nil
+			}
+		}
+		end = start
+	}
+	return 0, data, nil
+}
@@ -0,0 +1,158 @@
+package pcscat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcstest"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+func lines(n int) string {
+	builder := &strings.Builder{}
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(builder, "line %d\n", i)
+	}
+	return builder.String()
+}
+
+func TestHeadTail(t *testing.T) {
+	text := lines(10)
+	tests := []struct {
+		name string
+		fn   func(w *bytes.Buffer) error
+		want string
+	}{
+		{"head -n 3", func(w *bytes.Buffer) error { return HeadLines(w, strings.NewReader(text), 3) }, lines(3)},
+		{"head -n 20", func(w *bytes.Buffer) error { return HeadLines(w, strings.NewReader(text), 20) }, text},
+		{"head -n without newline", func(w *bytes.Buffer) error { return HeadLines(w, strings.NewReader("a\nb"), 5) }, "a\nb"},
+		{"head -c 8", func(w *bytes.Buffer) error { return HeadBytes(w, strings.NewReader(text), 8) }, "line 1\nl"},
+		{"tail -n 2", func(w *bytes.Buffer) error { return TailLines(w, strings.NewReader(text), 2) }, "line 9\nline 10\n"},
+		{"tail -n 20", func(w *bytes.Buffer) error { return TailLines(w, strings.NewReader(text), 20) }, text},
+		{"tail -n without newline", func(w *bytes.Buffer) error { return TailLines(w, strings.NewReader("a\nb\nc"), 2) }, "b\nc"},
+		{"tail -c 4", func(w *bytes.Buffer) error { return TailBytes(w, strings.NewReader(text), 4) }, " 10\n"},
+	}
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		if err := test.fn(buf); err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, buf.String(), test.want)
+		}
+	}
+}
+
+func TestTailOffset(t *testing.T) {
+	s := storage.NewMemory()
+	// 跨越多个块
+	text := strings.Repeat(lines(10), TailChunkSize/40)
+	s.WriteFile("/log.txt", strings.NewReader(text), int64(len(text)))
+	r := &StorageReader{Storage: s, Path: "/log.txt"}
+
+	for _, n := range []int{1, 3, 5000, 100000} {
+		offset, data, err := TailOffset(r, int64(len(text)), n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &bytes.Buffer{}
+		TailLines(want, strings.NewReader(text), n)
+		if string(data) != want.String() || offset != int64(len(text)-want.Len()) {
+			t.Errorf("tail -n %d: unexpected offset %d, %d bytes", n, offset, len(data))
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := Copy(buf, r, int64(len(text))-8, -1); err != nil || buf.String() != "line 10\n" {
+		t.Errorf("unexpected copy %q, %v", buf.String(), err)
+	}
+}
+
+func TestDlinkReader(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	text := lines(100)
+	srv.WriteFile("/logs/app.log", []byte(text))
+	srv.WriteFile("/empty", nil)
+	pcs := srv.NewPCS()
+
+	dr, err := NewDlinkReader(pcs, requester.NewHTTPClient(), "/logs/app.log", pcsdownload.BaiduPCSURLCheckFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dr.Size != int64(len(text)) {
+		t.Errorf("unexpected size %d", dr.Size)
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err = Copy(buf, dr, 7, 7); err != nil || buf.String() != "line 2\n" {
+		t.Errorf("unexpected range %q, %v", buf.String(), err)
+	}
+	_, data, err := TailOffset(dr, dr.Size, 2)
+	if err != nil || string(data) != "line 99\nline 100\n" {
+		t.Errorf("unexpected tail %q, %v", data, err)
+	}
+
+	dr, err = NewDlinkReader(pcs, requester.NewHTTPClient(), "/empty", pcsdownload.BaiduPCSURLCheckFunc)
+	if err != nil || dr.Size != 0 {
+		t.Fatalf("unexpected empty file reader %v, %v", dr, err)
+	}
+	buf.Reset()
+	if _, err = Copy(buf, dr, 0, -1); err != nil || buf.Len() != 0 {
+		t.Errorf("unexpected empty file content %q, %v", buf.String(), err)
+	}
+
+	if _, err = NewDlinkReader(pcs, requester.NewHTTPClient(), "/missing", nil); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+// flakyReader 每个范围第一次读取时返回不完整的数据, 先请求的范围返回得更慢
+type flakyReader struct {
+	RangeReader
+	mu    sync.Mutex
+	tried map[int64]bool
+}
+
+func (fr *flakyReader) ReadRange(offset, length int64) (io.ReadCloser, error) {
+	fr.mu.Lock()
+	first := !fr.tried[offset]
+	fr.tried[offset] = true
+	fr.mu.Unlock()
+
+	time.Sleep(time.Duration(1000-offset%1000) * time.Microsecond)
+	if first {
+		return io.NopCloser(strings.NewReader("x")), nil
+	}
+	return fr.RangeReader.ReadRange(offset, length)
+}
+
+func TestCopyParallel(t *testing.T) {
+	s := storage.NewMemory()
+	text := lines(1000)
+	s.WriteFile("/big.txt", strings.NewReader(text), int64(len(text)))
+	r := &flakyReader{RangeReader: &StorageReader{Storage: s, Path: "/big.txt"}, tried: map[int64]bool{}}
+
+	buf := &bytes.Buffer{}
+	n, err := CopyParallel(buf, r, int64(len(text)), 100, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(text)) || buf.String() != text {
+		t.Errorf("data differs, %d bytes written", n)
+	}
+
+	// 文件不存在
+	r = &flakyReader{RangeReader: &StorageReader{Storage: s, Path: "/missing"}, tried: map[int64]bool{}}
+	if _, err = CopyParallel(io.Discard, r, 1000, 100, 4); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"archive/zip"
 	"errors"
 	"fmt"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/diskfree"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 )
 
@@ -126,16 +126,14 @@ func (ct *CompressTask) countFiles() error {
 }
 
 func (ct *CompressTask) checkDiskSpace() error {
-	var stat syscall.Statfs_t
 	targetDir := filepath.Dir(ct.TargetZipPath)
 	if targetDir == "" {
 		targetDir = "."
 	}
-	err := syscall.Statfs(targetDir, &stat)
+	freeSpace, err := diskfree.Free(targetDir)
 	if err != nil {
 		return nil
 	}
-	freeSpace := int64(stat.Bavail) * int64(stat.Bsize)
 	estimatedSize := ct.TotalSize
 	if estimatedSize > freeSpace {
 		return ErrDiskSpaceInsufficient
@@ -157,5 +157,5 @@ func (cutu *CompressUploadTaskUnit) OnComplete(lastRunResult *taskframework.Task
 }
 
 func (cutu *CompressUploadTaskUnit) RetryWait() time.Duration {
-	return pcsfunctions.RetryWait(cutu.taskInfo.Retry())
+	return pcsfunctions.RetryWait(cutu.taskInfo)
 }
@@ -0,0 +1,184 @@
+// Package pcsdedupe 查找网盘中的重复文件
+package pcsdedupe
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// Group 大小和 md5 都相同的一组文件
+	Group struct {
+		Size  int64
+		MD5   string
+		Files baidupcs.FileDirectoryList
+	}
+
+	// FixMD5Func 修复服务器返回的不正确的 md5, 返回正确的 md5
+	FixMD5Func func(fd *baidupcs.FileDirectory) (md5 string, err error)
+
+	// Options 查找选项
+	Options struct {
+		MinSize int64      // 忽略小于 MinSize 的文件, 空文件总是忽略
+		FixMD5  FixMD5Func // 为空时忽略 md5 可能不正确的文件
+		OnSkip  func(fd *baidupcs.FileDirectory, err error)
+	}
+
+	// KeepPolicy 保留策略, 决定每组重复文件中保留哪一个
+	KeepPolicy struct {
+		Name string
+		Dir  string // first-in-dir 的目录
+	}
+)
+
+const (
+	// KeepOldest 保留最早创建的文件
+	KeepOldest = "oldest"
+	// KeepNewest 保留最新创建的文件
+	KeepNewest = "newest"
+	// KeepShortestPath 保留路径最短的文件
+	KeepShortestPath = "shortest-path"
+	// KeepFirstInDir 优先保留指定目录中的文件, 目录中没有时保留最早创建的文件
+	KeepFirstInDir = "first-in-dir"
+)
+
+var (
+	// ErrMD5Unreliable md5 可能不正确
+	ErrMD5Unreliable = errors.New("md5 可能不正确")
+)
+
+// Find 在 fdl 中查找重复文件, 按可释放的空间从大到小排序.
+// 只有大小相同的文件才会比较 md5, md5 可能不正确时先尝试修复.
+func Find(fdl baidupcs.FileDirectoryList, opt *Options) (groups []*Group) {
+	if opt == nil {
+		opt = &Options{}
+	}
+
+	bySize := map[int64]baidupcs.FileDirectoryList{}
+	for _, fd := range fdl {
+		if fd.Isdir || fd.Size <= 0 || fd.Size < opt.MinSize {
+			continue
+		}
+		bySize[fd.Size] = append(bySize[fd.Size], fd)
+	}
+
+	byMD5 := map[string]*Group{}
+	for size, files := range bySize {
+		if len(files) < 2 {
+			continue
+		}
+		for _, fd := range files {
+			md5 := strings.ToLower(fd.MD5)
+			if !fd.MD5Reliable() {
+				var err error = ErrMD5Unreliable
+				if opt.FixMD5 != nil {
+					md5, err = opt.FixMD5(fd)
+				}
+				if err != nil {
+					if opt.OnSkip != nil {
+						opt.OnSkip(fd, err)
+					}
+					continue
+				}
+				md5 = strings.ToLower(md5)
+			}
+
+			key := fmt.Sprintf("%d_%s", size, md5)
+			g := byMD5[key]
+			if g == nil {
+				g = &Group{Size: size, MD5: md5}
+				byMD5[key] = g
+			}
+			g.Files = append(g.Files, fd)
+		}
+	}
+
+	for _, g := range byMD5 {
+		if len(g.Files) < 2 {
+			continue
+		}
+		sort.Slice(g.Files, func(i, j int) bool {
+			return g.Files[i].Path < g.Files[j].Path
+		})
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].Reclaimable(), groups[j].Reclaimable()
+		if a != b {
+			return a > b
+		}
+		return groups[i].Files[0].Path < groups[j].Files[0].Path
+	})
+	return groups
+}
+
+// Reclaimable 只保留一个文件时可释放的空间
+func (g *Group) Reclaimable() int64 {
+	return g.Size * int64(len(g.Files)-1)
+}
+
+// ParseKeepPolicy 解析保留策略, 如 oldest, newest, shortest-path, first-in-dir /我的资源
+func ParseKeepPolicy(s string) (*KeepPolicy, error) {
+	fields := strings.SplitN(strings.TrimSpace(s), " ", 2)
+	switch fields[0] {
+	case KeepOldest, KeepNewest, KeepShortestPath:
+		if len(fields) == 1 {
+			return &KeepPolicy{Name: fields[0]}, nil
+		}
+	case KeepFirstInDir:
+		if len(fields) == 2 && strings.TrimSpace(fields[1]) != "" {
+			return &KeepPolicy{Name: fields[0], Dir: path.Clean(strings.TrimSpace(fields[1]))}, nil
+		}
+		return nil, fmt.Errorf("保留策略 %s 需要指定目录", KeepFirstInDir)
+	}
+	return nil, fmt.Errorf("未知的保留策略: %s, 支持: oldest, newest, shortest-path, first-in-dir <目录>", s)
+}
+
+// Split 按保留策略返回保留的文件和多余的文件
+func (g *Group) Split(policy *KeepPolicy) (keep *baidupcs.FileDirectory, extras baidupcs.FileDirectoryList) {
+	files := make(baidupcs.FileDirectoryList, len(g.Files))
+	copy(files, g.Files)
+
+	oldest := func(i, j int) bool {
+		if files[i].Ctime != files[j].Ctime {
+			return files[i].Ctime < files[j].Ctime
+		}
+		return files[i].Path < files[j].Path
+	}
+	switch policy.Name {
+	case KeepNewest:
+		sort.SliceStable(files, func(i, j int) bool {
+			if files[i].Ctime != files[j].Ctime {
+				return files[i].Ctime > files[j].Ctime
+			}
+			return files[i].Path < files[j].Path
+		})
+	case KeepShortestPath:
+		sort.SliceStable(files, func(i, j int) bool {
+			if len(files[i].Path) != len(files[j].Path) {
+				return len(files[i].Path) < len(files[j].Path)
+			}
+			return files[i].Path < files[j].Path
+		})
+	case KeepFirstInDir:
+		sort.SliceStable(files, func(i, j int) bool {
+			a, b := inDir(files[i].Path, policy.Dir), inDir(files[j].Path, policy.Dir)
+			if a != b {
+				return a
+			}
+			return oldest(i, j)
+		})
+	default:
+		sort.SliceStable(files, oldest)
+	}
+	return files[0], files[1:]
+}
+
+func inDir(p, dir string) bool {
+	return dir == baidupcs.PathSeparator || strings.HasPrefix(p, dir+baidupcs.PathSeparator)
+}
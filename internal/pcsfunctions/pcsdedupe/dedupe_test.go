@@ -0,0 +1,113 @@
+package pcsdedupe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+// md5Of 把测试用的短 md5 补齐为 32 位
+func md5Of(s string) string {
+	return s + strings.Repeat("0", 32-len(s))
+}
+
+func file(p string, size, ctime int64, md5 string, blocks int) *baidupcs.FileDirectory {
+	fd := &baidupcs.FileDirectory{
+		Path:  p,
+		Size:  size,
+		Ctime: ctime,
+		MD5:   md5Of(md5),
+	}
+	if blocks == 1 {
+		fd.BlockList = []string{fd.MD5}
+		return fd
+	}
+	for i := 0; i < blocks; i++ {
+		fd.BlockList = append(fd.BlockList, "block")
+	}
+	return fd
+}
+
+func testList() baidupcs.FileDirectoryList {
+	return baidupcs.FileDirectoryList{
+		file("/movies/a.mp4", 100, 3, "aaa", 1),
+		file("/backup/movies/a copy.mp4", 100, 1, "AAA", 1),
+		file("/tmp/a.mp4", 100, 2, "wrong", 3),
+		file("/movies/b.mp4", 100, 4, "bbb", 1),
+		file("/x/c.txt", 10, 1, "ccc", 1),
+		file("/y/c.txt", 10, 2, "ccc", 1),
+		file("/z/empty", 0, 1, "d41d8cd98f00b204e9800998ecf8427e", 1),
+		file("/z/empty2", 0, 1, "d41d8cd98f00b204e9800998ecf8427e", 1),
+		{Path: "/movies", Isdir: true},
+	}
+}
+
+func TestFind(t *testing.T) {
+	var skipped []string
+	groups := Find(testList(), &Options{
+		OnSkip: func(fd *baidupcs.FileDirectory, err error) {
+			skipped = append(skipped, fd.Path)
+		},
+	})
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	if groups[0].MD5 != md5Of("aaa") || len(groups[0].Files) != 2 || groups[0].Reclaimable() != 100 {
+		t.Errorf("unexpected group %+v", groups[0])
+	}
+	if groups[1].MD5 != md5Of("ccc") || groups[1].Reclaimable() != 10 {
+		t.Errorf("unexpected group %+v", groups[1])
+	}
+	if len(skipped) != 1 || skipped[0] != "/tmp/a.mp4" {
+		t.Errorf("unexpected skipped %v", skipped)
+	}
+
+	// 修复 md5 后加入分组
+	groups = Find(testList(), &Options{
+		MinSize: 50,
+		FixMD5: func(fd *baidupcs.FileDirectory) (string, error) {
+			if fd.Path == "/tmp/a.mp4" {
+				return md5Of("aaa"), nil
+			}
+			return "", errors.New("unexpected fix")
+		},
+	})
+	if len(groups) != 1 || len(groups[0].Files) != 3 || groups[0].Reclaimable() != 200 {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	g := Find(testList(), &Options{
+		FixMD5: func(fd *baidupcs.FileDirectory) (string, error) {
+			return md5Of("aaa"), nil
+		},
+	})[0]
+
+	cases := map[string]string{
+		"oldest":                   "/backup/movies/a copy.mp4",
+		"newest":                   "/movies/a.mp4",
+		"shortest-path":            "/tmp/a.mp4",
+		"first-in-dir /movies":     "/movies/a.mp4",
+		"first-in-dir /notexist/":  "/backup/movies/a copy.mp4",
+		"first-in-dir /tmp/../tmp": "/tmp/a.mp4",
+	}
+	for s, expected := range cases {
+		policy, err := ParseKeepPolicy(s)
+		if err != nil {
+			t.Fatalf("%s: %s", s, err)
+		}
+		keep, extras := g.Split(policy)
+		if keep.Path != expected || len(extras) != 2 {
+			t.Errorf("%s: keep %s, expected %s", s, keep.Path, expected)
+		}
+	}
+
+	for _, s := range []string{"", "largest", "first-in-dir", "oldest x"} {
+		if _, err := ParseKeepPolicy(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Package pcsdiff 比较两个网盘目录, 或网盘目录与保存的清单
+package pcsdiff
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// Entry 目录中的一个文件或目录, Path 为相对于根目录的路径, 不以 / 开头
+	Entry struct {
+		Path      string   `json:"path"`
+		Size      int64    `json:"size"`
+		MD5       string   `json:"md5,omitempty"`
+		BlockList []string `json:"block_list,omitempty"` // 分片 md5, 多于一个时 MD5 可能不正确
+		Mtime     int64    `json:"mtime,omitempty"`      // 修改日期, 0 为未知
+		Isdir     bool     `json:"isdir,omitempty"`
+	}
+
+	// Tree 按相对路径索引的目录树
+	Tree map[string]*Entry
+
+	// ChangeType 差异类型
+	ChangeType string
+
+	// Change 一处差异
+	Change struct {
+		Type   ChangeType `json:"type"`
+		Path   string     `json:"path"`             // 相对路径, 移动时为 A 中的路径
+		To     string     `json:"to,omitempty"`     // 移动时 B 中的路径
+		Reason string     `json:"reason,omitempty"` // 不同的原因
+		A      *Entry     `json:"a,omitempty"`
+		B      *Entry     `json:"b,omitempty"`
+	}
+
+	// Result 比较结果
+	Result struct {
+		Changes []*Change `json:"changes"`
+		Same    int       `json:"same"` // 相同的文件数
+	}
+)
+
+const (
+	// OnlyInA 只在 A 中
+	OnlyInA ChangeType = "only-a"
+	// OnlyInB 只在 B 中
+	OnlyInB ChangeType = "only-b"
+	// Mismatch 路径相同, 大小, md5 或类型不同
+	Mismatch ChangeType = "mismatch"
+	// Moved 内容相同, 路径不同
+	Moved ChangeType = "moved"
+	// Unknown 路径和大小相同, md5 可能不正确, 无法确定内容是否相同
+	Unknown ChangeType = "unknown"
+)
+
+// NewTree 从 root 下的文件列表生成目录树, 不在 root 下的忽略
+func NewTree(root string, fdl baidupcs.FileDirectoryList) Tree {
+	t := Tree{}
+	root = path.Clean(root)
+	for _, fd := range fdl {
+		rel, ok := relPath(root, fd.Path)
+		if !ok {
+			continue
+		}
+		t[rel] = &Entry{
+			Path:      rel,
+			Size:      fd.Size,
+			MD5:       strings.ToLower(fd.MD5),
+			BlockList: fd.BlockList,
+			Mtime:     fd.Mtime,
+			Isdir:     fd.Isdir,
+		}
+	}
+	return t
+}
+
+func relPath(root, p string) (string, bool) {
+	if root == baidupcs.PathSeparator {
+		return strings.TrimPrefix(p, baidupcs.PathSeparator), p != root
+	}
+	if !strings.HasPrefix(p, root+baidupcs.PathSeparator) {
+		return "", false
+	}
+	return p[len(root)+1:], true
+}
+
+// Walk 递归列出存储 s 中的目录 root, 任何目录列出失败时返回错误, 避免不完整的目录树产生错误的差异
+func Walk(s baidupcs.Storage, root string) (Tree, pcserror.Error) {
+	var (
+		fdl      baidupcs.FileDirectoryList
+		walkErr  pcserror.Error
+		rootInfo *baidupcs.FileDirectory
+	)
+	baidupcs.StorageRecurseList(s, root, baidupcs.DefaultOrderOptions, func(depth int, fdPath string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+		if pcsError != nil {
+			walkErr = pcsError
+			return false
+		}
+		if depth == 0 {
+			rootInfo = fd
+			return true
+		}
+		fdl = append(fdl, fd)
+		return true
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	if rootInfo != nil && !rootInfo.Isdir {
+		// 比较单个文件, 相对路径为文件名
+		return NewTree(path.Dir(rootInfo.Path), baidupcs.FileDirectoryList{rootInfo}), nil
+	}
+	return NewTree(root, fdl), nil
+}
+
+// Files 返回目录树中的文件, 按路径排序
+func (t Tree) Files() []*Entry {
+	files := make([]*Entry, 0, len(t))
+	for _, e := range t {
+		if !e.Isdir {
+			files = append(files, e)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// MD5Reliable 判断 md5 是否可信, 见 baidupcs.MD5Reliable
+func (e *Entry) MD5Reliable() bool {
+	return baidupcs.MD5Reliable(e.MD5, e.BlockList)
+}
+
+// compareContent 比较两个文件的内容, 相同时返回空的 ChangeType.
+// 缺少 md5 时返回 Unknown, md5 可能不正确时比较大小和修改日期, 修改日期不同或未知时返回 Unknown.
+func compareContent(a, b *Entry) (t ChangeType, reason string) {
+	if a.Size >= 0 && b.Size >= 0 && a.Size != b.Size {
+		return Mismatch, "大小不同"
+	}
+	if a.MD5 == "" || b.MD5 == "" {
+		return Unknown, "缺少 md5, 无法确定内容是否相同"
+	}
+	if a.MD5Reliable() && b.MD5Reliable() {
+		if a.MD5 != b.MD5 {
+			return Mismatch, "md5 不同"
+		}
+		return "", ""
+	}
+	if a.Mtime != 0 && a.Mtime == b.Mtime {
+		return "", ""
+	}
+	return Unknown, "md5 可能不正确, 无法确定内容是否相同"
+}
+
+// Compare 比较 a 和 b 中的文件, 按路径匹配; 只在一侧的文件如果内容与另一侧只在该侧的文件相同, 视为移动.
+// 目录只在类型不同时报告. 清单中大小未知的文件 Size 为 -1.
+// md5 可能不正确的文件不参与移动配对, 路径相同时按大小和修改日期比较.
+func Compare(a, b Tree) *Result {
+	var (
+		res   = &Result{}
+		onlyA []*Entry
+		onlyB []*Entry
+	)
+	for _, ea := range a.Files() {
+		eb, ok := b[ea.Path]
+		switch {
+		case !ok:
+			onlyA = append(onlyA, ea)
+		case eb.Isdir:
+			res.Changes = append(res.Changes, &Change{Type: Mismatch, Path: ea.Path, Reason: "A 中为文件, B 中为目录", A: ea, B: eb})
+		default:
+			if t, reason := compareContent(ea, eb); t == "" {
+				res.Same++
+			} else {
+				res.Changes = append(res.Changes, &Change{Type: t, Path: ea.Path, Reason: reason, A: ea, B: eb})
+			}
+		}
+	}
+	for _, eb := range b.Files() {
+		ea, ok := a[eb.Path]
+		switch {
+		case !ok:
+			onlyB = append(onlyB, eb)
+		case ea.Isdir:
+			res.Changes = append(res.Changes, &Change{Type: Mismatch, Path: eb.Path, Reason: "A 中为目录, B 中为文件", A: ea, B: eb})
+		}
+	}
+
+	// 按 md5 配对只在一侧的文件, md5 可能不正确的不配对
+	candidates := map[string][]*Entry{}
+	for _, eb := range onlyB {
+		if eb.MD5Reliable() {
+			candidates[eb.MD5] = append(candidates[eb.MD5], eb)
+		}
+	}
+	moved := map[*Entry]bool{}
+	for _, ea := range onlyA {
+		var (
+			list    = candidates[ea.MD5]
+			matched = -1
+		)
+		for k, eb := range list {
+			if t, _ := compareContent(ea, eb); t == "" && ea.MD5Reliable() {
+				matched = k
+				break
+			}
+		}
+		if matched < 0 {
+			res.Changes = append(res.Changes, &Change{Type: OnlyInA, Path: ea.Path, A: ea})
+			continue
+		}
+		eb := list[matched]
+		candidates[ea.MD5] = append(list[:matched:matched], list[matched+1:]...)
+		moved[eb] = true
+		res.Changes = append(res.Changes, &Change{Type: Moved, Path: ea.Path, To: eb.Path, A: ea, B: eb})
+	}
+	for _, eb := range onlyB {
+		if !moved[eb] {
+			res.Changes = append(res.Changes, &Change{Type: OnlyInB, Path: eb.Path, B: eb})
+		}
+	}
+
+	sort.SliceStable(res.Changes, func(i, j int) bool {
+		return res.Changes[i].Path < res.Changes[j].Path
+	})
+	return res
+}
+
+// Count 返回各类型差异的数量
+func (r *Result) Count() map[ChangeType]int {
+	count := map[ChangeType]int{}
+	for _, c := range r.Changes {
+		count[c.Type]++
+	}
+	return count
+}
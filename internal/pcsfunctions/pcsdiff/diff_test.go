@@ -0,0 +1,202 @@
+package pcsdiff
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeFiles(t *testing.T, s *storage.Memory, files map[string]string) {
+	for p, data := range files {
+		if pcsError := s.WriteFile(p, strings.NewReader(data), int64(len(data))); pcsError != nil {
+			t.Fatal(pcsError)
+		}
+	}
+}
+
+func summary(res *Result) (lines []string) {
+	for _, c := range res.Changes {
+		line := string(c.Type) + " " + c.Path
+		if c.To != "" {
+			line += " -> " + c.To
+		}
+		if c.Reason != "" {
+			line += " (" + c.Reason + ")"
+		}
+		lines = append(lines, line)
+	}
+	return
+}
+
+func TestCompare(t *testing.T) {
+	s := storage.NewMemory()
+	writeFiles(t, s, map[string]string{
+		"/a/same.txt":       "same",
+		"/a/changed.txt":    "old",
+		"/a/resized.txt":    "short",
+		"/a/only.txt":       "only a",
+		"/a/old/moved.txt":  "moved",
+		"/a/dup1.txt":       "dup",
+		"/a/dup2.txt":       "dup",
+		"/a/kind/x":         "dir in a",
+		"/b/same.txt":       "same",
+		"/b/changed.txt":    "new",
+		"/b/resized.txt":    "longer",
+		"/b/only.log":       "only b",
+		"/b/new/moved.txt":  "moved",
+		"/b/dup3.txt":       "dup",
+		"/b/kind":           "file in b",
+		"/b/empty/.keep":    "",
+		"/a/empty/.keep":    "",
+		"/elsewhere/x.txt":  "x",
+		"/a/deep/er/ok.bin": "ok",
+		"/b/deep/er/ok.bin": "ok",
+	})
+
+	a, pcsError := Walk(s, "/a")
+	if pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	b, pcsError := Walk(s, "/b")
+	if pcsError != nil {
+		t.Fatal(pcsError)
+	}
+
+	res := Compare(a, b)
+	got := summary(res)
+	if res.Same != 3 {
+		t.Errorf("expected 3 same files, got %d", res.Same)
+	}
+	for _, line := range []string{
+		"mismatch changed.txt (md5 不同)",
+		"mismatch resized.txt (大小不同)",
+		"moved dup1.txt -> dup3.txt",
+		"only-a dup2.txt",
+		"moved old/moved.txt -> new/moved.txt",
+		"only-b only.log",
+		"only-a only.txt",
+		"mismatch kind (A 中为目录, B 中为文件)",
+		"only-a kind/x",
+	} {
+		if !containsString(got, line) {
+			t.Errorf("missing %q in %v", line, got)
+		}
+	}
+	if len(got) != 9 {
+		t.Errorf("unexpected changes %v", got)
+	}
+
+	count := res.Count()
+	if count[Moved] != 2 || count[OnlyInA] != 3 || count[OnlyInB] != 1 || count[Mismatch] != 3 {
+		t.Errorf("unexpected count %v", count)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestManifest(t *testing.T) {
+	s := storage.NewMemory()
+	writeFiles(t, s, map[string]string{
+		"/p/1.txt":     "one",
+		"/p/sub/2.txt": "two",
+	})
+	tree, pcsError := Walk(s, "/p")
+	if pcsError != nil {
+		t.Fatal(pcsError)
+	}
+
+	filename := filepath.Join(t.TempDir(), "p.json")
+	err := NewManifest("/p", tree).Save(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManifest(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := Compare(tree, m.Tree()); len(res.Changes) != 0 || res.Same != 2 {
+		t.Errorf("unexpected diff with saved manifest: %v", summary(res))
+	}
+
+	// md5sum 格式, 只比较 md5
+	md5sum := md5Hex("one") + "  ./1.txt\n" +
+		"# comment\n\n" +
+		strings.ToUpper(md5Hex("2")) + " *sub\\2.txt\n" +
+		md5Hex("three") + "  3.txt\n"
+	filename = filepath.Join(t.TempDir(), "p.md5")
+	err = os.WriteFile(filename, []byte(md5sum), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err = LoadManifest(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := summary(Compare(tree, m.Tree()))
+	expected := []string{"only-b 3.txt", "mismatch sub/2.txt (md5 不同)"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	// 清单只能使用 md5
+	err = os.WriteFile(filename, []byte(sha256Hex("one")+"  1.txt\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadManifest(filename); err == nil {
+		t.Error("sha256 manifest: expected error")
+	}
+}
+
+func TestCompareUnreliableMD5(t *testing.T) {
+	fdl := baidupcs.FileDirectoryList{
+		{Path: "/a/same.bin", Size: 10, MD5: "aa", Mtime: 100, BlockListJSON: baidupcs.BlockListJSON{BlockList: []string{"1", "2"}}},
+		{Path: "/a/unknown.bin", Size: 10, MD5: "bb", Mtime: 100, BlockListJSON: baidupcs.BlockListJSON{BlockList: []string{"1", "2"}}},
+		{Path: "/a/old.bin", Size: 10, MD5: "cc", BlockListJSON: baidupcs.BlockListJSON{BlockList: []string{"1", "2"}}},
+		{Path: "/b/same.bin", Size: 10, MD5: "dd", Mtime: 100},
+		{Path: "/b/unknown.bin", Size: 10, MD5: "bb", Mtime: 200},
+		{Path: "/b/new.bin", Size: 10, MD5: "cc"},
+	}
+	a, b := NewTree("/a", fdl), NewTree("/b", fdl)
+	if len(a["same.bin"].BlockList) != 2 {
+		t.Fatalf("block list not kept: %v", a["same.bin"])
+	}
+
+	res := Compare(a, b)
+	got := summary(res)
+	expected := []string{"only-b new.bin", "only-a old.bin", "unknown unknown.bin (md5 可能不正确, 无法确定内容是否相同)"}
+	if !reflect.DeepEqual(got, expected) || res.Same != 1 {
+		t.Errorf("got %v, same %d, expected %v", got, res.Same, expected)
+	}
+}
+
+func TestCompareMissingMD5(t *testing.T) {
+	a := Tree{"1.txt": {Path: "1.txt", Size: 3}}
+	b := Tree{"1.txt": {Path: "1.txt", Size: 3, MD5: md5Hex("one")}}
+
+	res := Compare(a, b)
+	got := summary(res)
+	expected := []string{"unknown 1.txt (缺少 md5, 无法确定内容是否相同)"}
+	if !reflect.DeepEqual(got, expected) || res.Same != 0 {
+		t.Errorf("got %v, same %d, expected %v", got, res.Same, expected)
+	}
+}
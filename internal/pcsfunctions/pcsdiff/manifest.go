@@ -0,0 +1,82 @@
+package pcsdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+)
+
+type (
+	// Manifest 保存的目录清单
+	Manifest struct {
+		Root  string    `json:"root"`
+		Time  time.Time `json:"time"`
+		Files []*Entry  `json:"files"`
+	}
+)
+
+// NewManifest 从目录树生成清单
+func NewManifest(root string, t Tree) *Manifest {
+	return &Manifest{
+		Root:  root,
+		Time:  time.Now(),
+		Files: t.Files(),
+	}
+}
+
+// Save 保存清单到 filename
+func (m *Manifest) Save(filename string) error {
+	data, err := json.MarshalIndent(m, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// Tree 返回清单中的目录树
+func (m *Manifest) Tree() Tree {
+	t := make(Tree, len(m.Files))
+	for _, e := range m.Files {
+		t[e.Path] = e
+	}
+	return t
+}
+
+// LoadManifest 读取清单, 支持 Save 保存的 json 格式和 md5sum 格式 (包括 BSD 风格), 格式同 ParseSumFile.
+// md5sum 格式中没有文件大小, 只比较 md5.
+func LoadManifest(filename string) (*Manifest, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		m := &Manifest{}
+		err = json.Unmarshal(trimmed, m)
+		if err != nil {
+			return nil, fmt.Errorf("解析清单 %s 失败, %s", filename, err)
+		}
+		return m, nil
+	}
+
+	entries, err := ParseSumFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析清单 %s 失败, %s", filename, err)
+	}
+	files := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		if e.Algorithm != checksum.AlgorithmMD5 {
+			return nil, fmt.Errorf("解析清单 %s 失败, %s 不是 md5: %s", filename, e.Path, e.Algorithm)
+		}
+		files = append(files, &Entry{
+			Path: e.Path,
+			Size: e.Size,
+			MD5:  e.Sum,
+		})
+	}
+	return &Manifest{Files: files}, nil
+}
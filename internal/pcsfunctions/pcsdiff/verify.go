@@ -0,0 +1,301 @@
+package pcsdiff
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+)
+
+type (
+	// SumEntry 校验文件中的一项
+	SumEntry struct {
+		Path      string             // 相对路径, 不以 / 开头
+		Algorithm checksum.Algorithm // 摘要算法
+		Sum       string             // 摘要, 小写十六进制
+		Size      int64              // 文件大小, 未知为 -1
+	}
+
+	// SumFunc 计算相对路径为 rel 的文件的摘要, 按 algos 的顺序返回
+	SumFunc func(rel string, algos []checksum.Algorithm) (sums []string, err error)
+
+	// VerifyMismatch 内容不一致的文件
+	VerifyMismatch struct {
+		Path   string `json:"path"`
+		Reason string `json:"reason"`
+	}
+
+	// VerifyFailure 校验失败的文件
+	VerifyFailure struct {
+		Path string `json:"path"`
+		Err  string `json:"error"`
+	}
+
+	// VerifyResult 校验结果
+	VerifyResult struct {
+		OK       int               `json:"ok"`       // 一致的文件数
+		Missing  []string          `json:"missing"`  // 在清单中, 不在目录中
+		Extra    []string          `json:"extra"`    // 在目录中, 不在清单中
+		Mismatch []*VerifyMismatch `json:"mismatch"` // 内容不一致
+		Failed   []*VerifyFailure  `json:"failed"`   // 读取或计算摘要失败
+	}
+)
+
+// hexLenAlgorithms 按摘要长度推断 md5sum, sha1sum, sha256sum 格式的算法
+var hexLenAlgorithms = map[int]checksum.Algorithm{
+	8:  checksum.AlgorithmCRC32,
+	32: checksum.AlgorithmMD5,
+	40: checksum.AlgorithmSHA1,
+	64: checksum.AlgorithmSHA256,
+}
+
+// cleanRelPath 规范化清单中的路径, Windows 生成的清单使用反斜杠
+func cleanRelPath(name string) string {
+	name = strings.Replace(name, `\`, "/", -1)
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// ParseSumFile 解析 md5sum, sha1sum, sha256sum 格式的校验文件, 按摘要长度判断算法;
+// 也支持 BSD 风格 (--tag) 的 "SHA256 (路径) = 摘要", 忽略空行和 # 开头的行
+func ParseSumFile(r io.Reader) (entries []*SumEntry, err error) {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var (
+			algo      checksum.Algorithm
+			sum, name string
+		)
+		if i, j := strings.Index(line, " ("), strings.LastIndex(line, ") = "); i > 0 && j > i {
+			algos, err := checksum.ParseAlgorithms(line[:i])
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 行: %s", n, err)
+			}
+			algo, name, sum = algos[0], line[i+2:j], line[j+4:]
+		} else {
+			i := strings.IndexByte(line, ' ')
+			if i < 0 || i+2 > len(line) || (line[i+1] != ' ' && line[i+1] != '*') {
+				return nil, fmt.Errorf("第 %d 行格式错误", n)
+			}
+			sum, name = line[:i], line[i+2:]
+			algo = hexLenAlgorithms[len(sum)]
+			if algo == "" {
+				return nil, fmt.Errorf("第 %d 行无法识别摘要算法: %s", n, sum)
+			}
+		}
+		if _, err := hex.DecodeString(sum); err != nil || hexLenAlgorithms[len(sum)] != algo {
+			return nil, fmt.Errorf("第 %d 行不是 %s: %s", n, algo, sum)
+		}
+
+		entries = append(entries, &SumEntry{
+			Path:      cleanRelPath(name),
+			Algorithm: algo,
+			Sum:       strings.ToLower(sum),
+			Size:      -1,
+		})
+	}
+	return entries, scanner.Err()
+}
+
+// LoadSumFile 读取校验文件, 支持 ParseSumFile 的格式和 Manifest.Save 保存的 json 清单
+func LoadSumFile(filename string) ([]*SumEntry, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		m, err := LoadManifest(filename)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]*SumEntry, 0, len(m.Files))
+		for _, e := range m.Files {
+			if e.Isdir {
+				continue
+			}
+			entry := &SumEntry{
+				Path:      e.Path,
+				Algorithm: checksum.AlgorithmMD5,
+				Size:      e.Size,
+			}
+			// md5 可能不正确时只比较大小
+			if e.MD5Reliable() {
+				entry.Sum = e.MD5
+			}
+			entries = append(entries, entry)
+		}
+		return entries, nil
+	}
+
+	entries, err := ParseSumFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析校验文件 %s 失败, %s", filename, err)
+	}
+	return entries, nil
+}
+
+// WalkLocal 递归列出本地目录 root, 返回的目录树中没有 md5
+func WalkLocal(root string) (Tree, error) {
+	t := Tree{}
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		t[rel] = &Entry{
+			Path:  rel,
+			Size:  info.Size(),
+			Isdir: info.IsDir(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Verify 使用 parallel 个协程校验目录树 t 中的文件与清单 entries 是否一致.
+// 清单中的文件大小已知时先比较大小; md5 与目录树中可信的 md5 比较, 其他情况调用 sum 计算
+func Verify(entries []*SumEntry, t Tree, sum SumFunc, parallel int) *VerifyResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	var (
+		res    = &VerifyResult{}
+		byPath = map[string][]*SumEntry{}
+		paths  []string
+	)
+	for _, e := range entries {
+		if _, ok := byPath[e.Path]; !ok {
+			paths = append(paths, e.Path)
+		}
+		byPath[e.Path] = append(byPath[e.Path], e)
+	}
+	sort.Strings(paths)
+	for _, f := range t.Files() {
+		if _, ok := byPath[f.Path]; !ok {
+			res.Extra = append(res.Extra, f.Path)
+		}
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		jobs = make(chan string)
+	)
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				reason, err := verifyFile(byPath[p], t[p], sum)
+				mu.Lock()
+				switch {
+				case err != nil:
+					res.Failed = append(res.Failed, &VerifyFailure{Path: p, Err: err.Error()})
+				case reason != "":
+					res.Mismatch = append(res.Mismatch, &VerifyMismatch{Path: p, Reason: reason})
+				default:
+					res.OK++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, p := range paths {
+		if f, ok := t[p]; !ok || f.Isdir {
+			res.Missing = append(res.Missing, p)
+			continue
+		}
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(res.Mismatch, func(i, j int) bool {
+		return res.Mismatch[i].Path < res.Mismatch[j].Path
+	})
+	sort.Slice(res.Failed, func(i, j int) bool {
+		return res.Failed[i].Path < res.Failed[j].Path
+	})
+	return res
+}
+
+// verifyFile 校验一个文件, 不一致时返回原因
+func verifyFile(entries []*SumEntry, f *Entry, sum SumFunc) (reason string, err error) {
+	var (
+		algos    []checksum.Algorithm
+		expected []string
+	)
+	for _, e := range entries {
+		if e.Size >= 0 && f.Size >= 0 && e.Size != f.Size {
+			return "大小不同", nil
+		}
+		if e.Sum == "" {
+			continue
+		}
+		if e.Algorithm == checksum.AlgorithmMD5 && f.MD5Reliable() {
+			if e.Sum != f.MD5 {
+				return "md5 不同", nil
+			}
+			continue
+		}
+		algos = append(algos, e.Algorithm)
+		expected = append(expected, e.Sum)
+	}
+	if len(algos) == 0 {
+		return "", nil
+	}
+
+	sums, err := sum(f.Path, algos)
+	if err != nil {
+		return "", err
+	}
+	for i, algo := range algos {
+		if sums[i] != expected[i] {
+			return string(algo) + " 不同", nil
+		}
+	}
+	return "", nil
+}
+
+// LocalSumFunc 返回计算本地目录 root 中文件摘要的 SumFunc
+func LocalSumFunc(root string) SumFunc {
+	return func(rel string, algos []checksum.Algorithm) (sums []string, err error) {
+		flag := 0
+		for _, algo := range algos {
+			flag |= algo.Flag()
+		}
+		lfc, err := checksum.GetFileSum(filepath.Join(root, filepath.FromSlash(rel)), flag)
+		if err != nil {
+			return nil, err
+		}
+		sums = make([]string, 0, len(algos))
+		for _, algo := range algos {
+			sums = append(sums, lfc.HexSum(algo))
+		}
+		return sums, nil
+	}
+}
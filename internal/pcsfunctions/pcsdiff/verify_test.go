@@ -0,0 +1,156 @@
+package pcsdiff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseSumFile(t *testing.T) {
+	data := sha256Hex("one") + "  ./1.txt\r\n" +
+		"# comment\n\n" +
+		strings.ToUpper(md5Hex("two")) + " *sub\\2.txt\n" +
+		"SHA1 (a (1).txt) = " + strings.Repeat("ab", 20) + "\n" +
+		"CRC32 (c.txt) = 0000abcd\n"
+	entries, err := ParseSumFile(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*SumEntry{
+		{Path: "1.txt", Algorithm: checksum.AlgorithmSHA256, Sum: sha256Hex("one"), Size: -1},
+		{Path: "sub/2.txt", Algorithm: checksum.AlgorithmMD5, Sum: md5Hex("two"), Size: -1},
+		{Path: "a (1).txt", Algorithm: checksum.AlgorithmSHA1, Sum: strings.Repeat("ab", 20), Size: -1},
+		{Path: "c.txt", Algorithm: checksum.AlgorithmCRC32, Sum: "0000abcd", Size: -1},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		for _, e := range entries {
+			t.Logf("%+v", e)
+		}
+		t.Fatal("unexpected entries")
+	}
+
+	for _, bad := range []string{
+		"xyz  a.txt\n",
+		"abcd  a.txt\n",
+		md5Hex("a") + "\ta.txt\n",
+		"SHA512 (a.txt) = " + md5Hex("a") + "\n",
+		"SHA256 (a.txt) = " + md5Hex("a") + "\n",
+	} {
+		if _, err = ParseSumFile(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"1.txt":     "one",
+		"sub/2.txt": "two",
+		"3.txt":     "three",
+		"extra.txt": "extra",
+	}
+	for p, data := range files {
+		filename := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := []*SumEntry{
+		{Path: "1.txt", Algorithm: checksum.AlgorithmSHA256, Sum: sha256Hex("one"), Size: -1},
+		{Path: "1.txt", Algorithm: checksum.AlgorithmMD5, Sum: md5Hex("one"), Size: -1},
+		{Path: "sub/2.txt", Algorithm: checksum.AlgorithmMD5, Sum: md5Hex("2"), Size: -1},
+		{Path: "3.txt", Algorithm: checksum.AlgorithmMD5, Sum: md5Hex("three"), Size: 4},
+		{Path: "sub", Algorithm: checksum.AlgorithmMD5, Sum: md5Hex("sub"), Size: -1},
+		{Path: "missing.txt", Algorithm: checksum.AlgorithmMD5, Sum: md5Hex("missing"), Size: -1},
+	}
+	tree, err := WalkLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := Verify(entries, tree, LocalSumFunc(root), 3)
+	if res.OK != 1 {
+		t.Errorf("ok: %d", res.OK)
+	}
+	if !reflect.DeepEqual(res.Missing, []string{"missing.txt", "sub"}) {
+		t.Errorf("missing: %v", res.Missing)
+	}
+	if !reflect.DeepEqual(res.Extra, []string{"extra.txt"}) {
+		t.Errorf("extra: %v", res.Extra)
+	}
+	expected := []*VerifyMismatch{
+		{Path: "3.txt", Reason: "大小不同"},
+		{Path: "sub/2.txt", Reason: "md5 不同"},
+	}
+	if !reflect.DeepEqual(res.Mismatch, expected) {
+		t.Errorf("mismatch: %v", res.Mismatch)
+	}
+	if len(res.Failed) != 0 {
+		t.Errorf("failed: %v", res.Failed)
+	}
+
+	// 网盘目录使用列表中的 md5, 不需要计算
+	s := storage.NewMemory()
+	writeFiles(t, s, map[string]string{
+		"/p/1.txt":     "one",
+		"/p/sub/2.txt": "two",
+	})
+	remote, pcsError := Walk(s, "/p")
+	if pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	var computed []string
+	res = Verify(entries[:3], remote, func(rel string, algos []checksum.Algorithm) ([]string, error) {
+		computed = append(computed, rel)
+		return []string{sha256Hex("one")}, nil
+	}, 1)
+	if res.OK != 1 || len(res.Mismatch) != 1 || res.Mismatch[0].Path != "sub/2.txt" {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if !reflect.DeepEqual(computed, []string{"1.txt"}) {
+		t.Errorf("computed: %v", computed)
+	}
+}
+
+func TestVerifyUnreliableMD5(t *testing.T) {
+	var computed []string
+	sum := func(rel string, algos []checksum.Algorithm) ([]string, error) {
+		computed = append(computed, rel)
+		return []string{md5Hex(rel)}, nil
+	}
+	tree := Tree{
+		"ok.txt":      {Path: "ok.txt", Size: 2, MD5: md5Hex("ok.txt")},
+		"multi.txt":   {Path: "multi.txt", Size: 2, MD5: "bad", BlockList: []string{"1", "2"}},
+		"changed.txt": {Path: "changed.txt", Size: 2, MD5: "bad", BlockList: []string{"1", "2"}},
+	}
+	entries := []*SumEntry{
+		{Path: "ok.txt", Algorithm: checksum.AlgorithmMD5, Sum: md5Hex("ok.txt"), Size: -1},
+		{Path: "multi.txt", Algorithm: checksum.AlgorithmMD5, Sum: md5Hex("multi.txt"), Size: -1},
+		{Path: "changed.txt", Algorithm: checksum.AlgorithmMD5, Sum: md5Hex("other"), Size: -1},
+	}
+
+	// 服务器记录的 md5 可能不正确时下载文件计算
+	res := Verify(entries, tree, sum, 1)
+	if res.OK != 2 || len(res.Mismatch) != 1 || res.Mismatch[0].Path != "changed.txt" {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if strings.Join(computed, ",") != "changed.txt,multi.txt" {
+		t.Errorf("unexpected computed files %v", computed)
+	}
+}
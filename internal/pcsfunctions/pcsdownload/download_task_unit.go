@@ -33,6 +33,7 @@ type (
 
 		Cfg                *downloader.Config
 		PCS                *baidupcs.BaiduPCS
+		Storage            baidupcs.Storage // 非网盘的存储后端, 设置后直接从该存储读取文件
 		ParentTaskExecutor *taskframework.TaskExecutor
 
 		DownloadStatistic *DownloadStatistic // 下载统计
@@ -71,6 +72,8 @@ const (
 	StrDownloadChecksumFailed = "检测文件有效性失败"
 	// StrDownloadCheckLengthFailed 检测文件大小一致性失败
 	StrDownloadCheckLengthFailed = "检测文件大小一致性失败"
+	// StrDownloadNeedRelogin 登录状态失效
+	StrDownloadNeedRelogin = "登录状态失效, 请重新登录"
 	// DefaultDownloadMaxRetry 默认下载失败最大重试次数
 	DefaultDownloadMaxRetry = 3
 )
@@ -180,21 +183,34 @@ func (dtu *DownloadTaskUnit) download(downloadURL string, client *requester.HTTP
 			status.TimeElapsed()/1e7*1e7, leftStr,
 		)
 
+		// 如果未完成下载, 就输出
+		var statusLine string
 		if !isComplete {
-			// 如果未完成下载, 就输出
-			fmt.Print(builder.String())
+			statusLine = builder.String()
 		}
+		dtu.taskInfo.ReportStatus(status.Downloaded(), status.TotalSize(), statusLine)
 	})
 
 	der.OnExecute(func() {
 		if dtu.Cfg.IsTest {
-			fmt.Printf("[%s] 测试下载开始\n\n", dtu.taskInfo.Id())
+			dtu.taskInfo.Printf("[%s] 测试下载开始\n\n", dtu.taskInfo.Id())
 		}
 	})
 
+	// 任务被取消时停止下载
+	stopWatch := make(chan struct{})
+	go func() {
+		select {
+		case <-dtu.taskInfo.Context().Done():
+			der.Cancel()
+		case <-stopWatch:
+		}
+	}()
+
 	err = der.Execute()
+	close(stopWatch)
 	isComplete = true
-	fmt.Print("\n")
+	dtu.taskInfo.Printf("\n")
 
 	if err != nil {
 		// 下载发生错误
@@ -219,13 +235,13 @@ func (dtu *DownloadTaskUnit) download(downloadURL string, client *requester.HTTP
 		if dtu.IsExecutedPermission {
 			err = file.Chmod(0766)
 			if err != nil {
-				fmt.Printf("[%s] 警告, 加执行权限错误: %s\n", dtu.taskInfo.Id(), err)
+				dtu.taskInfo.Printf("[%s] 警告, 加执行权限错误: %s\n", dtu.taskInfo.Id(), err)
 			}
 		}
 
-		fmt.Printf("[%s] 下载完成, 保存位置: %s\n", dtu.taskInfo.Id(), dtu.SavePath)
+		dtu.taskInfo.Printf("[%s] 下载完成, 保存位置: %s\n", dtu.taskInfo.Id(), dtu.SavePath)
 	} else {
-		fmt.Printf("[%s] 测试下载结束\n", dtu.taskInfo.Id())
+		dtu.taskInfo.Printf("[%s] 测试下载结束\n", dtu.taskInfo.Id())
 	}
 
 	return nil
@@ -252,31 +268,11 @@ func (dtu *DownloadTaskUnit) panHTTPClient() *requester.HTTPClient {
 }
 
 func (dtu *DownloadTaskUnit) handleError(result *taskframework.TaskUnitRunResult) {
-	switch value := result.Err.(type) {
-	case pcserror.Error: // pcserror 接口
-		switch value.GetErrType() {
-		case pcserror.ErrTypeRemoteError:
-		// 远程服务器错误
-		case 31045: // user not exists
-			fallthrough
-		case 31066: // file does not exist
-			result.NeedRetry = false
-		case 31297: // file does not exist
-			result.NeedRetry = false
-		case 31626: // user is not authorized
-			//可能是User-Agent不对
-			//重试
-			fallthrough
-		default:
-			result.NeedRetry = true
-		}
-	case *os.PathError:
-		// 系统级别的错误, 可能是权限问题
-		result.NeedRetry = false
-	default:
-		// 其他错误, 需要重试
-		result.NeedRetry = true
+	// 按错误分类决定是否重试, 31626 user is not authorized 可能是User-Agent不对, 会重试
+	if pcserror.Classify(result.Err) == pcserror.ErrClassNeedRelogin {
+		result.ResultMessage += ", " + StrDownloadNeedRelogin
 	}
+	result.NeedRetry = pcsfunctions.NeedRetry(dtu.taskInfo, result.Err)
 }
 
 func (dtu *DownloadTaskUnit) execPanDownload(dlink string, result *taskframework.TaskUnitRunResult, okPtr *bool) {
@@ -351,6 +347,55 @@ func (dtu *DownloadTaskUnit) pcsOrStreamingDownload(mode DownloadMode, result *t
 	return true // 下载成功
 }
 
+// storage 返回文件所在的存储
+func (dtu *DownloadTaskUnit) storage() baidupcs.Storage {
+	if dtu.Storage != nil {
+		return dtu.Storage
+	}
+	return dtu.PCS
+}
+
+// storageDownload 从存储后端直接读取文件
+func (dtu *DownloadTaskUnit) storageDownload(result *taskframework.TaskUnitRunResult) (ok bool) {
+	rc, pcsError := dtu.Storage.OpenRange(dtu.PcsPath, 0, -1)
+	if pcsError != nil {
+		result.ResultMessage = StrDownloadFailed
+		result.Err = pcsError
+		dtu.handleError(result)
+		return
+	}
+	defer rc.Close()
+
+	var w io.Writer = io.Discard
+	if !dtu.Cfg.IsTest {
+		err := os.MkdirAll(filepath.Dir(dtu.SavePath), 0777)
+		if err != nil {
+			result.ResultMessage = StrDownloadInitError
+			result.Err = err
+			return
+		}
+		file, err := os.Create(dtu.SavePath)
+		if err != nil {
+			result.ResultMessage = StrDownloadInitError
+			result.Err = err
+			return
+		}
+		defer file.Close()
+		w = file
+	}
+
+	n, err := io.Copy(w, rc)
+	dtu.taskInfo.ReportProgress(n, dtu.FileInfo.Size)
+	if err != nil {
+		result.ResultMessage = StrDownloadFailed
+		result.Err = err
+		result.NeedRetry = true
+		return
+	}
+	dtu.taskInfo.Printf("[%s] 下载完成, 数据总量: %s\n", dtu.taskInfo.Id(), converter.ConvertFileSize(n, 2))
+	return true
+}
+
 // checkFileValid 检测文件有效性
 func (dtu *DownloadTaskUnit) checkFileValid(result *taskframework.TaskUnitRunResult) (ok bool) {
 	fi, err := os.Stat(dtu.SavePath)
@@ -364,13 +409,13 @@ func (dtu *DownloadTaskUnit) checkFileValid(result *taskframework.TaskUnitRunRes
 	}
 	if dtu.Cfg.IsTest || dtu.NoCheck {
 		// 不检测文件有效性
-		fmt.Printf("[%s] 跳过文件有效性检验\n", dtu.taskInfo.Id())
+		dtu.taskInfo.Printf("[%s] 跳过文件有效性检验\n", dtu.taskInfo.Id())
 		return true
 	}
 
 	if dtu.FileInfo.Size >= 128*converter.MB {
 		// 大文件, 输出一句提示消息
-		fmt.Printf("[%s] 开始检验文件有效性, 请稍候...\n", dtu.taskInfo.Id())
+		dtu.taskInfo.Printf("[%s] 开始检验文件有效性, 请稍候...\n", dtu.taskInfo.Id())
 	}
 
 	// 就在这里处理校验出错
@@ -383,7 +428,7 @@ func (dtu *DownloadTaskUnit) checkFileValid(result *taskframework.TaskUnitRunRes
 			// 文件不支持校验
 			result.ResultMessage = "检验文件有效性"
 			result.Err = err
-			fmt.Printf("[%s] 检验文件有效性: %s\n", dtu.taskInfo.Id(), err)
+			dtu.taskInfo.Printf("[%s] 检验文件有效性: %s\n", dtu.taskInfo.Id(), err)
 			return true
 		case ErrDownloadFileBanned:
 			// 违规文件
@@ -401,7 +446,7 @@ func (dtu *DownloadTaskUnit) checkFileValid(result *taskframework.TaskUnitRunRes
 		}
 	}
 
-	fmt.Printf("[%s] 检验文件有效性成功: %s\n", dtu.taskInfo.Id(), dtu.SavePath)
+	dtu.taskInfo.Printf("[%s] 检验文件有效性成功: %s\n", dtu.taskInfo.Id(), dtu.SavePath)
 	return true
 }
 
@@ -409,10 +454,10 @@ func (dtu *DownloadTaskUnit) OnRetry(lastRunResult *taskframework.TaskUnitRunRes
 	// 输出错误信息
 	if lastRunResult.Err == nil {
 		// result中不包含Err, 忽略输出
-		fmt.Printf("[%s] %s, 重试 %d/%d\n", dtu.taskInfo.Id(), lastRunResult.ResultMessage, dtu.taskInfo.Retry(), dtu.taskInfo.MaxRetry())
+		dtu.taskInfo.Printf("[%s] %s, 重试 %d/%d\n", dtu.taskInfo.Id(), lastRunResult.ResultMessage, dtu.taskInfo.Retry(), dtu.taskInfo.MaxRetry())
 		return
 	}
-	fmt.Printf("[%s] %s, %s, 重试 %d/%d\n", dtu.taskInfo.Id(), lastRunResult.ResultMessage, lastRunResult.Err, dtu.taskInfo.Retry(), dtu.taskInfo.MaxRetry())
+	dtu.taskInfo.Printf("[%s] %s, %s, 重试 %d/%d\n", dtu.taskInfo.Id(), lastRunResult.ResultMessage, lastRunResult.Err, dtu.taskInfo.Retry(), dtu.taskInfo.MaxRetry())
 }
 
 func (dtu *DownloadTaskUnit) OnSuccess(lastRunResult *taskframework.TaskUnitRunResult) {
@@ -422,17 +467,17 @@ func (dtu *DownloadTaskUnit) OnFailed(lastRunResult *taskframework.TaskUnitRunRe
 	// 失败
 	if lastRunResult.Err == nil {
 		// result中不包含Err, 忽略输出
-		fmt.Printf("[%s] %s\n", dtu.taskInfo.Id(), lastRunResult.ResultMessage)
+		dtu.taskInfo.Printf("[%s] %s\n", dtu.taskInfo.Id(), lastRunResult.ResultMessage)
 		return
 	}
-	fmt.Printf("[%s] %s, %s\n", dtu.taskInfo.Id(), lastRunResult.ResultMessage, lastRunResult.Err)
+	dtu.taskInfo.Printf("[%s] %s, %s\n", dtu.taskInfo.Id(), lastRunResult.ResultMessage, lastRunResult.Err)
 }
 
 func (dtu *DownloadTaskUnit) OnComplete(lastRunResult *taskframework.TaskUnitRunResult) {
 }
 
 func (dtu *DownloadTaskUnit) RetryWait() time.Duration {
-	return pcsfunctions.RetryWait(dtu.taskInfo.Retry())
+	return pcsfunctions.RetryWait(dtu.taskInfo)
 }
 
 func (dtu *DownloadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
@@ -443,7 +488,7 @@ func (dtu *DownloadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
 		// 没有获取文件信息
 		// 如果是动态添加的下载任务, 是会写入文件信息的
 		// 如果该任务重试过, 则应该再获取一次文件信息
-		dtu.FileInfo, err = dtu.PCS.FilesDirectoriesMeta(dtu.PcsPath)
+		dtu.FileInfo, err = dtu.storage().FilesDirectoriesMeta(dtu.PcsPath)
 		if err != nil {
 			// 如果不是未登录或文件不存在, 则不重试
 			result.ResultMessage = "获取下载路径信息错误"
@@ -454,8 +499,8 @@ func (dtu *DownloadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
 	}
 
 	// 输出文件信息
-	fmt.Print("\n")
-	fmt.Printf("[%s] ----\n%s\n", dtu.taskInfo.Id(), dtu.FileInfo.String())
+	dtu.taskInfo.Printf("\n")
+	dtu.taskInfo.Printf("[%s] ----\n%s\n", dtu.taskInfo.Id(), dtu.FileInfo.String())
 
 	// 如果是一个目录, 将子文件和子目录加入队列
 	if dtu.FileInfo.Isdir {
@@ -498,25 +543,27 @@ func (dtu *DownloadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
 		return
 	}
 
-	fmt.Printf("[%s] 准备下载: %s\n", dtu.taskInfo.Id(), dtu.PcsPath)
+	dtu.taskInfo.Printf("[%s] 准备下载: %s\n", dtu.taskInfo.Id(), dtu.PcsPath)
 
 	if !dtu.Cfg.IsTest && !dtu.IsOverwrite && FileExist(dtu.SavePath) {
-		fmt.Printf("[%s] 文件已经存在: %s, 跳过...\n", dtu.taskInfo.Id(), dtu.SavePath)
+		dtu.taskInfo.Printf("[%s] 文件已经存在: %s, 跳过...\n", dtu.taskInfo.Id(), dtu.SavePath)
 		result.Succeed = true // 执行成功
 		return
 	}
 
 	if !dtu.Cfg.IsTest {
 		// 不是测试下载, 输出下载路径
-		fmt.Printf("[%s] 将会下载到路径: %s\n\n", dtu.taskInfo.Id(), dtu.SavePath)
+		dtu.taskInfo.Printf("[%s] 将会下载到路径: %s\n\n", dtu.taskInfo.Id(), dtu.SavePath)
 	}
 
 	var ok bool
 	// 获取下载链接
-	switch dtu.DownloadMode {
-	case DownloadModeLocate:
+	switch {
+	case dtu.Storage != nil:
+		ok = dtu.storageDownload(result)
+	case dtu.DownloadMode == DownloadModeLocate:
 		ok = dtu.locateDownload(result)
-	case DownloadModePCS, DownloadModeStreaming:
+	case dtu.DownloadMode == DownloadModePCS, dtu.DownloadMode == DownloadModeStreaming:
 		ok = dtu.pcsOrStreamingDownload(dtu.DownloadMode, result)
 	}
 
@@ -0,0 +1,98 @@
+package pcsdownload_test
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcstest"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/downloader"
+)
+
+func newDownloadUnit(pcs *baidupcs.BaiduPCS, pcspath, savePath string, maxRate int64) *pcsdownload.DownloadTaskUnit {
+	cfg := downloader.NewConfig()
+	cfg.MaxParallel = 2
+	cfg.MaxRate = maxRate
+	cfg.InstanceStateStorageFormat = downloader.InstanceStateStorageFormatProto3
+	return &pcsdownload.DownloadTaskUnit{
+		Cfg:               cfg,
+		PCS:               pcs,
+		DownloadStatistic: &pcsdownload.DownloadStatistic{},
+		DownloadMode:      pcsdownload.DownloadModePCS,
+		PcsPath:           pcspath,
+		SavePath:          savePath,
+	}
+}
+
+func TestE2EDownloadTaskUnit(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	data := make([]byte, 512*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	srv.WriteFile("/data.bin", data)
+	savePath := filepath.Join(t.TempDir(), "data.bin")
+
+	// 限速下载, 下载一部分后取消
+	executor := &taskframework.TaskExecutor{}
+	executor.Subscribe(taskframework.ObserverFunc(func(e *taskframework.Event) {
+		if e.Type == taskframework.EventProgress && e.Done > 0 && e.Done < e.Total {
+			executor.CancelAll()
+		}
+	}))
+	info := executor.Append(newDownloadUnit(pcs, "/data.bin", savePath, 128*1024), 0)
+	executor.Execute()
+	if info.State() != taskframework.TaskStateCanceled {
+		t.Fatalf("canceled download: state %d", info.State())
+	}
+	downloaded := pcsdownload.DownloadedSize(savePath)
+	if downloaded <= 0 || downloaded >= int64(len(data)) {
+		t.Fatalf("canceled download: downloaded %d", downloaded)
+	}
+
+	// 从断点继续下载, 下载完成后检验文件有效性
+	resumeExecutor := &taskframework.TaskExecutor{}
+	info = resumeExecutor.Append(newDownloadUnit(pcs, "/data.bin", savePath, 0), 0)
+	resumeExecutor.Execute()
+	if info.State() != taskframework.TaskStateSucceeded {
+		t.Fatalf("resumed download: state %d", info.State())
+	}
+	if got, _ := os.ReadFile(savePath); !bytes.Equal(got, data) {
+		t.Fatalf("resumed download: content mismatch")
+	}
+	if _, err := os.Stat(savePath + pcsdownload.DownloadSuffix); !os.IsNotExist(err) {
+		t.Fatalf("resumed download: state file not removed, %v", err)
+	}
+}
+
+func TestE2EDownloadChecksumFailed(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	srv.WriteFile("/a.txt", []byte("aaa"))
+	fd, pcsError := pcs.FilesDirectoriesMeta("/a.txt")
+	if pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	if len(fd.BlockList) != 1 {
+		t.Fatalf("block list: %v", fd.BlockList)
+	}
+
+	// 网盘记录的 md5 与下载的内容不一致
+	fd.MD5 = "0cc175b9c0f1b6a831c399e269772661"
+	unit := newDownloadUnit(pcs, "/a.txt", filepath.Join(t.TempDir(), "a.txt"), 0)
+	unit.FileInfo = fd
+	executor := &taskframework.TaskExecutor{}
+	info := executor.Append(unit, 0)
+	executor.Execute()
+	if info.State() != taskframework.TaskStateFailed || !unit.IsOverwrite {
+		t.Fatalf("checksum failed: state %d, overwrite %v", info.State(), unit.IsOverwrite)
+	}
+}
@@ -0,0 +1,20 @@
+package pcsdownload
+
+import (
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"path/filepath"
+)
+
+// MapSavePath 按配置的文件名映射规则, 将相对路径 rel 映射后拼接到保存目录 base
+func MapSavePath(base, rel string) (string, error) {
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		absBase = base
+	}
+
+	mapped, err := pcsconfig.Config.FilenameMapper().MapPath(filepath.ToSlash(rel), absBase)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, filepath.FromSlash(mapped)), nil
+}
@@ -0,0 +1,164 @@
+package pcsdownload
+
+import (
+	"errors"
+	"fmt"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// ConflictPolicySkip 保存路径冲突时跳过
+	ConflictPolicySkip = "skip"
+	// ConflictPolicyOverwrite 保存路径冲突时覆盖
+	ConflictPolicyOverwrite = "overwrite"
+	// ConflictPolicySuffix 保存路径冲突时添加序号后缀
+	ConflictPolicySuffix = "suffix"
+
+	// defaultTemplateTimeLayout {mtime} 未指定格式时使用的格式
+	defaultTemplateTimeLayout = "2006-01-02"
+)
+
+var (
+	// ErrSaveTemplateEmpty 保存路径模板为空
+	ErrSaveTemplateEmpty = errors.New("保存路径模板为空")
+)
+
+type (
+	// SaveTemplate 本地保存路径模板
+	//
+	// 支持的占位符:
+	//  {path}     网盘路径, 不含开头的 /
+	//  {dir}      网盘路径所在的目录, 不含开头的 /
+	//  {rel}      相对于下载目标的路径, 与默认的保存路径一致
+	//  {filename} 文件名
+	//  {name}     不含扩展名的文件名
+	//  {ext}      扩展名, 含 .
+	//  {mtime}    修改时间, 可指定格式, 如 {mtime:2006/01}
+	//  {ctime}    创建时间, 可指定格式
+	//  {md5}      文件的 md5
+	//  {account}  当前帐号的用户名
+	SaveTemplate struct {
+		segments []templateSegment
+	}
+
+	templateSegment struct {
+		literal string
+		key     string
+		arg     string
+	}
+
+	// SaveTemplateContext 渲染模板时的附加信息
+	SaveTemplateContext struct {
+		Account string
+	}
+)
+
+// ParseSaveTemplate 解析保存路径模板
+func ParseSaveTemplate(tmpl string) (*SaveTemplate, error) {
+	if tmpl == "" {
+		return nil, ErrSaveTemplateEmpty
+	}
+
+	st := &SaveTemplate{}
+	for len(tmpl) > 0 {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			st.segments = append(st.segments, templateSegment{literal: tmpl})
+			break
+		}
+		if start > 0 {
+			st.segments = append(st.segments, templateSegment{literal: tmpl[:start]})
+		}
+
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("保存路径模板未闭合: %s", tmpl[start:])
+		}
+		end += start
+
+		key, arg := tmpl[start+1:end], ""
+		if i := strings.IndexByte(key, ':'); i >= 0 {
+			key, arg = key[:i], key[i+1:]
+		}
+		switch key {
+		case "path", "dir", "rel", "filename", "name", "ext", "md5", "account":
+			if arg != "" {
+				return nil, fmt.Errorf("占位符 {%s} 不支持参数", key)
+			}
+		case "mtime", "ctime":
+			if arg == "" {
+				arg = defaultTemplateTimeLayout
+			}
+		default:
+			return nil, fmt.Errorf("未知的占位符: {%s}", key)
+		}
+		st.segments = append(st.segments, templateSegment{key: key, arg: arg})
+		tmpl = tmpl[end+1:]
+	}
+	return st, nil
+}
+
+// Render 根据文件信息渲染出本地保存路径(相对路径或绝对路径)
+func (st *SaveTemplate) Render(fd *baidupcs.FileDirectory, ctx *SaveTemplateContext) string {
+	if ctx == nil {
+		ctx = &SaveTemplateContext{}
+	}
+
+	var (
+		builder  strings.Builder
+		filename = path.Base(fd.Path)
+		ext      = path.Ext(filename)
+	)
+	for _, seg := range st.segments {
+		if seg.key == "" {
+			builder.WriteString(seg.literal)
+			continue
+		}
+
+		switch seg.key {
+		case "path":
+			builder.WriteString(strings.TrimPrefix(fd.Path, baidupcs.PathSeparator))
+		case "dir":
+			builder.WriteString(strings.TrimPrefix(path.Dir(fd.Path), baidupcs.PathSeparator))
+		case "rel":
+			builder.WriteString(filepath.ToSlash(filepath.Join(fd.PreBase, filename)))
+		case "filename":
+			builder.WriteString(filename)
+		case "name":
+			builder.WriteString(strings.TrimSuffix(filename, ext))
+		case "ext":
+			builder.WriteString(ext)
+		case "mtime":
+			builder.WriteString(time.Unix(fd.Mtime, 0).Format(seg.arg))
+		case "ctime":
+			builder.WriteString(time.Unix(fd.Ctime, 0).Format(seg.arg))
+		case "md5":
+			builder.WriteString(fd.MD5)
+		case "account":
+			builder.WriteString(converter.TrimPathInvalidChars(ctx.Account))
+		}
+	}
+	return filepath.Clean(filepath.FromSlash(builder.String()))
+}
+
+// ParseConflictPolicy 检查保存路径冲突的处理策略, 为空时使用 skip
+func ParseConflictPolicy(policy string) (string, error) {
+	switch policy {
+	case "":
+		return ConflictPolicySkip, nil
+	case ConflictPolicySkip, ConflictPolicyOverwrite, ConflictPolicySuffix:
+		return policy, nil
+	}
+	return "", fmt.Errorf("未知的冲突处理策略: %s, 可选值: %s, %s, %s", policy, ConflictPolicySkip, ConflictPolicyOverwrite, ConflictPolicySuffix)
+}
+
+// SuffixSavePath 为保存路径添加序号后缀, 如 a.txt => a (1).txt
+func SuffixSavePath(savePath string, n int) string {
+	ext := filepath.Ext(savePath)
+	return fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(savePath, ext), n, ext)
+}
@@ -0,0 +1,70 @@
+package pcsdownload
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+func TestSaveTemplateRender(t *testing.T) {
+	mtime := time.Date(2023, 4, 5, 12, 0, 0, 0, time.Local).Unix()
+	fd := &baidupcs.FileDirectory{
+		Path:    "/我的照片/2023/a.b.jpg",
+		PreBase: "2023",
+		MD5:     "0cc175b9c0f1b6a831c399e269772661",
+		Mtime:   mtime,
+		Ctime:   mtime,
+	}
+	cases := map[string]string{
+		"{path}":                       "我的照片/2023/a.b.jpg",
+		"{dir}/{filename}":             "我的照片/2023/a.b.jpg",
+		"{rel}":                        "2023/a.b.jpg",
+		"{name}-{md5}{ext}":            "a.b-0cc175b9c0f1b6a831c399e269772661.jpg",
+		"{mtime}/{filename}":           "2023-04-05/a.b.jpg",
+		"{mtime:2006/01}/{filename}":   "2023/04/a.b.jpg",
+		"{account}/{ctime:2006}/x":     "ab/2023/x",
+		"static/../{filename}":         "a.b.jpg",
+		"{account}/{mtime:01}/{name}/": "ab/04/a.b",
+	}
+	for tmplStr, expected := range cases {
+		tmpl, err := ParseSaveTemplate(tmplStr)
+		if err != nil {
+			t.Fatalf("%s: %s", tmplStr, err)
+		}
+		got := tmpl.Render(fd, &SaveTemplateContext{Account: "a:b"})
+		if got != filepath.FromSlash(expected) {
+			t.Errorf("%s: got %s, expected %s", tmplStr, got, expected)
+		}
+	}
+
+	for _, bad := range []string{"", "{filename", "{unknown}", "{md5:x}"} {
+		if _, err := ParseSaveTemplate(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	for policy, expected := range map[string]string{
+		"":                      ConflictPolicySkip,
+		ConflictPolicySkip:      ConflictPolicySkip,
+		ConflictPolicyOverwrite: ConflictPolicyOverwrite,
+		ConflictPolicySuffix:    ConflictPolicySuffix,
+	} {
+		got, err := ParseConflictPolicy(policy)
+		if err != nil || got != expected {
+			t.Errorf("%q: got %q, %v", policy, got, err)
+		}
+	}
+	if _, err := ParseConflictPolicy("rename"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestSuffixSavePath(t *testing.T) {
+	if got := SuffixSavePath(filepath.FromSlash("a/b.txt"), 2); got != filepath.FromSlash("a/b (2).txt") {
+		t.Errorf("unexpected path %s", got)
+	}
+}
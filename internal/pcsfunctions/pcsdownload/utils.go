@@ -6,6 +6,7 @@ import (
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/downloader"
 	"golang.org/x/net/publicsuffix"
 	"net/http"
 	"net/http/cookiejar"
@@ -42,6 +43,21 @@ func CheckFileValid(filePath string, fileInfo *baidupcs.FileDirectory) error {
 	return nil
 }
 
+// DownloadedSize 从断点续传信息获取已下载的大小
+func DownloadedSize(savePath string) int64 {
+	f, err := os.Open(savePath + DownloadSuffix)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	eii := downloader.NewInstanceState(f, downloader.InstanceStateStorageFormatProto3).Get()
+	if eii == nil || eii.DownloadStatus == nil {
+		return 0
+	}
+	return eii.DownloadStatus.Downloaded()
+}
+
 // FileExist 检查文件是否存在,
 // 只有当文件存在, 文件大小不为0或断点续传文件不存在时, 才判断为存在
 func FileExist(path string) bool {
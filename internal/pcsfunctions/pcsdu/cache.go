@@ -0,0 +1,27 @@
+package pcsdu
+
+import (
+	"errors"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+var (
+	// ErrCacheNotFound 没有可用的统计缓存
+	ErrCacheNotFound = errors.New("统计缓存不存在或已失效")
+)
+
+// SaveCache 把统计结果保存到 pcs 的磁盘缓存, 统计的目录被修改时缓存失效
+func (r *Result) SaveCache(pcs *baidupcs.BaiduPCS) error {
+	return pcs.StoreDuCache(r.Path, r)
+}
+
+// LoadCache 从 pcs 的磁盘缓存读取目录 dir 的统计结果
+func LoadCache(pcs *baidupcs.BaiduPCS, dir string) (*Result, error) {
+	r := &Result{}
+	if !pcs.LoadDuCache(dir, r) || r.Root == nil {
+		return nil, ErrCacheNotFound
+	}
+	r.Root.link()
+	return r, nil
+}
@@ -0,0 +1,213 @@
+// Package pcsdu 统计网盘目录的空间占用
+package pcsdu
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// Node 文件或目录的空间占用, 目录的统计包括所有子目录
+	Node struct {
+		Name     string
+		Isdir    bool
+		Size     int64 // 总大小
+		Files    int64 // 文件总数
+		Dirs     int64 // 子目录总数
+		Mtime    int64
+		Children []*Node
+
+		parent *Node
+	}
+
+	// Result 统计结果
+	Result struct {
+		Path     string // 统计的目录
+		ScanTime time.Time
+		Root     *Node
+	}
+
+	// SortBy 排序方式
+	SortBy string
+)
+
+const (
+	// SortBySize 按大小排序, 从大到小
+	SortBySize SortBy = "size"
+	// SortByName 按名称排序
+	SortByName SortBy = "name"
+	// SortByCount 按文件数排序, 从多到少
+	SortByCount SortBy = "count"
+	// SortByMtime 按修改时间排序, 从新到旧
+	SortByMtime SortBy = "mtime"
+)
+
+// Scan 递归列出存储 s 中的目录 dir, 统计每个目录的空间占用
+func Scan(s baidupcs.Storage, dir string) (result *Result, err error) {
+	var (
+		root *Node
+		dirs = map[string]*Node{}
+	)
+	baidupcs.StorageRecurseList(s, dir, baidupcs.DefaultOrderOptions, func(depth int, fdPath string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+		if pcsError != nil {
+			err = pcsError
+			return false
+		}
+
+		n := &Node{
+			Name:  fd.Filename,
+			Isdir: fd.Isdir,
+			Size:  fd.Size,
+			Mtime: fd.Mtime,
+		}
+		if depth == 0 {
+			root = n
+			if n.Name == "" {
+				n.Name = fd.Path
+			}
+		} else if parent := dirs[path.Dir(fd.Path)]; parent != nil {
+			parent.Children = append(parent.Children, n)
+			n.parent = parent
+		}
+		if fd.Isdir {
+			dirs[fd.Path] = n
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	root.sum()
+	return &Result{
+		Path:     dir,
+		ScanTime: time.Now(),
+		Root:     root,
+	}, nil
+}
+
+// sum 自底向上统计目录的空间占用
+func (n *Node) sum() {
+	if !n.Isdir {
+		n.Files = 1
+		return
+	}
+	n.Size, n.Files, n.Dirs = 0, 0, 0
+	for _, child := range n.Children {
+		child.sum()
+		n.Size += child.Size
+		n.Files += child.Files
+		n.Dirs += child.Dirs
+		if child.Isdir {
+			n.Dirs++
+		}
+		if child.Mtime > n.Mtime {
+			n.Mtime = child.Mtime
+		}
+	}
+}
+
+// link 设置 parent, 从缓存读取后调用
+func (n *Node) link() {
+	for _, child := range n.Children {
+		child.parent = n
+		child.link()
+	}
+}
+
+// Parent 返回上级目录, 根目录返回 nil
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Path 返回 n 在网盘中的路径, root 为统计的目录
+func (n *Node) Path(root string) string {
+	if n.parent == nil {
+		return root
+	}
+	return path.Join(n.parent.Path(root), n.Name)
+}
+
+// Sort 排序子文件和子目录
+func (n *Node) Sort(by SortBy) {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		switch by {
+		case SortByName:
+			return a.Name < b.Name
+		case SortByCount:
+			return a.Files > b.Files
+		case SortByMtime:
+			return a.Mtime > b.Mtime
+		}
+		return a.Size > b.Size
+	})
+}
+
+// Remove 从统计中移除 n, 并更新上级目录的统计
+func (n *Node) Remove() {
+	parent := n.parent
+	if parent == nil {
+		return
+	}
+	for k := range parent.Children {
+		if parent.Children[k] == n {
+			parent.Children = append(parent.Children[:k], parent.Children[k+1:]...)
+			break
+		}
+	}
+	dirs := n.Dirs
+	if n.Isdir {
+		dirs++
+	}
+	for p := parent; p != nil; p = p.parent {
+		p.Size -= n.Size
+		p.Files -= n.Files
+		p.Dirs -= dirs
+	}
+	n.parent = nil
+}
+
+// Walk 遍历 n 及 depth 层以内的子目录, depth 小于 0 时不限制
+func (n *Node) Walk(depth int, fn func(n *Node, depth int)) {
+	n.walk(0, depth, fn)
+}
+
+func (n *Node) walk(cur, depth int, fn func(n *Node, depth int)) {
+	fn(n, cur)
+	if depth >= 0 && cur >= depth {
+		return
+	}
+	for _, child := range n.Children {
+		if child.Isdir {
+			child.walk(cur+1, depth, fn)
+		}
+	}
+}
+
+// Find 按路径查找子文件或子目录, p 为相对于 n 的路径
+func (n *Node) Find(p string) *Node {
+	cur := n
+	for _, name := range strings.Split(strings.Trim(path.Clean("/"+p), "/"), "/") {
+		if name == "" {
+			continue
+		}
+		var next *Node
+		for _, child := range cur.Children {
+			if child.Name == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		cur = next
+	}
+	return cur
+}
@@ -0,0 +1,137 @@
+package pcsdu
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires/cachemap"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcstest"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+func newTestResult(t *testing.T) *Result {
+	s := storage.NewMemory()
+	files := map[string]int{
+		"/a/movie.mp4":      100,
+		"/a/b/song.mp3":     30,
+		"/a/b/notes.txt":    5,
+		"/a/b/c/photo.JPG":  20,
+		"/a/b/c/README":     1,
+		"/a/d/archive.zip":  50,
+		"/other/ignore.txt": 1000,
+	}
+	for p, size := range files {
+		if pcsError := s.WriteFile(p, strings.NewReader(strings.Repeat("x", size)), int64(size)); pcsError != nil {
+			t.Fatal(pcsError)
+		}
+	}
+	if pcsError := s.Mkdir("/a/empty"); pcsError != nil {
+		t.Fatal(pcsError)
+	}
+
+	r, err := Scan(s, "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func checkNode(t *testing.T, n *Node, size, files, dirs int64) {
+	t.Helper()
+	if n == nil {
+		t.Fatal("node not found")
+	}
+	if n.Size != size || n.Files != files || n.Dirs != dirs {
+		t.Errorf("%s: got size %d, files %d, dirs %d, expected %d, %d, %d", n.Name, n.Size, n.Files, n.Dirs, size, files, dirs)
+	}
+}
+
+func TestScan(t *testing.T) {
+	r := newTestResult(t)
+	checkNode(t, r.Root, 206, 6, 4)
+	checkNode(t, r.Root.Find("b"), 56, 4, 1)
+	checkNode(t, r.Root.Find("b/c"), 21, 2, 0)
+	checkNode(t, r.Root.Find("empty"), 0, 0, 0)
+
+	if p := r.Root.Find("b/c/photo.JPG").Path(r.Path); p != "/a/b/c/photo.JPG" {
+		t.Errorf("Path: got %s", p)
+	}
+
+	var walked []string
+	r.Root.Walk(1, func(n *Node, depth int) {
+		walked = append(walked, n.Path(r.Path))
+	})
+	if strings.Join(walked, ",") != "/a,/a/b,/a/d,/a/empty" {
+		t.Errorf("Walk: got %v", walked)
+	}
+
+	r.Root.Sort(SortBySize)
+	if r.Root.Children[0].Name != "movie.mp4" || r.Root.Children[1].Name != "b" {
+		t.Errorf("Sort: unexpected order")
+	}
+
+	groups := r.Root.Groups(GroupByCategory)
+	if groups[0].Name != "视频" || groups[0].Size != 100 || groups[len(groups)-1].Name != "其他" {
+		t.Errorf("Groups: unexpected %+v", groups[0])
+	}
+	groups = r.Root.Groups(GroupByExt)
+	for _, g := range groups {
+		if g.Name == ".jpg" && g.Files != 1 {
+			t.Errorf("Groups: unexpected %+v", g)
+		}
+	}
+
+	r.Root.Find("b/c").Remove()
+	checkNode(t, r.Root, 185, 4, 3)
+	checkNode(t, r.Root.Find("b"), 35, 2, 0)
+}
+
+func TestCache(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	srv.WriteFile("/a/b/c/README", []byte("x"))
+	srv.WriteFile("/a/d/archive.zip", []byte("zip"))
+	srv.WriteFile("/other/ignore.txt", []byte("x"))
+
+	pcs := srv.NewPCS()
+	pcs.SetDiskCache(cachemap.NewDiskCache(t.TempDir(), map[string]time.Duration{
+		baidupcs.OperationDu: time.Hour,
+	}))
+	r, err := Scan(pcs, "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.SaveCache(pcs); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadCache(pcs, "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Path != "/a" || !loaded.ScanTime.Equal(r.ScanTime) {
+		t.Errorf("Load: unexpected %+v", loaded)
+	}
+	checkNode(t, loaded.Root, 4, 2, 3)
+	if p := loaded.Root.Find("b/c/README").Path(loaded.Path); p != "/a/b/c/README" {
+		t.Errorf("Load: parent not linked, got %s", p)
+	}
+
+	// 修改统计范围之外的文件, 缓存仍然有效
+	if pcsError := pcs.Remove("/other/ignore.txt"); pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	if _, err = LoadCache(pcs, "/a"); err != nil {
+		t.Errorf("Load after unrelated remove: %s", err)
+	}
+
+	// 修改子目录中的文件, 缓存失效
+	if pcsError := pcs.Remove("/a/b/c/README"); pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	if _, err = LoadCache(pcs, "/a"); err != ErrCacheNotFound {
+		t.Errorf("Load after remove: expected %s, got %v", ErrCacheNotFound, err)
+	}
+}
@@ -0,0 +1,107 @@
+package pcsdu
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+type (
+	// Group 按扩展名或类型分组的统计
+	Group struct {
+		Name  string
+		Size  int64
+		Files int64
+	}
+
+	// GroupBy 分组方式
+	GroupBy string
+)
+
+const (
+	// GroupByExt 按扩展名分组
+	GroupByExt GroupBy = "ext"
+	// GroupByCategory 按文件类型分组
+	GroupByCategory GroupBy = "category"
+
+	// NoExt 没有扩展名的文件
+	NoExt = "(无扩展名)"
+)
+
+var (
+	categories = map[string][]string{
+		"视频":  {".mp4", ".mkv", ".avi", ".rmvb", ".rm", ".flv", ".mov", ".wmv", ".m4v", ".ts", ".webm", ".mpg", ".mpeg", ".3gp"},
+		"音频":  {".mp3", ".flac", ".wav", ".ape", ".aac", ".m4a", ".ogg", ".wma", ".opus"},
+		"图片":  {".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp", ".heic", ".tif", ".tiff", ".raw", ".svg"},
+		"文档":  {".txt", ".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx", ".md", ".epub", ".mobi", ".csv", ".rtf"},
+		"应用":  {".exe", ".msi", ".apk", ".ipa", ".dmg", ".pkg", ".deb", ".rpm"},
+		"压缩包": {".zip", ".rar", ".7z", ".tar", ".gz", ".bz2", ".xz", ".tgz", ".iso"},
+	}
+
+	extCategory = map[string]string{}
+)
+
+func init() {
+	for category, exts := range categories {
+		for _, ext := range exts {
+			extCategory[ext] = category
+		}
+	}
+}
+
+// Category 根据扩展名返回文件类型
+func Category(name string) string {
+	if category, ok := extCategory[strings.ToLower(path.Ext(name))]; ok {
+		return category
+	}
+	return "其他"
+}
+
+// Ext 返回小写的扩展名
+func Ext(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == "" {
+		return NoExt
+	}
+	return ext
+}
+
+// Groups 统计 n 中所有文件, 按 by 分组, 按大小从大到小排序
+func (n *Node) Groups(by GroupBy) []*Group {
+	key := Ext
+	if by == GroupByCategory {
+		key = Category
+	}
+
+	groups := map[string]*Group{}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n.Isdir {
+			for _, child := range n.Children {
+				walk(child)
+			}
+			return
+		}
+		name := key(n.Name)
+		g := groups[name]
+		if g == nil {
+			g = &Group{Name: name}
+			groups[name] = g
+		}
+		g.Size += n.Size
+		g.Files++
+	}
+	walk(n)
+
+	list := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Size != list[j].Size {
+			return list[i].Size > list[j].Size
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
@@ -0,0 +1,226 @@
+package pcsfind
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+const (
+	day = 24 * time.Hour
+)
+
+type (
+	// Env 匹配时的环境
+	Env struct {
+		Now      time.Time
+		nonEmpty map[string]struct{} // 非空目录
+	}
+
+	// Expr 查找条件
+	Expr interface {
+		Match(fd *baidupcs.FileDirectory, env *Env) bool
+	}
+
+	// cmpOp 数值比较方式, 对应 +N, -N, N
+	cmpOp int
+
+	trueExpr  struct{}
+	andExpr   struct{ left, right Expr }
+	orExpr    struct{ left, right Expr }
+	notExpr   struct{ expr Expr }
+	emptyExpr struct{}
+
+	nameExpr struct {
+		pattern string
+		fold    bool // 忽略大小写
+	}
+
+	regexExpr struct {
+		re *regexp.Regexp
+	}
+
+	sizeExpr struct {
+		op   cmpOp
+		n    int64
+		unit int64
+	}
+
+	mtimeExpr struct {
+		op   cmpOp
+		days int64
+	}
+
+	typeExpr struct {
+		isdir bool
+	}
+
+	md5Expr struct {
+		md5 string
+	}
+)
+
+const (
+	cmpEQ cmpOp = iota
+	cmpGT
+	cmpLT
+)
+
+func (op cmpOp) compare(a, b int64) bool {
+	switch op {
+	case cmpGT:
+		return a > b
+	case cmpLT:
+		return a < b
+	}
+	return a == b
+}
+
+func (trueExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	return true
+}
+
+func (e *andExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	return e.left.Match(fd, env) && e.right.Match(fd, env)
+}
+
+func (e *orExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	return e.left.Match(fd, env) || e.right.Match(fd, env)
+}
+
+func (e *notExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	return !e.expr.Match(fd, env)
+}
+
+// Match 空文件或空目录
+func (emptyExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	if !fd.Isdir {
+		return fd.Size == 0
+	}
+	_, ok := env.nonEmpty[fd.Path]
+	return !ok
+}
+
+func (e *nameExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	pattern, name := e.pattern, fd.Filename
+	if e.fold {
+		pattern, name = strings.ToLower(pattern), strings.ToLower(name)
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// Match 正则表达式匹配完整路径
+func (e *regexExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	return e.re.MatchString(fd.Path)
+}
+
+// Match 按单位向上取整后比较文件大小, +N 和 -N 按字节比较, 目录不匹配
+func (e *sizeExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	if fd.Isdir {
+		return false
+	}
+	if e.op == cmpEQ {
+		return (fd.Size+e.unit-1)/e.unit == e.n
+	}
+	return e.op.compare(fd.Size, e.n*e.unit)
+}
+
+// Match 按修改时间距今的天数 (向下取整) 比较
+func (e *mtimeExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	days := int64(env.Now.Sub(time.Unix(fd.Mtime, 0)) / day)
+	return e.op.compare(days, e.days)
+}
+
+func (e *typeExpr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	return fd.Isdir == e.isdir
+}
+
+func (e *md5Expr) Match(fd *baidupcs.FileDirectory, env *Env) bool {
+	return !fd.Isdir && strings.EqualFold(fd.MD5, e.md5)
+}
+
+// usesEmpty 判断条件中是否含有 -empty, 需要完整的目录列表才能判断目录是否为空
+func usesEmpty(expr Expr) bool {
+	switch e := expr.(type) {
+	case emptyExpr:
+		return true
+	case *andExpr:
+		return usesEmpty(e.left) || usesEmpty(e.right)
+	case *orExpr:
+		return usesEmpty(e.left) || usesEmpty(e.right)
+	case *notExpr:
+		return usesEmpty(e.expr)
+	}
+	return false
+}
+
+// filesOnly 判断条件是否只能匹配文件, 服务器搜索不返回目录
+func filesOnly(expr Expr) bool {
+	switch e := expr.(type) {
+	case *andExpr:
+		return filesOnly(e.left) || filesOnly(e.right)
+	case *typeExpr:
+		return !e.isdir
+	case *sizeExpr, *md5Expr:
+		return true
+	}
+	return false
+}
+
+// searchKeyword 返回可用于服务器搜索预先筛选的关键字.
+// 只有所有匹配结果都必须满足的 -name 条件才能使用, 取其中最长的不含通配符的部分.
+func searchKeyword(expr Expr) string {
+	switch e := expr.(type) {
+	case *andExpr:
+		left, right := searchKeyword(e.left), searchKeyword(e.right)
+		if len(right) > len(left) {
+			return right
+		}
+		return left
+	case *nameExpr:
+		if e.fold {
+			return ""
+		}
+		return longestLiteral(e.pattern)
+	}
+	return ""
+}
+
+// longestLiteral 返回通配符中最长的普通字符部分
+func longestLiteral(pattern string) (longest string) {
+	var (
+		builder   = &strings.Builder{}
+		inBracket bool
+	)
+	flush := func() {
+		if builder.Len() > len(longest) {
+			longest = builder.String()
+		}
+		builder.Reset()
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case inBracket:
+			if c == ']' {
+				inBracket = false
+			}
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			builder.WriteByte(pattern[i])
+		case c == '[':
+			inBracket = true
+			flush()
+		case c == '*' || c == '?':
+			flush()
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	flush()
+	return
+}
@@ -0,0 +1,91 @@
+// Package pcsfind 按条件查找网盘中的文件和目录
+package pcsfind
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// Searcher 支持服务器搜索的存储
+	Searcher interface {
+		Search(targetPath, keyword string, recursive bool) (fdl baidupcs.FileDirectoryList, pcsError pcserror.Error)
+	}
+)
+
+// Find 在存储 s 的目录 dir 中查找匹配 q 的文件和目录, 包括 dir 本身.
+// 条件只能匹配文件且含有 -name 时, 先使用服务器搜索预先筛选, 搜索失败则改为递归列出目录.
+func Find(s baidupcs.Storage, dir string, q *Query) (matched baidupcs.FileDirectoryList, err error) {
+	candidates, err := searchCandidates(s, dir, q)
+	if err == nil && candidates == nil {
+		candidates, err = listCandidates(s, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	env := &Env{
+		Now:      time.Now(),
+		nonEmpty: map[string]struct{}{},
+	}
+	for _, fd := range candidates {
+		env.nonEmpty[path.Dir(fd.Path)] = struct{}{}
+	}
+	for _, fd := range candidates {
+		if q.Expr.Match(fd, env) {
+			matched = append(matched, fd)
+		}
+	}
+	return matched, nil
+}
+
+// searchCandidates 使用服务器搜索获取候选结果, 不能使用时返回 nil
+func searchCandidates(s baidupcs.Storage, dir string, q *Query) (baidupcs.FileDirectoryList, error) {
+	searcher, ok := s.(Searcher)
+	if !ok || q.NoSearch || usesEmpty(q.Expr) || !filesOnly(q.Expr) {
+		return nil, nil
+	}
+	keyword := searchKeyword(q.Expr)
+	if keyword == "" {
+		return nil, nil
+	}
+
+	root, pcsError := s.FilesDirectoriesMeta(dir)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	if !root.Isdir {
+		return baidupcs.FileDirectoryList{root}, nil
+	}
+
+	fdl, pcsError := searcher.Search(dir, keyword, true)
+	if pcsError != nil {
+		return nil, nil
+	}
+
+	candidates := baidupcs.FileDirectoryList{root}
+	for _, fd := range fdl {
+		// 搜索结果可能不在 dir 中
+		if strings.HasPrefix(fd.Path, strings.TrimSuffix(dir, baidupcs.PathSeparator)+baidupcs.PathSeparator) {
+			candidates = append(candidates, fd)
+		}
+	}
+	return candidates, nil
+}
+
+// listCandidates 递归列出目录
+func listCandidates(s baidupcs.Storage, dir string) (candidates baidupcs.FileDirectoryList, err error) {
+	baidupcs.StorageRecurseList(s, dir, baidupcs.DefaultOrderOptions, func(depth int, fdPath string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+		if pcsError != nil {
+			err = pcsError
+			return false
+		}
+		candidates = append(candidates, fd)
+		return true
+	})
+	return
+}
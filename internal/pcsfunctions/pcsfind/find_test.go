@@ -0,0 +1,149 @@
+package pcsfind
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+// searchStorage 记录搜索次数, 搜索结果不含目录
+type searchStorage struct {
+	*storage.Memory
+	searched int
+}
+
+func (s *searchStorage) Search(targetPath, keyword string, recursive bool) (fdl baidupcs.FileDirectoryList, pcsError pcserror.Error) {
+	s.searched++
+	baidupcs.StorageRecurseList(s.Memory, targetPath, nil, func(depth int, fdPath string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+		if fd != nil && !fd.Isdir && strings.Contains(fd.Filename, keyword) {
+			fdl = append(fdl, fd)
+		}
+		return true
+	})
+	return
+}
+
+func newTestStorage(t *testing.T) *searchStorage {
+	s := &searchStorage{Memory: storage.NewMemory()}
+	files := map[string]string{
+		"/a/movie.mp4":      strings.Repeat("x", 2048),
+		"/a/Trailer.MP4":    "xx",
+		"/a/b/notes.txt":    "hello",
+		"/a/b/empty.txt":    "",
+		"/a/b/c/movie2.mp4": "x",
+	}
+	for p, data := range files {
+		if pcsError := s.WriteFile(p, strings.NewReader(data), int64(len(data))); pcsError != nil {
+			t.Fatal(pcsError)
+		}
+	}
+	if pcsError := s.Mkdir("/a/d"); pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	return s
+}
+
+func find(t *testing.T, s baidupcs.Storage, args ...string) []string {
+	q, err := Parse(args)
+	if err != nil {
+		t.Fatalf("%v: %s", args, err)
+	}
+	fdl, err := Find(s, "/a", q)
+	if err != nil {
+		t.Fatalf("%v: %s", args, err)
+	}
+	var paths []string
+	for _, fd := range fdl {
+		paths = append(paths, fd.Path)
+	}
+	return paths
+}
+
+func TestFind(t *testing.T) {
+	s := newTestStorage(t)
+	cases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"-name", "*.mp4"}, []string{"/a/b/c/movie2.mp4", "/a/movie.mp4"}},
+		{[]string{"-iname", "*.mp4"}, []string{"/a/b/c/movie2.mp4", "/a/Trailer.MP4", "/a/movie.mp4"}},
+		{[]string{"-type", "d", "-empty"}, []string{"/a/d"}},
+		{[]string{"-type", "f", "-empty"}, []string{"/a/b/empty.txt"}},
+		{[]string{"-size", "+1K"}, []string{"/a/movie.mp4"}},
+		{[]string{"-size", "2K"}, []string{"/a/movie.mp4"}},
+		{[]string{"-regex", "/a/b/.*\\.txt"}, []string{"/a/b/empty.txt", "/a/b/notes.txt"}},
+		{[]string{"-md5", "5D41402ABC4B2A76B9719D911017C592"}, []string{"/a/b/notes.txt"}},
+		{[]string{"-type", "f", "-not", "(", "-name", "*.mp4", "-or", "-name", "*.txt", ")"}, []string{"/a/Trailer.MP4"}},
+		{[]string{"-type", "d", "-a", "-mtime", "-1", "-name", "[!ab]"}, []string{"/a/b/c", "/a/d"}},
+	}
+	for _, c := range cases {
+		if paths := find(t, s, c.args...); !reflect.DeepEqual(paths, c.expected) {
+			t.Errorf("%v: got %v, expected %v", c.args, paths, c.expected)
+		}
+	}
+	if s.searched != 0 {
+		t.Errorf("search should not be used, searched %d times", s.searched)
+	}
+
+	// 只能匹配文件时使用服务器搜索
+	paths := find(t, s, "-type", "f", "-name", "movie*.mp4")
+	if !reflect.DeepEqual(paths, []string{"/a/b/c/movie2.mp4", "/a/movie.mp4"}) {
+		t.Errorf("search: got %v", paths)
+	}
+	if s.searched != 1 {
+		t.Errorf("search should be used once, searched %d times", s.searched)
+	}
+	find(t, s, "-type", "f", "-name", "movie*.mp4", "-nosearch")
+	if s.searched != 1 {
+		t.Errorf("-nosearch should disable search, searched %d times", s.searched)
+	}
+}
+
+func TestParse(t *testing.T) {
+	q, err := Parse([]string{"-name", "*.mp4", "-print0", "-move", "/b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Actions) != 2 || q.Actions[0].Type != ActionPrint0 || q.Actions[1].Type != ActionMove || q.Actions[1].Arg != "/b" {
+		t.Fatalf("unexpected actions %v", q.Actions)
+	}
+	if !q.Modifies() {
+		t.Errorf("-move should modify files")
+	}
+
+	q, err = Parse(nil)
+	if err != nil || len(q.Actions) != 1 || q.Actions[0].Type != ActionPrint || q.Modifies() {
+		t.Fatalf("unexpected default query %v, %v", q, err)
+	}
+
+	for _, args := range [][]string{
+		{"-name"},
+		{"(", "-name", "a"},
+		{"-name", "a", ")"},
+		{"-size", "1X"},
+		{"-type", "x"},
+		{"-regex", "("},
+		{"-unknown"},
+		{"-not", "-print"},
+	} {
+		if _, err = Parse(args); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+
+	if kw := searchKeyword(mustParse(t, "-name", "*abc*de?.mp4")); kw != ".mp4" {
+		t.Errorf("searchKeyword: got %q", kw)
+	}
+}
+
+func mustParse(t *testing.T, args ...string) Expr {
+	q, err := Parse(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q.Expr
+}
@@ -0,0 +1,294 @@
+package pcsfind
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+)
+
+type (
+	// ActionType 对匹配结果执行的操作
+	ActionType int
+
+	// Action 操作
+	Action struct {
+		Type ActionType
+		Arg  string // -download 和 -move 的目标目录
+	}
+
+	// Query 解析后的查找表达式
+	Query struct {
+		Expr     Expr
+		Actions  []*Action
+		NoSearch bool // 不使用服务器搜索预先筛选
+	}
+
+	parser struct {
+		args []string
+		pos  int
+		q    *Query
+	}
+)
+
+const (
+	// ActionPrint 输出路径
+	ActionPrint ActionType = iota
+	// ActionPrint0 输出路径, 以 \0 分隔
+	ActionPrint0
+	// ActionJSON 以 json 格式输出, 每行一条
+	ActionJSON
+	// ActionDelete 删除
+	ActionDelete
+	// ActionDownload 下载到本地目录
+	ActionDownload
+	// ActionMove 移动到网盘目录
+	ActionMove
+)
+
+var (
+	// ErrMissingArgument 缺少参数
+	ErrMissingArgument = errors.New("缺少参数")
+	// ErrUnmatchedParen 括号不匹配
+	ErrUnmatchedParen = errors.New("括号不匹配")
+)
+
+// Modifies 判断是否含有修改网盘文件的操作
+func (q *Query) Modifies() bool {
+	for _, action := range q.Actions {
+		if action.Type == ActionDelete || action.Type == ActionMove {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse 解析查找表达式, 条件之间默认为 -and, 未指定操作时默认为 -print
+func Parse(args []string) (q *Query, err error) {
+	p := &parser{
+		args: args,
+		q:    &Query{},
+	}
+	if len(args) == 0 {
+		p.q.Expr = trueExpr{}
+	} else {
+		p.q.Expr, err = p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos < len(p.args) {
+			if p.args[p.pos] == ")" {
+				return nil, ErrUnmatchedParen
+			}
+			return nil, fmt.Errorf("未知的参数: %s", p.args[p.pos])
+		}
+	}
+	if p.q.Expr == nil {
+		p.q.Expr = trueExpr{}
+	}
+	if len(p.q.Actions) == 0 {
+		p.q.Actions = []*Action{{Type: ActionPrint}}
+	}
+	return p.q, nil
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.args) {
+		return p.args[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() (string, error) {
+	if p.pos >= len(p.args) {
+		return "", fmt.Errorf("%s: %s", p.args[p.pos-1], ErrMissingArgument)
+	}
+	p.pos++
+	return p.args[p.pos-1], nil
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "-or" || p.peek() == "-o" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = combine(left, right, false)
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case "", ")", "-or", "-o":
+			return left, nil
+		case "-and", "-a":
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = combine(left, right, true)
+	}
+}
+
+// combine 合并条件, 操作 (nil) 不参与运算
+func combine(left, right Expr, and bool) Expr {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	case and:
+		return &andExpr{left, right}
+	}
+	return &orExpr{left, right}
+}
+
+// parseUnary 解析单个条件, 操作返回 nil
+func (p *parser) parseUnary() (Expr, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, ErrMissingArgument
+	}
+
+	switch token {
+	case "-not", "!":
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if expr == nil {
+			return nil, fmt.Errorf("%s: 不能用于操作", token)
+		}
+		return &notExpr{expr}, nil
+	case "(":
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, ErrUnmatchedParen
+		}
+		p.pos++
+		return expr, nil
+	case "-nosearch":
+		p.q.NoSearch = true
+		return nil, nil
+	case "-print":
+		p.q.Actions = append(p.q.Actions, &Action{Type: ActionPrint})
+		return nil, nil
+	case "-print0":
+		p.q.Actions = append(p.q.Actions, &Action{Type: ActionPrint0})
+		return nil, nil
+	case "-json":
+		p.q.Actions = append(p.q.Actions, &Action{Type: ActionJSON})
+		return nil, nil
+	case "-delete":
+		p.q.Actions = append(p.q.Actions, &Action{Type: ActionDelete})
+		return nil, nil
+	case "-download", "-move":
+		arg, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		typ := ActionDownload
+		if token == "-move" {
+			typ = ActionMove
+		}
+		p.q.Actions = append(p.q.Actions, &Action{Type: typ, Arg: arg})
+		return nil, nil
+	case "-empty":
+		return emptyExpr{}, nil
+	}
+
+	arg, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case "-name", "-iname":
+		pattern := strings.Replace(arg, "[!", "[^", -1)
+		if _, err = path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s %s: %s", token, arg, err)
+		}
+		return &nameExpr{pattern: pattern, fold: token == "-iname"}, nil
+	case "-regex":
+		re, err := regexp.Compile("^(?:" + arg + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %s", token, arg, err)
+		}
+		return &regexExpr{re: re}, nil
+	case "-size":
+		return parseSize(arg)
+	case "-mtime":
+		op, n, err := parseCmp(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %s", token, arg, err)
+		}
+		return &mtimeExpr{op: op, days: n}, nil
+	case "-type":
+		switch arg {
+		case "f":
+			return &typeExpr{isdir: false}, nil
+		case "d":
+			return &typeExpr{isdir: true}, nil
+		}
+		return nil, fmt.Errorf("%s %s: 类型只能为 f 或 d", token, arg)
+	case "-md5":
+		return &md5Expr{md5: arg}, nil
+	}
+	return nil, fmt.Errorf("未知的参数: %s", token)
+}
+
+// parseCmp 解析 +N, -N, N
+func parseCmp(s string) (op cmpOp, n int64, err error) {
+	switch {
+	case strings.HasPrefix(s, "+"):
+		op, s = cmpGT, s[1:]
+	case strings.HasPrefix(s, "-"):
+		op, s = cmpLT, s[1:]
+	}
+	n, err = strconv.ParseInt(s, 10, 64)
+	return
+}
+
+// parseSize 解析 -size 的参数, 如 +1G, -100K, 10M, 不带单位时为字节
+func parseSize(arg string) (Expr, error) {
+	s := strings.ToUpper(arg)
+	unit := int64(converter.B)
+	if len(s) > 0 {
+		switch s[len(s)-1] {
+		case 'B':
+			s = s[:len(s)-1]
+		case 'K':
+			unit, s = converter.KB, s[:len(s)-1]
+		case 'M':
+			unit, s = converter.MB, s[:len(s)-1]
+		case 'G':
+			unit, s = converter.GB, s[:len(s)-1]
+		case 'T':
+			unit, s = converter.TB, s[:len(s)-1]
+		}
+	}
+	op, n, err := parseCmp(s)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("-size %s: 大小格式错误", arg)
+	}
+	return &sizeExpr{op: op, n: n, unit: unit}, nil
+}
@@ -0,0 +1,218 @@
+// Package pcsfixmd5 批量修复网盘文件的 md5, 记录进度以便中断后继续
+package pcsfixmd5
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// Status 修复结果
+	Status string
+
+	// Result 一个文件的修复结果
+	Result struct {
+		Path   string `json:"path"`
+		Status Status `json:"status"`
+		MD5    string `json:"md5,omitempty"`   // 修复后的 md5
+		Err    string `json:"error,omitempty"` // 失败的原因
+	}
+
+	// FixFunc 修复文件 fd 的 md5, 返回修复后的 md5
+	FixFunc func(fd *baidupcs.FileDirectory) (md5 string, err error)
+
+	// State 修复进度, 每完成一个文件追加一行到进度文件
+	State struct {
+		filename string
+		done     map[string]*Result
+		file     *os.File
+		mu       sync.Mutex
+	}
+
+	// Report 修复报告
+	Report struct {
+		Fixed     []*Result `json:"fixed"`
+		Failed    []*Result `json:"failed"`
+		Unchanged []*Result `json:"unchanged"`
+		Skipped   int       `json:"skipped"` // 上次已完成, 本次跳过的文件数
+	}
+)
+
+const (
+	// StatusFixed 修复成功
+	StatusFixed Status = "fixed"
+	// StatusFailed 修复失败
+	StatusFailed Status = "failed"
+	// StatusUnchanged md5 正确, 不需要修复
+	StatusUnchanged Status = "unchanged"
+)
+
+// NeedFix 判断文件的 md5 是否可能不正确, 见 baidupcs.MD5Reliable
+func NeedFix(fd *baidupcs.FileDirectory) bool {
+	if fd == nil || fd.Isdir || fd.Size == 0 {
+		return false
+	}
+	return !fd.MD5Reliable()
+}
+
+// OpenState 打开进度文件, 读取上次已完成的文件, 进度文件不存在时新建
+func OpenState(filename string) (*State, error) {
+	st := &State{
+		filename: filename,
+		done:     map[string]*Result{},
+	}
+	f, err := os.Open(filename)
+	if err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			res := &Result{}
+			// 中断时可能写入不完整的行, 忽略
+			if json.Unmarshal(scanner.Bytes(), res) != nil || res.Path == "" {
+				continue
+			}
+			if res.Status == StatusFailed {
+				delete(st.done, res.Path)
+				continue
+			}
+			st.done[res.Path] = res
+		}
+		f.Close()
+		if err = scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return nil, err
+	}
+	st.file, err = os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Done 判断文件 pcspath 上次是否已完成 (修复成功或不需要修复), 失败的文件会重试
+func (st *State) Done(pcspath string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	_, ok := st.done[pcspath]
+	return ok
+}
+
+// Record 记录一个文件的修复结果
+func (st *State) Record(res *Result) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if res.Status != StatusFailed {
+		st.done[res.Path] = res
+	}
+	_, err = st.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close 关闭进度文件
+func (st *State) Close() error {
+	return st.file.Close()
+}
+
+// Remove 关闭并删除进度文件, 全部完成后调用
+func (st *State) Remove() error {
+	st.file.Close()
+	return os.Remove(st.filename)
+}
+
+// Run 使用 parallel 个协程修复 fdl 中的文件, 目录和 state 中已完成的文件跳过,
+// needFix 判断文件是否需要修复, 为 nil 时使用 NeedFix.
+// 每完成一个文件调用 onResult, onResult 不会被并发调用. state 可以为 nil
+func Run(fdl baidupcs.FileDirectoryList, fix FixFunc, needFix func(fd *baidupcs.FileDirectory) bool, parallel int, state *State, onResult func(res *Result)) *Report {
+	if parallel < 1 {
+		parallel = 1
+	}
+	if needFix == nil {
+		needFix = NeedFix
+	}
+
+	var (
+		report = &Report{}
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		jobs   = make(chan *baidupcs.FileDirectory)
+	)
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for fd := range jobs {
+				res := &Result{Path: fd.Path, Status: StatusUnchanged, MD5: fd.MD5}
+				if needFix(fd) {
+					md5, err := fix(fd)
+					if err != nil {
+						res.Status, res.MD5, res.Err = StatusFailed, "", err.Error()
+					} else {
+						res.Status, res.MD5 = StatusFixed, md5
+					}
+				}
+				if state != nil {
+					if err := state.Record(res); err != nil && res.Err == "" {
+						res.Err = "记录进度失败, " + err.Error()
+					}
+				}
+
+				mu.Lock()
+				switch res.Status {
+				case StatusFixed:
+					report.Fixed = append(report.Fixed, res)
+				case StatusFailed:
+					report.Failed = append(report.Failed, res)
+				default:
+					report.Unchanged = append(report.Unchanged, res)
+				}
+				if onResult != nil {
+					onResult(res)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, fd := range fdl {
+		if fd.Isdir {
+			continue
+		}
+		if state != nil && state.Done(fd.Path) {
+			report.Skipped++
+			continue
+		}
+		jobs <- fd
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, list := range [][]*Result{report.Fixed, report.Failed, report.Unchanged} {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Path < list[j].Path
+		})
+	}
+	return report
+}
+
+// Save 保存报告到 filename, json 格式
+func (r *Report) Save(filename string) error {
+	data, err := json.MarshalIndent(r, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,132 @@
+package pcsfixmd5
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+const (
+	md5A = "0cc175b9c0f1b6a831c399e269772661"
+	md5B = "92eb5ffee6ae2fec3ad71c777531578f"
+)
+
+func newFile(pcspath string, size int64, md5 string, blockList ...string) *baidupcs.FileDirectory {
+	fd := &baidupcs.FileDirectory{Path: pcspath, Size: size, MD5: md5}
+	fd.BlockList = blockList
+	return fd
+}
+
+func TestNeedFix(t *testing.T) {
+	cases := []struct {
+		fd   *baidupcs.FileDirectory
+		need bool
+	}{
+		{newFile("", 1, md5A, md5A), false},
+		{&baidupcs.FileDirectory{Size: 1, MD5: md5A}, false},
+		{newFile("", 1, md5A, md5A, md5B), true},
+		{newFile("", 1, md5A, md5B), true},
+		{&baidupcs.FileDirectory{Size: 1, MD5: ""}, true},
+		{&baidupcs.FileDirectory{Size: 1, MD5: "xyz"}, true},
+		{&baidupcs.FileDirectory{Size: 0, MD5: ""}, false},
+		{&baidupcs.FileDirectory{Isdir: true}, false},
+	}
+	for k, c := range cases {
+		if got := NeedFix(c.fd); got != c.need {
+			t.Errorf("case %d: NeedFix = %v, expected %v", k, got, c.need)
+		}
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	var (
+		multi = []string{md5A, md5B}
+		fdl   = baidupcs.FileDirectoryList{
+			{Path: "/d", Isdir: true},
+			newFile("/d/ok", 1, md5A, md5A),
+			newFile("/d/fix", 2, md5B, multi...),
+			newFile("/d/fail", 2, md5B, multi...),
+		}
+		mu    sync.Mutex
+		fixed []string
+		fail  = true
+		fix   = func(fd *baidupcs.FileDirectory) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			fixed = append(fixed, fd.Path)
+			if fd.Path == "/d/fail" && fail {
+				return "", errors.New("server error")
+			}
+			return md5A, nil
+		}
+		filename = filepath.Join(t.TempDir(), "state", "fixmd5.jsonl")
+	)
+
+	state, err := OpenState(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results int
+	report := Run(fdl, fix, nil, 2, state, func(res *Result) {
+		results++
+	})
+	state.Close()
+	if results != 3 || len(report.Fixed) != 1 || len(report.Failed) != 1 || len(report.Unchanged) != 1 || report.Skipped != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Fixed[0].MD5 != md5A || report.Failed[0].Err != "server error" {
+		t.Errorf("unexpected results %+v %+v", report.Fixed[0], report.Failed[0])
+	}
+
+	// 继续: 只重试失败的文件
+	fixed, fail = nil, false
+	state, err = OpenState(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report = Run(fdl, fix, nil, 2, state, nil)
+	if !reflect.DeepEqual(fixed, []string{"/d/fail"}) {
+		t.Errorf("fixed %v, expected only /d/fail", fixed)
+	}
+	if report.Skipped != 2 || len(report.Fixed) != 1 || len(report.Failed) != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if err = state.Remove(); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = OpenState(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer state.Close()
+	if state.Done("/d/ok") {
+		t.Error("state not removed")
+	}
+}
+
+func TestRunNeedFix(t *testing.T) {
+	var (
+		fdl = baidupcs.FileDirectoryList{
+			newFile("/a", 1, md5A, md5A),
+			newFile("/b", 1, md5B, md5B),
+		}
+		fixed []string
+		fix   = func(fd *baidupcs.FileDirectory) (string, error) {
+			fixed = append(fixed, fd.Path)
+			return fd.MD5, nil
+		}
+	)
+
+	// 指定的文件即使 md5 正确也修复
+	report := Run(fdl, fix, func(fd *baidupcs.FileDirectory) bool {
+		return fd.Path == "/a" || NeedFix(fd)
+	}, 1, nil, nil)
+	if !reflect.DeepEqual(fixed, []string{"/a"}) || len(report.Fixed) != 1 || len(report.Unchanged) != 1 {
+		t.Errorf("fixed %v, unexpected report %+v", fixed, report)
+	}
+}
@@ -0,0 +1,426 @@
+package pcsindex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	bolt "go.etcd.io/bbolt"
+)
+
+type (
+	// txn 在一个事务中读写索引, 只读事务中不存在的 bucket 为 nil, 视为空
+	txn struct {
+		tx       *bolt.Tx
+		meta     *bolt.Bucket
+		records  *bolt.Bucket // 路径 -> 记录
+		children *bolt.Bucket // 目录 + "\x00" + 文件名 -> 是否为目录
+		fsID     *bolt.Bucket // fs_id -> 路径
+	}
+)
+
+const (
+	// openTimeout 等待其他进程释放索引文件的时间
+	openTimeout = 3 * time.Second
+)
+
+var (
+	// ErrNoFile 索引没有对应的文件, 不能写入
+	ErrNoFile = errors.New("索引没有对应的文件")
+
+	bucketMeta     = []byte("meta")
+	bucketRecords  = []byte("records")
+	bucketChildren = []byte("children")
+	bucketFsID     = []byte("fsid")
+	keyCursor      = []byte("cursor")
+	keyUpdated     = []byte("updated")
+)
+
+// Open 打开索引文件, 文件不存在时新建
+func Open(filename string) (*Index, error) {
+	err := os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return nil, err
+	}
+	return open(filename, false)
+}
+
+// OpenReadOnly 只读打开索引文件, 多个进程可以同时只读打开
+func OpenReadOnly(filename string) (*Index, error) {
+	// 只读打开时 bbolt 不会创建文件, 提前返回 os.IsNotExist 可以判断的错误
+	if _, err := os.Stat(filename); err != nil {
+		return nil, err
+	}
+	return open(filename, true)
+}
+
+func open(filename string, readOnly bool) (*Index, error) {
+	db, err := bolt.Open(filename, 0600, &bolt.Options{Timeout: openTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{db: db}
+	err = idx.view(func(t *txn) error {
+		if t.meta == nil {
+			return nil
+		}
+		idx.Cursor = string(t.meta.Get(keyCursor))
+		if data := t.meta.Get(keyUpdated); data != nil {
+			return idx.Updated.UnmarshalBinary(data)
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Close 关闭索引文件
+func (idx *Index) Close() error {
+	if idx.db == nil {
+		return nil
+	}
+	return idx.db.Close()
+}
+
+// Build 获取全部文件, 在临时文件中建立新的索引, 完成后替换 filename, 返回打开的索引.
+// 出错时保留原有的索引文件
+func Build(filename string, d Differ, onPage func(n int)) (*Index, error) {
+	tmp := filename + ".tmp"
+	os.Remove(tmp)
+	idx, err := Open(tmp)
+	if err != nil {
+		return nil, err
+	}
+	_, pcsError := idx.Update(d, onPage)
+	err = idx.Close()
+	if pcsError != nil {
+		os.Remove(tmp)
+		return nil, pcsError
+	}
+	if err == nil {
+		// bbolt 映射了文件, 关闭后才能替换
+		err = os.Rename(tmp, filename)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return Open(filename)
+}
+
+// view 在只读事务中执行 fn, 空索引使用空的事务
+func (idx *Index) view(fn func(t *txn) error) error {
+	if idx.db == nil {
+		return fn(&txn{})
+	}
+	return idx.db.View(func(tx *bolt.Tx) error {
+		return fn(&txn{
+			tx:       tx,
+			meta:     tx.Bucket(bucketMeta),
+			records:  tx.Bucket(bucketRecords),
+			children: tx.Bucket(bucketChildren),
+			fsID:     tx.Bucket(bucketFsID),
+		})
+	})
+}
+
+// update 在读写事务中执行 fn, fn 返回错误时事务中的修改全部撤销
+func (idx *Index) update(fn func(t *txn) error) error {
+	if idx.db == nil {
+		return ErrNoFile
+	}
+	return idx.db.Update(func(tx *bolt.Tx) error {
+		t := &txn{tx: tx}
+		err := t.createBuckets()
+		if err != nil {
+			return err
+		}
+		return fn(t)
+	})
+}
+
+func (t *txn) createBuckets() (err error) {
+	for _, b := range []struct {
+		name   []byte
+		bucket **bolt.Bucket
+	}{
+		{bucketMeta, &t.meta},
+		{bucketRecords, &t.records},
+		{bucketChildren, &t.children},
+		{bucketFsID, &t.fsID},
+	} {
+		*b.bucket, err = t.tx.CreateBucketIfNotExists(b.name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reset 删除全部记录
+func (t *txn) reset() error {
+	for _, name := range [][]byte{bucketMeta, bucketRecords, bucketChildren, bucketFsID} {
+		err := t.tx.DeleteBucket(name)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+	}
+	return t.createBuckets()
+}
+
+func (t *txn) setMeta(cursor string, updated time.Time) error {
+	data, err := updated.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	err = t.meta.Put(keyCursor, []byte(cursor))
+	if err != nil {
+		return err
+	}
+	return t.meta.Put(keyUpdated, data)
+}
+
+func fsIDKey(fsID int64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(fsID))
+	return key
+}
+
+func childKey(p string) []byte {
+	return []byte(path.Dir(p) + "\x00" + path.Base(p))
+}
+
+// get 读取路径的记录, 根目录总是存在
+func (t *txn) get(p string) *Record {
+	if p == baidupcs.PathSeparator {
+		return &Record{Path: baidupcs.PathSeparator, Isdir: true}
+	}
+	if t.records == nil {
+		return nil
+	}
+	data := t.records.Get([]byte(p))
+	if data == nil {
+		return nil
+	}
+	r := &Record{}
+	if json.Unmarshal(data, r) != nil {
+		return nil
+	}
+	return r
+}
+
+func (t *txn) getByFsID(fsID int64) *Record {
+	if t.fsID == nil || fsID == 0 {
+		return nil
+	}
+	p := t.fsID.Get(fsIDKey(fsID))
+	if p == nil {
+		return nil
+	}
+	return t.get(string(p))
+}
+
+// eachChild 按文件名顺序遍历目录的子文件和子目录, fn 返回 false 时停止遍历
+func (t *txn) eachChild(dir string, fn func(name string, isdir bool) bool) {
+	if t.children == nil {
+		return
+	}
+	prefix := []byte(dir + "\x00")
+	c := t.children.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		if !fn(string(k[len(prefix):]), len(v) > 0 && v[0] == 1) {
+			return
+		}
+	}
+}
+
+func (t *txn) list(dir string) (list []*Record) {
+	t.eachChild(dir, func(name string, _ bool) bool {
+		if r := t.get(path.Join(dir, name)); r != nil {
+			list = append(list, r)
+		}
+		return true
+	})
+	return
+}
+
+// hasSubdir 判断目录是否含有子目录
+func (t *txn) hasSubdir(dir string) (ok bool) {
+	t.eachChild(dir, func(_ string, isdir bool) bool {
+		ok = isdir
+		return !ok
+	})
+	return
+}
+
+func (t *txn) walk(r *Record, fn func(r *Record) bool) bool {
+	if r == nil {
+		return true
+	}
+	if !fn(r) {
+		return false
+	}
+	if !r.Isdir {
+		return true
+	}
+	for _, child := range t.list(r.Path) {
+		if !t.walk(child, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *txn) put(r *Record) error {
+	if r.Path == baidupcs.PathSeparator {
+		return nil
+	}
+
+	// fs_id 不变, 路径改变, 为移动或重命名
+	if old := t.getByFsID(r.FsID); old != nil && old.Path != r.Path {
+		var err error
+		if old.Isdir && r.Isdir {
+			err = t.move(old.Path, r.Path)
+		} else {
+			err = t.remove(old.Path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if old := t.get(r.Path); old != nil {
+		var err error
+		if old.Isdir && !r.Isdir {
+			err = t.remove(old.Path)
+		} else if old.FsID != r.FsID {
+			err = t.deleteFsID(old)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	err := t.mkdirAll(path.Dir(r.Path))
+	if err != nil {
+		return err
+	}
+	return t.set(r)
+}
+
+func (t *txn) set(r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	err = t.records.Put([]byte(r.Path), data)
+	if err != nil {
+		return err
+	}
+	if r.FsID != 0 {
+		err = t.fsID.Put(fsIDKey(r.FsID), []byte(r.Path))
+		if err != nil {
+			return err
+		}
+	}
+	isdir := []byte{0}
+	if r.Isdir {
+		isdir[0] = 1
+	}
+	return t.children.Put(childKey(r.Path), isdir)
+}
+
+// deleteFsID 删除记录的 fs_id, fs_id 已指向其他路径时保留
+func (t *txn) deleteFsID(r *Record) error {
+	if r.FsID == 0 || string(t.fsID.Get(fsIDKey(r.FsID))) != r.Path {
+		return nil
+	}
+	return t.fsID.Delete(fsIDKey(r.FsID))
+}
+
+// mkdirAll 补全上级目录, 变更顺序不保证上级目录先出现
+func (t *txn) mkdirAll(dir string) error {
+	if dir == baidupcs.PathSeparator {
+		return nil
+	}
+	if r := t.get(dir); r != nil {
+		if r.Isdir {
+			return nil
+		}
+		err := t.remove(dir)
+		if err != nil {
+			return err
+		}
+	}
+	err := t.mkdirAll(path.Dir(dir))
+	if err != nil {
+		return err
+	}
+	return t.set(&Record{Path: dir, Isdir: true})
+}
+
+// remove 删除记录及其子文件和子目录
+func (t *txn) remove(p string) error {
+	r := t.get(p)
+	if r == nil || p == baidupcs.PathSeparator {
+		return nil
+	}
+	// 遍历时不能修改, 先记录子文件和子目录
+	var names []string
+	t.eachChild(p, func(name string, _ bool) bool {
+		names = append(names, name)
+		return true
+	})
+	for _, name := range names {
+		err := t.remove(path.Join(p, name))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := t.records.Delete([]byte(p))
+	if err != nil {
+		return err
+	}
+	err = t.deleteFsID(r)
+	if err != nil {
+		return err
+	}
+	return t.children.Delete(childKey(p))
+}
+
+// move 把目录 from 及其子文件和子目录移动到 to
+func (t *txn) move(from, to string) error {
+	var moved []*Record
+	t.walk(t.get(from), func(r *Record) bool {
+		moved = append(moved, r)
+		return true
+	})
+	for _, p := range []string{from, to} {
+		err := t.remove(p)
+		if err != nil {
+			return err
+		}
+	}
+	err := t.mkdirAll(path.Dir(to))
+	if err != nil {
+		return err
+	}
+	for _, r := range moved {
+		r.Path = to + strings.TrimPrefix(r.Path, from)
+		err = t.set(r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
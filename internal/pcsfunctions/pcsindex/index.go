@@ -0,0 +1,199 @@
+// Package pcsindex 网盘文件的本地索引, 使用文件变更 cursor 增量更新, 网络不可用时用于离线查询.
+// 索引保存在本地的 bbolt 数据库中, 每页变更在一个事务中写入, 查询时只读取需要的记录.
+package pcsindex
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	bolt "go.etcd.io/bbolt"
+)
+
+type (
+	// Record 索引中的文件或目录
+	Record struct {
+		FsID      int64    `json:"fs_id,omitempty"`
+		Path      string   `json:"path"`
+		Size      int64    `json:"size,omitempty"`
+		MD5       string   `json:"md5,omitempty"`
+		Ctime     int64    `json:"ctime,omitempty"`
+		Mtime     int64    `json:"mtime,omitempty"`
+		BlockList []string `json:"block_list,omitempty"`
+		Isdir     bool     `json:"isdir,omitempty"`
+	}
+
+	// Index 网盘文件索引, 按路径保存全部文件和目录
+	Index struct {
+		Cursor  string    // 下一次增量更新使用的 cursor
+		Updated time.Time // 最后更新时间
+
+		db *bolt.DB // 为空时为不对应文件的空索引
+	}
+
+	// Differ 获取文件变更, *baidupcs.BaiduPCS 实现了此接口
+	Differ interface {
+		FilesDirectoriesDiff(cursor string) (diff *baidupcs.FileDiff, pcsError pcserror.Error)
+	}
+)
+
+const (
+	// OperationUpdate 更新本地索引
+	OperationUpdate = "更新本地索引"
+)
+
+// New 返回空的索引, 只包含根目录, 不对应索引文件, 不能写入
+func New() *Index {
+	return &Index{}
+}
+
+// NewRecord 从文件信息生成记录
+func NewRecord(fd *baidupcs.FileDirectory) *Record {
+	return &Record{
+		FsID:      fd.FsID,
+		Path:      path.Clean(fd.Path),
+		Size:      fd.Size,
+		MD5:       fd.MD5,
+		Ctime:     fd.Ctime,
+		Mtime:     fd.Mtime,
+		BlockList: fd.BlockList,
+		Isdir:     fd.Isdir,
+	}
+}
+
+// Update 从 Cursor 开始增量更新索引, 直到没有更多变更, 返回变更的数量.
+// 每获取一页变更调用一次 onPage, n 为已处理的变更数量.
+// 每页变更和 cursor 在同一个事务中写入, 出错时已写入的变更仍然有效, 可以继续更新.
+func (idx *Index) Update(d Differ, onPage func(n int)) (n int, pcsError pcserror.Error) {
+	for {
+		diff, pcsError := d.FilesDirectoriesDiff(idx.Cursor)
+		if pcsError != nil {
+			return n, pcsError
+		}
+		if err := idx.Apply(diff); err != nil {
+			return n, &pcserror.PCSErrInfo{
+				Operation: OperationUpdate,
+				ErrType:   pcserror.ErrTypeOthers,
+				Err:       err,
+			}
+		}
+		n += len(diff.Entries)
+		if onPage != nil {
+			onPage(n)
+		}
+		if !diff.HasMore {
+			return n, nil
+		}
+	}
+}
+
+// Apply 应用一页变更
+func (idx *Index) Apply(diff *baidupcs.FileDiff) error {
+	updated := time.Now()
+	err := idx.update(func(t *txn) error {
+		if diff.Reset {
+			if err := t.reset(); err != nil {
+				return err
+			}
+		}
+		for _, e := range diff.Entries {
+			var err error
+			if e.IsDelete {
+				if r := t.getByFsID(e.FsID); r != nil {
+					err = t.remove(r.Path)
+				} else if r = t.get(path.Clean(e.Path)); r != nil && r.FsID == 0 {
+					err = t.remove(r.Path)
+				}
+			} else {
+				err = t.put(NewRecord(e.FileDirectory))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return t.setMeta(diff.Cursor, updated)
+	})
+	if err != nil {
+		return err
+	}
+	idx.Cursor, idx.Updated = diff.Cursor, updated
+	return nil
+}
+
+// Put 添加或更新记录
+func (idx *Index) Put(r *Record) error {
+	return idx.update(func(t *txn) error {
+		return t.put(r)
+	})
+}
+
+// Len 返回索引中文件和目录的数量, 不包含根目录
+func (idx *Index) Len() (n int) {
+	idx.view(func(t *txn) error {
+		if t.records != nil {
+			n = t.records.Stats().KeyN
+		}
+		return nil
+	})
+	return
+}
+
+// Meta 返回路径的记录
+func (idx *Index) Meta(p string) (r *Record, ok bool) {
+	idx.view(func(t *txn) error {
+		r = t.get(path.Clean(baidupcs.PathSeparator + p))
+		return nil
+	})
+	return r, r != nil
+}
+
+// GetByFsID 按 fs_id 查找记录
+func (idx *Index) GetByFsID(fsID int64) (r *Record, ok bool) {
+	idx.view(func(t *txn) error {
+		r = t.getByFsID(fsID)
+		return nil
+	})
+	return r, r != nil
+}
+
+// List 返回目录下的文件和目录, 按文件名排序
+func (idx *Index) List(dir string) (list []*Record) {
+	idx.view(func(t *txn) error {
+		list = t.list(path.Clean(baidupcs.PathSeparator + dir))
+		return nil
+	})
+	return
+}
+
+// Walk 按路径顺序遍历 root 及其子文件和子目录, fn 返回 false 时停止遍历
+func (idx *Index) Walk(root string, fn func(r *Record) bool) {
+	idx.view(func(t *txn) error {
+		t.walk(t.get(path.Clean(baidupcs.PathSeparator+root)), fn)
+		return nil
+	})
+}
+
+// Search 在目录 root 下按文件名搜索文件和目录, 不区分大小写
+func (idx *Index) Search(root, keyword string, recursive bool) (list []*Record) {
+	root = path.Clean(baidupcs.PathSeparator + root)
+	keyword = strings.ToLower(keyword)
+	match := func(r *Record) bool {
+		if r.Path != root && strings.Contains(strings.ToLower(path.Base(r.Path)), keyword) {
+			list = append(list, r)
+		}
+		return true
+	}
+	idx.view(func(t *txn) error {
+		if !recursive {
+			for _, r := range t.list(root) {
+				match(r)
+			}
+			return nil
+		}
+		t.walk(t.get(root), match)
+		return nil
+	})
+	return
+}
@@ -0,0 +1,215 @@
+package pcsindex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcstest"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+// snapshot 递归列出存储中的全部文件和目录
+func snapshot(t *testing.T, s baidupcs.Storage) (lines []string) {
+	baidupcs.StorageRecurseList(s, baidupcs.PathSeparator, baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+		if pcsError != nil {
+			t.Fatal(pcsError)
+		}
+		if depth == 0 {
+			// 根目录不在变更中, 索引中没有 fs_id
+			return true
+		}
+		lines = append(lines, fmt.Sprintf("%s %d %d %s %v", fd.Path, fd.FsID, fd.Size, fd.MD5, fd.Isdir))
+		return true
+	})
+	return
+}
+
+// openTemp 在临时目录中打开索引
+func openTemp(t *testing.T) *Index {
+	idx, err := Open(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		idx.Close()
+	})
+	return idx
+}
+
+func TestBuildUpdate(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	for i := 0; i < pcstest.DiffPageSize; i++ {
+		srv.WriteFile(fmt.Sprintf("/photos/%03d.jpg", i), []byte("jpg"))
+	}
+	srv.WriteFile("/docs/a.txt", []byte("a"))
+	srv.WriteFile("/docs/sub/b.txt", []byte("bb"))
+	srv.Mkdir("/empty")
+
+	pages := 0
+	filename := filepath.Join(t.TempDir(), "index.db")
+	idx, err := Build(filename, pcs, func(n int) { pages++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 2 || idx.Len() != pcstest.DiffPageSize+6 {
+		t.Errorf("unexpected pages %d, len %d", pages, idx.Len())
+	}
+	if expected := snapshot(t, pcs); !reflect.DeepEqual(snapshot(t, idx), expected) {
+		t.Errorf("index differs from server after build")
+	}
+
+	// 重命名目录, 删除, 覆盖和新建文件
+	if pcsError := pcs.Rename("/docs", "/papers"); pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	if pcsError := pcs.Remove("/photos/000.jpg", "/empty"); pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	srv.WriteFile("/papers/a.txt", []byte("changed"))
+	srv.WriteFile("/new/c.txt", []byte("c"))
+
+	n, pcsError := idx.Update(pcs, nil)
+	if pcsError != nil {
+		t.Fatal(pcsError)
+	}
+	if n == 0 {
+		t.Error("expected changes")
+	}
+	if expected := snapshot(t, pcs); !reflect.DeepEqual(snapshot(t, idx), expected) {
+		t.Errorf("index differs from server after update")
+	}
+
+	// 没有变更
+	n, pcsError = idx.Update(pcs, nil)
+	if pcsError != nil || n != 0 {
+		t.Errorf("expected no changes, got %d, %v", n, pcsError)
+	}
+
+	// 每页变更已写入文件, 重新打开后相同
+	expected := snapshot(t, idx)
+	if err = idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := OpenReadOnly(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Close()
+	if loaded.Cursor != idx.Cursor || !loaded.Updated.Equal(idx.Updated) || !reflect.DeepEqual(snapshot(t, loaded), expected) {
+		t.Errorf("reopened index differs")
+	}
+	if err = loaded.Put(&Record{Path: "/x"}); err == nil {
+		t.Errorf("expected error writing read-only index")
+	}
+
+	// 建立索引失败时保留原有的索引
+	srv.Fail("api/batch/filediff", 31034, 1)
+	if _, err = Build(filename, pcs, nil); err == nil {
+		t.Fatal("expected build error")
+	}
+	if _, err = os.Stat(filename + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary index not removed: %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	entry := func(fsID int64, p string, isdir, isDelete bool) *baidupcs.FileDiffEntry {
+		return &baidupcs.FileDiffEntry{
+			FileDirectory: &baidupcs.FileDirectory{FsID: fsID, Path: p, Isdir: isdir, Size: fsID},
+			IsDelete:      isDelete,
+		}
+	}
+	paths := func(idx *Index) (list []string) {
+		idx.Walk("/", func(r *Record) bool {
+			list = append(list, r.Path)
+			return true
+		})
+		return
+	}
+
+	idx := openTemp(t)
+	// 子文件先于上级目录出现
+	idx.Apply(&baidupcs.FileDiff{Cursor: "1", Entries: []*baidupcs.FileDiffEntry{
+		entry(2, "/a/b/c.txt", false, false),
+		entry(1, "/a", true, false),
+		entry(3, "/x", false, false),
+	}})
+	if got := paths(idx); !reflect.DeepEqual(got, []string{"/", "/a", "/a/b", "/a/b/c.txt", "/x"}) {
+		t.Errorf("unexpected paths %v", got)
+	}
+
+	// 只报告目录的移动, 子文件跟随移动; 文件被同名目录替换
+	idx.Apply(&baidupcs.FileDiff{Cursor: "2", Entries: []*baidupcs.FileDiffEntry{
+		entry(1, "/d", true, false),
+		entry(4, "/x", true, false),
+		entry(3, "/gone", false, true),
+	}})
+	if got := paths(idx); !reflect.DeepEqual(got, []string{"/", "/d", "/d/b", "/d/b/c.txt", "/x"}) {
+		t.Errorf("unexpected paths %v", got)
+	}
+	if r, ok := idx.Meta("/d/b/c.txt"); !ok || r.FsID != 2 || idx.Cursor != "2" {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	idx.Apply(&baidupcs.FileDiff{Cursor: "3", Entries: []*baidupcs.FileDiffEntry{
+		entry(1, "/d", true, true),
+	}})
+	if got := paths(idx); !reflect.DeepEqual(got, []string{"/", "/x"}) {
+		t.Errorf("unexpected paths %v", got)
+	}
+
+	idx.Apply(&baidupcs.FileDiff{Cursor: "4", Reset: true, Entries: []*baidupcs.FileDiffEntry{
+		entry(5, "/y", false, false),
+	}})
+	if got := paths(idx); !reflect.DeepEqual(got, []string{"/", "/y"}) {
+		t.Errorf("unexpected paths after reset %v", got)
+	}
+}
+
+func TestStorage(t *testing.T) {
+	idx := openTemp(t)
+	for k, p := range []string{"/a/Report.PDF", "/a/b/report-2.txt", "/a/b/c/d.txt", "/other.txt"} {
+		idx.Put(&Record{FsID: int64(k + 1), Path: p, Size: int64(k)})
+	}
+
+	var got []string
+	for _, r := range idx.Search("/a", "report", true) {
+		got = append(got, r.Path)
+	}
+	if !reflect.DeepEqual(got, []string{"/a/Report.PDF", "/a/b/report-2.txt"}) {
+		t.Errorf("unexpected search result %v", got)
+	}
+	if list := idx.Search("/a", "report", false); len(list) != 1 {
+		t.Errorf("unexpected non-recursive search result %d", len(list))
+	}
+
+	fdl, pcsError := idx.FilesDirectoriesList("/a", baidupcs.DefaultOrderOptions)
+	if pcsError != nil || len(fdl) != 2 || !fdl[0].Isdir || !fdl[0].Ifhassubdir || fdl[1].Filename != "Report.PDF" {
+		t.Errorf("unexpected list %v, %v", fdl, pcsError)
+	}
+	if _, pcsError = idx.FilesDirectoriesMeta("/missing"); !storage.IsNotExist(pcsError) {
+		t.Errorf("expected not exist error, got %v", pcsError)
+	}
+	if pcsError = idx.Remove("/a"); pcsError == nil || pcsError.GetError() != ErrReadOnly {
+		t.Errorf("expected read only error, got %v", pcsError)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	idx := New()
+	fdl, pcsError := idx.FilesDirectoriesList("/", baidupcs.DefaultOrderOptions)
+	if pcsError != nil || len(fdl) != 0 || idx.Len() != 0 {
+		t.Errorf("unexpected list %v, %v", fdl, pcsError)
+	}
+	if err := idx.Put(&Record{Path: "/a"}); err != ErrNoFile {
+		t.Errorf("expected ErrNoFile, got %v", err)
+	}
+}
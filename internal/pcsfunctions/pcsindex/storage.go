@@ -0,0 +1,149 @@
+package pcsindex
+
+import (
+	"errors"
+	"io"
+	"path"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+const (
+	// errCodeFileNotExists 文件或目录不存在, 与百度网盘的错误代码一致
+	errCodeFileNotExists = 31066
+)
+
+var (
+	// ErrReadOnly 离线索引只能读取文件列表
+	ErrReadOnly = errors.New("离线索引只支持列出文件, 不支持读取或修改")
+
+	_ baidupcs.Storage = (*Index)(nil)
+)
+
+func notExistError(op string) pcserror.Error {
+	return &pcserror.PCSErrInfo{
+		Operation: op,
+		ErrType:   pcserror.ErrTypeRemoteError,
+		ErrCode:   errCodeFileNotExists,
+		ErrMsg:    "文件或目录不在索引中",
+	}
+}
+
+func readOnlyError(op string) pcserror.Error {
+	return &pcserror.PCSErrInfo{
+		Operation: op,
+		ErrType:   pcserror.ErrTypeOthers,
+		Err:       ErrReadOnly,
+	}
+}
+
+// fileDirectory 转换为文件信息
+func (t *txn) fileDirectory(r *Record) *baidupcs.FileDirectory {
+	fd := &baidupcs.FileDirectory{
+		FsID:     r.FsID,
+		Path:     r.Path,
+		Filename: path.Base(r.Path),
+		Ctime:    r.Ctime,
+		Mtime:    r.Mtime,
+		MD5:      r.MD5,
+		Size:     r.Size,
+		Isdir:    r.Isdir,
+	}
+	fd.BlockList = r.BlockList
+	if r.Path == baidupcs.PathSeparator {
+		fd.Filename = ""
+	}
+	if r.Isdir {
+		fd.Ifhassubdir = t.hasSubdir(r.Path)
+	}
+	return fd
+}
+
+// FileDirectories 把记录转换为文件信息
+func (idx *Index) FileDirectories(records []*Record) (fdl baidupcs.FileDirectoryList) {
+	fdl = make(baidupcs.FileDirectoryList, 0, len(records))
+	idx.view(func(t *txn) error {
+		for _, r := range records {
+			fdl = append(fdl, t.fileDirectory(r))
+		}
+		return nil
+	})
+	return
+}
+
+// FilesDirectoriesMeta 从索引获取文件/目录的元信息
+func (idx *Index) FilesDirectoriesMeta(p string) (data *baidupcs.FileDirectory, pcsError pcserror.Error) {
+	idx.view(func(t *txn) error {
+		if r := t.get(path.Clean(baidupcs.PathSeparator + p)); r != nil {
+			data = t.fileDirectory(r)
+		}
+		return nil
+	})
+	if data == nil {
+		return nil, notExistError(baidupcs.OperationFilesDirectoriesMeta)
+	}
+	return data, nil
+}
+
+// FilesDirectoriesList 从索引获取目录下的文件和目录列表
+func (idx *Index) FilesDirectoriesList(p string, options *baidupcs.OrderOptions) (data baidupcs.FileDirectoryList, pcsError pcserror.Error) {
+	p = path.Clean(baidupcs.PathSeparator + p)
+	idx.view(func(t *txn) error {
+		r := t.get(p)
+		if r == nil {
+			pcsError = notExistError(baidupcs.OperationFilesDirectoriesList)
+			return nil
+		}
+		if !r.Isdir {
+			data = baidupcs.FileDirectoryList{t.fileDirectory(r)}
+			return nil
+		}
+		data = baidupcs.FileDirectoryList{}
+		for _, child := range t.list(p) {
+			data = append(data, t.fileDirectory(child))
+		}
+		return nil
+	})
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	storage.SortList(data, options)
+	return data, nil
+}
+
+// OpenRange 不支持
+func (idx *Index) OpenRange(p string, offset, length int64) (readCloser io.ReadCloser, pcsError pcserror.Error) {
+	return nil, readOnlyError(baidupcs.OperationOpenRange)
+}
+
+// WriteFile 不支持
+func (idx *Index) WriteFile(p string, r io.Reader, size int64) (pcsError pcserror.Error) {
+	return readOnlyError(baidupcs.OperationWriteFile)
+}
+
+// Remove 不支持
+func (idx *Index) Remove(paths ...string) (pcsError pcserror.Error) {
+	return readOnlyError(baidupcs.OperationRemove)
+}
+
+// Mkdir 不支持
+func (idx *Index) Mkdir(pcspath string) (pcsError pcserror.Error) {
+	return readOnlyError(baidupcs.OperationMkdir)
+}
+
+// Rename 不支持
+func (idx *Index) Rename(from, to string) (pcsError pcserror.Error) {
+	return readOnlyError(baidupcs.OperationRename)
+}
+
+// Copy 不支持
+func (idx *Index) Copy(cpmvJSON ...*baidupcs.CpMvJSON) (pcsError pcserror.Error) {
+	return readOnlyError(baidupcs.OperationCopy)
+}
+
+// Move 不支持
+func (idx *Index) Move(cpmvJSON ...*baidupcs.CpMvJSON) (pcsError pcserror.Error) {
+	return readOnlyError(baidupcs.OperationMove)
+}
@@ -0,0 +1,262 @@
+// Package pcsjournal 记录修改网盘文件的操作, 用于查看历史和撤销
+package pcsjournal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/filelock"
+)
+
+type (
+	// Op 操作类型
+	Op string
+
+	// Item 操作涉及的一个文件/目录
+	Item struct {
+		Path     string `json:"path,omitempty"`     // 源路径, 删除时为被删除的路径, 创建, 上传, 转存和还原时为新增的路径
+		To       string `json:"to,omitempty"`       // 移动, 重命名, 拷贝的目标路径
+		FsID     int64  `json:"fs_id,omitempty"`    // 被删除的文件/目录的 fs_id
+		Replaced int64  `json:"replaced,omitempty"` // 拷贝时被覆盖的目标的 fs_id
+	}
+
+	// Entry 一次操作
+	Entry struct {
+		ID     int       `json:"id"`
+		Op     Op        `json:"op"`
+		Time   time.Time `json:"time"`
+		Items  []*Item   `json:"items"`
+		Undone bool      `json:"undone,omitempty"` // 是否已撤销
+	}
+
+	// Journal 操作日志, 每行一个 json 格式的 Entry
+	Journal struct {
+		filename string
+		entries  []*Entry
+		mu       sync.Mutex
+	}
+)
+
+const (
+	// OpRemove 删除, 文件进入回收站
+	OpRemove Op = "rm"
+	// OpMove 移动
+	OpMove Op = "mv"
+	// OpRename 重命名
+	OpRename Op = "rename"
+	// OpCopy 拷贝
+	OpCopy Op = "cp"
+	// OpRecycleDelete 删除回收站中的文件
+	OpRecycleDelete Op = "recycle-delete"
+	// OpRecycleClear 清空回收站
+	OpRecycleClear Op = "recycle-clear"
+	// OpRecycleRestore 还原回收站中的文件
+	OpRecycleRestore Op = "recycle-restore"
+	// OpMkdir 创建目录
+	OpMkdir Op = "mkdir"
+	// OpUpload 上传文件
+	OpUpload Op = "upload"
+	// OpTransfer 转存分享链接
+	OpTransfer Op = "transfer"
+
+	// MaxEntries 最多保留的操作数, 超出时删除最早的操作
+	MaxEntries = 1000
+)
+
+var (
+	// ErrIrreversible 操作不可撤销
+	ErrIrreversible = errors.New("操作不可撤销")
+	// ErrUndone 操作已撤销
+	ErrUndone = errors.New("操作已撤销")
+)
+
+// Reversible 操作是否可以撤销
+func (op Op) Reversible() bool {
+	switch op {
+	case OpRemove, OpMove, OpRename, OpCopy, OpRecycleRestore, OpMkdir, OpUpload, OpTransfer:
+		return true
+	}
+	return false
+}
+
+// Open 打开操作日志, 文件不存在时返回空的日志
+func Open(filename string) (*Journal, error) {
+	j := &Journal{
+		filename: filename,
+	}
+
+	l, err := j.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer l.Release()
+
+	err = j.load()
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// lock 获取操作日志的文件锁, 多个进程同时修改日志时依次进行
+func (j *Journal) lock() (*filelock.Lock, error) {
+	return filelock.Acquire(j.filename + ".lock")
+}
+
+// load 重新读取日志文件, 需要持有文件锁
+func (j *Journal) load() error {
+	j.entries = nil
+	f, err := os.Open(j.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		e := &Entry{}
+		err = json.Unmarshal(scanner.Bytes(), e)
+		if err != nil {
+			return fmt.Errorf("解析操作日志 %s 失败, %s", j.filename, err)
+		}
+		j.entries = append(j.entries, e)
+	}
+	return scanner.Err()
+}
+
+// Entries 返回所有操作, 从早到晚排列
+func (j *Journal) Entries() []*Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries := make([]*Entry, len(j.entries))
+	copy(entries, j.entries)
+	return entries
+}
+
+// Get 返回 id 对应的操作, 不存在时返回 nil
+func (j *Journal) Get(id int) *Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, e := range j.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// Add 记录一次操作, 先重新读取日志, 避免与其他进程记录的操作冲突
+func (j *Journal) Add(op Op, items []*Item) (*Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	l, err := j.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer l.Release()
+
+	err = j.load()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Entry{
+		ID:    1,
+		Op:    op,
+		Time:  time.Now(),
+		Items: items,
+	}
+	if len(j.entries) > 0 {
+		e.ID = j.entries[len(j.entries)-1].ID + 1
+	}
+	j.entries = append(j.entries, e)
+
+	if len(j.entries) > MaxEntries {
+		j.entries = j.entries[len(j.entries)-MaxEntries:]
+		return e, j.save()
+	}
+	return e, j.append(e)
+}
+
+// MarkUndone 把操作标记为已撤销
+func (j *Journal) MarkUndone(e *Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	l, err := j.lock()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	err = j.load()
+	if err != nil {
+		return err
+	}
+	e.Undone = true
+	for _, v := range j.entries {
+		if v.ID == e.ID {
+			v.Undone = true
+		}
+	}
+	return j.save()
+}
+
+func (j *Journal) append(e *Entry) error {
+	err := os.MkdirAll(filepath.Dir(j.filename), 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(j.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(e)
+}
+
+// save 重写整个日志文件
+func (j *Journal) save() error {
+	err := os.MkdirAll(filepath.Dir(j.filename), 0700)
+	if err != nil {
+		return err
+	}
+
+	tmp := j.filename + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(f)
+	for _, e := range j.entries {
+		err = encoder.Encode(e)
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	err = f.Close()
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, j.filename)
+}
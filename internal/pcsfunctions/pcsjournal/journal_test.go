@@ -0,0 +1,185 @@
+package pcsjournal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+)
+
+// fakeUndoer 在内存存储上模拟回收站
+type fakeUndoer struct {
+	*storage.Memory
+	restored []int64
+}
+
+func (f *fakeUndoer) RecycleRestore(fidList ...int64) (sussFsIDList []*baidupcs.FsIDJSON, pcsError pcserror.Error) {
+	f.restored = append(f.restored, fidList...)
+	for _, fid := range fidList {
+		sussFsIDList = append(sussFsIDList, &baidupcs.FsIDJSON{FsID: fid})
+	}
+	return
+}
+
+func TestJournal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "journal", "1.jsonl")
+	j, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e1, err := j.Add(OpRemove, []*Item{{Path: "/a", FsID: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e2, err := j.Add(OpMove, []*Item{{Path: "/b", To: "/c/b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e1.ID != 1 || e2.ID != 2 {
+		t.Fatalf("unexpected ids %d %d", e1.ID, e2.ID)
+	}
+	err = j.MarkUndone(e1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := j.Entries()
+	if len(entries) != 2 || !entries[0].Undone || entries[1].Undone || entries[1].Items[0].To != "/c/b" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if j.Get(2) == nil || j.Get(3) != nil {
+		t.Error("unexpected Get result")
+	}
+
+	// 超出 MaxEntries 时删除最早的操作, id 继续递增
+	for i := 0; i < MaxEntries; i++ {
+		_, err = j.Add(OpRecycleClear, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	j, err = Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries = j.Entries()
+	if len(entries) != MaxEntries || entries[0].ID != 3 || entries[len(entries)-1].ID != MaxEntries+2 {
+		t.Fatalf("unexpected entries after trim: %d, first %d", len(entries), entries[0].ID)
+	}
+}
+
+func TestJournalConcurrent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "journal", "1.jsonl")
+
+	// 每个 Journal 相当于一个进程
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j, err := Open(filename)
+			if err == nil {
+				_, err = j.Add(OpRemove, []*Item{{Path: "/a"}})
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	j, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := j.Entries()
+	if len(entries) != 20 {
+		t.Fatalf("expected 20 entries, got %d", len(entries))
+	}
+	for k, e := range entries {
+		if e.ID != k+1 {
+			t.Fatalf("entry %d has id %d", k, e.ID)
+		}
+	}
+}
+
+func TestUndo(t *testing.T) {
+	u := &fakeUndoer{Memory: storage.NewMemory()}
+	for _, p := range []string{"/new/a.txt", "/copy/b.txt"} {
+		if pcsError := u.WriteFile(p, strings.NewReader("x"), 1); pcsError != nil {
+			t.Fatal(pcsError)
+		}
+	}
+
+	// 移动
+	err := Undo(u, &Entry{Op: OpMove, Items: []*Item{{Path: "/old/a.txt", To: "/new/a.txt"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, pcsError := u.FilesDirectoriesMeta("/old/a.txt"); pcsError != nil {
+		t.Errorf("move not undone: %s", pcsError)
+	}
+
+	// 分批撤销重命名
+	var items []*Item
+	for i := 0; i < MoveBatchSize+1; i++ {
+		p := fmt.Sprintf("/r/%03d", i)
+		if pcsError := u.WriteFile(p+".new", strings.NewReader("x"), 1); pcsError != nil {
+			t.Fatal(pcsError)
+		}
+		items = append(items, &Item{Path: p, To: p + ".new"})
+	}
+	if err = Undo(u, &Entry{Op: OpRename, Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	if fdl, _ := u.FilesDirectoriesList("/r", nil); len(fdl) != MoveBatchSize+1 || fdl[0].Filename != "000" {
+		t.Errorf("rename not undone: %d files", len(fdl))
+	}
+
+	// 删除
+	err = Undo(u, &Entry{Op: OpRemove, Items: []*Item{{Path: "/x", FsID: 7}, {Path: "/y", FsID: 8}}})
+	if err != nil || len(u.restored) != 2 {
+		t.Fatalf("remove not undone: %v %v", err, u.restored)
+	}
+	if err = Undo(u, &Entry{Op: OpRemove, Items: []*Item{{Path: "/x"}}}); err != ErrNoFsID {
+		t.Errorf("expected ErrNoFsID, got %v", err)
+	}
+
+	// 覆盖拷贝
+	err = Undo(u, &Entry{Op: OpCopy, Items: []*Item{{Path: "/b.txt", To: "/copy/b.txt", Replaced: 9}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, pcsError := u.FilesDirectoriesMeta("/copy/b.txt"); pcsError == nil {
+		t.Error("copy not removed")
+	}
+	if u.restored[len(u.restored)-1] != 9 {
+		t.Errorf("replaced file not restored: %v", u.restored)
+	}
+
+	// 上传
+	err = Undo(u, &Entry{Op: OpUpload, Items: []*Item{{Path: "/old/a.txt"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, pcsError := u.FilesDirectoriesMeta("/old/a.txt"); pcsError == nil {
+		t.Error("upload not undone")
+	}
+
+	if err = Undo(u, &Entry{Op: OpRecycleDelete}); err != ErrIrreversible {
+		t.Errorf("expected ErrIrreversible, got %v", err)
+	}
+	if err = Undo(u, &Entry{Op: OpMove, Undone: true}); err != ErrUndone {
+		t.Errorf("expected ErrUndone, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package pcsjournal
+
+import (
+	"errors"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// Undoer 撤销操作需要的网盘接口, BaiduPCS 实现了此接口
+	Undoer interface {
+		Move(cpmvJSON ...*baidupcs.CpMvJSON) (pcsError pcserror.Error)
+		Remove(paths ...string) (pcsError pcserror.Error)
+		RecycleRestore(fidList ...int64) (sussFsIDList []*baidupcs.FsIDJSON, pcsError pcserror.Error)
+	}
+)
+
+const (
+	// MoveBatchSize 撤销移动和重命名时每次请求移动的文件数
+	MoveBatchSize = 100
+)
+
+var (
+	// ErrNoFsID 没有记录 fs_id, 无法从回收站还原
+	ErrNoFsID = errors.New("没有记录 fs_id, 无法从回收站还原")
+)
+
+// Undo 撤销操作: 移动和重命名移回原处, 删除的文件从回收站还原,
+// 拷贝的目标被删除, 被覆盖的文件从回收站还原,
+// 创建, 上传, 转存和从回收站还原的文件/目录被删除, 可在回收站找回.
+func Undo(u Undoer, e *Entry) error {
+	if e.Undone {
+		return ErrUndone
+	}
+
+	switch e.Op {
+	case OpMove, OpRename:
+		list := make([]*baidupcs.CpMvJSON, 0, len(e.Items))
+		for i := len(e.Items) - 1; i >= 0; i-- {
+			list = append(list, &baidupcs.CpMvJSON{
+				From: e.Items[i].To,
+				To:   e.Items[i].Path,
+			})
+		}
+		for len(list) > 0 {
+			n := len(list)
+			if n > MoveBatchSize {
+				n = MoveBatchSize
+			}
+			pcsError := u.Move(list[:n]...)
+			if pcsError != nil {
+				return pcsError
+			}
+			list = list[n:]
+		}
+		return nil
+	case OpRemove:
+		fidList := make([]int64, 0, len(e.Items))
+		for _, item := range e.Items {
+			if item.FsID == 0 {
+				return ErrNoFsID
+			}
+			fidList = append(fidList, item.FsID)
+		}
+		return restore(u, fidList)
+	case OpCopy:
+		var (
+			paths   = make([]string, 0, len(e.Items))
+			fidList []int64
+		)
+		for _, item := range e.Items {
+			paths = append(paths, item.To)
+			if item.Replaced != 0 {
+				fidList = append(fidList, item.Replaced)
+			}
+		}
+		pcsError := u.Remove(paths...)
+		if pcsError != nil {
+			return pcsError
+		}
+		return restore(u, fidList)
+	case OpMkdir, OpUpload, OpTransfer, OpRecycleRestore:
+		paths := make([]string, 0, len(e.Items))
+		for _, item := range e.Items {
+			paths = append(paths, item.Path)
+		}
+		pcsError := u.Remove(paths...)
+		if pcsError != nil {
+			return pcsError
+		}
+		return nil
+	}
+	return ErrIrreversible
+}
+
+func restore(u Undoer, fidList []int64) error {
+	if len(fidList) == 0 {
+		return nil
+	}
+	_, pcsError := u.RecycleRestore(fidList...)
+	if pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package pcsrecycle 筛选回收站中的文件, 用于批量还原和删除
+package pcsrecycle
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// Filter 回收站筛选条件, 零值表示不限制
+	Filter struct {
+		Pattern string    // 原路径的通配符, 支持 ** 和 {a,b}
+		Since   time.Time // 删除时间不早于 Since
+		Until   time.Time // 删除时间早于 Until
+		MinSize int64
+		MaxSize int64
+		Type    string // TypeFile 或 TypeDir
+	}
+
+	// Conflict 还原时原路径已被占用的文件
+	Conflict struct {
+		*baidupcs.RecycleFDInfo
+		Reason string
+	}
+)
+
+const (
+	// TypeFile 只筛选文件
+	TypeFile = "file"
+	// TypeDir 只筛选目录
+	TypeDir = "dir"
+
+	dateLayout = "2006-01-02"
+)
+
+// DeleteTime 删除时间, 回收站列表中的修改时间即为删除时间
+func DeleteTime(fd *baidupcs.RecycleFDInfo) time.Time {
+	return time.Unix(fd.Mtime, 0)
+}
+
+// ParseDate 解析 2006-01-02 格式的日期, end 为 true 时返回第二天的零点, 用于包含当天的结束日期
+func ParseDate(s string, end bool) (time.Time, error) {
+	t, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期格式错误: %s, 应为 %s", s, dateLayout)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// Check 检查筛选条件
+func (f *Filter) Check() error {
+	switch f.Type {
+	case "", TypeFile, TypeDir:
+	default:
+		return fmt.Errorf("未知的类型: %s, 可选值: %s, %s", f.Type, TypeFile, TypeDir)
+	}
+	if f.Pattern != "" {
+		if _, err := baidupcs.ParseShellPattern(f.Pattern); err != nil {
+			return err
+		}
+	}
+	if f.MaxSize > 0 && f.MinSize > f.MaxSize {
+		return fmt.Errorf("最小文件大小不能大于最大文件大小")
+	}
+	return nil
+}
+
+// IsEmpty 是否没有任何筛选条件
+func (f *Filter) IsEmpty() bool {
+	return *f == Filter{}
+}
+
+// Match 判断 fd 是否符合筛选条件
+func (f *Filter) Match(fd *baidupcs.RecycleFDInfo) bool {
+	switch f.Type {
+	case TypeFile:
+		if fd.Isdir == 1 {
+			return false
+		}
+	case TypeDir:
+		if fd.Isdir != 1 {
+			return false
+		}
+	}
+	if fd.Size < f.MinSize || (f.MaxSize > 0 && fd.Size > f.MaxSize) {
+		return false
+	}
+
+	deleted := DeleteTime(fd)
+	if !f.Since.IsZero() && deleted.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !deleted.Before(f.Until) {
+		return false
+	}
+
+	if f.Pattern != "" {
+		matched, _ := baidupcs.MatchShellPattern(f.Pattern, fd.Path)
+		return matched
+	}
+	return true
+}
+
+// Select 返回 fdl 中符合筛选条件的文件
+func Select(fdl baidupcs.RecycleFDInfoList, f *Filter) (selected baidupcs.RecycleFDInfoList) {
+	for _, fd := range fdl {
+		if f.Match(fd) {
+			selected = append(selected, fd)
+		}
+	}
+	return
+}
+
+// SplitConflicts 找出还原时会冲突的文件: 原路径已存在, 或多个文件原路径相同.
+// 原路径相同时保留最后删除的一个. exists 判断原路径当前是否存在.
+func SplitConflicts(fdl baidupcs.RecycleFDInfoList, exists func(p string) bool) (restorable baidupcs.RecycleFDInfoList, conflicts []*Conflict) {
+	sorted := make(baidupcs.RecycleFDInfoList, len(fdl))
+	copy(sorted, fdl)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Mtime > sorted[j].Mtime
+	})
+
+	seen := map[string]struct{}{}
+	for _, fd := range sorted {
+		switch _, dup := seen[fd.Path]; {
+		case dup:
+			conflicts = append(conflicts, &Conflict{fd, "有更晚删除的同路径文件"})
+		case exists(fd.Path):
+			conflicts = append(conflicts, &Conflict{fd, "原路径已存在"})
+		default:
+			restorable = append(restorable, fd)
+		}
+		seen[fd.Path] = struct{}{}
+	}
+	return
+}
+
+// FsIDs 返回 fdl 的 fs_id
+func FsIDs(fdl baidupcs.RecycleFDInfoList) []int64 {
+	fidList := make([]int64, 0, len(fdl))
+	for _, fd := range fdl {
+		fidList = append(fidList, fd.FsID)
+	}
+	return fidList
+}
+
+// Batches 把 fidList 按 size 分批
+func Batches(fidList []int64, size int) (batches [][]int64) {
+	if size <= 0 {
+		size = len(fidList)
+	}
+	for len(fidList) > size {
+		batches = append(batches, fidList[:size])
+		fidList = fidList[size:]
+	}
+	if len(fidList) > 0 {
+		batches = append(batches, fidList)
+	}
+	return
+}
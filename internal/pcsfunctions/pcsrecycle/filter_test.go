@@ -0,0 +1,86 @@
+package pcsrecycle
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+func day(s string) int64 {
+	t, _ := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+	return t.Unix()
+}
+
+func testList() baidupcs.RecycleFDInfoList {
+	return baidupcs.RecycleFDInfoList{
+		{FsID: 1, Path: "/projects/a/main.go", Size: 100, Mtime: day("2026-10-01 10:00")},
+		{FsID: 2, Path: "/projects/a", Isdir: 1, Mtime: day("2026-10-03 00:00")},
+		{FsID: 3, Path: "/projects/b.zip", Size: 5000, Mtime: day("2026-09-30 23:59")},
+		{FsID: 4, Path: "/photos/1.jpg", Size: 2000, Mtime: day("2026-10-05 12:00")},
+		{FsID: 5, Path: "/projects/a/main.go", Size: 120, Mtime: day("2026-10-04 09:00")},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	since, err := ParseDate("2026-10-01", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	until, err := ParseDate("2026-10-04", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		filter   Filter
+		expected []int64
+	}{
+		{Filter{}, []int64{1, 2, 3, 4, 5}},
+		{Filter{Pattern: "/projects/**"}, []int64{1, 2, 3, 5}},
+		{Filter{Pattern: "/projects/**", Since: since}, []int64{1, 2, 5}},
+		{Filter{Since: since, Until: until}, []int64{1, 2, 5}},
+		{Filter{Pattern: "/{photos,projects}/*.{jpg,zip}"}, []int64{3, 4}},
+		{Filter{Type: TypeDir}, []int64{2}},
+		{Filter{Type: TypeFile, MinSize: 1000}, []int64{3, 4}},
+		{Filter{MinSize: 100, MaxSize: 2000}, []int64{1, 4, 5}},
+	}
+	for _, c := range cases {
+		if err := c.filter.Check(); err != nil {
+			t.Fatalf("%+v: %s", c.filter, err)
+		}
+		got := FsIDs(Select(testList(), &c.filter))
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%+v: got %v, expected %v", c.filter, got, c.expected)
+		}
+	}
+
+	for _, f := range []Filter{{Type: "link"}, {Pattern: "relative/*"}, {MinSize: 10, MaxSize: 5}} {
+		if err := f.Check(); err == nil {
+			t.Errorf("%+v: expected error", f)
+		}
+	}
+	if _, err = ParseDate("2026/10/01", false); err == nil {
+		t.Error("expected date error")
+	}
+}
+
+func TestSplitConflicts(t *testing.T) {
+	restorable, conflicts := SplitConflicts(testList(), func(p string) bool {
+		return p == "/photos/1.jpg"
+	})
+	if got := FsIDs(restorable); !reflect.DeepEqual(got, []int64{5, 2, 3}) {
+		t.Errorf("unexpected restorable %v", got)
+	}
+	if len(conflicts) != 2 || conflicts[0].FsID != 4 || conflicts[1].FsID != 1 {
+		t.Errorf("unexpected conflicts %+v", conflicts)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	batches := Batches([]int64{1, 2, 3, 4, 5}, 2)
+	if !reflect.DeepEqual(batches, [][]int64{{1, 2}, {3, 4}, {5}}) {
+		t.Errorf("unexpected batches %v", batches)
+	}
+}
@@ -0,0 +1,130 @@
+package pcsrename
+
+import (
+	"path"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// Item 一个文件的重命名
+	Item struct {
+		From     string
+		To       string
+		Conflict string // 冲突原因, 为空时没有冲突
+	}
+
+	// Plan 重命名计划
+	Plan struct {
+		Items     []*Item
+		Unchanged int // 文件名没有变化的文件数
+	}
+)
+
+// NewPlan 按 r 生成 fdl 中文件的重命名计划, fdl 中的文件按顺序编号.
+// siblings 为文件所在目录的已有文件名, 用于检测冲突; 为空时只检测计划内的冲突.
+// 目标文件已存在, 多个文件重命名为同一个文件, 或目标文件也在重命名列表中时标记为冲突.
+func NewPlan(fdl baidupcs.FileDirectoryList, r Renamer, siblings map[string][]string) (*Plan, error) {
+	var (
+		plan    = &Plan{}
+		sources = make(map[string]struct{}, len(fdl))
+		targets = make(map[string]*Item, len(fdl))
+	)
+	for _, fd := range fdl {
+		sources[fd.Path] = struct{}{}
+	}
+
+	for k, fd := range fdl {
+		name, err := r.Rename(fd, k+1)
+		if err != nil {
+			return nil, err
+		}
+		if name == fd.Filename {
+			plan.Unchanged++
+			continue
+		}
+
+		item := &Item{
+			From: fd.Path,
+			To:   path.Join(path.Dir(fd.Path), name),
+		}
+		plan.Items = append(plan.Items, item)
+
+		switch {
+		case name == "":
+			item.Conflict = ErrEmptyName.Error()
+		case strings.Contains(name, baidupcs.PathSeparator), name == ".", name == "..":
+			item.Conflict = ErrInvalidName.Error()
+		case targets[item.To] != nil:
+			item.Conflict = "与 " + targets[item.To].From + " 的目标相同"
+			if targets[item.To].Conflict == "" {
+				targets[item.To].Conflict = "与 " + item.From + " 的目标相同"
+			}
+		case hasName(siblings[path.Dir(fd.Path)], name) && !hasKey(sources, item.To):
+			item.Conflict = "目标文件已存在"
+		}
+		if _, ok := targets[item.To]; !ok {
+			targets[item.To] = item
+		}
+	}
+
+	// 链式或交换的重命名不能在一次移动中完成
+	for _, item := range plan.Items {
+		if item.Conflict == "" && hasKey(sources, item.To) {
+			item.Conflict = "目标文件也在重命名列表中"
+		}
+	}
+	return plan, nil
+}
+
+func hasKey(m map[string]struct{}, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func hasName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Conflicts 返回冲突的数量
+func (p *Plan) Conflicts() (n int) {
+	for _, item := range p.Items {
+		if item.Conflict != "" {
+			n++
+		}
+	}
+	return
+}
+
+// CpMvJSONList 转换为 filemanager 移动的参数
+func (p *Plan) CpMvJSONList() baidupcs.CpMvJSONList {
+	list := make(baidupcs.CpMvJSONList, 0, len(p.Items))
+	for _, item := range p.Items {
+		list = append(list, &baidupcs.CpMvJSON{
+			From: item.From,
+			To:   item.To,
+		})
+	}
+	return list
+}
+
+// Batches 把 list 按 size 分批
+func Batches(list baidupcs.CpMvJSONList, size int) (batches []baidupcs.CpMvJSONList) {
+	if size <= 0 {
+		size = len(list)
+	}
+	for len(list) > size {
+		batches = append(batches, list[:size])
+		list = list[size:]
+	}
+	if len(list) > 0 {
+		batches = append(batches, list)
+	}
+	return
+}
@@ -0,0 +1,197 @@
+// Package pcsrename 批量重命名网盘文件
+package pcsrename
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// Renamer 根据文件信息生成新的文件名, n 为从 1 开始的序号
+	Renamer interface {
+		Rename(fd *baidupcs.FileDirectory, n int) (string, error)
+	}
+
+	// regexpRenamer s/正则/替换/标志
+	regexpRenamer struct {
+		re     *regexp.Regexp
+		repl   string
+		global bool
+	}
+
+	// templateRenamer 模板, 如 {mtime:20060102}_{name}
+	templateRenamer struct {
+		parts []templatePart
+	}
+
+	templatePart struct {
+		literal string
+		field   string
+		arg     string
+	}
+)
+
+var (
+	// ErrEmptyName 新文件名为空
+	ErrEmptyName = errors.New("新文件名为空")
+	// ErrInvalidName 新文件名含有路径分隔符
+	ErrInvalidName = errors.New("新文件名不能含有 /")
+
+	templateFieldRE = regexp.MustCompile(`\{([a-z]+)(?::([^}]*))?\}`)
+)
+
+// ParseRenamer 解析重命名表达式.
+// 以 s 和分隔符开头的为正则替换, 如 s/IMG_(\d+)/photo_$1/g, 标志 g 替换全部, i 忽略大小写;
+// 其他为模板, 支持 {name} {base} {ext} {dir} {size} {md5} {n} {n:宽度} {mtime:格式} {ctime:格式},
+// 时间格式使用 Go 的格式, 如 20060102.
+func ParseRenamer(expr string) (Renamer, error) {
+	if len(expr) >= 2 && expr[0] == 's' && !isWordChar(expr[1]) {
+		return parseRegexp(expr)
+	}
+	return parseTemplate(expr)
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '{' || c == '.' || c == '-' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c >= 0x80
+}
+
+// splitDelim 按分隔符拆分, 反斜杠转义的分隔符作为普通字符
+func splitDelim(s string, delim byte) (parts []string) {
+	builder := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == delim:
+			builder.WriteByte(delim)
+			i++
+		case s[i] == delim:
+			parts = append(parts, builder.String())
+			builder.Reset()
+		default:
+			builder.WriteByte(s[i])
+		}
+	}
+	return append(parts, builder.String())
+}
+
+func parseRegexp(expr string) (Renamer, error) {
+	parts := splitDelim(expr[2:], expr[1])
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("正则表达式格式错误: %s, 应为 s/正则/替换/标志", expr)
+	}
+
+	var (
+		pattern = parts[0]
+		r       = &regexpRenamer{repl: parts[1]}
+	)
+	for _, flag := range parts[2] {
+		switch flag {
+		case 'g':
+			r.global = true
+		case 'i':
+			pattern = "(?i)" + pattern
+		default:
+			return nil, fmt.Errorf("未知的正则标志: %c", flag)
+		}
+	}
+
+	var err error
+	r.re, err = regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *regexpRenamer) Rename(fd *baidupcs.FileDirectory, n int) (string, error) {
+	if r.global {
+		return r.re.ReplaceAllString(fd.Filename, r.repl), nil
+	}
+
+	loc := r.re.FindStringSubmatchIndex(fd.Filename)
+	if loc == nil {
+		return fd.Filename, nil
+	}
+	dst := r.re.ExpandString(nil, r.repl, fd.Filename, loc)
+	return fd.Filename[:loc[0]] + string(dst) + fd.Filename[loc[1]:], nil
+}
+
+func parseTemplate(expr string) (Renamer, error) {
+	var (
+		r    = &templateRenamer{}
+		last = 0
+	)
+	for _, loc := range templateFieldRE.FindAllStringSubmatchIndex(expr, -1) {
+		if loc[0] > last {
+			r.parts = append(r.parts, templatePart{literal: expr[last:loc[0]]})
+		}
+		part := templatePart{field: expr[loc[2]:loc[3]]}
+		if loc[4] >= 0 {
+			part.arg = expr[loc[4]:loc[5]]
+		}
+		switch part.field {
+		case "name", "base", "ext", "dir", "size", "md5":
+		case "n":
+			if part.arg != "" {
+				if _, err := strconv.Atoi(part.arg); err != nil {
+					return nil, fmt.Errorf("{n:%s}: 宽度应为数字", part.arg)
+				}
+			}
+		case "mtime", "ctime":
+			if part.arg == "" {
+				part.arg = "20060102"
+			}
+		default:
+			return nil, fmt.Errorf("未知的模板字段: {%s}", part.field)
+		}
+		r.parts = append(r.parts, part)
+		last = loc[1]
+	}
+	if last < len(expr) {
+		r.parts = append(r.parts, templatePart{literal: expr[last:]})
+	}
+	return r, nil
+}
+
+func (r *templateRenamer) Rename(fd *baidupcs.FileDirectory, n int) (string, error) {
+	builder := &strings.Builder{}
+	ext := path.Ext(fd.Filename)
+	if fd.Isdir {
+		ext = ""
+	}
+	for _, part := range r.parts {
+		switch part.field {
+		case "":
+			builder.WriteString(part.literal)
+		case "name":
+			builder.WriteString(fd.Filename)
+		case "base":
+			builder.WriteString(strings.TrimSuffix(fd.Filename, ext))
+		case "ext":
+			builder.WriteString(ext)
+		case "dir":
+			builder.WriteString(path.Base(path.Dir(fd.Path)))
+		case "size":
+			builder.WriteString(strconv.FormatInt(fd.Size, 10))
+		case "md5":
+			builder.WriteString(fd.MD5)
+		case "n":
+			s := strconv.Itoa(n)
+			if width, _ := strconv.Atoi(part.arg); len(s) < width {
+				s = strings.Repeat("0", width-len(s)) + s
+			}
+			builder.WriteString(s)
+		case "mtime":
+			builder.WriteString(time.Unix(fd.Mtime, 0).Format(part.arg))
+		case "ctime":
+			builder.WriteString(time.Unix(fd.Ctime, 0).Format(part.arg))
+		}
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,124 @@
+package pcsrename
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+func file(p string, mtime int64) *baidupcs.FileDirectory {
+	return &baidupcs.FileDirectory{
+		Path:     p,
+		Filename: path.Base(p),
+		Mtime:    mtime,
+		Size:     10,
+	}
+}
+
+func TestRenamer(t *testing.T) {
+	mtime := time.Date(2021, 3, 4, 12, 0, 0, 0, time.Local).Unix()
+	fd := file("/camera/IMG_0012_IMG_3.JPG", mtime)
+
+	cases := []struct {
+		expr     string
+		n        int
+		expected string
+	}{
+		{`s/IMG_(\d+)/photo_$1/`, 1, "photo_0012_IMG_3.JPG"},
+		{`s/IMG_(\d+)/photo_${1}x/g`, 1, "photo_0012x_photo_3x.JPG"},
+		{`s/img_/p/ig`, 1, "p0012_p3.JPG"},
+		{`s#\.JPG$#.jpg#`, 1, "IMG_0012_IMG_3.jpg"},
+		{`s/\//x/`, 1, "IMG_0012_IMG_3.JPG"},
+		{`s/nomatch/x/`, 1, "IMG_0012_IMG_3.JPG"},
+		{`{mtime:20060102}_{name}`, 1, "20210304_IMG_0012_IMG_3.JPG"},
+		{`{dir}_{n:03}{ext}`, 7, "camera_007.JPG"},
+		{`{base}-{n}-{size}`, 12, "IMG_0012_IMG_3-12-10"},
+		{`sample_{name}`, 1, "sample_IMG_0012_IMG_3.JPG"},
+	}
+	for _, c := range cases {
+		r, err := ParseRenamer(c.expr)
+		if err != nil {
+			t.Fatalf("%s: %s", c.expr, err)
+		}
+		name, err := r.Rename(fd, c.n)
+		if err != nil {
+			t.Fatalf("%s: %s", c.expr, err)
+		}
+		if name != c.expected {
+			t.Errorf("%s: got %s, expected %s", c.expr, name, c.expected)
+		}
+	}
+
+	for _, expr := range []string{`s/a/b`, `s/a/b/x`, `s/(/b/`, `{unknown}`, `{n:x}`} {
+		if _, err := ParseRenamer(expr); err == nil {
+			t.Errorf("%s: expected error", expr)
+		}
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	fdl := baidupcs.FileDirectoryList{
+		file("/a/IMG_1.jpg", 0),
+		file("/a/IMG_2.jpg", 0),
+		file("/a/IMG_01.jpg", 0),
+		file("/a/IMG_3.jpg", 0),
+		file("/a/other.jpg", 0),
+		file("/b/IMG_4.jpg", 0),
+	}
+	siblings := map[string][]string{
+		"/a": {"IMG_1.jpg", "IMG_2.jpg", "IMG_01.jpg", "IMG_3.jpg", "other.jpg", "photo_3.jpg"},
+		"/b": {"IMG_4.jpg"},
+	}
+
+	r, err := ParseRenamer(`s/IMG_0*(\d+)/photo_$1/`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := NewPlan(fdl, r, siblings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Unchanged != 1 || len(plan.Items) != 5 || plan.Conflicts() != 3 {
+		t.Fatalf("unexpected plan %+v, conflicts %d", plan, plan.Conflicts())
+	}
+	conflicts := map[string]bool{
+		"/a/IMG_1.jpg":  true, // 与 IMG_01.jpg 目标相同
+		"/a/IMG_2.jpg":  false,
+		"/a/IMG_01.jpg": true,
+		"/a/IMG_3.jpg":  true, // 目标已存在
+		"/b/IMG_4.jpg":  false,
+	}
+	for _, item := range plan.Items {
+		if (item.Conflict != "") != conflicts[item.From] {
+			t.Errorf("%s -> %s: unexpected conflict %q", item.From, item.To, item.Conflict)
+		}
+	}
+
+	// 交换文件名
+	r, _ = ParseRenamer(`{n}.jpg`)
+	plan, _ = NewPlan(baidupcs.FileDirectoryList{file("/c/2.jpg", 0), file("/c/1.jpg", 0)}, r, nil)
+	if plan.Conflicts() != 2 {
+		t.Errorf("expected swap conflicts, got %+v", plan.Items)
+	}
+
+	// 含有路径分隔符
+	r, _ = ParseRenamer(`s/_/\//`)
+	plan, _ = NewPlan(fdl[:1], r, nil)
+	if plan.Conflicts() != 1 || plan.Items[0].Conflict != ErrInvalidName.Error() {
+		t.Errorf("expected invalid name conflict, got %+v", plan.Items)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	list := baidupcs.CpMvJSONList{
+		{From: "/a/1", To: "/a/x"},
+		{From: "/a/2", To: "/a/y"},
+		{From: "/a/3", To: "/a/z"},
+	}
+	batches := Batches(list, 2)
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("unexpected batches %v", batches)
+	}
+}
@@ -0,0 +1,155 @@
+package pcsupload_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcstest"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsupload"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+)
+
+// partReader 实现 rio.ReaderLen64
+type partReader struct {
+	*bytes.Reader
+}
+
+func (pr partReader) Len() int64 {
+	return int64(pr.Reader.Len())
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// runUpload 使用上传任务单元上传本地文件, 返回任务状态
+func runUpload(t *testing.T, pcs *baidupcs.BaiduPCS, localPath, savePath, policy string) taskframework.TaskState {
+	db, err := pcsupload.NewUploadingDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	executor := &taskframework.TaskExecutor{}
+	info := executor.Append(&pcsupload.UploadTaskUnit{
+		LocalFileChecksum: checksum.NewLocalFileChecksum(localPath, int(baidupcs.SliceMD5Size)),
+		SavePath:          savePath,
+		PCS:               pcs,
+		UploadingDatabase: db,
+		Parallel:          2,
+		Policy:            policy,
+		UploadStatistic:   &pcsupload.UploadStatistic{},
+	}, 0)
+	executor.Execute()
+	return info.State()
+}
+
+func TestE2EUploadTaskUnit(t *testing.T) {
+	t.Setenv(pcsconfig.EnvConfigDir, t.TempDir())
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	// 超过一个分片
+	data := make([]byte, baidupcs.MinUploadBlockSize+100)
+	rand.New(rand.NewSource(1)).Read(data)
+	localPath := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(localPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if state := runUpload(t, pcs, localPath, "/upload/data.bin", baidupcs.OverWritePolicy); state != taskframework.TaskStateSucceeded {
+		t.Fatalf("upload: state %d", state)
+	}
+	if got, ok := srv.ReadFile("/upload/data.bin"); !ok || !bytes.Equal(got, data) {
+		t.Fatalf("upload: content mismatch")
+	}
+	fd, pcsError := pcs.FilesDirectoriesMeta("/upload/data.bin")
+	if pcsError != nil || fd.MD5 != md5Hex(data) {
+		t.Fatalf("meta: %v, %v", fd, pcsError)
+	}
+	parts := srv.Requests("pcs/superfile2/upload")
+	if parts < 2 {
+		t.Fatalf("superfile2 requests: %d", parts)
+	}
+
+	// 已存在相同的文件, 秒传
+	if state := runUpload(t, pcs, localPath, "/upload/copy.bin", baidupcs.OverWritePolicy); state != taskframework.TaskStateSucceeded {
+		t.Fatalf("rapid upload: state %d", state)
+	}
+	if got, ok := srv.ReadFile("/upload/copy.bin"); !ok || !bytes.Equal(got, data) || srv.Requests("pcs/superfile2/upload") != parts {
+		t.Fatalf("rapid upload: content mismatch or parts uploaded again")
+	}
+
+	// 分片上传失败后只重传失败的分片
+	data2 := make([]byte, len(data))
+	rand.New(rand.NewSource(2)).Read(data2)
+	localPath2 := filepath.Join(t.TempDir(), "data2.bin")
+	if err := os.WriteFile(localPath2, data2, 0644); err != nil {
+		t.Fatal(err)
+	}
+	before := srv.Requests("pcs/superfile2/upload")
+	srv.Fail("pcs/superfile2/upload", 31034, 1)
+	if state := runUpload(t, pcs, localPath2, "/upload/retry.bin", baidupcs.OverWritePolicy); state != taskframework.TaskStateSucceeded {
+		t.Fatalf("retry upload: state %d", state)
+	}
+	if got, ok := srv.ReadFile("/upload/retry.bin"); !ok || !bytes.Equal(got, data2) {
+		t.Fatalf("retry upload: content mismatch")
+	}
+	// 重试的次数与并发的时机有关, 至少每个分片一次, 加上失败的一次
+	if got := srv.Requests("pcs/superfile2/upload") - before; got < parts+1 {
+		t.Fatalf("retry upload: superfile2 requests %d, expected at least %d", got, parts+1)
+	}
+
+	// skip 策略不覆盖已存在的文件
+	srv.WriteFile("/upload/old.bin", []byte("old"))
+	runUpload(t, pcs, localPath, "/upload/old.bin", baidupcs.SkipPolicy)
+	if got, _ := srv.ReadFile("/upload/old.bin"); string(got) != "old" {
+		t.Fatalf("skip policy: file overwritten")
+	}
+}
+
+func TestE2ECreateSuperFileChecksumMismatch(t *testing.T) {
+	srv := pcstest.NewServer()
+	defer srv.Close()
+	pcs := srv.NewPCS()
+
+	data := make([]byte, 2048)
+	rand.New(rand.NewSource(3)).Read(data)
+	parts := [][]byte{data[:1024], data[1024:]}
+	blockList := []string{md5Hex(parts[0]), md5Hex(parts[1])}
+	pcsError, jsonData := pcs.RapidUpload("/bad.bin", baidupcs.OverWritePolicy, "", md5Hex(data), md5Hex(data[:1024]), "", "0", 0, 0, int64(len(data)), 0, blockList)
+	if pcsError != nil {
+		t.Fatalf("precreate: %s", pcsError)
+	}
+
+	pu := pcsupload.NewPCSUpload(pcs, "/bad.bin")
+	for seq, part := range parts {
+		if _, err := pu.TmpFile(context.Background(), jsonData.UploadID, "/bad.bin", seq, int64(seq*1024), partReader{bytes.NewReader(part)}); err != nil {
+			t.Fatalf("upload part %d: %s", seq, err)
+		}
+	}
+
+	// 分片 md5 与服务器收到的数据不一致
+	err := pu.CreateSuperFile(pcs.GetPCSAddr(), baidupcs.OverWritePolicy, jsonData.UploadID, int64(len(data)), map[int]string{
+		0: blockList[1],
+		1: blockList[0],
+	})
+	if pcsError, ok := err.(pcserror.Error); !ok || pcsError.GetRemoteErrCode() != 31363 {
+		t.Fatalf("create superfile: got %v, want 31363", err)
+	}
+	if srv.Exists("/bad.bin") {
+		t.Fatalf("create superfile: file should not exist")
+	}
+}
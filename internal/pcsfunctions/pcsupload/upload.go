@@ -69,7 +69,7 @@ func (pu *PCSUpload) TmpFile(ctx context.Context, uploadId, targetPath string, p
 		}()
 	}
 
-	checksum, pcsError := pu.pcs.UploadTmpFile(uploadId, targetPath, partSeq, partOffset, func(uploadURL string, jar http.CookieJar) (resp *http.Response, err error) {
+	checksum, pcsError := pu.pcs.UploadTmpFileContext(ctx, uploadId, targetPath, partSeq, partOffset, func(ctx context.Context, uploadURL string, jar http.CookieJar) (resp *http.Response, err error) {
 		client.SetCookiejar(jar)
 		client.SetTimeout(200 * time.Second)
 
@@ -77,27 +77,16 @@ func (pu *PCSUpload) TmpFile(ctx context.Context, uploadId, targetPath string, p
 		mr.AddFormFile("uploadedfile", "", r)
 		mr.CloseMultipart()
 
-		doneChan := make(chan struct{}, 1)
-		go func() {
-			resp, err = client.Req(http.MethodPost, uploadURL, mr, nil)
-			doneChan <- struct{}{}
-
-			if resp != nil {
-				// 不可恢复的错误
-				switch resp.StatusCode {
-				case 400, 401, 403, 413: // 4xx通常是由客户端非法操作引发，直接深度重试
-					respErr = &uploader.MultiError{
-						Terminated: true,
-					}
+		// 取消时中断请求
+		resp, err = client.ReqWithContext(ctx, http.MethodPost, uploadURL, mr, nil)
+		if resp != nil {
+			// 不可恢复的错误
+			switch resp.StatusCode {
+			case 400, 401, 403, 413: // 4xx通常是由客户端非法操作引发，直接深度重试
+				respErr = &uploader.MultiError{
+					Terminated: true,
 				}
 			}
-		}()
-		select {
-		case <-ctx.Done(): // 取消
-			// 返回, 让那边关闭连接
-			return resp, ctx.Err()
-		case <-doneChan:
-			// return
 		}
 		return
 	})
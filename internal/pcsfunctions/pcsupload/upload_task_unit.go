@@ -32,6 +32,7 @@ type (
 		PrintFormat       string
 
 		PCS               *baidupcs.BaiduPCS
+		Storage           baidupcs.Storage   // 非网盘的存储后端, 设置后直接写入该存储
 		UploadingDatabase *UploadingDatabase // 数据库
 		Parallel          int
 		NoRapidUpload     bool   // 禁用秒传
@@ -82,35 +83,35 @@ func (utu *UploadTaskUnit) prepareFile() {
 	//utu.state = utu.UploadingDatabase.Search(&utu.LocalFileChecksum.LocalFileMeta)
 	//if utu.state != nil || utu.LocalFileChecksum.LocalFileMeta.BlocksList != nil { // 读取到了md5分片信息
 	//	utu.Step = StepUploadRapidUpload
-	//	fmt.Printf("[%s] 检测到断点信息, 准备续传...\n", utu.taskInfo.Id())
+	//	utu.taskInfo.Printf("[%s] 检测到断点信息, 准备续传...\n", utu.taskInfo.Id())
 	//	return
 	//}
 	utu.state = &uploader.InstanceState{}
 
 	if utu.LocalFileChecksum.Length >= baidupcs.RecommendedUploadSize {
-		fmt.Printf("[%s] 文件超过32GB, 上传有可能失败, 建议分割文件...\n", utu.taskInfo.Id())
+		utu.taskInfo.Printf("[%s] 文件超过32GB, 上传有可能失败, 建议分割文件...\n", utu.taskInfo.Id())
 	}
 
 	if utu.LocalFileChecksum.Length > baidupcs.MinCheckLeftSpaceThreshold {
 		freeSpace, err := utu.PCS.SpaceLeftInfo()
 		if err == nil && freeSpace < utu.LocalFileChecksum.Length {
-			fmt.Printf("[%s] 目标文件大小超过剩余空间, 跳过...\n", utu.taskInfo.Id())
+			utu.taskInfo.Printf("[%s] 目标文件大小超过剩余空间, 跳过...\n", utu.taskInfo.Id())
 			utu.Step = JustGoon
 			return
 		}
 	}
 
 	if utu.NoRapidUpload {
-		//fmt.Printf("[%s] 注意: 跳过秒传将无法使用断点续传...\n", utu.taskInfo.Id())
+		//utu.taskInfo.Printf("[%s] 注意: 跳过秒传将无法使用断点续传...\n", utu.taskInfo.Id())
 		pcsError, jsonData := utu.PCS.FakeRapidUpload(utu.SavePath, utu.Policy, utu.LocalFileChecksum.Length)
 		if pcsError != nil {
 			errcode := pcsError.GetRemoteErrCode()
 			if errcode != 114514 && errcode != 1919810 {
-				fmt.Printf("[%s] 跳过秒传失败, 开始秒传...\n", utu.taskInfo.Id())
+				utu.taskInfo.Printf("[%s] 跳过秒传失败, 开始秒传...\n", utu.taskInfo.Id())
 				utu.Step = StepUploadRapidUpload
 				return
 			} else {
-				fmt.Printf("[%s] 目标文件已存在, 跳过...\n", utu.taskInfo.Id())
+				utu.taskInfo.Printf("[%s] 目标文件已存在, 跳过...\n", utu.taskInfo.Id())
 				utu.Step = JustGoon
 				return
 			}
@@ -157,7 +158,7 @@ func (utu *UploadTaskUnit) rapidUpload() (isContinue bool, result *taskframework
 		}
 	}
 
-	fmt.Printf("[%s] 开始计算文件元信息, 请稍候...\n", utu.taskInfo.Id())
+	utu.taskInfo.Printf("[%s] 开始计算文件元信息, 请稍候...\n", utu.taskInfo.Id())
 
 	// 经测试, 文件的 crc32 值并非秒传文件所必需
 	if utu.LocalFileChecksum.LocalFileMeta.MD5 == nil || utu.LocalFileChecksum.LocalFileMeta.SliceMD5 == nil {
@@ -177,7 +178,8 @@ func (utu *UploadTaskUnit) rapidUpload() (isContinue bool, result *taskframework
 				decodedMD5, _ := hex.DecodeString(fd.MD5)
 				// TODO: fd.MD5 有可能是错误的
 				if (utu.Policy == baidupcs.SkipPolicy) || (bytes.Compare(decodedMD5, utu.LocalFileChecksum.MD5) == 0) {
-					fmt.Printf("[%s] 目标文件, %s, 已存在, 跳过...\n", utu.taskInfo.Id(), utu.SavePath)
+					utu.taskInfo.Printf("[%s] 目标文件, %s, 已存在, 跳过...\n", utu.taskInfo.Id(), utu.SavePath)
+					result.Extra = baidupcs.SkipPolicy
 					result.Succeed = true // 成功
 					return
 				}
@@ -208,7 +210,7 @@ func (utu *UploadTaskUnit) rapidUpload() (isContinue bool, result *taskframework
 
 	blockSize := getBlockSize(utu.LocalFileChecksum.Length)
 
-	fmt.Printf("[%s] 开始计算文件分块md5, 请稍候...\n", utu.taskInfo.Id())
+	utu.taskInfo.Printf("[%s] 开始计算文件分块md5, 请稍候...\n", utu.taskInfo.Id())
 	if utu.LocalFileChecksum.LocalFileMeta.BlocksList == nil || len(utu.LocalFileChecksum.LocalFileMeta.BlocksList) == 0 {
 		err = utu.LocalFileChecksum.CalculateChunkedSum(blockSize)
 		if err != nil {
@@ -224,7 +226,7 @@ func (utu *UploadTaskUnit) rapidUpload() (isContinue bool, result *taskframework
 		offset, dataLength, utu.LocalFileChecksum.Length, currentTime, utu.LocalFileChecksum.BlocksList)
 	if pcsError == nil {
 		if jsonData.ReturnType == 2 {
-			fmt.Printf("[%s] 秒传成功, 保存到网盘路径: %s\n\n", utu.taskInfo.Id(), utu.SavePath)
+			utu.taskInfo.Printf("[%s] 秒传成功, 保存到网盘路径: %s\n\n", utu.taskInfo.Id(), utu.SavePath)
 			// 统计
 			utu.UploadStatistic.AddTotalSize(utu.LocalFileChecksum.Length)
 			result.Succeed = true // 成功
@@ -261,7 +263,7 @@ func (utu *UploadTaskUnit) rapidUpload() (isContinue bool, result *taskframework
 		return
 	}
 
-	fmt.Printf("[%s] 开始上传文件...\n\n", utu.taskInfo.Id())
+	utu.taskInfo.Printf("[%s] 开始上传文件...\n\n", utu.taskInfo.Id())
 
 	// 保存秒传信息
 	if utu.state.Uploadid == "" {
@@ -303,19 +305,19 @@ func (utu *UploadTaskUnit) upload() (result *taskframework.TaskUnitRunResult) {
 		default:
 		}
 
-		fmt.Printf(utu.PrintFormat, utu.taskInfo.Id(),
+		utu.taskInfo.ReportStatus(status.Uploaded(), status.TotalSize(), fmt.Sprintf(utu.PrintFormat, utu.taskInfo.Id(),
 			converter.ConvertFileSize(status.Uploaded(), 2),
 			converter.ConvertFileSize(status.TotalSize(), 2),
 			converter.ConvertFileSize(status.SpeedsPerSecond(), 2),
 			status.TimeElapsed(),
-		)
+		))
 	})
 
 	// result
 	result = &taskframework.TaskUnitRunResult{}
 	muer.OnSuccess(func() {
-		fmt.Printf("\n")
-		fmt.Printf("[%s] 上传文件成功, 保存到网盘路径: %s\n", utu.taskInfo.Id(), utu.SavePath)
+		utu.taskInfo.Printf("\n")
+		utu.taskInfo.Printf("[%s] 上传文件成功, 保存到网盘路径: %s\n", utu.taskInfo.Id(), utu.SavePath)
 		// 统计
 		utu.UploadStatistic.AddTotalSize(utu.LocalFileChecksum.Length)
 		utu.UploadingDatabase.Delete(&utu.LocalFileChecksum.LocalFileMeta) // 删除
@@ -379,6 +381,7 @@ func (utu *UploadTaskUnit) upload() (result *taskframework.TaskUnitRunResult) {
 			default:
 				result.ResultMessage = StrUploadFailed
 				result.Err = pcsError
+				result.NeedRetry = pcsfunctions.NeedRetry(utu.taskInfo, pcsError)
 			}
 		case pcserror.ErrTypeNetError:
 			// 网络错误
@@ -390,6 +393,7 @@ func (utu *UploadTaskUnit) upload() (result *taskframework.TaskUnitRunResult) {
 				result.NeedRetry = false
 				return
 			}
+			result.NeedRetry = pcsfunctions.NeedRetry(utu.taskInfo, pcsError)
 		default:
 			result.ResultMessage = StrUploadFailed
 			result.NeedRetry = false
@@ -402,14 +406,50 @@ func (utu *UploadTaskUnit) upload() (result *taskframework.TaskUnitRunResult) {
 	return
 }
 
+// storageUpload 直接写入存储后端
+func (utu *UploadTaskUnit) storageUpload() (result *taskframework.TaskUnitRunResult) {
+	result = &taskframework.TaskUnitRunResult{}
+
+	// 与网盘相同的重名文件策略
+	if fd, pcsError := utu.Storage.FilesDirectoriesMeta(utu.SavePath); pcsError == nil {
+		switch {
+		case fd.Isdir:
+			result.ResultMessage = StrUploadFailed
+			result.Err = errors.New("保存路径不可以覆盖目录")
+			return
+		case utu.Policy == baidupcs.SkipPolicy:
+			result.Extra = baidupcs.SkipPolicy
+			result.ResultMessage = fmt.Sprintf("%s 目标已存在, 跳过", utu.SavePath)
+			return
+		case utu.Policy == baidupcs.RsyncPolicy && fd.Size == utu.LocalFileChecksum.Length:
+			result.Extra = baidupcs.RsyncPolicy
+			result.ResultMessage = fmt.Sprintf("%s 目标大小未发生改变, 跳过", utu.SavePath)
+			return
+		}
+	}
+
+	pcsError := utu.Storage.WriteFile(utu.SavePath, utu.LocalFileChecksum.GetFile(), utu.LocalFileChecksum.Length)
+	if pcsError != nil {
+		result.ResultMessage = StrUploadFailed
+		result.Err = pcsError
+		result.NeedRetry = pcsfunctions.NeedRetry(utu.taskInfo, pcsError)
+		return
+	}
+	utu.taskInfo.ReportProgress(utu.LocalFileChecksum.Length, utu.LocalFileChecksum.Length)
+	utu.UploadStatistic.AddTotalSize(utu.LocalFileChecksum.Length)
+	utu.taskInfo.Printf("[%s] 上传文件成功, 保存到网盘路径: %s\n", utu.taskInfo.Id(), utu.SavePath)
+	result.Succeed = true
+	return
+}
+
 func (utu *UploadTaskUnit) OnRetry(lastRunResult *taskframework.TaskUnitRunResult) {
 	// 输出错误信息
 	if lastRunResult.Err == nil {
 		// result中不包含Err, 忽略输出
-		fmt.Printf("[%s] %s, 重试 %d/%d\n", utu.taskInfo.Id(), lastRunResult.ResultMessage, utu.taskInfo.Retry(), utu.taskInfo.MaxRetry())
+		utu.taskInfo.Printf("[%s] %s, 重试 %d/%d\n", utu.taskInfo.Id(), lastRunResult.ResultMessage, utu.taskInfo.Retry(), utu.taskInfo.MaxRetry())
 		return
 	}
-	fmt.Printf("[%s] %s, %s, 重试 %d/%d\n", utu.taskInfo.Id(), lastRunResult.ResultMessage, lastRunResult.Err, utu.taskInfo.Retry(), utu.taskInfo.MaxRetry())
+	utu.taskInfo.Printf("[%s] %s, %s, 重试 %d/%d\n", utu.taskInfo.Id(), lastRunResult.ResultMessage, lastRunResult.Err, utu.taskInfo.Retry(), utu.taskInfo.MaxRetry())
 }
 
 func (utu *UploadTaskUnit) OnSuccess(lastRunResult *taskframework.TaskUnitRunResult) {
@@ -419,34 +459,38 @@ func (utu *UploadTaskUnit) OnFailed(lastRunResult *taskframework.TaskUnitRunResu
 	// 失败
 	if lastRunResult.Err == nil {
 		// result中不包含Err, 忽略输出
-		fmt.Printf("[%s] %s\n", utu.taskInfo.Id(), lastRunResult.ResultMessage)
+		utu.taskInfo.Printf("[%s] %s\n", utu.taskInfo.Id(), lastRunResult.ResultMessage)
 		return
 	}
-	fmt.Printf("[%s] %s, %s\n", utu.taskInfo.Id(), lastRunResult.ResultMessage, lastRunResult.Err)
+	utu.taskInfo.Printf("[%s] %s, %s\n", utu.taskInfo.Id(), lastRunResult.ResultMessage, lastRunResult.Err)
 }
 
 func (utu *UploadTaskUnit) OnComplete(lastRunResult *taskframework.TaskUnitRunResult) {
 }
 
 func (utu *UploadTaskUnit) RetryWait() time.Duration {
-	return pcsfunctions.RetryWait(utu.taskInfo.Retry())
+	return pcsfunctions.RetryWait(utu.taskInfo)
 }
 
 func (utu *UploadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
-	fmt.Printf("[%s] 准备上传: %s\n", utu.taskInfo.Id(), utu.LocalFileChecksum.Path)
+	utu.taskInfo.Printf("[%s] 准备上传: %s\n", utu.taskInfo.Id(), utu.LocalFileChecksum.Path)
 
 	if utu.LocalFileChecksum.Length > baidupcs.MaxUploadSize {
-		fmt.Printf("[%s] 文件大小超过128G, 无法上传, 跳过...\n", utu.taskInfo.Id())
+		utu.taskInfo.Printf("[%s] 文件大小超过128G, 无法上传, 跳过...\n", utu.taskInfo.Id())
 		return
 	}
 
 	err := utu.LocalFileChecksum.OpenPath()
 	if err != nil {
-		fmt.Printf("[%s] 文件不可读, 错误信息: %s, 跳过...\n", utu.taskInfo.Id(), err)
+		utu.taskInfo.Printf("[%s] 文件不可读, 错误信息: %s, 跳过...\n", utu.taskInfo.Id(), err)
 		return
 	}
 	defer utu.LocalFileChecksum.Close() // 关闭文件
 
+	if utu.Storage != nil {
+		return utu.storageUpload()
+	}
+
 	// 准备文件
 	utu.prepareFile()
 
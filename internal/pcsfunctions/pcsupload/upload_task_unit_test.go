@@ -0,0 +1,54 @@
+package pcsupload_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/storage"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsupload"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+)
+
+func TestStorageUploadPolicy(t *testing.T) {
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已存在的文件内容为 old, 与本地文件大小相同
+	for policy, expected := range map[string]string{
+		baidupcs.SkipPolicy:      "old",
+		baidupcs.RsyncPolicy:     "old",
+		baidupcs.OverWritePolicy: "new",
+	} {
+		s := storage.NewMemory()
+		if pcsError := s.WriteFile("/a.txt", strings.NewReader("old"), 3); pcsError != nil {
+			t.Fatal(pcsError)
+		}
+
+		executor := &taskframework.TaskExecutor{}
+		executor.Append(&pcsupload.UploadTaskUnit{
+			LocalFileChecksum: checksum.NewLocalFileChecksum(localPath, int(baidupcs.SliceMD5Size)),
+			SavePath:          "/a.txt",
+			Storage:           s,
+			Policy:            policy,
+			UploadStatistic:   &pcsupload.UploadStatistic{},
+		}, 0)
+		executor.Execute()
+
+		rc, pcsError := s.OpenRange("/a.txt", 0, -1)
+		if pcsError != nil {
+			t.Fatal(pcsError)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(data) != expected {
+			t.Errorf("%s: got %q, expected %q", policy, data, expected)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/olekukonko/tablewriter"
@@ -122,6 +123,29 @@ func main() {
 			EnvVar:      pcsverbose.EnvVerbose,
 			Destination: &pcsverbose.IsVerbose,
 		},
+		cli.BoolFlag{
+			Name:   "offline",
+			Usage:  "离线模式, 从本地索引列出文件",
+			EnvVar: "BAIDUPCS_GO_OFFLINE",
+		},
+		cli.StringFlag{
+			Name:   "storage",
+			Usage:  "使用其他存储后端代替网盘, 用于测试或整理本地文件, 可选值: memory (内存), local:<本地目录>",
+			EnvVar: "BAIDUPCS_GO_STORAGE",
+		},
+	}
+	app.Before = func(c *cli.Context) error {
+		// 交互模式下每条命令都会重新解析全局选项, 只在指定时开启
+		if c.GlobalBool("offline") {
+			pcscommand.Offline = true
+		}
+		if c.GlobalIsSet("storage") {
+			if err := pcscommand.UseStorage(c.GlobalString("storage")); err != nil {
+				fmt.Println(err)
+				return err
+			}
+		}
+		return nil
 	}
 	app.Action = func(c *cli.Context) {
 		if c.NArg() != 0 {
@@ -189,7 +213,6 @@ func main() {
 
 			var (
 				activeUser  = pcsconfig.Config.ActiveUser()
-				pcs         = pcsconfig.Config.ActiveUserBaiduPCS()
 				runeFunc    = unicode.IsSpace
 				pcsRuneFunc = func(r rune) bool {
 					switch r {
@@ -231,7 +254,7 @@ func main() {
 					targetDir = path.Dir(targetDir)
 				}
 			}
-			files, err := pcs.CacheFilesDirectoriesList(targetDir, baidupcs.DefaultOrderOptions)
+			files, err := pcscommand.CompleteList(targetDir)
 			if err != nil {
 				return
 			}
@@ -325,6 +348,53 @@ func main() {
 		}
 	}
 
+	// 回收站筛选条件
+	recycleFilterFlags := []cli.Flag{
+		cli.StringFlag{
+			Name:  "path",
+			Usage: "原路径的通配符, 支持 ** 和 {a,b}",
+		},
+		cli.StringFlag{
+			Name:  "since",
+			Usage: "删除日期不早于, 格式: 2006-01-02",
+		},
+		cli.StringFlag{
+			Name:  "until",
+			Usage: "删除日期不晚于, 格式: 2006-01-02",
+		},
+		cli.StringFlag{
+			Name:  "minsize",
+			Usage: "最小文件大小, 如 100MB",
+		},
+		cli.StringFlag{
+			Name:  "maxsize",
+			Usage: "最大文件大小, 如 1GB",
+		},
+		cli.StringFlag{
+			Name:  "type",
+			Usage: "类型, 可选值: file, dir",
+		},
+	}
+	recycleOptions := func(c *cli.Context) (opt *pcscommand.RecycleOptions, ok bool) {
+		for _, name := range []string{"path", "since", "until", "minsize", "maxsize", "type"} {
+			ok = ok || c.IsSet(name)
+		}
+		if !ok {
+			return nil, false
+		}
+
+		f, err := pcscommand.NewRecycleFilter(c.String("path"), c.String("since"), c.String("until"), c.String("minsize"), c.String("maxsize"), c.String("type"))
+		if err != nil {
+			fmt.Println(err)
+			return nil, true
+		}
+		return &pcscommand.RecycleOptions{
+			Filter: *f,
+			DryRun: c.Bool("dry-run"),
+			Yes:    c.Bool("y"),
+		}, true
+	}
+
 	app.Commands = []cli.Command{
 		{
 			Name:     "run",
@@ -659,6 +729,73 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:      "du",
+			Usage:     "统计目录的空间占用",
+			UsageText: app.Name + " du [-d 深度] [--top N] [--by ext|category] [-i] [目录]",
+			Description: `
+	递归统计目录及其子目录的大小和文件数, 默认统计当前工作目录.
+	统计结果会缓存到配置目录下的 cache 目录, 再次统计同一目录时直接读取缓存, 使用 --refresh 重新统计.
+	使用 --storage 指定其他存储后端时不使用缓存.
+	rm, mv, 上传等修改操作会删除相关目录的统计缓存, 缓存时间默认为 24 小时, 可通过 config set -disk_cache_ttl du=1h 修改.
+
+	使用 -i 进入交互式浏览, 可逐级进入目录, 排序, 标记并删除文件/目录.
+
+	示例:
+
+	1. 统计 /我的资源 目录, 显示两层子目录
+	BaiduPCS-Go du -d 2 /我的资源
+
+	2. 显示根目录中占用最大的 20 个目录
+	BaiduPCS-Go du -d -1 --top 20 /
+
+	3. 按文件类型统计根目录的空间占用
+	BaiduPCS-Go du --by category /
+
+	4. 交互式浏览根目录的空间占用
+	BaiduPCS-Go du -i /
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				dir := "."
+				if c.NArg() > 0 {
+					dir = c.Args().Get(0)
+				}
+
+				pcscommand.RunDu(dir, &pcscommand.DuOptions{
+					Depth:       c.Int("d"),
+					Top:         c.Int("top"),
+					By:          c.String("by"),
+					Interactive: c.Bool("i"),
+					Refresh:     c.Bool("refresh"),
+				})
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "d",
+					Usage: "显示的目录深度, 小于 0 时不限制",
+					Value: 1,
+				},
+				cli.IntFlag{
+					Name:  "top",
+					Usage: "只显示占用最大的 N 条",
+				},
+				cli.StringFlag{
+					Name:  "by",
+					Usage: "按扩展名 (ext) 或文件类型 (category) 统计",
+				},
+				cli.BoolFlag{
+					Name:  "i",
+					Usage: "交互式浏览",
+				},
+				cli.BoolFlag{
+					Name:  "refresh",
+					Usage: "忽略缓存, 重新统计",
+				},
+			},
+		},
 		{
 			Name:     "cd",
 			Category: "百度网盘",
@@ -835,6 +972,73 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:      "find",
+			Usage:     "按条件查找文件和目录",
+			UsageText: app.Name + " find [目录] [表达式]",
+			Description: `
+	递归查找目录中匹配条件的文件和目录, 用法与 Unix 的 find 类似.
+	默认在当前工作目录查找, 结果包括目录本身.
+
+	条件:
+	-name <通配符>      文件名匹配通配符, 如 "*.mp4"
+	-iname <通配符>     同 -name, 忽略大小写
+	-regex <正则>       完整路径匹配正则表达式
+	-size [+-]N[KMGT]   文件大小, +N 为大于, -N 为小于, 不带单位时为字节
+	-mtime [+-]N        修改时间距今的天数, +N 为大于, -N 为小于
+	-type f|d           文件或目录
+	-md5 <md5>          文件的 md5
+	-empty              空文件或空目录
+	-and, -a            与, 条件之间默认为与
+	-or, -o             或
+	-not, !             非
+	( )                 分组
+
+	操作 (未指定时默认为 -print):
+	-print              输出路径
+	-print0             输出路径, 以 \0 分隔
+	-json               以 json 格式输出, 每行一条
+	-delete             删除, 可在网盘文件回收站找回
+	-download <目录>    下载到本地目录
+	-move <目录>        移动到网盘目录
+
+	条件只能匹配文件 (含有 -type f, -size 或 -md5) 且含有 -name 时,
+	会先使用服务器搜索预先筛选, 使用 -nosearch 可禁用.
+
+	示例:
+
+	1. 查找 /我的资源 目录中大于 1GB 的 mp4 文件
+	BaiduPCS-Go find /我的资源 -name "*.mp4" -size +1G
+
+	2. 查找当前工作目录中 7 天内修改过的文件, 以 json 格式输出
+	BaiduPCS-Go find -type f -mtime -7 -json
+
+	3. 删除 /logs 目录中的空目录
+	BaiduPCS-Go find /logs -type d -empty -delete
+
+	4. 把 /photos 目录中的 jpg 或 png 文件移动到 /图片
+	BaiduPCS-Go find /photos ( -iname "*.jpg" -or -iname "*.png" ) -move /图片
+`,
+			Category:        "百度网盘",
+			Before:          reloadFn,
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) error {
+				var (
+					dir  = "."
+					args = c.Args()
+				)
+				if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+				if len(args) > 0 && !strings.HasPrefix(args[0], "-") && args[0] != "(" && args[0] != "!" {
+					dir, args = args[0], args[1:]
+				}
+
+				pcscommand.RunFind(dir, args)
+				return nil
+			},
+		},
 		{
 			Name:      "tree",
 			Aliases:   []string{"t"},
@@ -905,116 +1109,940 @@ func main() {
 					as = ca
 				}
 
-				pcscommand.RunGetMeta(as...)
+				pcscommand.RunGetMeta(as...)
+				return nil
+			},
+		},
+		{
+			Name:      "cat",
+			Usage:     "输出文件内容",
+			UsageText: app.Name + " cat <文件1> <文件2> ...",
+			Description: `
+	通过下载链接读取网盘文件并输出, 不保存到本地.
+
+	示例:
+
+	1. 输出 /我的资源/config.ini
+	BaiduPCS-Go cat /我的资源/config.ini
+
+	2. 解压并输出 .gz 文件
+	BaiduPCS-Go cat -z /logs/app.log.gz
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+				pcscommand.RunCat(c.Args(), &pcscommand.CatOptions{
+					Gunzip: c.Bool("z"),
+				})
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "z",
+					Usage: "解压 .gz 文件",
+				},
+			},
+		},
+		{
+			Name:      "head",
+			Usage:     "输出文件开头的部分",
+			UsageText: app.Name + " head [-n 行数 | -c 大小] <文件1> <文件2> ...",
+			Description: `
+	按范围读取网盘文件开头的部分, 只下载需要的数据. 多个文件时输出文件名.
+
+	示例:
+
+	1. 输出 /logs/app.log 的前 10 行
+	BaiduPCS-Go head /logs/app.log
+
+	2. 输出前 100 行
+	BaiduPCS-Go head -n 100 /logs/app.log
+
+	3. 输出前 1KB
+	BaiduPCS-Go head -c 1KB /logs/app.log
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+				opt := &pcscommand.CatOptions{
+					Lines:  c.Int("n"),
+					Gunzip: c.Bool("z"),
+				}
+				if c.IsSet("c") {
+					size, err := converter.ParseFileSizeStr(c.String("c"))
+					if err != nil {
+						fmt.Printf("解析 -c 错误: %s\n", err)
+						return nil
+					}
+					opt.Bytes = size
+				}
+				pcscommand.RunHead(c.Args(), opt)
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "n",
+					Usage: "输出的行数",
+					Value: 10,
+				},
+				cli.StringFlag{
+					Name:  "c",
+					Usage: "输出的大小, 如 100, 1KB, 设置后忽略 -n",
+				},
+				cli.BoolFlag{
+					Name:  "z",
+					Usage: "解压 .gz 文件",
+				},
+			},
+		},
+		{
+			Name:      "tail",
+			Usage:     "输出文件结尾的部分",
+			UsageText: app.Name + " tail [-n 行数 | -c 大小] [-f] <文件1> <文件2> ...",
+			Description: `
+	从文件末尾按范围读取网盘文件, 只下载需要的数据. 多个文件时输出文件名.
+	解压 .gz 文件时需要读取整个文件.
+
+	示例:
+
+	1. 输出 /logs/app.log 的最后 10 行
+	BaiduPCS-Go tail /logs/app.log
+
+	2. 输出最后 1KB
+	BaiduPCS-Go tail -c 1KB /logs/app.log
+
+	3. 输出最后 20 行, 之后每 10 秒检查一次, 输出文件新增的内容, 按 Ctrl+C 退出
+	BaiduPCS-Go tail -n 20 -f --interval 10 /logs/app.log
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+				opt := &pcscommand.CatOptions{
+					Lines:  c.Int("n"),
+					Gunzip: c.Bool("z"),
+				}
+				if c.IsSet("c") {
+					size, err := converter.ParseFileSizeStr(c.String("c"))
+					if err != nil {
+						fmt.Printf("解析 -c 错误: %s\n", err)
+						return nil
+					}
+					opt.Bytes = size
+				}
+				opt.Follow = c.Bool("f")
+				opt.Interval = time.Duration(c.Int("interval")) * time.Second
+				pcscommand.RunTail(c.Args(), opt)
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "n",
+					Usage: "输出的行数",
+					Value: 10,
+				},
+				cli.StringFlag{
+					Name:  "c",
+					Usage: "输出的大小, 如 100, 1KB, 设置后忽略 -n",
+				},
+				cli.BoolFlag{
+					Name:  "z",
+					Usage: "解压 .gz 文件",
+				},
+				cli.BoolFlag{
+					Name:  "f",
+					Usage: "持续输出文件新增的内容",
+				},
+				cli.IntFlag{
+					Name:  "interval",
+					Usage: "使用 -f 时检查文件是否增长的间隔, 单位: 秒",
+					Value: 5,
+				},
+			},
+		},
+		{
+			Name:      "hash",
+			Usage:     "计算网盘文件的 sha256, sha1, md5, crc32",
+			UsageText: app.Name + " hash [--algo sha256,sha1,md5,crc32] [-p 连接数] [-o 文件] <文件/目录1> <文件/目录2> ...",
+			Description: `
+	使用多个连接按顺序下载网盘文件, 直接计算摘要, 不保存到本地. 目录会递归计算其中的所有文件.
+	输出的路径相对于参数中的目录, 参数为文件时只输出文件名, 在下载到本地的对应目录中可以直接校验.
+	只指定一种算法时输出格式与 sha256sum 等工具相同, 可以使用 sha256sum -c 校验本地文件;
+	指定多种算法时使用 BSD 风格输出, 如 "SHA256 (文件) = 摘要", 可以使用 sha256sum -c 等校验.
+	crc32 没有可以按此格式校验的标准工具 (cksum 使用的是另一种 crc), 只用于显示, 可以使用 verify 校验.
+	连接数默认使用 max_parallel 的设置.
+
+	示例:
+
+	1. 计算 /我的资源/1.mp4 的 sha256
+	BaiduPCS-Go hash /我的资源/1.mp4
+
+	2. 计算 /我的资源 目录中所有文件的 sha1 和 md5, 保存到本地文件 sums.txt, 在本地的 我的资源 目录中校验
+	BaiduPCS-Go hash --algo sha1,md5 -o sums.txt /我的资源
+	cd 我的资源 && sha1sum -c --ignore-missing ../sums.txt
+
+	3. 使用 4 个连接计算 crc32
+	BaiduPCS-Go hash --algo crc32 -p 4 /我的资源/1.mp4
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+				algos, err := checksum.ParseAlgorithms(c.String("algo"))
+				if err != nil {
+					fmt.Println(err)
+					return nil
+				}
+				pcscommand.RunHash(c.Args(), &pcscommand.HashOptions{
+					Algorithms: algos,
+					Parallel:   c.Int("p"),
+					Output:     c.String("o"),
+				})
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "algo",
+					Usage: "摘要算法, 多个算法用逗号分隔, 可选: sha256, sha1, md5, crc32",
+					Value: "sha256",
+				},
+				cli.IntFlag{
+					Name:  "p",
+					Usage: "同时下载的连接数, 默认使用 max_parallel",
+				},
+				cli.StringFlag{
+					Name:  "o",
+					Usage: "输出到本地文件, 默认输出到终端",
+				},
+			},
+		},
+		{
+			Name:      "rm",
+			Usage:     "删除文件/目录",
+			UsageText: app.Name + " rm <文件/目录的路径1> <文件/目录2> <文件/目录3> ...",
+			Description: `
+	注意: 删除多个文件和目录时, 请确保每一个文件和目录都存在, 否则删除操作会失败.
+	被删除的文件或目录可在网盘文件回收站找回.
+
+	示例:
+
+	删除 /我的资源/1.mp4
+	BaiduPCS-Go rm /我的资源/1.mp4
+
+	删除 /我的资源/1.mp4 和 /我的资源/2.mp4
+	BaiduPCS-Go rm /我的资源/1.mp4 /我的资源/2.mp4
+
+	删除 /我的资源 内的所有文件和目录, 但不删除该目录
+	BaiduPCS-Go rm /我的资源/*
+
+	删除 /我的资源 整个目录 !!
+	BaiduPCS-Go rm /我的资源
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+
+				pcscommand.RunRemove(c.Args()...)
+				return nil
+			},
+		},
+		{
+			Name:      "dedupe",
+			Usage:     "查找重复文件",
+			UsageText: app.Name + " dedupe [--keep <保留策略>] [--delete | --move <隔离目录>] [--dry-run] [--fixmd5] [目录1] [目录2] ...",
+			Description: `
+	按文件大小和 md5 查找重复文件, 输出每组重复文件和可释放的空间, 默认在当前工作目录查找.
+	md5 可能不正确的文件 (分片上传的文件) 默认跳过, 使用 --fixmd5 先尝试修复这些文件的 md5.
+	修复 md5 会在网盘中重新保存这些文件, 使用 --dry-run 时不会修复.
+
+	指定保留策略后, 可删除多余的文件, 或移动到隔离目录 (保留原来的路径结构).
+	保留策略:
+	oldest              保留最早创建的文件
+	newest              保留最新创建的文件
+	shortest-path       保留路径最短的文件
+	first-in-dir <目录> 优先保留该目录中的文件, 目录中没有时保留最早创建的文件
+
+	示例:
+
+	1. 查找 /我的资源 和 /备份 中的重复文件
+	BaiduPCS-Go dedupe /我的资源 /备份
+
+	2. 查找根目录中大于 100MB 的重复文件, 优先保留 /我的资源 中的文件, 把多余的文件移动到 /重复文件
+	BaiduPCS-Go dedupe --minsize 100MB --keep "first-in-dir /我的资源" --move /重复文件 /
+
+	3. 预览删除操作, 保留最早创建的文件
+	BaiduPCS-Go dedupe --keep oldest --delete --dry-run /
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				var minSize int64
+				if c.IsSet("minsize") {
+					var err error
+					minSize, err = converter.ParseFileSizeStr(c.String("minsize"))
+					if err != nil {
+						fmt.Printf("文件大小格式错误, %s\n", err)
+						return nil
+					}
+				}
+
+				pcscommand.RunDedupe(c.Args(), &pcscommand.DedupeOptions{
+					Keep:       c.String("keep"),
+					Quarantine: c.String("move"),
+					Delete:     c.Bool("delete"),
+					DryRun:     c.Bool("dry-run"),
+					FixMD5:     c.Bool("fixmd5"),
+					MinSize:    minSize,
+				})
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "keep",
+					Usage: "保留策略: oldest, newest, shortest-path, first-in-dir <目录>",
+				},
+				cli.StringFlag{
+					Name:  "move",
+					Usage: "把多余的文件移动到该目录",
+				},
+				cli.BoolFlag{
+					Name:  "delete",
+					Usage: "删除多余的文件",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "只输出将要执行的操作",
+				},
+				cli.BoolFlag{
+					Name:  "fixmd5",
+					Usage: "修复可能不正确的 md5, 默认跳过这些文件",
+				},
+				cli.StringFlag{
+					Name:  "minsize",
+					Usage: "忽略小于该大小的文件, 如 1MB",
+				},
+			},
+		},
+		{
+			Name:      "mkdir",
+			Usage:     "创建目录",
+			UsageText: app.Name + " mkdir <目录>",
+			Category:  "百度网盘",
+			Before:    reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+
+				pcscommand.RunMkdir(c.Args().Get(0))
+				return nil
+			},
+		},
+		{
+			Name:  "cp",
+			Usage: "拷贝文件/目录",
+			UsageText: `BaiduPCS-Go cp <文件/目录> <目标文件/目录>
+	BaiduPCS-Go cp <文件/目录1> <文件/目录2> <文件/目录3> ... <目标目录>`,
+			Description: `
+	注意: 拷贝多个文件和目录时, 请确保每一个文件和目录都存在, 否则拷贝操作会失败.
+
+	示例:
+
+	将 /我的资源/1.mp4 复制到 根目录 /
+	BaiduPCS-Go cp /我的资源/1.mp4 /
+
+	将 /我的资源/1.mp4 和 /我的资源/2.mp4 复制到 根目录 /
+	BaiduPCS-Go cp /我的资源/1.mp4 /我的资源/2.mp4 /
+
+	覆盖 / 下已存在的 1.mp4, 被覆盖的文件移到回收站, 可使用 undo 撤销
+	BaiduPCS-Go cp --overwrite /我的资源/1.mp4 /
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() <= 1 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+
+				pcscommand.RunCopy(c.Bool("overwrite"), c.Args()...)
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "overwrite",
+					Usage: "覆盖已存在的目标文件/目录, 被覆盖的移到回收站",
+				},
+			},
+		},
+		{
+			Name:  "mv",
+			Usage: "移动/重命名文件/目录",
+			UsageText: `移动:
+	BaiduPCS-Go mv <文件/目录1> <文件/目录2> <文件/目录3> ... <目标目录>
+
+	重命名:
+	BaiduPCS-Go mv <文件/目录> <重命名的文件/目录>`,
+			Description: `
+	注意: 移动多个文件和目录时, 请确保每一个文件和目录都存在, 否则移动操作会失败.
+
+	示例:
+
+	将 /我的资源/1.mp4 移动到 根目录 /
+	BaiduPCS-Go mv /我的资源/1.mp4 /
+
+	将 /我的资源/1.mp4 重命名为 /我的资源/3.mp4
+	BaiduPCS-Go mv /我的资源/1.mp4 /我的资源/3.mp4
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() <= 1 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+
+				pcscommand.RunMove(c.Args()...)
+				return nil
+			},
+		},
+		{
+			Name:      "diff",
+			Usage:     "比较两个网盘目录",
+			UsageText: app.Name + " diff [--json] <目录A> <目录B>\n   " + app.Name + " diff [--json] --manifest <清单文件> <目录A>",
+			Description: `
+	递归列出两个目录, 按相对路径比较其中的文件, 输出:
+		仅 A: 只在目录 A 中的文件;
+		仅 B: 只在目录 B 中的文件;
+		不同: 路径相同, 但大小, md5 或类型不同;
+		移动: 内容相同 (大小和 md5 相同), 但路径不同;
+		未知: 路径和大小相同, 但缺少 md5, 或分片上传的文件 md5 可能不正确且修改日期不同, 无法确定内容是否相同.
+	md5 可能不正确的文件不会被识别为移动.
+
+	使用 --save 把目录 A 保存为清单, 之后使用 --manifest 与清单比较.
+	清单也可以是 md5sum 格式 (包括 BSD 风格) 的文件, 此时只比较 md5.
+
+	示例:
+
+	1. 比较 /我的资源 和 /备份/我的资源
+	BaiduPCS-Go diff /我的资源 /备份/我的资源
+
+	2. 保存 /我的资源 的清单, 整理后再与清单比较
+	BaiduPCS-Go diff --save 我的资源.json /我的资源
+	BaiduPCS-Go diff --manifest 我的资源.json /我的资源
+
+	3. 输出 json 格式
+	BaiduPCS-Go diff --json /a /b
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				var (
+					opt = &pcscommand.DiffOptions{
+						Manifest: c.String("manifest"),
+						Save:     c.String("save"),
+						JSON:     c.Bool("json"),
+					}
+					nargs = 2
+				)
+				if opt.Manifest != "" || opt.Save != "" {
+					nargs = 1
+				}
+				if c.NArg() != nargs && !(opt.Save != "" && c.NArg() == 2) {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+
+				pcscommand.RunDiff(c.Args().Get(0), c.Args().Get(1), opt)
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "manifest",
+					Usage: "与保存的清单比较, 代替目录B",
+				},
+				cli.StringFlag{
+					Name:  "save",
+					Usage: "把目录A保存为清单",
+				},
+				cli.BoolFlag{
+					Name:  "json",
+					Usage: "输出 json 格式",
+				},
+			},
+		},
+		{
+			Name:  "manifest",
+			Usage: "生成和校验 md5sum/sha256sum 校验文件",
+			Description: `
+	create: 为网盘目录生成校验文件, 路径相对于该目录, 格式与 md5sum, sha256sum 等工具兼容,
+	下载目录后可以在本地使用 md5sum -c 校验. 默认使用服务器记录的 md5, 不需要下载文件;
+	分片上传的文件服务器记录的 md5 可能不正确, 此时也下载文件计算;
+	使用 --algo 指定其他算法或使用 --compute 时, 下载文件计算摘要, 不保存到本地.
+
+	verify: 使用校验文件校验本地目录或网盘目录, 报告缺少, 多余和不一致的文件.
+	校验文件支持 md5sum, sha1sum, sha256sum 格式, BSD 风格 (--tag) 和 diff --save 保存的清单.
+	目录在本地存在时校验本地目录, 使用 --remote 强制校验网盘目录.
+	校验网盘目录时, md5 与服务器记录的 md5 比较, 服务器记录的 md5 可能不正确时和其他算法一样下载文件计算.
+	diff --save 保存的清单中 md5 可能不正确的文件只比较大小.
+
+	示例:
+
+	1. 生成 /我的资源 的 md5 校验文件
+	BaiduPCS-Go manifest create -o 我的资源.md5 /我的资源
+
+	2. 下载文件计算 sha256, 生成校验文件
+	BaiduPCS-Go manifest create --algo sha256 -o 我的资源.sha256 /我的资源
+
+	3. 校验下载到本地的目录
+	BaiduPCS-Go manifest verify 我的资源.md5 ./我的资源
+
+	4. 使用第三方提供的校验文件校验网盘目录
+	BaiduPCS-Go manifest verify --remote 项目.sha256 /项目
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				cli.ShowCommandHelp(c, c.Command.Name)
+				return nil
+			},
+			Subcommands: []cli.Command{
+				{
+					Name:      "create",
+					Usage:     "生成网盘目录的校验文件",
+					UsageText: app.Name + " manifest create [--algo md5] [--compute] [-o 文件] <网盘目录>",
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 1 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						algos, err := checksum.ParseAlgorithms(c.String("algo"))
+						if err != nil {
+							fmt.Println(err)
+							return nil
+						}
+						pcscommand.RunManifestCreate(c.Args().Get(0), &pcscommand.ManifestOptions{
+							Algorithms: algos,
+							Compute:    c.Bool("compute"),
+							Output:     c.String("o"),
+							Parallel:   c.Int("p"),
+						})
+						return nil
+					},
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "algo",
+							Usage: "摘要算法, 多个算法用逗号分隔, 可选: md5, sha1, sha256, crc32",
+							Value: "md5",
+						},
+						cli.BoolFlag{
+							Name:  "compute",
+							Usage: "下载文件计算 md5, 不使用服务器记录的 md5",
+						},
+						cli.StringFlag{
+							Name:  "o",
+							Usage: "保存到本地文件, 默认输出到终端",
+						},
+						cli.IntFlag{
+							Name:  "p",
+							Usage: "下载文件计算摘要时的连接数, 默认使用 max_parallel",
+						},
+					},
+				},
+				{
+					Name:      "verify",
+					Usage:     "使用校验文件校验本地目录或网盘目录",
+					UsageText: app.Name + " manifest verify [--remote] [--compute] [-p 数量] [--json] <校验文件> <本地目录|网盘目录>",
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 2 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunManifestVerify(c.Args().Get(0), c.Args().Get(1), &pcscommand.ManifestOptions{
+							Compute:  c.Bool("compute"),
+							Parallel: c.Int("p"),
+							Remote:   c.Bool("remote"),
+							JSON:     c.Bool("json"),
+						})
+						return nil
+					},
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "remote",
+							Usage: "校验网盘目录",
+						},
+						cli.BoolFlag{
+							Name:  "compute",
+							Usage: "校验网盘目录时下载文件计算 md5, 不使用服务器记录的 md5",
+						},
+						cli.IntFlag{
+							Name:  "p",
+							Usage: "校验本地目录时同时计算的文件数, 默认为 CPU 数; 校验网盘目录时为下载的连接数",
+						},
+						cli.BoolFlag{
+							Name:  "json",
+							Usage: "输出 json 格式",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:      "fixmd5",
+			Usage:     "修复文件MD5",
+			UsageText: app.Name + " fixmd5 [-r] [-p 数量] [--report 文件] [--restart] <文件/目录1> <文件/目录2> ...",
+			Description: `
+	尝试修复文件的MD5值, 以便于校验文件的完整性和导出文件.
+
+	使用分片上传文件, 当文件分片数大于1时, 网盘端最终计算所得的md5值和本地的不一致, 这可能是网盘的bug.
+	不过把上传的文件下载到本地后，对比md5值是匹配的.
+	可通过秒传的原理来修复md5值.
+
+	只修复md5可能不正确的文件, 即md5无效或分片数大于1的文件, 包括明确指定的文件和使用 -r 列出的目录中的文件,
+	其他文件报告为不需要修复.
+	每修复一个文件都会记录进度, 中断后再次运行相同的命令, 跳过已完成的文件, 重试失败的文件.
+	同时修复的文件数受 api_rate_limit 等请求频率限制的约束.
+
+	修复md5不一定能成功, 原因可能是服务器未刷新, 可过几天后再尝试.
+	修复md5过程中, 可能会出现"文件不存在"的提示, 原因是文件名有特殊字符.
+
+	示例:
+
+	1. 修复 /我的资源/1.mp4 的md5
+	BaiduPCS-Go fixmd5 /我的资源/1.mp4
+
+	2. 修复 /我的资源 目录中所有文件的md5, 同时修复 8 个文件, 保存修复报告
+	BaiduPCS-Go fixmd5 -r -p 8 --report fixmd5.json /我的资源
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() <= 0 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+				pcscommand.RunFixMD5(c.Args(), &pcscommand.FixMD5Options{
+					Recurse:  c.Bool("r"),
+					Parallel: c.Int("p"),
+					Report:   c.String("report"),
+					Restart:  c.Bool("restart"),
+				})
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "r",
+					Usage: "递归修复目录中的文件",
+				},
+				cli.IntFlag{
+					Name:  "p",
+					Usage: "同时修复的文件数",
+					Value: 4,
+				},
+				cli.StringFlag{
+					Name:  "report",
+					Usage: "保存修复报告到本地文件, json 格式",
+				},
+				cli.BoolFlag{
+					Name:  "restart",
+					Usage: "忽略上次的进度, 重新开始",
+				},
+			},
+		},
+		{
+			Name:  "cache",
+			Usage: "缓存管理",
+			Description: `
+	目录列表和帐号 uk 默认只在内存中缓存, 每次运行程序都要重新获取, du 的统计结果默认缓存到磁盘 24 小时.
+	设置 disk_cache_ttl 后同时缓存到配置目录下的 cache 目录, 多次运行和同时运行的多个进程之间共用,
+	ls, tree, find, du 等只读的命令也使用缓存的目录列表, rm, mv, rename, find -delete 等修改文件的命令总是重新获取.
+	rm, mkdir, cp, mv, 上传, 从回收站还原等修改操作会删除相关目录及新建的上级目录的缓存.
+
+	示例:
+
+	1. 启用磁盘缓存, 目录列表缓存 5 分钟, uk 缓存 24 小时
+	BaiduPCS-Go config set -disk_cache_ttl list=5m,uk=24h
+
+	2. 查看缓存
+	BaiduPCS-Go cache stats
+
+	3. 清除缓存, 网页端修改文件后可以清除缓存
+	BaiduPCS-Go cache clear
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				cli.ShowCommandHelp(c, c.Command.Name)
 				return nil
 			},
+			Subcommands: []cli.Command{
+				{
+					Name:      "stats",
+					Usage:     "查看磁盘缓存",
+					UsageText: app.Name + " cache stats",
+					Action: func(c *cli.Context) error {
+						pcscommand.RunCacheStats()
+						return nil
+					},
+				},
+				{
+					Name:      "clear",
+					Usage:     "清除内存和磁盘缓存",
+					UsageText: app.Name + " cache clear",
+					Action: func(c *cli.Context) error {
+						pcscommand.RunCacheClear()
+						return nil
+					},
+				},
+			},
 		},
 		{
-			Name:      "rm",
-			Usage:     "删除文件/目录",
-			UsageText: app.Name + " rm <文件/目录的路径1> <文件/目录2> <文件/目录3> ...",
+			Name:  "index",
+			Usage: "本地文件索引",
 			Description: `
-	注意: 删除多个文件和目录时, 请确保每一个文件和目录都存在, 否则删除操作会失败.
-	被删除的文件或目录可在网盘文件回收站找回.
+	把网盘中全部文件的路径, fs_id, 大小, md5, 修改日期和分片信息保存到本地索引,
+	之后使用文件变更记录增量更新.
+	索引保存在配置目录中的 bbolt 数据库, 每获取一页变更写入一次, 查询时只读取需要的记录, 不会全部读入内存.
+
+	使用全局选项 --offline 时, ls, tree, find, search, du 和 tab 自动补全从本地索引读取, 不访问网络;
+	网络不可用时也会自动使用本地索引. 离线模式下不能修改文件.
 
 	示例:
 
-	删除 /我的资源/1.mp4
-	BaiduPCS-Go rm /我的资源/1.mp4
+	1. 建立索引
+	BaiduPCS-Go index build
 
-	删除 /我的资源/1.mp4 和 /我的资源/2.mp4
-	BaiduPCS-Go rm /我的资源/1.mp4 /我的资源/2.mp4
+	2. 增量更新索引
+	BaiduPCS-Go index update
 
-	删除 /我的资源 内的所有文件和目录, 但不删除该目录
-	BaiduPCS-Go rm /我的资源/*
+	3. 查看索引信息
+	BaiduPCS-Go index query
 
-	删除 /我的资源 整个目录 !!
-	BaiduPCS-Go rm /我的资源
+	4. 在索引中搜索文件名包含 "报告" 的文件
+	BaiduPCS-Go index query -r 报告
+
+	5. 按 md5 或 fs_id 查找文件
+	BaiduPCS-Go index query --md5 0cc175b9c0f1b6a831c399e269772661
+	BaiduPCS-Go index query --fsid 643596340463870
+
+	6. 离线列出目录
+	BaiduPCS-Go --offline ls /我的资源
 `,
 			Category: "百度网盘",
 			Before:   reloadFn,
 			Action: func(c *cli.Context) error {
-				if c.NArg() == 0 {
-					cli.ShowCommandHelp(c, c.Command.Name)
-					return nil
-				}
-
-				pcscommand.RunRemove(c.Args()...)
+				cli.ShowCommandHelp(c, c.Command.Name)
 				return nil
 			},
+			Subcommands: []cli.Command{
+				{
+					Name:      "build",
+					Usage:     "获取全部文件, 重新建立索引",
+					UsageText: app.Name + " index build",
+					Action: func(c *cli.Context) error {
+						pcscommand.RunIndexBuild()
+						return nil
+					},
+				},
+				{
+					Name:      "update",
+					Usage:     "增量更新索引",
+					UsageText: app.Name + " index update",
+					Action: func(c *cli.Context) error {
+						pcscommand.RunIndexUpdate()
+						return nil
+					},
+				},
+				{
+					Name:        "query",
+					Usage:       "查询索引",
+					UsageText:   app.Name + " index query [选项] [关键字]",
+					Description: `没有关键字和选项时, 输出索引信息`,
+					Action: func(c *cli.Context) error {
+						pcscommand.RunIndexQuery(c.Args().Get(0), &pcscommand.IndexQueryOptions{
+							Path:    c.String("path"),
+							MD5:     c.String("md5"),
+							FsID:    c.Int64("fsid"),
+							Total:   c.Bool("l"),
+							Recurse: c.Bool("r"),
+						})
+						return nil
+					},
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "path",
+							Usage: "搜索的目录",
+							Value: ".",
+						},
+						cli.StringFlag{
+							Name:  "md5",
+							Usage: "按 md5 查找文件",
+						},
+						cli.Int64Flag{
+							Name:  "fsid",
+							Usage: "按 fs_id 查找",
+						},
+						cli.BoolFlag{
+							Name:  "l",
+							Usage: "详细显示",
+						},
+						cli.BoolFlag{
+							Name:  "r",
+							Usage: "递归搜索",
+						},
+					},
+				},
+			},
 		},
 		{
-			Name:      "mkdir",
-			Usage:     "创建目录",
-			UsageText: app.Name + " mkdir <目录>",
-			Category:  "百度网盘",
-			Before:    reloadFn,
+			Name:  "rename",
+			Usage: "批量重命名文件/目录",
+			UsageText: `批量重命名:
+	BaiduPCS-Go rename <表达式> <文件/目录1> <文件/目录2> ...
+
+	撤销:
+	BaiduPCS-Go rename --undo <操作id>`,
+			Description: `
+	表达式可以是正则替换 s/正则/替换/标志, 替换中使用 $1, ${name} 引用分组,
+	标志 g 替换全部匹配, i 忽略大小写, 只作用于文件名, 不会改变所在目录.
+
+	表达式也可以是模板, 支持的字段:
+		{name} 文件名, {base} 不含扩展名的文件名, {ext} 扩展名, {dir} 所在目录名,
+		{size} 文件大小, {md5} md5, {n} 序号, {n:3} 补零到 3 位的序号,
+		{mtime:20060102} 修改时间, {ctime:20060102} 创建时间, 格式同 Go 的时间格式.
+
+	执行前会输出重命名预览, 有冲突时取消执行.
+	重命名完成后会记录到操作记录 (见 history), 可使用 --undo <操作id> 撤销整批重命名.
+
+	示例:
+
+	将 /相机 下的 IMG_0001.jpg 重命名为 photo_0001.jpg
+	BaiduPCS-Go rename 's/IMG_(\d+)/photo_$1/' /相机/*
+
+	在文件名前加上修改日期
+	BaiduPCS-Go rename '{mtime:20060102}_{name}' /相机/*
+
+	只预览, 不执行
+	BaiduPCS-Go rename --dry-run '{n:3}{ext}' /相机/*.jpg
+
+	撤销 id 为 12 的批量重命名
+	BaiduPCS-Go rename --undo 12
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
 			Action: func(c *cli.Context) error {
-				if c.NArg() == 0 {
+				if c.IsSet("undo") {
+					pcscommand.RunRenameUndo(c.Int("undo"), c.Bool("dry-run"))
+					return nil
+				}
+				if c.NArg() < 2 {
 					cli.ShowCommandHelp(c, c.Command.Name)
 					return nil
 				}
 
-				pcscommand.RunMkdir(c.Args().Get(0))
+				pcscommand.RunRename(c.Args().Get(0), c.Args().Tail(), &pcscommand.RenameOptions{
+					DryRun: c.Bool("dry-run"),
+					Yes:    c.Bool("y"),
+				})
 				return nil
 			},
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "undo",
+					Usage: "撤销操作记录中该 id 对应的批量重命名",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "只输出预览, 不执行",
+				},
+				cli.BoolFlag{
+					Name:  "y",
+					Usage: "不询问, 直接执行",
+				},
+			},
 		},
 		{
-			Name:  "cp",
-			Usage: "拷贝文件/目录",
-			UsageText: `BaiduPCS-Go cp <文件/目录> <目标文件/目录>
-	BaiduPCS-Go cp <文件/目录1> <文件/目录2> <文件/目录3> ... <目标目录>`,
+			Name:      "history",
+			Usage:     "列出修改网盘文件的操作记录",
+			UsageText: app.Name + " history",
 			Description: `
-	注意: 拷贝多个文件和目录时, 请确保每一个文件和目录都存在, 否则拷贝操作会失败.
+	列出 rm, mv, cp, rename, mkdir, upload, transfer, recycle 等命令修改网盘文件的操作记录, 最近的在前.
+	操作记录保存在配置目录, 每个帐号单独记录, 最多保留 1000 条.
+	使用 undo <id> 撤销操作.
 
 	示例:
 
-	将 /我的资源/1.mp4 复制到 根目录 /
-	BaiduPCS-Go cp /我的资源/1.mp4 /
+	列出最近 20 次操作
+	BaiduPCS-Go history
 
-	将 /我的资源/1.mp4 和 /我的资源/2.mp4 复制到 根目录 /
-	BaiduPCS-Go cp /我的资源/1.mp4 /我的资源/2.mp4 /
+	列出最近 100 次操作
+	BaiduPCS-Go history -n 100
 `,
 			Category: "百度网盘",
 			Before:   reloadFn,
 			Action: func(c *cli.Context) error {
-				if c.NArg() <= 1 {
-					cli.ShowCommandHelp(c, c.Command.Name)
-					return nil
-				}
-
-				pcscommand.RunCopy(c.Args()...)
+				pcscommand.RunHistory(c.Int("n"))
 				return nil
 			},
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "n",
+					Usage: "列出的操作数量, 0 为全部",
+					Value: 20,
+				},
+			},
 		},
 		{
-			Name:  "mv",
-			Usage: "移动/重命名文件/目录",
-			UsageText: `移动:
-	BaiduPCS-Go mv <文件/目录1> <文件/目录2> <文件/目录3> ... <目标目录>
-
-	重命名:
-	BaiduPCS-Go mv <文件/目录> <重命名的文件/目录>`,
+			Name:      "undo",
+			Usage:     "撤销修改网盘文件的操作",
+			UsageText: app.Name + " undo <id>",
 			Description: `
-	注意: 移动多个文件和目录时, 请确保每一个文件和目录都存在, 否则移动操作会失败.
+	id 为 history 列出的操作 id.
+	移动和重命名: 移回原来的路径;
+	删除: 使用记录的 fs_id 从回收站还原;
+	拷贝: 删除拷贝的文件/目录, 被覆盖的文件/目录从回收站还原;
+	创建目录, 上传, 转存和从回收站还原: 删除新增的文件/目录, 可在回收站找回.
+	删除回收站中的文件和清空回收站不能撤销.
+	使用 --storage 指定其他存储后端时, 操作不会被记录, 也不能撤销.
 
 	示例:
 
-	将 /我的资源/1.mp4 移动到 根目录 /
-	BaiduPCS-Go mv /我的资源/1.mp4 /
-
-	将 /我的资源/1.mp4 重命名为 /我的资源/3.mp4
-	BaiduPCS-Go mv /我的资源/1.mp4 /我的资源/3.mp4
+	撤销 id 为 12 的操作
+	BaiduPCS-Go undo 12
 `,
 			Category: "百度网盘",
 			Before:   reloadFn,
 			Action: func(c *cli.Context) error {
-				if c.NArg() <= 1 {
+				if c.NArg() != 1 {
 					cli.ShowCommandHelp(c, c.Command.Name)
 					return nil
 				}
 
-				pcscommand.RunMove(c.Args()...)
+				id, err := strconv.Atoi(c.Args().Get(0))
+				if err != nil {
+					fmt.Printf("id 格式错误, %s\n", err)
+					return nil
+				}
+				pcscommand.RunUndo(id)
 				return nil
 			},
 		},
@@ -1029,6 +2057,8 @@ func main() {
 	支持多个文件或目录下载.
 	支持下载完成后自动校验文件, 但并不是所有的文件都支持校验!
 	自动跳过下载重名的文件!
+	下载前会检查本地剩余空间(已扣除断点续传已下载的部分), 空间不足时拒绝下载, 使用 --force 忽略此检查.
+	下载到 windows, exFAT 等文件系统时, 可通过 config set -filename_profile 映射文件名中的非法字符, 上传时自动还原.
 
 	下载模式说明:
 		pcs: 通过百度网盘的 PCS API 下载, locate模式提示user is not authorized可尝试此模式
@@ -1052,6 +2082,26 @@ func main() {
 	下载网盘内的全部文件!!
 	BaiduPCS-Go d /
 	BaiduPCS-Go d *
+
+	按修改时间从新到旧下载 /我的照片
+	BaiduPCS-Go d --order -mtime /我的照片
+
+	下载进行中, 在另一个终端请求优先下载 /我的资源/1.mp4
+	BaiduPCS-Go d --bump /我的资源/1.mp4
+
+	保存路径模板:
+	通过 --save-template 指定本地保存路径, 相对路径基于 --saveto 或当前帐号的默认保存目录, 支持的占位符:
+		{path}: 网盘路径, {dir}: 网盘路径所在的目录, {rel}: 默认的相对保存路径
+		{filename}: 文件名, {name}: 不含扩展名的文件名, {ext}: 扩展名, 含 .
+		{mtime:2006/01}, {ctime:2006/01}: 修改/创建时间, 使用 Go 的时间格式
+		{md5}: 文件的 md5, {account}: 当前帐号的用户名
+	模板生成的保存路径冲突时, 通过 --conflict 指定处理策略: skip(跳过), overwrite(覆盖本地文件), suffix(添加序号后缀)
+
+	按修改时间的年月整理 /我的照片 目录下的文件
+	BaiduPCS-Go d --save-template "{account}/照片/{mtime:2006/01}/{filename}" /我的照片
+
+	将 /我的资源 目录展平下载, 重名文件添加序号后缀
+	BaiduPCS-Go d --save-template "{filename}" --conflict suffix /我的资源
 `,
 			Category: "百度网盘",
 			Before:   reloadFn,
@@ -1061,6 +2111,11 @@ func main() {
 					return nil
 				}
 
+				if c.Bool("bump") {
+					pcscommand.RunDownloadBump(c.Args())
+					return nil
+				}
+
 				// 处理saveTo
 				var (
 					saveTo string
@@ -1102,6 +2157,10 @@ func main() {
 					LinkPrefer:           c.Int("dindex"),
 					ModifyMTime:          c.Bool("mtime"),
 					FullPath:             c.Bool("fullpath"),
+					SaveTemplate:         c.String("save-template"),
+					ConflictPolicy:       c.String("conflict"),
+					Force:                c.Bool("force"),
+					Order:                c.String("order"),
 				}
 
 				pcscommand.RunDownload(c.Args(), do)
@@ -1167,6 +2226,28 @@ func main() {
 					Name:  "fullpath",
 					Usage: "以网盘完整路径保存到本地",
 				},
+				cli.StringFlag{
+					Name:  "save-template",
+					Usage: "本地保存路径模板, 如 {mtime:2006/01}/{filename}",
+				},
+				cli.StringFlag{
+					Name:  "conflict",
+					Usage: "模板生成的保存路径冲突时的处理策略, 可选值: skip, overwrite, suffix",
+					Value: "skip",
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "忽略本地剩余空间检查, 强制下载",
+				},
+				cli.StringFlag{
+					Name:  "order",
+					Usage: "下载顺序, 可选值: size(小文件优先), -size, name, mtime, -mtime, path",
+					Value: "size",
+				},
+				cli.BoolFlag{
+					Name:  "bump",
+					Usage: "不下载, 而是请求正在进行的下载优先下载指定的文件或目录, 10分钟内未匹配的请求失效",
+				},
 			},
 		},
 		{
@@ -1217,6 +2298,7 @@ func main() {
 					Load:          c.Int("l"),
 					NoRapidUpload: c.Bool("norapid"),
 					Policy:        c.String("policy"),
+					Force:         c.Bool("force"),
 				})
 				return nil
 			},
@@ -1242,6 +2324,10 @@ func main() {
 					Name:  "policy",
 					Usage: fmt.Sprintf("对同名文件的处理策略 (default: %s), %s, %s", baidupcs.SkipPolicy, baidupcs.OverWritePolicy, baidupcs.RsyncPolicy),
 				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "忽略网盘剩余空间检查, 强制上传",
+				},
 			},
 		},
 		{
@@ -1388,6 +2474,21 @@ func main() {
 			Description: fmt.Sprintf(`
 	获取下载直链
 
+	使用 --format 可递归获取目录下所有文件的下载链接, 并导出为 aria2, curl, wget 或 json 格式,
+	导出内容包含下载所需的 User-Agent, Cookie, 相对保存路径, 文件大小, md5 和链接过期时间.
+
+	示例:
+
+	导出 /我的资源 目录下所有文件的下载链接为 aria2 输入文件
+	BaiduPCS-Go locate --format aria2 -o links.txt /我的资源
+	aria2c -i links.txt
+
+	导出为 curl 脚本
+	BaiduPCS-Go locate --format curl -o download.sh /我的资源
+
+	使用 --pan 时通过网盘 API 按 fs_id 批量获取下载链接
+	BaiduPCS-Go locate --pan --format json -o links.json /我的资源
+
 	若该功能无法正常使用, 提示"user is not authorized, hitcode:xxx", 尝试更换 User-Agent 为 %s:
 	BaiduPCS-Go config set -user_agent "%s"
 `, baidupcs.NetdiskUA, baidupcs.NetdiskUA),
@@ -1401,6 +2502,8 @@ func main() {
 
 				opt := &pcscommand.LocateDownloadOption{
 					FromPan: c.Bool("pan"),
+					Format:  c.String("format"),
+					Output:  c.String("o"),
 				}
 
 				pcscommand.RunLocateDownload(c.Args(), opt)
@@ -1411,20 +2514,32 @@ func main() {
 					Name:  "pan",
 					Usage: "从百度网盘首页获取下载链接",
 				},
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "递归导出下载链接, 可选值: aria2, curl, wget, json",
+				},
+				cli.StringFlag{
+					Name:  "o",
+					Usage: "导出到文件, 默认输出到终端",
+				},
 			},
 		},
 		{
 			Name:      "sumfile",
 			Aliases:   []string{"sf"},
 			Usage:     "获取本地文件的秒传信息(目前秒传功能已失效)",
-			UsageText: app.Name + " sumfile <本地文件的路径1> <本地文件的路径2> ...",
+			UsageText: app.Name + " sumfile [--algo sha256,sha1,md5,crc32] <本地文件的路径1> <本地文件的路径2> ...",
 			Description: `
 	获取本地文件的大小, md5, 前256KB切片的md5, crc32, 曾经可用于秒传文件.
+	指定 --algo 时输出与 sha256sum 等工具兼容的格式, 可以与 hash 命令的输出对比.
 
 	示例:
 
-	获取 C:\Users\Administrator\Desktop\1.mp4 的秒传信息
+	1. 获取 C:\Users\Administrator\Desktop\1.mp4 的秒传信息
 	BaiduPCS-Go sumfile C:/Users/Administrator/Desktop/1.mp4
+
+	2. 计算 1.mp4 的 sha256 和 sha1
+	BaiduPCS-Go sumfile --algo sha256,sha1 1.mp4
 `,
 			Category: "其他",
 			Before:   reloadFn,
@@ -1434,6 +2549,29 @@ func main() {
 					return nil
 				}
 
+				if c.IsSet("algo") {
+					algos, err := checksum.ParseAlgorithms(c.String("algo"))
+					if err != nil {
+						fmt.Println(err)
+						return nil
+					}
+					flag := 0
+					for _, algo := range algos {
+						flag |= algo.Flag()
+					}
+					for _, filePath := range c.Args() {
+						lp, err := checksum.GetFileSum(filePath, flag)
+						if err != nil {
+							fmt.Fprintf(os.Stderr, "%s: %s\n", filePath, err)
+							continue
+						}
+						for _, algo := range algos {
+							fmt.Println(checksum.FormatSumLine(algo, lp.HexSum(algo), filePath, len(algos) > 1))
+						}
+					}
+					return nil
+				}
+
 				for k, filePath := range c.Args() {
 					lp, err := checksum.GetFileSum(filePath, checksum.CHECKSUM_MD5|checksum.CHECKSUM_SLICE_MD5|checksum.CHECKSUM_CRC32)
 					if err != nil {
@@ -1463,6 +2601,12 @@ func main() {
 
 				return nil
 			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "algo",
+					Usage: "以 sha256sum 兼容的格式输出摘要, 多个算法用逗号分隔, 可选: sha256, sha1, md5, crc32 (crc32 只用于显示)",
+				},
+			},
 		},
 		{
 			Name:      "transfer",
@@ -1816,6 +2960,17 @@ func main() {
 
 	3. 清空回收站, 程序不会进行二次确认, 谨慎操作!!!
 	BaiduPCS-Go recycle delete -all
+
+	4. 列出回收站中原路径在 /projects 下, 2026-10-01 及之后删除的文件
+	BaiduPCS-Go recycle list --path '/projects/**' --since 2026-10-01
+
+	5. 还原以上文件, 原路径已被占用的文件会跳过并列出
+	BaiduPCS-Go recycle restore --path '/projects/**' --since 2026-10-01
+
+	6. 从回收站彻底删除大于 1GB 的视频, 只预览
+	BaiduPCS-Go recycle delete --path '/**/*.{mp4,mkv}' --minsize 1GB --dry-run
+
+	筛选时会列出回收站的全部页面, 删除日期即回收站列表中的修改日期.
 `,
 			Category: "百度网盘",
 			Before:   reloadFn,
@@ -1827,43 +2982,62 @@ func main() {
 			},
 			Subcommands: []cli.Command{
 				{
-					Name:      "list",
-					Aliases:   []string{"ls", "l"},
-					Usage:     baidupcs.OperationRecycleList,
-					UsageText: app.Name + " recycle list",
+					Name:        "list",
+					Aliases:     []string{"ls", "l"},
+					Usage:       baidupcs.OperationRecycleList,
+					UsageText:   app.Name + " recycle list [--page <页数>] [筛选条件]",
+					Description: `指定筛选条件时, 列出回收站全部页面中符合条件的文件或目录`,
 					Action: func(c *cli.Context) error {
+						if opt, ok := recycleOptions(c); ok {
+							if opt != nil {
+								pcscommand.RunRecycleSearch(&opt.Filter)
+							}
+							return nil
+						}
 						pcscommand.RunRecycleList(c.Int("page"))
 						return nil
 					},
-					Flags: []cli.Flag{
+					Flags: append([]cli.Flag{
 						cli.IntFlag{
 							Name:  "page",
 							Usage: "回收站文件列表页数",
 							Value: 1,
 						},
-					},
+					}, recycleFilterFlags...),
 				},
 				{
 					Name:        "restore",
 					Aliases:     []string{"r"},
 					Usage:       baidupcs.OperationRecycleRestore,
-					UsageText:   app.Name + " recycle restore <fs_id 1> <fs_id 2> <fs_id 3> ...",
-					Description: `根据文件/目录的 fs_id, 还原回收站指定的文件或目录`,
+					UsageText:   app.Name + " recycle restore <fs_id 1> <fs_id 2> <fs_id 3> ... 或 " + app.Name + " recycle restore [筛选条件]",
+					Description: `根据文件/目录的 fs_id 或筛选条件, 还原回收站指定的文件或目录. 按筛选条件还原时, 原路径已被占用的文件或目录会跳过`,
 					Action: func(c *cli.Context) error {
-						if c.NArg() <= 0 {
-							cli.ShowCommandHelp(c, c.Command.Name)
+						if c.NArg() > 0 {
+							pcscommand.RunRecycleRestore(c.Args()...)
 							return nil
 						}
-						pcscommand.RunRecycleRestore(c.Args()...)
+						opt, ok := recycleOptions(c)
+						switch {
+						case !ok:
+							cli.ShowCommandHelp(c, c.Command.Name)
+						case opt != nil:
+							pcscommand.RunRecycleRestoreByFilter(opt)
+						}
 						return nil
 					},
+					Flags: append([]cli.Flag{
+						cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "只输出将要还原的文件, 不执行",
+						},
+					}, recycleFilterFlags...),
 				},
 				{
 					Name:        "delete",
 					Aliases:     []string{"d"},
 					Usage:       baidupcs.OperationRecycleDelete + "/" + baidupcs.OperationRecycleClear,
-					UsageText:   app.Name + " recycle delete [-all] <fs_id 1> <fs_id 2> <fs_id 3> ...",
-					Description: `根据文件/目录的 fs_id 或 -all 参数, 删除回收站指定的文件或目录或清空回收站`,
+					UsageText:   app.Name + " recycle delete [-all] <fs_id 1> <fs_id 2> <fs_id 3> ... 或 " + app.Name + " recycle delete [筛选条件]",
+					Description: `根据文件/目录的 fs_id, 筛选条件或 -all 参数, 删除回收站指定的文件或目录或清空回收站. 按筛选条件删除时会二次确认`,
 					Action: func(c *cli.Context) error {
 						if c.Bool("all") {
 							// 清空回收站
@@ -1871,19 +3045,33 @@ func main() {
 							return nil
 						}
 
-						if c.NArg() <= 0 {
-							cli.ShowCommandHelp(c, c.Command.Name)
+						if c.NArg() > 0 {
+							pcscommand.RunRecycleDelete(c.Args()...)
 							return nil
 						}
-						pcscommand.RunRecycleDelete(c.Args()...)
+						opt, ok := recycleOptions(c)
+						switch {
+						case !ok:
+							cli.ShowCommandHelp(c, c.Command.Name)
+						case opt != nil:
+							pcscommand.RunRecycleDeleteByFilter(opt)
+						}
 						return nil
 					},
-					Flags: []cli.Flag{
+					Flags: append([]cli.Flag{
 						cli.BoolFlag{
 							Name:  "all",
 							Usage: "清空回收站, 程序不会进行二次确认, 谨慎操作!!!",
 						},
-					},
+						cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "只输出将要删除的文件, 不执行",
+						},
+						cli.BoolFlag{
+							Name:  "y",
+							Usage: "按筛选条件删除时不询问, 直接执行",
+						},
+					}, recycleFilterFlags...),
 				},
 			},
 		},
@@ -1933,6 +3121,43 @@ func main() {
 						if c.IsSet("ignore_illegal") {
 							pcsconfig.Config.SetIgnoreIllegal(c.Bool("ignore_illegal"))
 						}
+						if c.IsSet("filename_profile") {
+							if !pcsconfig.Config.SetFilenameProfile(c.String("filename_profile")) {
+								fmt.Println("设置 filename_profile 错误: 可选值为 posix, windows, exfat 或留空")
+							}
+						}
+						if c.IsSet("filename_normalize") {
+							if !pcsconfig.Config.SetFilenameNormalize(c.String("filename_normalize")) {
+								fmt.Println("设置 filename_normalize 错误: 可选值为 nfc, nfd 或留空")
+							}
+						}
+						if c.IsSet("max_path_length") {
+							pcsconfig.Config.SetMaxPathLength(c.Int("max_path_length"))
+						}
+						if c.IsSet("retry_initial_wait") {
+							pcsconfig.Config.SetRetryInitialWait(c.Int("retry_initial_wait"))
+						}
+						if c.IsSet("retry_max_wait") {
+							pcsconfig.Config.SetRetryMaxWait(c.Int("retry_max_wait"))
+						}
+						if c.IsSet("retry_max_elapsed") {
+							pcsconfig.Config.SetRetryMaxElapsed(c.Int("retry_max_elapsed"))
+						}
+						if c.IsSet("api_rate_limit") {
+							err := pcsconfig.Config.SetAPIRateLimit(c.String("api_rate_limit"))
+							if err != nil {
+								fmt.Printf("设置 api_rate_limit 错误: %s\n", err)
+							}
+						}
+						if c.IsSet("api_cooldown") {
+							pcsconfig.Config.SetAPICooldown(c.Int("api_cooldown"))
+						}
+						if c.IsSet("disk_cache_ttl") {
+							err := pcsconfig.Config.SetDiskCacheTTL(c.String("disk_cache_ttl"))
+							if err != nil {
+								fmt.Printf("设置 disk_cache_ttl 错误: %s\n", err)
+							}
+						}
 						if c.IsSet("force_login_username") {
 							pcsconfig.Config.SetForceLogin(c.String("force_login_username"))
 						}
@@ -2060,6 +3285,42 @@ func main() {
 							Name:  "ignore_illegal",
 							Usage: "忽略上传时文件名中的非法字符",
 						},
+						cli.StringFlag{
+							Name:  "filename_profile",
+							Usage: "下载文件名映射规则, 可选值: posix, windows, exfat",
+						},
+						cli.StringFlag{
+							Name:  "filename_normalize",
+							Usage: "文件名 Unicode 规范化形式, 可选值: nfc, nfd",
+						},
+						cli.IntFlag{
+							Name:  "max_path_length",
+							Usage: "下载保存路径最大长度, 0代表不限制",
+						},
+						cli.IntFlag{
+							Name:  "retry_initial_wait",
+							Usage: "失败重试第一次的等待时间, 单位: 秒",
+						},
+						cli.IntFlag{
+							Name:  "retry_max_wait",
+							Usage: "失败重试的最大等待时间, 单位: 秒",
+						},
+						cli.IntFlag{
+							Name:  "retry_max_elapsed",
+							Usage: "任务从开始执行起超过此时间不再重试, 单位: 秒, 0代表不限制",
+						},
+						cli.StringFlag{
+							Name:  "api_rate_limit",
+							Usage: "各类接口每秒最多请求的次数, 如 list=5,manage=2",
+						},
+						cli.IntFlag{
+							Name:  "api_cooldown",
+							Usage: "触发百度服务器频率限制后暂停请求的时间, 单位: 秒",
+						},
+						cli.StringFlag{
+							Name:  "disk_cache_ttl",
+							Usage: "各操作的磁盘缓存时间, 如 list=5m,uk=24h,du=1h, 留空表示只在内存中缓存 (du 默认缓存 24 小时)",
+						},
 						cli.StringFlag{
 							Name:  "force_login_username",
 							Usage: "强制登录指定用户名, 只适用于tieba接口失效的情况",
@@ -2132,10 +3393,27 @@ func main() {
 			Description: `
 	测试通配符匹配路径, 操作成功则输出所有匹配到的路径.
 
+	支持的通配符:
+	*        匹配任意数量的字符, 不包括路径分隔符
+	?        匹配单个字符
+	[a-z]    匹配中括号内的字符, [!a-z] 或 [^a-z] 匹配不在中括号内的字符
+	**       单独作为一级路径时, 匹配任意层级的目录 (包括零层)
+	{a,b}    花括号展开, 分别匹配 a 和 b, 支持嵌套
+	\        转义, 如 \* 匹配字符 *
+
+	花括号只在表达式同时含有 * ? [ 时展开, 否则按普通字符匹配文件名,
+	如 /a{1,2}.txt 只匹配文件 a{1,2}.txt. 需要与通配符一起匹配花括号本身时, 使用 \{ 转义.
+
 	示例:
 
 	1. 匹配 /我的资源 目录下所有mp4格式的文件
 	BaiduPCS-Go match /我的资源/*.mp4
+
+	2. 匹配 /photos 目录及其所有子目录下的jpg文件
+	BaiduPCS-Go match /photos/**/*.jpg
+
+	3. 匹配 /logs 目录下以 2023- 或 2024- 开头的log文件, 并显示展开计划
+	BaiduPCS-Go match --plan "/logs/{2023,2024}-*.log"
 `,
 			Category: "百度网盘",
 			Before:   reloadFn,
@@ -2145,9 +3423,15 @@ func main() {
 					return nil
 				}
 
-				pcscommand.RunTestShellPattern(c.Args()[0])
+				pcscommand.RunTestShellPattern(c.Args()[0], c.Bool("plan"))
 				return nil
 			},
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "plan",
+					Usage: "显示通配符的展开计划",
+				},
+			},
 		},
 		{
 			Name:  "tool",
@@ -0,0 +1,135 @@
+package checksum
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+type (
+	// Algorithm 摘要算法
+	Algorithm string
+)
+
+const (
+	// AlgorithmMD5 md5
+	AlgorithmMD5 Algorithm = "md5"
+	// AlgorithmSHA1 sha1
+	AlgorithmSHA1 Algorithm = "sha1"
+	// AlgorithmSHA256 sha256
+	AlgorithmSHA256 Algorithm = "sha256"
+	// AlgorithmCRC32 crc32
+	AlgorithmCRC32 Algorithm = "crc32"
+)
+
+var (
+	// Algorithms 支持的摘要算法
+	Algorithms = []Algorithm{AlgorithmMD5, AlgorithmSHA1, AlgorithmSHA256, AlgorithmCRC32}
+)
+
+// ParseAlgorithms 解析以逗号分隔的算法列表, 去除重复的算法
+func ParseAlgorithms(s string) (algos []Algorithm, err error) {
+	for _, field := range strings.Split(s, ",") {
+		algo := Algorithm(strings.ToLower(strings.TrimSpace(field)))
+		if algo == "" {
+			continue
+		}
+		if !algo.valid() {
+			return nil, fmt.Errorf("不支持的算法: %s, 可选: md5, sha1, sha256, crc32", field)
+		}
+		if !containsAlgorithm(algos, algo) {
+			algos = append(algos, algo)
+		}
+	}
+	if len(algos) == 0 {
+		return nil, fmt.Errorf("未指定算法")
+	}
+	return algos, nil
+}
+
+func containsAlgorithm(algos []Algorithm, algo Algorithm) bool {
+	for _, a := range algos {
+		if a == algo {
+			return true
+		}
+	}
+	return false
+}
+
+func (algo Algorithm) valid() bool {
+	return containsAlgorithm(Algorithms, algo)
+}
+
+// Flag 返回算法对应的 CHECKSUM_ 标志
+func (algo Algorithm) Flag() int {
+	switch algo {
+	case AlgorithmMD5:
+		return CHECKSUM_MD5
+	case AlgorithmSHA1:
+		return CHECKSUM_SHA1
+	case AlgorithmSHA256:
+		return CHECKSUM_SHA256
+	case AlgorithmCRC32:
+		return CHECKSUM_CRC32
+	}
+	return 0
+}
+
+// Tag 返回 BSD 风格输出使用的算法名, 如 SHA256
+func (algo Algorithm) Tag() string {
+	return strings.ToUpper(string(algo))
+}
+
+// NewChecksumWriter 返回算法对应的 ChecksumWriter
+func (algo Algorithm) NewChecksumWriter() ChecksumWriter {
+	switch algo {
+	case AlgorithmMD5:
+		return NewHashChecksumWriter(md5.New())
+	case AlgorithmSHA1:
+		return NewHashChecksumWriter(sha1.New())
+	case AlgorithmSHA256:
+		return NewHashChecksumWriter(sha256.New())
+	case AlgorithmCRC32:
+		return NewHash32ChecksumWriter(crc32.NewIEEE())
+	}
+	panic("unknown algorithm: " + string(algo))
+}
+
+// FormatSum 把 ChecksumWriter 的 Sum 格式化为十六进制字符串
+func FormatSum(sum interface{}) string {
+	switch v := sum.(type) {
+	case []byte:
+		return hex.EncodeToString(v)
+	case uint32:
+		return fmt.Sprintf("%08x", v)
+	}
+	return fmt.Sprint(sum)
+}
+
+// HexSum 返回本地文件算法 algo 的摘要值, 需要先用对应的标志调用 Sum
+func (lfm *LocalFileMeta) HexSum(algo Algorithm) string {
+	switch algo {
+	case AlgorithmMD5:
+		return hex.EncodeToString(lfm.MD5)
+	case AlgorithmSHA1:
+		return hex.EncodeToString(lfm.SHA1)
+	case AlgorithmSHA256:
+		return hex.EncodeToString(lfm.SHA256)
+	case AlgorithmCRC32:
+		return FormatSum(lfm.CRC32)
+	}
+	return ""
+}
+
+// FormatSumLine 返回 sha256sum 等工具兼容的一行输出.
+// tag 为 true 时使用 BSD 风格, 如 "SHA256 (file) = ...", 可以在一个文件中混合多种算法
+func FormatSumLine(algo Algorithm, sum, filename string, tag bool) string {
+	if tag {
+		return fmt.Sprintf("%s (%s) = %s", algo.Tag(), filename, sum)
+	}
+	return sum + "  " + filename
+}
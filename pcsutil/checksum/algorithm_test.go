@@ -0,0 +1,72 @@
+package checksum_test
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+)
+
+func TestParseAlgorithms(t *testing.T) {
+	algos, err := checksum.ParseAlgorithms("SHA256, md5,sha256,crc32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []checksum.Algorithm{checksum.AlgorithmSHA256, checksum.AlgorithmMD5, checksum.AlgorithmCRC32}
+	if !reflect.DeepEqual(algos, expected) {
+		t.Errorf("unexpected algorithms %v", algos)
+	}
+	for _, s := range []string{"", "sha512"} {
+		if _, err = checksum.ParseAlgorithms(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestAlgorithmSum(t *testing.T) {
+	data := []byte("hello, world\n")
+	filename := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sha1Sum, sha256Sum := sha1.Sum(data), sha256.Sum256(data)
+	expected := map[checksum.Algorithm]string{
+		checksum.AlgorithmSHA1:   hex.EncodeToString(sha1Sum[:]),
+		checksum.AlgorithmSHA256: hex.EncodeToString(sha256Sum[:]),
+		checksum.AlgorithmCRC32:  checksum.FormatSum(crc32.ChecksumIEEE(data)),
+		checksum.AlgorithmMD5:    "22c3683b094136c3398391ae71b20f04",
+	}
+
+	flag := 0
+	for _, algo := range checksum.Algorithms {
+		flag |= algo.Flag()
+	}
+	lfc, err := checksum.GetFileSum(filename, flag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for algo, sum := range expected {
+		if got := lfc.HexSum(algo); got != sum {
+			t.Errorf("%s: local file sum %s, expected %s", algo, got, sum)
+		}
+		w := algo.NewChecksumWriter()
+		w.Write(data)
+		if got := checksum.FormatSum(w.Sum()); got != sum {
+			t.Errorf("%s: writer sum %s, expected %s", algo, got, sum)
+		}
+	}
+
+	if line := checksum.FormatSumLine(checksum.AlgorithmSHA1, "ab", "a b.txt", false); line != "ab  a b.txt" {
+		t.Errorf("unexpected line %q", line)
+	}
+	if line := checksum.FormatSumLine(checksum.AlgorithmSHA256, "ab", "/x", true); line != "SHA256 (/x) = ab" {
+		t.Errorf("unexpected tagged line %q", line)
+	}
+}
@@ -3,6 +3,8 @@ package checksum
 
 import (
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/cachepool"
@@ -24,6 +26,10 @@ const (
 	CHECKSUM_SLICE_MD5
 	// CHECKSUM_CRC32 获取文件的 crc32 值
 	CHECKSUM_CRC32
+	// CHECKSUM_SHA1 获取文件的 sha1 值
+	CHECKSUM_SHA1
+	// CHECKSUM_SHA256 获取文件的 sha256 值
+	CHECKSUM_SHA256
 )
 
 type (
@@ -36,6 +42,9 @@ type (
 		MD5        []byte   `json:"md5"`       // 文件的 md5
 		CRC32      uint32   `json:"crc32"`     // 文件的 crc32
 		ModTime    int64    `json:"modtime"`   // 修改日期
+
+		SHA1   []byte `json:"sha1,omitempty"`   // 文件的 sha1
+		SHA256 []byte `json:"sha256,omitempty"` // 文件的 sha256
 	}
 
 	// LocalFileChecksum 校验本地文件
@@ -231,6 +240,37 @@ func (lfc *LocalFileChecksum) Sum(checkSumFlag int) (err error) {
 		defer d(err)
 	}
 
+	if (checkSumFlag & CHECKSUM_SHA1) != 0 {
+		wu, d := lfc.createChecksumWriteUnit(
+			NewHashChecksumWriter(sha1.New()),
+			true,
+			false,
+			func(sliceSum interface{}, sum interface{}) {
+				if sum != nil {
+					lfc.SHA1 = sum.([]byte)
+				}
+			},
+		)
+
+		wus = append(wus, wu)
+		defer d(err)
+	}
+	if (checkSumFlag & CHECKSUM_SHA256) != 0 {
+		wu, d := lfc.createChecksumWriteUnit(
+			NewHashChecksumWriter(sha256.New()),
+			true,
+			false,
+			func(sliceSum interface{}, sum interface{}) {
+				if sum != nil {
+					lfc.SHA256 = sum.([]byte)
+				}
+			},
+		)
+
+		wus = append(wus, wu)
+		defer d(err)
+	}
+
 	err = lfc.repeatRead(wus...)
 	return
 }
@@ -0,0 +1,48 @@
+package delay
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff 指数退避策略
+type Backoff struct {
+	Initial    time.Duration // 第一次重试的等待时间
+	Max        time.Duration // 最大等待时间
+	Multiplier float64       // 每次重试等待时间的倍数
+	Jitter     float64       // 随机抖动的比例, 0 ~ 1
+	MaxElapsed time.Duration // 从第一次执行开始, 超过此时间不再重试, 0 代表不限制
+}
+
+// Delay 第 retry 次重试前的等待时间, retry 从 1 开始
+func (b *Backoff) Delay(retry int) time.Duration {
+	if retry < 1 {
+		retry = 1
+	}
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	d := float64(b.Initial) * math.Pow(multiplier, float64(retry-1))
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		jitter := b.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		d = d * (1 - jitter + 2*jitter*rand.Float64())
+		if b.Max > 0 && d > float64(b.Max) {
+			d = float64(b.Max)
+		}
+	}
+	return time.Duration(d)
+}
+
+// Exceeded 已用时间是否超出限制
+func (b *Backoff) Exceeded(elapsed time.Duration) bool {
+	return b.MaxElapsed > 0 && elapsed >= b.MaxElapsed
+}
@@ -0,0 +1,49 @@
+package delay_test
+
+import (
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/delay"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := delay.Backoff{
+		Initial:    time.Second,
+		Max:        10 * time.Second,
+		Multiplier: 2,
+	}
+	expected := []time.Duration{time.Second, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for retry, want := range expected {
+		if d := b.Delay(retry); d != want {
+			t.Errorf("retry %d: got %s, want %s", retry, d, want)
+		}
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	b := delay.Backoff{
+		Initial:    4 * time.Second,
+		Max:        5 * time.Second,
+		Multiplier: 2,
+		Jitter:     0.5,
+	}
+	for i := 0; i < 100; i++ {
+		if d := b.Delay(1); d < 2*time.Second || d > 5*time.Second {
+			t.Fatalf("retry 1: %s out of range", d)
+		}
+		if d := b.Delay(3); d < 2500*time.Millisecond || d > 5*time.Second {
+			t.Fatalf("retry 3: %s out of range", d)
+		}
+	}
+}
+
+func TestBackoffExceeded(t *testing.T) {
+	b := delay.Backoff{}
+	if b.Exceeded(time.Hour) {
+		t.Error("MaxElapsed 0 should never exceed")
+	}
+	b.MaxElapsed = time.Minute
+	if b.Exceeded(time.Second) || !b.Exceeded(time.Minute) {
+		t.Error("unexpected Exceeded result")
+	}
+}
@@ -0,0 +1,42 @@
+// Package diskfree 获取磁盘剩余空间
+package diskfree
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrUnsupported 当前系统不支持获取磁盘剩余空间
+	ErrUnsupported = errors.New("get disk free space is not supported on this platform")
+)
+
+// ExistingDir 返回 p 自身或最近的已存在的上级目录
+func ExistingDir(p string) string {
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	for {
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			return p
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return p
+		}
+		p = parent
+	}
+}
+
+// Free 获取 p 所在磁盘的可用空间, p 不存在时使用最近的已存在的上级目录
+func Free(p string) (int64, error) {
+	return free(ExistingDir(p))
+}
+
+// Volume 返回 p 所在文件系统的标识, 同一文件系统中的路径返回相同的值,
+// p 不存在时使用最近的已存在的上级目录
+func Volume(p string) (string, error) {
+	return volume(ExistingDir(p))
+}
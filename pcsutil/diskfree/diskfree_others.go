@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package diskfree
+
+func free(dir string) (int64, error) {
+	return 0, ErrUnsupported
+}
+
+func volume(dir string) (string, error) {
+	return "", ErrUnsupported
+}
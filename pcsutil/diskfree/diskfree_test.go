@@ -0,0 +1,37 @@
+package diskfree_test
+
+import (
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/diskfree"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFree(t *testing.T) {
+	dir := t.TempDir()
+	if got := diskfree.ExistingDir(filepath.Join(dir, "a", "b", "c.txt")); got != dir {
+		t.Fatalf("existing dir: %s, want: %s", got, dir)
+	}
+
+	vol, err := diskfree.Volume(dir)
+	if err == nil {
+		sub, err := diskfree.Volume(filepath.Join(dir, "a", "b"))
+		if err != nil || sub != vol {
+			t.Fatalf("volume of missing subdir: %s, %v, want: %s", sub, err, vol)
+		}
+	} else if err != diskfree.ErrUnsupported {
+		t.Fatal(err)
+	}
+
+	free, err := diskfree.Free(filepath.Join(dir, "not_exist"))
+	if err == diskfree.ErrUnsupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free <= 0 {
+		t.Fatalf("free: %d", free)
+	}
+	os.RemoveAll(dir)
+}
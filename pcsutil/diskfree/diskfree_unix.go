@@ -0,0 +1,27 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package diskfree
+
+import (
+	"strconv"
+	"syscall"
+)
+
+func free(dir string) (int64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(dir, &stat)
+	if err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+func volume(dir string) (string, error) {
+	var stat syscall.Stat_t
+	err := syscall.Stat(dir, &stat)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(uint64(stat.Dev), 10), nil
+}
@@ -0,0 +1,33 @@
+package diskfree
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+func free(dir string) (int64, error) {
+	dirPtr, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var freeBytes uint64
+	err = windows.GetDiskFreeSpaceEx(dirPtr, &freeBytes, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	return int64(freeBytes), nil
+}
+
+func volume(dir string) (string, error) {
+	dirPtr, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]uint16, windows.MAX_PATH+1)
+	err = windows.GetVolumePathName(dirPtr, &buf[0], uint32(len(buf)))
+	if err != nil {
+		return "", err
+	}
+	return windows.UTF16ToString(buf), nil
+}
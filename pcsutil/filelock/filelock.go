@@ -0,0 +1,42 @@
+// Package filelock 进程间的文件锁
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+)
+
+type (
+	// Lock 文件锁
+	Lock struct {
+		f *os.File
+	}
+)
+
+// Acquire 获取文件锁, 锁文件不存在时自动创建, 其他进程持有锁时等待.
+// 锁文件应与被保护的文件分开, 删除或重写被保护的文件不影响锁.
+// 不支持文件锁的系统上不加锁
+func Acquire(name string) (*Lock, error) {
+	err := os.MkdirAll(filepath.Dir(name), 0700)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err = lock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Lock{f: f}, nil
+}
+
+// Release 释放文件锁
+func (l *Lock) Release() error {
+	err := unlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
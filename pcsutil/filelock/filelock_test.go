@@ -0,0 +1,41 @@
+package filelock_test
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/filelock"
+)
+
+func TestAcquire(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "sub", "a.lock")
+	l, err := filelock.Acquire(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		l2, err := filelock.Acquire(name)
+		if err != nil {
+			t.Error(err)
+		} else {
+			l2.Release()
+		}
+		close(acquired)
+	}()
+
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		select {
+		case <-acquired:
+			t.Fatal("lock acquired twice")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	if err = l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	<-acquired
+}
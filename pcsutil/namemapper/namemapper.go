@@ -0,0 +1,355 @@
+// Package namemapper 文件名映射, 用于在不同文件系统之间保存网盘文件
+//
+// 非法字符会被替换为对应的全角字符或控制符号(如 ':' => '：', 0x01 => '␁'),
+// 末尾的 '.' 和 ' ' 替换为 '．' 和 '␠', windows 保留的文件名 (如 CON) 以 '‛' 作为前缀.
+// 只有 <>:"/\|?* 的全角字符, 控制符号和末尾的 '．', '␠' 会被还原,
+// 文件名本身含有的这些字符会以 '‛' 作为前缀转义, 从而保证映射可逆, 其他全角字符 (如 '（') 保持不变.
+package namemapper
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang.org/x/text/unicode/norm"
+	"path"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Profile 目标文件系统的规则
+type Profile string
+
+const (
+	// ProfileNone 不映射
+	ProfileNone Profile = ""
+	// ProfilePOSIX linux, macOS 等
+	ProfilePOSIX Profile = "posix"
+	// ProfileWindows windows (NTFS)
+	ProfileWindows Profile = "windows"
+	// ProfileExFAT exFAT, FAT32 等, 常见于U盘, 移动硬盘, 部分 NAS
+	ProfileExFAT Profile = "exfat"
+)
+
+// Normalization Unicode 规范化形式
+type Normalization string
+
+const (
+	// NormNone 不进行规范化
+	NormNone Normalization = ""
+	// NormNFC NFC, 百度网盘和大多数系统使用的形式
+	NormNFC Normalization = "nfc"
+	// NormNFD NFD, macOS 的 HFS+ 使用的形式
+	NormNFD Normalization = "nfd"
+)
+
+const (
+	// escapeRune 转义前缀
+	escapeRune = '‛'
+
+	// DefaultMaxNameLength 默认的文件名最大长度
+	DefaultMaxNameLength = 255
+
+	// hashSuffixLength 截断时附加的散列长度
+	hashSuffixLength = 8
+)
+
+var (
+	// ErrPathTooLong 路径过长且无法截断
+	ErrPathTooLong = errors.New("path too long")
+
+	windowsReservedNames = map[string]bool{
+		"CON": true, "PRN": true, "AUX": true, "NUL": true,
+		"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+		"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	}
+)
+
+type (
+	// Mapper 文件名映射
+	Mapper struct {
+		Profile       Profile
+		Normalization Normalization
+		MaxNameLength int // 单个文件名的最大长度, posix 按字节计算, windows/exfat 按 UTF-16 计算, 0 为默认值
+		MaxPathLength int // 完整路径的最大长度, 0 为不限制
+	}
+)
+
+// CheckProfile 检查 profile 是否合法
+func CheckProfile(p string) bool {
+	switch Profile(p) {
+	case ProfileNone, ProfilePOSIX, ProfileWindows, ProfileExFAT:
+		return true
+	}
+	return false
+}
+
+// CheckNormalization 检查规范化形式是否合法
+func CheckNormalization(n string) bool {
+	switch Normalization(n) {
+	case NormNone, NormNFC, NormNFD:
+		return true
+	}
+	return false
+}
+
+// IsEnabled 是否需要进行映射
+func (m *Mapper) IsEnabled() bool {
+	return m != nil && (m.Profile != ProfileNone || m.Normalization != NormNone || m.MaxPathLength > 0)
+}
+
+func (m *Mapper) normalize(s string) string {
+	switch m.Normalization {
+	case NormNFC:
+		return norm.NFC.String(s)
+	case NormNFD:
+		return norm.NFD.String(s)
+	}
+	return s
+}
+
+// substitutedChars 会被替换为全角字符的非法字符
+const substitutedChars = `<>:"/\|?*`
+
+// isReplacement 是否为映射使用的替换字符, 末尾的 '．' 和 '␠' 由 isTrailingReplacement 判断
+func isReplacement(r rune) bool {
+	switch {
+	case r == escapeRune:
+		return true
+	case r >= 0x2400 && r < 0x2420:
+		return true
+	case r >= 0xFF01 && r <= 0xFF5E:
+		return strings.ContainsRune(substitutedChars, r-0xFEE0)
+	}
+	return false
+}
+
+// isTrailingReplacement 是否为末尾的 '.' 和 ' ' 使用的替换字符
+func isTrailingReplacement(r rune) bool {
+	return r == 0xFF0E || r == 0x2420
+}
+
+// isTrailing 判断 runes[i:] 是否只含有 '.', ' ' 和它们的替换字符
+func isTrailing(runes []rune, i int) bool {
+	for _, r := range runes[i:] {
+		if r != '.' && r != ' ' && !isTrailingReplacement(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// replace 非法字符 => 替换字符
+func replace(r rune) rune {
+	switch {
+	case r < 0x20:
+		return 0x2400 + r
+	case r == ' ':
+		return 0x2420
+	}
+	return r + 0xFEE0
+}
+
+// restore 替换字符 => 原字符
+func restore(r rune) rune {
+	switch {
+	case r >= 0xFF01 && r <= 0xFF5E:
+		return r - 0xFEE0
+	case r >= 0x2400 && r < 0x2420:
+		return r - 0x2400
+	case r == 0x2420:
+		return ' '
+	}
+	return r
+}
+
+func (m *Mapper) illegal(r rune) bool {
+	switch m.Profile {
+	case ProfilePOSIX:
+		return r == 0 || r == '/'
+	case ProfileWindows, ProfileExFAT:
+		return r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r)
+	}
+	return false
+}
+
+// EncodeName 映射单个文件名
+func (m *Mapper) EncodeName(name string) string {
+	name = m.normalize(name)
+	if m.Profile == ProfileNone {
+		return name
+	}
+
+	var (
+		runes   = []rune(name)
+		builder strings.Builder
+		// 末尾的 '.' 和 ' ' 在 windows 下会被丢弃
+		trailing = len(runes)
+	)
+	if m.Profile != ProfilePOSIX {
+		for trailing > 0 && (runes[trailing-1] == '.' || runes[trailing-1] == ' ') {
+			trailing--
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case isReplacement(r), isTrailingReplacement(r) && isTrailing(runes, i):
+			builder.WriteRune(escapeRune)
+			builder.WriteRune(r)
+		case m.illegal(r) || i >= trailing:
+			builder.WriteRune(replace(r))
+		case i == 0 && m.Profile == ProfileWindows && isWindowsReserved(name):
+			// 添加前缀, 避免与保留的文件名相同
+			builder.WriteRune(escapeRune)
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// DecodeName 还原映射后的文件名, 启用规范化时统一还原为网盘使用的 NFC 形式
+func (m *Mapper) DecodeName(name string) string {
+	if m.Profile != ProfileNone {
+		var (
+			runes   []rune
+			escaped []bool
+			builder strings.Builder
+		)
+		for src := []rune(name); len(src) > 0; src = src[1:] {
+			if src[0] == escapeRune && len(src) > 1 {
+				src = src[1:]
+				runes, escaped = append(runes, src[0]), append(escaped, true)
+				continue
+			}
+			runes, escaped = append(runes, src[0]), append(escaped, false)
+		}
+
+		// 末尾未转义的 '．' 和 '␠' 才是替换字符
+		trailing := len(runes)
+		for trailing > 0 && !escaped[trailing-1] && isTrailingReplacement(runes[trailing-1]) {
+			trailing--
+		}
+		for i, r := range runes {
+			switch {
+			case escaped[i]:
+				builder.WriteRune(r)
+			case isReplacement(r), i >= trailing:
+				builder.WriteRune(restore(r))
+			default:
+				builder.WriteRune(r)
+			}
+		}
+		name = builder.String()
+	}
+	if m.Normalization != NormNone {
+		return norm.NFC.String(name)
+	}
+	return name
+}
+
+func isWindowsReserved(name string) bool {
+	stem := name
+	if i := strings.IndexByte(stem, '.'); i >= 0 {
+		stem = stem[:i]
+	}
+	return windowsReservedNames[strings.ToUpper(stem)]
+}
+
+// nameLength 按 profile 计算文件名长度
+func (m *Mapper) nameLength(name string) int {
+	if m.Profile == ProfileWindows || m.Profile == ProfileExFAT {
+		return len(utf16.Encode([]rune(name)))
+	}
+	return len(name)
+}
+
+func (m *Mapper) maxNameLength() int {
+	if m.MaxNameLength > 0 {
+		return m.MaxNameLength
+	}
+	return DefaultMaxNameLength
+}
+
+// truncateName 截断文件名到 max 以内, 保留扩展名, 并附加原文件名的散列以避免冲突
+func (m *Mapper) truncateName(name string, max int) (string, error) {
+	if m.nameLength(name) <= max {
+		return name, nil
+	}
+
+	sum := md5.Sum([]byte(name))
+	var (
+		ext    = path.Ext(name)
+		suffix = "~" + hex.EncodeToString(sum[:])[:hashSuffixLength] + ext
+		stem   = []rune(strings.TrimSuffix(name, ext))
+	)
+	if m.nameLength(suffix) >= max {
+		// 扩展名过长, 不保留
+		suffix = "~" + hex.EncodeToString(sum[:])[:hashSuffixLength]
+		stem = []rune(name)
+	}
+
+	for len(stem) > 0 && m.nameLength(string(stem)+suffix) > max {
+		stem = stem[:len(stem)-1]
+	}
+	if len(stem) == 0 {
+		return "", ErrPathTooLong
+	}
+	return string(stem) + suffix, nil
+}
+
+// MapPath 映射以 / 分隔的相对路径, base 为保存目录, 用于计算完整路径长度,
+// 路径长度与文件名长度使用相同的单位
+func (m *Mapper) MapPath(p, base string) (string, error) {
+	if !m.IsEnabled() {
+		return p, nil
+	}
+
+	var (
+		parts = strings.Split(p, "/")
+		err   error
+	)
+	for k := range parts {
+		if parts[k] == "" || parts[k] == "." || parts[k] == ".." {
+			continue
+		}
+		parts[k], err = m.truncateName(m.EncodeName(parts[k]), m.maxNameLength())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if m.MaxPathLength <= 0 {
+		return strings.Join(parts, "/"), nil
+	}
+
+	// 完整路径过长, 截断最后一级
+	last := len(parts) - 1
+	over := m.nameLength(base) + 1 + m.nameLength(strings.Join(parts, "/")) - m.MaxPathLength
+	if over > 0 {
+		parts[last], err = m.truncateName(parts[last], m.nameLength(parts[last])-over)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", p, ErrPathTooLong)
+		}
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// UnmapPath 还原以 / 分隔的路径
+func (m *Mapper) UnmapPath(p string) string {
+	if !m.IsEnabled() {
+		return p
+	}
+
+	parts := strings.Split(p, "/")
+	for k := range parts {
+		if !utf8.ValidString(parts[k]) {
+			continue
+		}
+		parts[k] = m.DecodeName(parts[k])
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,75 @@
+package namemapper_test
+
+import (
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/namemapper"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	m := &namemapper.Mapper{Profile: namemapper.ProfileWindows}
+	for _, name := range []string{"a:b?.txt", "trailing. ", "CON.txt", "con", "已有：全角‛", "normal.mp4", "tab\tname",
+		"报告（终稿）.doc", "问题？.txt", "全角结尾．", "全角结尾．.", "‛．.", "ＣＯＮ", "a␠b ", "‛CON"} {
+		encoded := m.EncodeName(name)
+		if strings.ContainsAny(encoded, `<>:"/\|?*`+"\t") || strings.HasSuffix(encoded, ".") || strings.HasSuffix(encoded, " ") {
+			t.Errorf("illegal encoded name: %q => %q", name, encoded)
+		}
+		if decoded := m.DecodeName(encoded); decoded != name {
+			t.Errorf("not reversible: %q => %q => %q", name, encoded, decoded)
+		}
+	}
+	if m.EncodeName("normal.mp4") != "normal.mp4" {
+		t.Errorf("legal name changed")
+	}
+}
+
+func TestUnmapKeepsFullwidth(t *testing.T) {
+	// 上传本地文件时, 不是映射产生的全角标点不能被还原
+	for _, profile := range []namemapper.Profile{namemapper.ProfilePOSIX, namemapper.ProfileWindows, namemapper.ProfileExFAT} {
+		m := &namemapper.Mapper{Profile: profile}
+		for _, p := range []string{"报告（终稿）.doc", "目录，一/文件！", "ＡＢＣ．txt", "句号．结尾 x"} {
+			if got := m.UnmapPath(p); got != p {
+				t.Errorf("%s: %q => %q", profile, p, got)
+			}
+			if got := m.UnmapPath(m.EncodeName(p)); !strings.Contains(p, "/") && got != p {
+				t.Errorf("%s: not reversible %q => %q", profile, p, got)
+			}
+		}
+		if got := m.UnmapPath("问题？/a：b"); got != "问题?/a:b" {
+			t.Errorf("%s: substituted characters not restored: %q", profile, got)
+		}
+	}
+}
+
+func TestMapPathTooLong(t *testing.T) {
+	m := &namemapper.Mapper{Profile: namemapper.ProfileExFAT, MaxPathLength: 40}
+	base := "D:/下载/百度网盘"
+	p, err := m.MapPath("dir/"+strings.Repeat("长", 50)+".mp4", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(p, ".mp4") || len(utf16.Encode([]rune(base+"/"+p))) != 40 {
+		t.Errorf("bad truncated path: %s", p)
+	}
+
+	// posix 按字节计算, 中文的保存目录占 3 字节
+	m = &namemapper.Mapper{Profile: namemapper.ProfilePOSIX, MaxPathLength: 60}
+	p, err = m.MapPath("目录/"+strings.Repeat("长", 30)+".mp4", "/下载")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(p, ".mp4") || len("/下载/"+p) > 60 {
+		t.Errorf("bad truncated path: %s (%d bytes)", p, len("/下载/"+p))
+	}
+}
+
+func TestNormalization(t *testing.T) {
+	m := &namemapper.Mapper{Normalization: namemapper.NormNFD}
+	if m.EncodeName("caf\u00e9") != "cafe\u0301" {
+		t.Errorf("nfd failed")
+	}
+	if m.UnmapPath("cafe\u0301/a") != "caf\u00e9/a" {
+		t.Errorf("nfc failed")
+	}
+}
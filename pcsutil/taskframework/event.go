@@ -0,0 +1,76 @@
+package taskframework
+
+type (
+	// EventType 任务事件类型
+	EventType int
+
+	// Event 任务事件
+	Event struct {
+		Type   EventType
+		Info   *TaskInfo
+		Unit   TaskUnit
+		Result *TaskUnitRunResult // 执行结果, 只在 Retried, Succeeded, Failed, Canceled 时有效
+
+		// 进度, 只在 Progress 时有效
+		Done  int64
+		Total int64
+
+		// 输出信息, 在 Message 时有效, Progress 时为可选的状态行
+		Message string
+	}
+
+	// Observer 任务事件订阅者, 事件在执行任务的 goroutine 中同步回调,
+	// 实现需要保证并发安全, 且不应阻塞
+	Observer interface {
+		OnTaskEvent(e *Event)
+	}
+
+	// ObserverFunc 函数形式的 Observer
+	ObserverFunc func(e *Event)
+)
+
+const (
+	// EventQueued 任务加入队列
+	EventQueued EventType = iota
+	// EventStarted 任务开始执行
+	EventStarted
+	// EventProgress 任务进度更新
+	EventProgress
+	// EventRetried 任务执行失败, 等待重试
+	EventRetried
+	// EventSucceeded 任务执行成功
+	EventSucceeded
+	// EventFailed 任务执行失败
+	EventFailed
+	// EventCanceled 任务被取消
+	EventCanceled
+	// EventMessage 任务输出信息
+	EventMessage
+)
+
+// OnTaskEvent 实现 Observer
+func (f ObserverFunc) OnTaskEvent(e *Event) {
+	f(e)
+}
+
+func (t EventType) String() string {
+	switch t {
+	case EventQueued:
+		return "queued"
+	case EventStarted:
+		return "started"
+	case EventProgress:
+		return "progress"
+	case EventRetried:
+		return "retried"
+	case EventSucceeded:
+		return "succeeded"
+	case EventFailed:
+		return "failed"
+	case EventCanceled:
+		return "canceled"
+	case EventMessage:
+		return "message"
+	}
+	return "unknown"
+}
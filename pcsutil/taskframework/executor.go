@@ -1,23 +1,47 @@
 package taskframework
 
 import (
+	"context"
+	"errors"
 	"github.com/GeertJohan/go.incremental"
 	"github.com/oleiade/lane"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/waitgroup"
 	"strconv"
+	"sync"
 	"time"
 )
 
+var (
+	// ErrTaskCanceled 任务已取消
+	ErrTaskCanceled = errors.New("task canceled")
+	// ErrDependencyFailed 依赖的任务未执行成功
+	ErrDependencyFailed = errors.New("dependency failed")
+)
+
 type (
 	TaskExecutor struct {
 		incr     *incremental.Int // 任务id生成
-		deque    *lane.Deque      // 队列
+		queue    *taskQueue       // 优先队列
 		parallel int              // 任务的最大并发量
 
+		ctx       context.Context
+		cancel    context.CancelFunc
+		mu        sync.Mutex
+		waiting   []*TaskInfoItem          // 等待依赖完成的任务
+		running   map[string]*TaskInfoItem // 执行中的任务
+		observers []Observer
+
 		// 是否统计失败队列
 		IsFailedDeque bool
 		failedDeque   *lane.Deque
 	}
+
+	// TaskOptions 任务可选项
+	TaskOptions struct {
+		MaxRetry  int
+		Priority  int         // 优先级, 越大越先执行
+		DependsOn []*TaskInfo // 依赖的任务, 需要在同一个 TaskExecutor 中, 全部执行成功后才会执行
+	}
 )
 
 func NewTaskExecutor() *TaskExecutor {
@@ -25,8 +49,8 @@ func NewTaskExecutor() *TaskExecutor {
 }
 
 func (te *TaskExecutor) lazyInit() {
-	if te.deque == nil {
-		te.deque = lane.NewDeque()
+	if te.queue == nil {
+		te.queue = &taskQueue{}
 	}
 	if te.incr == nil {
 		te.incr = &incremental.Int{}
@@ -34,7 +58,13 @@ func (te *TaskExecutor) lazyInit() {
 	if te.parallel < 1 {
 		te.parallel = 1
 	}
-	if te.IsFailedDeque {
+	if te.ctx == nil {
+		te.SetContext(context.Background())
+	}
+	if te.running == nil {
+		te.running = map[string]*TaskInfoItem{}
+	}
+	if te.IsFailedDeque && te.failedDeque == nil {
 		te.failedDeque = lane.NewDeque()
 	}
 }
@@ -44,32 +74,239 @@ func (te *TaskExecutor) SetParallel(parallel int) {
 	te.parallel = parallel
 }
 
+// SetContext 设置所有任务的父 context, 需要在加入任务之前设置
+func (te *TaskExecutor) SetContext(ctx context.Context) {
+	te.ctx, te.cancel = context.WithCancel(ctx)
+}
+
+// Subscribe 订阅任务事件
+func (te *TaskExecutor) Subscribe(o Observer) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.observers = append(te.observers[:len(te.observers):len(te.observers)], o)
+}
+
+func (te *TaskExecutor) emit(e *Event) {
+	te.mu.Lock()
+	observers := te.observers
+	te.mu.Unlock()
+	for _, o := range observers {
+		o.OnTaskEvent(e)
+	}
+}
+
 //Append 将任务加到任务队列末尾
 func (te *TaskExecutor) Append(unit TaskUnit, maxRetry int) *TaskInfo {
+	return te.AppendWithOptions(unit, &TaskOptions{
+		MaxRetry: maxRetry,
+	})
+}
+
+//AppendWithPriority 按优先级将任务加入队列, 优先级高的先执行
+func (te *TaskExecutor) AppendWithPriority(unit TaskUnit, maxRetry, priority int) *TaskInfo {
+	return te.AppendWithOptions(unit, &TaskOptions{
+		MaxRetry: maxRetry,
+		Priority: priority,
+	})
+}
+
+//AppendWithOptions 将任务加入队列, 有依赖的任务会在依赖全部执行成功后才加入队列
+func (te *TaskExecutor) AppendWithOptions(unit TaskUnit, opt *TaskOptions) *TaskInfo {
 	te.lazyInit()
+	if opt == nil {
+		opt = &TaskOptions{}
+	}
+
 	taskInfo := &TaskInfo{
 		id:       strconv.Itoa(te.incr.Next()),
-		maxRetry: maxRetry,
+		maxRetry: opt.MaxRetry,
+		priority: opt.Priority,
+		deps:     opt.DependsOn,
+	}
+	taskInfo.ctx, taskInfo.cancel = context.WithCancel(te.ctx)
+	taskInfo.notify = func(e *Event) {
+		e.Unit = unit
+		te.emit(e)
 	}
 	unit.SetTaskInfo(taskInfo)
-	te.deque.Append(&TaskInfoItem{
+	item := &TaskInfoItem{
 		Info: taskInfo,
 		Unit: unit,
-	})
+	}
+
+	te.mu.Lock()
+	ready, failed := depsState(taskInfo)
+	switch {
+	case failed:
+		te.mu.Unlock()
+		te.finishWithoutRun(item, TaskStateFailed, ErrDependencyFailed)
+	case !ready:
+		taskInfo.setState(TaskStateWaiting)
+		te.waiting = append(te.waiting, item)
+		te.mu.Unlock()
+		te.emit(&Event{Type: EventQueued, Info: taskInfo, Unit: unit})
+	default:
+		te.mu.Unlock()
+		te.enqueue(item)
+	}
 	return taskInfo
 }
 
+// depsState 依赖是否全部执行成功, 是否有依赖执行失败
+func depsState(info *TaskInfo) (ready, failed bool) {
+	ready = true
+	for _, dep := range info.deps {
+		switch dep.State() {
+		case TaskStateSucceeded:
+		case TaskStateFailed, TaskStateCanceled:
+			return false, true
+		default:
+			ready = false
+		}
+	}
+	return ready, false
+}
+
+func (te *TaskExecutor) enqueue(item *TaskInfoItem) {
+	item.Info.setState(TaskStateQueued)
+	te.queue.Push(item)
+	te.emit(&Event{Type: EventQueued, Info: item.Info, Unit: item.Unit})
+}
+
+// releaseDependents 将依赖已满足的任务加入队列, 依赖失败的任务标记为失败
+func (te *TaskExecutor) releaseDependents() {
+	var ready, failed []*TaskInfoItem
+	te.mu.Lock()
+	waiting := te.waiting[:0]
+	for _, item := range te.waiting {
+		r, f := depsState(item.Info)
+		switch {
+		case f:
+			failed = append(failed, item)
+		case r:
+			ready = append(ready, item)
+		default:
+			waiting = append(waiting, item)
+		}
+	}
+	te.waiting = waiting
+	te.mu.Unlock()
+
+	for _, item := range ready {
+		te.enqueue(item)
+	}
+	for _, item := range failed {
+		te.finishWithoutRun(item, TaskStateFailed, ErrDependencyFailed)
+	}
+}
+
+// finish 任务结束
+func (te *TaskExecutor) finish(item *TaskInfoItem, state TaskState, result *TaskUnitRunResult) {
+	item.Info.setState(state)
+	item.Info.cancel() // 释放 context
+
+	e := &Event{Info: item.Info, Unit: item.Unit, Result: result}
+	switch state {
+	case TaskStateSucceeded:
+		e.Type = EventSucceeded
+	case TaskStateCanceled:
+		e.Type = EventCanceled
+	default:
+		e.Type = EventFailed
+	}
+	te.emit(e)
+	te.releaseDependents()
+}
+
+// finishWithoutRun 未执行就结束的任务, 如被取消或依赖失败
+func (te *TaskExecutor) finishWithoutRun(item *TaskInfoItem, state TaskState, err error) {
+	result := &TaskUnitRunResult{
+		Err:           err,
+		ResultMessage: err.Error(),
+	}
+	item.Unit.OnFailed(result)
+	if te.IsFailedDeque && state == TaskStateFailed {
+		te.failedDeque.Append(item)
+	}
+	item.Unit.OnComplete(result)
+	te.finish(item, state, result)
+}
+
+//Cancel 取消任务, 未执行的任务直接结束, 执行中的任务通过 context 通知
+func (te *TaskExecutor) Cancel(id string) bool {
+	te.lazyInit()
+	if item := te.queue.Remove(id); item != nil {
+		te.finishWithoutRun(item, TaskStateCanceled, ErrTaskCanceled)
+		return true
+	}
+
+	te.mu.Lock()
+	for k, item := range te.waiting {
+		if item.Info.id != id {
+			continue
+		}
+		te.waiting = append(te.waiting[:k], te.waiting[k+1:]...)
+		te.mu.Unlock()
+		te.finishWithoutRun(item, TaskStateCanceled, ErrTaskCanceled)
+		return true
+	}
+	item, ok := te.running[id]
+	te.mu.Unlock()
+	if ok {
+		item.Info.cancel()
+	}
+	return ok
+}
+
+//SetPriority 修改等待中的任务的优先级, 任务不在队列中返回 false
+func (te *TaskExecutor) SetPriority(id string, priority int) bool {
+	te.lazyInit()
+	return te.queue.SetPriority(id, priority)
+}
+
+//BumpToFront 将等待中的任务移到队列最前, 多个任务之间保持原有顺序, 返回移动的任务数量
+func (te *TaskExecutor) BumpToFront(ids ...string) (n int) {
+	te.lazyInit()
+	priority, ok := te.queue.MaxPriority()
+	if !ok {
+		return 0
+	}
+	for _, id := range ids {
+		if te.queue.SetPriority(id, priority+1) {
+			n++
+		}
+	}
+	return n
+}
+
+//PendingTasks 返回等待中的任务, 按执行顺序排列
+func (te *TaskExecutor) PendingTasks() []*TaskInfoItem {
+	if te.queue == nil {
+		return nil
+	}
+	return te.queue.Items()
+}
+
 //AppendNoRetry 将任务加到任务队列末尾, 不重试
 func (te *TaskExecutor) AppendNoRetry(unit TaskUnit) {
 	te.Append(unit, 0)
 }
 
-//Count 返回任务数量
+//CancelAll 取消所有任务, 执行中的任务通过 context 通知, 未执行的任务在取出时结束
+func (te *TaskExecutor) CancelAll() {
+	te.lazyInit()
+	te.cancel()
+}
+
+//Count 返回任务数量, 包括等待依赖完成的任务
 func (te *TaskExecutor) Count() int {
-	if te.deque == nil {
+	if te.queue == nil {
 		return 0
 	}
-	return te.deque.Size()
+	te.mu.Lock()
+	n := len(te.waiting)
+	te.mu.Unlock()
+	return te.queue.Size() + n
 }
 
 //Execute 执行任务
@@ -79,71 +316,125 @@ func (te *TaskExecutor) Execute() {
 	for {
 		wg := waitgroup.NewWaitGroup(te.parallel)
 		for {
-			e := te.deque.Shift()
-			if e == nil { // 任务为空
+			// 先占用并发数再取任务, 保证取出的是当前优先级最高的任务
+			wg.AddDelta()
+			task := te.queue.Pop()
+			if task == nil { // 任务为空
+				wg.Done()
 				break
 			}
 
-			// 获取任务
-			task := e.(*TaskInfoItem)
-			wg.AddDelta()
+			// 已被取消
+			if task.Info.Context().Err() != nil {
+				wg.Done()
+				te.finishWithoutRun(task, TaskStateCanceled, ErrTaskCanceled)
+				continue
+			}
 
 			go func(task *TaskInfoItem) {
 				defer wg.Done()
-
-				result := task.Unit.Run()
-
-				// 返回结果为空
-				if result == nil {
-					task.Unit.OnComplete(result)
-					return
-				}
-
-				if result.Succeed {
-					task.Unit.OnSuccess(result)
-					task.Unit.OnComplete(result)
-					return
-				}
-
-				// 需要进行重试
-				if result.NeedRetry {
-					// 重试次数超出限制
-					// 执行失败
-					if task.Info.IsExceedRetry() {
-						task.Unit.OnFailed(result)
-						if te.IsFailedDeque {
-							// 加入失败队列
-							te.failedDeque.Append(task)
-						}
-						task.Unit.OnComplete(result)
-						return
-					}
-					task.Info.retry++         // 增加重试次数
-					task.Unit.OnRetry(result) // 调用重试
-					task.Unit.OnComplete(result)
-
-					time.Sleep(task.Unit.RetryWait()) // 等待
-					te.deque.Append(task)             // 重新加入队列末尾
-					return
-				}
-
-				// 执行失败
-				task.Unit.OnFailed(result)
-				if te.IsFailedDeque && result.Extra != "skip" {
-					// 加入失败队列
-					te.failedDeque.Append(task)
-				}
-				task.Unit.OnComplete(result)
+				te.run(task)
 			}(task)
 		}
 
 		wg.Wait()
 
 		// 没有任务了
-		if te.deque.Size() == 0 {
+		if te.queue.Size() == 0 {
 			break
 		}
 	}
+
+	// 剩下的任务依赖了不在此执行器中的任务, 无法执行
+	te.mu.Lock()
+	left := te.waiting
+	te.waiting = nil
+	te.mu.Unlock()
+	for _, item := range left {
+		te.finishWithoutRun(item, TaskStateFailed, ErrDependencyFailed)
+	}
+}
+
+func (te *TaskExecutor) run(task *TaskInfoItem) {
+	te.mu.Lock()
+	te.running[task.Info.id] = task
+	te.mu.Unlock()
+	defer func() {
+		te.mu.Lock()
+		delete(te.running, task.Info.id)
+		te.mu.Unlock()
+	}()
+
+	task.Info.setState(TaskStateRunning)
+	if task.Info.startTime.IsZero() {
+		task.Info.startTime = time.Now()
+	}
+	te.emit(&Event{Type: EventStarted, Info: task.Info, Unit: task.Unit})
+
+	result := task.Unit.Run()
+
+	// 返回结果为空
+	if result == nil {
+		task.Unit.OnComplete(result)
+		te.finish(task, TaskStateSucceeded, result)
+		return
+	}
+
+	if result.Succeed {
+		task.Unit.OnSuccess(result)
+		task.Unit.OnComplete(result)
+		te.finish(task, TaskStateSucceeded, result)
+		return
+	}
+
+	// 执行中被取消, 不再重试
+	if task.Info.Context().Err() != nil {
+		if result.Err == nil {
+			result.Err = ErrTaskCanceled
+		}
+		task.Unit.OnFailed(result)
+		task.Unit.OnComplete(result)
+		te.finish(task, TaskStateCanceled, result)
+		return
+	}
+
+	// 需要进行重试
+	if result.NeedRetry {
+		// 重试次数超出限制
+		// 执行失败
+		if task.Info.IsExceedRetry() {
+			task.Unit.OnFailed(result)
+			if te.IsFailedDeque {
+				// 加入失败队列
+				te.failedDeque.Append(task)
+			}
+			task.Unit.OnComplete(result)
+			te.finish(task, TaskStateFailed, result)
+			return
+		}
+		task.Info.retry++ // 增加重试次数
+		task.Info.lastErr = result.Err
+		task.Unit.OnRetry(result) // 调用重试
+		task.Unit.OnComplete(result)
+		te.emit(&Event{Type: EventRetried, Info: task.Info, Unit: task.Unit, Result: result})
+
+		// 等待, 期间可被取消
+		select {
+		case <-time.After(task.Unit.RetryWait()):
+		case <-task.Info.Context().Done():
+		}
+		te.enqueue(task) // 重新加入队列, 排在同优先级任务的末尾
+		return
+	}
+
+	// 执行失败
+	task.Unit.OnFailed(result)
+	if te.IsFailedDeque && result.Extra != "skip" {
+		// 加入失败队列
+		te.failedDeque.Append(task)
+	}
+	task.Unit.OnComplete(result)
+	te.finish(task, TaskStateFailed, result)
 }
 
 //FailedDeque 获取失败队列
@@ -151,9 +442,10 @@ func (te *TaskExecutor) FailedDeque() *lane.Deque {
 	return te.failedDeque
 }
 
-//Stop 停止执行
+//Stop 停止执行, 取消所有任务
 func (te *TaskExecutor) Stop() {
-
+	te.lazyInit()
+	te.cancel()
 }
 
 //Pause 暂停执行
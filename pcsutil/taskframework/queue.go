@@ -0,0 +1,125 @@
+package taskframework
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+type (
+	// taskQueue 任务优先队列, 优先级高的先执行, 优先级相同的按加入顺序执行
+	taskQueue struct {
+		items taskHeap
+		seq   uint64
+		mu    sync.Mutex
+	}
+
+	taskHeap []*TaskInfoItem
+)
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].Info.priority != h[j].Info.priority {
+		return h[i].Info.priority > h[j].Info.priority
+	}
+	return h[i].Info.seq < h[j].Info.seq
+}
+
+func (h taskHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].Info.index = i
+	h[j].Info.index = j
+}
+
+func (h *taskHeap) Push(x interface{}) {
+	item := x.(*TaskInfoItem)
+	item.Info.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *taskHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.Info.index = -1
+	*h = old[:n-1]
+	return item
+}
+
+// Push 加入队列
+func (q *taskQueue) Push(item *TaskInfoItem) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	item.Info.seq = q.seq
+	heap.Push(&q.items, item)
+}
+
+// Pop 取出优先级最高的任务, 队列为空返回 nil
+func (q *taskQueue) Pop() *TaskInfoItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return nil
+	}
+	return heap.Pop(&q.items).(*TaskInfoItem)
+}
+
+// Size 队列中的任务数量
+func (q *taskQueue) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// MaxPriority 队列中最高的优先级
+func (q *taskQueue) MaxPriority() (priority int, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return 0, false
+	}
+	return q.items[0].Info.priority, true
+}
+
+// SetPriority 修改队列中任务的优先级
+func (q *taskQueue) SetPriority(id string, priority int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, item := range q.items {
+		if item.Info.id == id {
+			item.Info.priority = priority
+			heap.Fix(&q.items, item.Info.index)
+			return true
+		}
+	}
+	return false
+}
+
+// Items 返回队列中任务的快照, 按执行顺序排列
+func (q *taskQueue) Items() []*TaskInfoItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := make([]*TaskInfoItem, len(q.items))
+	copy(items, q.items)
+
+	// 优先级可能同时被修改, 在锁内排序
+	sort.Slice(items, func(i, j int) bool {
+		return taskHeap(items).Less(i, j)
+	})
+	return items
+}
+
+// Remove 从队列中移除任务
+func (q *taskQueue) Remove(id string) *TaskInfoItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, item := range q.items {
+		if item.Info.id == id {
+			return heap.Remove(&q.items, item.Info.index).(*TaskInfoItem)
+		}
+	}
+	return nil
+}
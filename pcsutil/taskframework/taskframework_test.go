@@ -3,6 +3,7 @@ package taskframework_test
 import (
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+	"sync"
 	"testing"
 	"time"
 )
@@ -57,3 +58,160 @@ func TestTaskExecutor(t *testing.T) {
 	}
 	te.Execute()
 }
+
+type (
+	OrderUnit struct {
+		name     string
+		order    *[]string
+		taskInfo *taskframework.TaskInfo
+	}
+)
+
+func (ou *OrderUnit) SetTaskInfo(taskInfo *taskframework.TaskInfo) { ou.taskInfo = taskInfo }
+func (ou *OrderUnit) OnFailed(*taskframework.TaskUnitRunResult)    {}
+func (ou *OrderUnit) OnSuccess(*taskframework.TaskUnitRunResult)   {}
+func (ou *OrderUnit) OnComplete(*taskframework.TaskUnitRunResult)  {}
+func (ou *OrderUnit) OnRetry(*taskframework.TaskUnitRunResult)     {}
+func (ou *OrderUnit) RetryWait() time.Duration                     { return 0 }
+
+func (ou *OrderUnit) Run() (result *taskframework.TaskUnitRunResult) {
+	*ou.order = append(*ou.order, ou.name)
+	return &taskframework.TaskUnitRunResult{Succeed: true}
+}
+
+func TestTaskExecutorPriority(t *testing.T) {
+	var (
+		order []string
+		te    = taskframework.NewTaskExecutor()
+	)
+	te.SetParallel(1)
+	te.Append(&OrderUnit{name: "a", order: &order}, 0)
+	te.Append(&OrderUnit{name: "b", order: &order}, 0)
+	te.AppendWithPriority(&OrderUnit{name: "c", order: &order}, 0, 1)
+	d := te.Append(&OrderUnit{name: "d", order: &order}, 0)
+	if te.BumpToFront(d.Id()) != 1 {
+		t.Fatalf("bump failed")
+	}
+	te.Execute()
+
+	if fmt.Sprint(order) != "[d c a b]" {
+		t.Fatalf("order: %v", order)
+	}
+}
+
+type (
+	FuncUnit struct {
+		run      func(info *taskframework.TaskInfo) *taskframework.TaskUnitRunResult
+		taskInfo *taskframework.TaskInfo
+	}
+)
+
+func (fu *FuncUnit) SetTaskInfo(taskInfo *taskframework.TaskInfo) { fu.taskInfo = taskInfo }
+func (fu *FuncUnit) OnFailed(*taskframework.TaskUnitRunResult)    {}
+func (fu *FuncUnit) OnSuccess(*taskframework.TaskUnitRunResult)   {}
+func (fu *FuncUnit) OnComplete(*taskframework.TaskUnitRunResult)  {}
+func (fu *FuncUnit) OnRetry(*taskframework.TaskUnitRunResult)     {}
+func (fu *FuncUnit) RetryWait() time.Duration                     { return 0 }
+func (fu *FuncUnit) Run() *taskframework.TaskUnitRunResult        { return fu.run(fu.taskInfo) }
+
+func TestTaskExecutorDependsOn(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+		te     = &taskframework.TaskExecutor{IsFailedDeque: true}
+		ok     = func(info *taskframework.TaskInfo) *taskframework.TaskUnitRunResult {
+			info.ReportProgress(1, 1)
+			return &taskframework.TaskUnitRunResult{Succeed: true}
+		}
+		fail = func(*taskframework.TaskInfo) *taskframework.TaskUnitRunResult {
+			return &taskframework.TaskUnitRunResult{}
+		}
+	)
+	te.SetParallel(4)
+	te.Subscribe(taskframework.ObserverFunc(func(e *taskframework.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e.Info.Id()+":"+e.Type.String())
+	}))
+
+	compress := te.Append(&FuncUnit{run: ok}, 0)
+	upload := te.AppendWithOptions(&FuncUnit{run: fail}, &taskframework.TaskOptions{DependsOn: []*taskframework.TaskInfo{compress}})
+	remove := te.AppendWithOptions(&FuncUnit{run: ok}, &taskframework.TaskOptions{DependsOn: []*taskframework.TaskInfo{upload}})
+	te.Execute()
+
+	if compress.State() != taskframework.TaskStateSucceeded || upload.State() != taskframework.TaskStateFailed || remove.State() != taskframework.TaskStateFailed {
+		t.Fatalf("states: %d %d %d", compress.State(), upload.State(), remove.State())
+	}
+	if te.FailedDeque().Size() != 2 {
+		t.Fatalf("failed: %d", te.FailedDeque().Size())
+	}
+	want := "[1:queued 2:queued 3:queued 1:started 1:progress 1:succeeded 2:queued 2:started 2:failed 3:failed]"
+	if fmt.Sprint(events) != want {
+		t.Fatalf("events: %v", events)
+	}
+}
+
+func TestTaskExecutorCancel(t *testing.T) {
+	var (
+		te      = taskframework.NewTaskExecutor()
+		started = make(chan struct{})
+	)
+	te.SetParallel(1)
+	running := te.Append(&FuncUnit{run: func(info *taskframework.TaskInfo) *taskframework.TaskUnitRunResult {
+		close(started)
+		<-info.Context().Done()
+		return &taskframework.TaskUnitRunResult{NeedRetry: true, Err: info.Context().Err()}
+	}}, 3)
+	queued := te.AppendWithPriority(&FuncUnit{run: func(*taskframework.TaskInfo) *taskframework.TaskUnitRunResult {
+		t.Error("canceled task should not run")
+		return nil
+	}}, 0, -1)
+
+	go func() {
+		<-started
+		te.Cancel(queued.Id())
+		te.Cancel(running.Id())
+	}()
+	te.Execute()
+
+	if running.State() != taskframework.TaskStateCanceled || queued.State() != taskframework.TaskStateCanceled {
+		t.Fatalf("states: %d %d", running.State(), queued.State())
+	}
+}
+
+func TestTaskExecutorCancelAll(t *testing.T) {
+	var (
+		te      = taskframework.NewTaskExecutor()
+		started = make(chan struct{})
+		msgs    []string
+	)
+	te.SetParallel(1)
+	te.Subscribe(taskframework.ObserverFunc(func(e *taskframework.Event) {
+		if e.Type == taskframework.EventMessage {
+			msgs = append(msgs, e.Message)
+		}
+	}))
+	running := te.Append(&FuncUnit{run: func(info *taskframework.TaskInfo) *taskframework.TaskUnitRunResult {
+		info.Printf("[%s] running\n", info.Id())
+		close(started)
+		<-info.Context().Done()
+		return &taskframework.TaskUnitRunResult{NeedRetry: true, Err: info.Context().Err()}
+	}}, 3)
+	queued := te.Append(&FuncUnit{run: func(*taskframework.TaskInfo) *taskframework.TaskUnitRunResult {
+		t.Error("canceled task should not run")
+		return nil
+	}}, 0)
+
+	go func() {
+		<-started
+		te.CancelAll()
+	}()
+	te.Execute()
+
+	if running.State() != taskframework.TaskStateCanceled || queued.State() != taskframework.TaskStateCanceled {
+		t.Fatalf("states: %d %d", running.State(), queued.State())
+	}
+	if fmt.Sprint(msgs) != "[[1] running\n]" {
+		t.Fatalf("messages: %q", msgs)
+	}
+}
@@ -1,10 +1,33 @@
 package taskframework
 
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
 type (
+	// TaskState 任务状态
+	TaskState int32
+
 	TaskInfo struct {
 		id       string
 		maxRetry int
 		retry    int
+		priority int // 优先级, 越大越先执行
+
+		seq   uint64 // 入队顺序
+		index int    // 在队列中的位置
+
+		ctx    context.Context
+		cancel context.CancelFunc
+		deps   []*TaskInfo // 依赖的任务
+		state  int32
+		notify func(e *Event)
+
+		startTime time.Time // 第一次执行的时间
+		lastErr   error     // 上一次执行的错误
 	}
 
 	TaskInfoItem struct {
@@ -13,6 +36,21 @@ type (
 	}
 )
 
+const (
+	// TaskStateWaiting 等待依赖的任务完成
+	TaskStateWaiting TaskState = iota
+	// TaskStateQueued 在队列中等待执行
+	TaskStateQueued
+	// TaskStateRunning 执行中
+	TaskStateRunning
+	// TaskStateSucceeded 执行成功
+	TaskStateSucceeded
+	// TaskStateFailed 执行失败
+	TaskStateFailed
+	// TaskStateCanceled 已取消
+	TaskStateCanceled
+)
+
 // IsExceedRetry 重试次数达到限制
 func (t *TaskInfo) IsExceedRetry() bool {
 	return t.retry >= t.maxRetry
@@ -33,3 +71,80 @@ func (t *TaskInfo) SetMaxRetry(maxRetry int) {
 func (t *TaskInfo) Retry() int {
 	return t.retry
 }
+
+// Priority 返回任务的优先级
+func (t *TaskInfo) Priority() int {
+	return t.priority
+}
+
+// Elapsed 从第一次执行到现在经过的时间, 未执行过返回 0
+func (t *TaskInfo) Elapsed() time.Duration {
+	if t.startTime.IsZero() {
+		return 0
+	}
+	return time.Since(t.startTime)
+}
+
+// LastError 上一次执行失败的错误, 可用于决定重试等待时间
+func (t *TaskInfo) LastError() error {
+	return t.lastErr
+}
+
+// Context 返回任务的 context, 任务被取消时 Done
+func (t *TaskInfo) Context() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// State 返回任务状态
+func (t *TaskInfo) State() TaskState {
+	return TaskState(atomic.LoadInt32(&t.state))
+}
+
+func (t *TaskInfo) setState(state TaskState) {
+	atomic.StoreInt32(&t.state, int32(state))
+}
+
+// IsFinished 任务是否已结束
+func (t *TaskInfo) IsFinished() bool {
+	switch t.State() {
+	case TaskStateSucceeded, TaskStateFailed, TaskStateCanceled:
+		return true
+	}
+	return false
+}
+
+// ReportProgress 报告任务进度, 发送 Progress 事件
+func (t *TaskInfo) ReportProgress(done, total int64) {
+	t.ReportStatus(done, total, "")
+}
+
+// ReportStatus 报告任务进度和状态行, 发送 Progress 事件
+func (t *TaskInfo) ReportStatus(done, total int64, status string) {
+	if t.notify == nil {
+		return
+	}
+	t.notify(&Event{
+		Type:    EventProgress,
+		Info:    t,
+		Done:    done,
+		Total:   total,
+		Message: status,
+	})
+}
+
+// Printf 输出任务信息, 发送 Message 事件, 任务不在执行器中时直接输出到标准输出
+func (t *TaskInfo) Printf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	if t.notify == nil {
+		fmt.Print(msg)
+		return
+	}
+	t.notify(&Event{
+		Type:    EventMessage,
+		Info:    t,
+		Message: msg,
+	})
+}
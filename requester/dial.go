@@ -37,6 +37,10 @@ var (
 
 // SetLocalTCPAddrList 设置网卡地址
 func SetLocalTCPAddrList(ips ...string) {
+	localTCPAddrList = parseTCPAddrList(ips...)
+}
+
+func parseTCPAddrList(ips ...string) []*net.TCPAddr {
 	list := make([]*net.TCPAddr, 0, len(ips))
 	for k := range ips {
 		p := net.ParseIP(ips[k])
@@ -48,17 +52,22 @@ func SetLocalTCPAddrList(ips ...string) {
 			IP: p,
 		})
 	}
-	localTCPAddrList = list
+	return list
 }
 
 func proxyFunc(req *http.Request) (*url.URL, error) {
-	u, err := checkProxyAddr(ProxyAddr)
+	return proxyWithRules(req, ProxyAddr, ProxyHostnameRules)
+}
+
+// proxyWithRules 根据代理地址和走代理的域名范围, 返回请求使用的代理
+func proxyWithRules(req *http.Request, proxyAddr, hostnameRules string) (*url.URL, error) {
+	u, err := checkProxyAddr(proxyAddr)
 	if err != nil {
 		return http.ProxyFromEnvironment(req)
 	}
 
-	if ProxyHostnameRules != "" {
-		if strings.Contains(ProxyHostnameRules, req.URL.Hostname()) {
+	if hostnameRules != "" {
+		if strings.Contains(hostnameRules, req.URL.Hostname()) {
 			return u, err
 		} else {
 			return http.ProxyFromEnvironment(req)
@@ -68,18 +77,26 @@ func proxyFunc(req *http.Request) (*url.URL, error) {
 }
 
 func getLocalTCPAddr() *net.TCPAddr {
-	if len(localTCPAddrList) == 0 {
+	return randomTCPAddr(localTCPAddrList)
+}
+
+func randomTCPAddr(list []*net.TCPAddr) *net.TCPAddr {
+	if len(list) == 0 {
 		return nil
 	}
-	i := mathrand.Intn(len(localTCPAddrList))
-	return localTCPAddrList[i]
+	i := mathrand.Intn(len(list))
+	return list[i]
 }
 
 func getDialer() *net.Dialer {
+	return newDialer(getLocalTCPAddr())
+}
+
+func newDialer(localAddr *net.TCPAddr) *net.Dialer {
 	return &net.Dialer{
 		Timeout:   30 * time.Second,
 		KeepAlive: 30 * time.Second,
-		LocalAddr: getLocalTCPAddr(),
+		LocalAddr: localAddr,
 		DualStack: true,
 	}
 }
@@ -141,6 +158,11 @@ func resolveTCPHost(ctx context.Context, host string) (ip net.IP, err error) {
 }
 
 func dialContext(ctx context.Context, network, address string) (conn net.Conn, err error) {
+	return dialContextWithLocalAddr(ctx, network, address, getLocalTCPAddr)
+}
+
+// dialContextWithLocalAddr 建立连接, 使用 localAddr 选择本地网卡地址, tcp 连接的域名解析结果会被缓存
+func dialContextWithLocalAddr(ctx context.Context, network, address string, localAddr func() *net.TCPAddr) (conn net.Conn, err error) {
 	switch network {
 	case "tcp", "tcp4", "tcp6":
 		host, portStr, err := net.SplitHostPort(address)
@@ -163,14 +185,15 @@ func dialContext(ctx context.Context, network, address string) (conn net.Conn, e
 			return nil, err
 		}
 
-		return net.DialTCP(network, getLocalTCPAddr(), &net.TCPAddr{
+		tcpAddr := &net.TCPAddr{
 			IP:   data.Data().(net.IP),
 			Port: port, // 设置端口
-		})
+		}
+		return newDialer(localAddr()).DialContext(ctx, network, tcpAddr.String())
 	}
 
 	// 非 tcp 请求
-	conn, err = getDialer().DialContext(ctx, network, address)
+	conn, err = newDialer(localAddr()).DialContext(ctx, network, address)
 	return
 }
 
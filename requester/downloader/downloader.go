@@ -456,8 +456,9 @@ func (der *Downloader) Execute() error {
 	// 开始执行
 	der.executeTime = time.Now()
 	pcsutil.Trigger(der.onExecuteEvent)
-	der.downloadStatusEvent() // 启动执行状态处理事件
+	der.downloadStatusEvent(moniterCtx) // 启动执行状态处理事件
 	der.monitor.Execute(moniterCtx)
+	moniterCancelFunc() // 停止执行状态处理事件
 
 	// 检查错误
 	err = der.monitor.Err()
@@ -474,7 +475,7 @@ func (der *Downloader) Execute() error {
 }
 
 // downloadStatusEvent 执行状态处理事件
-func (der *Downloader) downloadStatusEvent() {
+func (der *Downloader) downloadStatusEvent(ctx context.Context) {
 	if der.onDownloadStatusEvent == nil {
 		return
 	}
@@ -487,6 +488,8 @@ func (der *Downloader) downloadStatusEvent() {
 			select {
 			case <-der.monitor.completed:
 				return
+			case <-ctx.Done():
+				return
 			case <-ticker.C:
 				der.onDownloadStatusEvent(status, der.monitor.RangeWorker)
 			}
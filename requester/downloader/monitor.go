@@ -370,6 +370,14 @@ func (mt *Monitor) Execute(cancelCtx context.Context) {
 					pcsverbose.Verbosef("DEBUG: cancel failed, worker id: %d, err: %s\n", worker.ID(), err)
 				}
 			}
+			// 保留断点信息, 下次继续下载
+			if mt.instanceState != nil {
+				mt.instanceState.Put(&transfer.DownloadInstanceInfo{
+					DownloadStatus: mt.status,
+					Ranges:         mt.GetAllWorkersRange(),
+				})
+			}
+			mt.err = cancelCtx.Err()
 			return
 		case <-mt.completed:
 			return
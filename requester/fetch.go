@@ -2,6 +2,7 @@ package requester
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester/rio"
 	"io"
@@ -38,6 +39,11 @@ func Fetch(method string, urlStr string, post interface{}, header map[string]str
 // post (post 数据), header (header 请求头数据), 进行网站访问。
 // 返回值分别为 *http.Response, 错误信息
 func (h *HTTPClient) Req(method string, urlStr string, post interface{}, header map[string]string) (resp *http.Response, err error) {
+	return h.ReqWithContext(context.Background(), method, urlStr, post, header)
+}
+
+// ReqWithContext 同 Req, ctx 被取消或超时时中断请求
+func (h *HTTPClient) ReqWithContext(ctx context.Context, method string, urlStr string, post interface{}, header map[string]string) (resp *http.Response, err error) {
 	h.lazyInit()
 	var (
 		req           *http.Request
@@ -90,7 +96,7 @@ func (h *HTTPClient) Req(method string, urlStr string, post interface{}, header
 			contentType = value.ContentType()
 		}
 	}
-	req, err = http.NewRequest(method, urlStr, obody)
+	req, err = http.NewRequestWithContext(ctx, method, urlStr, obody)
 	if err != nil {
 		return nil, err
 	}
@@ -126,8 +132,13 @@ func (h *HTTPClient) Req(method string, urlStr string, post interface{}, header
 // post (post 数据), header (header 请求头数据), 进行网站访问。
 // 返回值分别为 网站主体, 错误信息
 func (h *HTTPClient) Fetch(method string, urlStr string, post interface{}, header map[string]string) (body []byte, err error) {
+	return h.FetchWithContext(context.Background(), method, urlStr, post, header)
+}
+
+// FetchWithContext 同 Fetch, ctx 被取消或超时时中断请求
+func (h *HTTPClient) FetchWithContext(ctx context.Context, method string, urlStr string, post interface{}, header map[string]string) (body []byte, err error) {
 	h.lazyInit()
-	resp, err := h.Req(method, urlStr, post, header)
+	resp, err := h.ReqWithContext(ctx, method, urlStr, post, header)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
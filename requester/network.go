@@ -0,0 +1,40 @@
+package requester
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// NetworkOptions 网络设置, 只对设置的 HTTPClient 生效, 不影响全局设置
+type NetworkOptions struct {
+	Proxy          string   // 代理地址, 支持 http/socks5 代理, 为空则使用环境变量中的代理
+	ProxyHostnames string   // 走代理的域名范围, 多个域名以逗号分隔, 为空表示全部代理
+	LocalAddrs     []string // 本地网卡地址, 多个地址时随机选择
+}
+
+// SetNetworkOptions 设置当前 HTTPClient 的代理和本地网卡地址, 代替全局的
+// SetGlobalProxy, SetProxyHostnameRules, SetLocalTCPAddrList. opt 为空时恢复使用全局设置
+func (h *HTTPClient) SetNetworkOptions(opt *NetworkOptions) {
+	h.lazyInit()
+	if opt == nil {
+		h.transport.Proxy = proxyFunc
+		h.transport.DialContext = dialContext
+		return
+	}
+
+	var (
+		proxyAddr     = opt.Proxy
+		hostnameRules = opt.ProxyHostnames
+		localAddrs    = parseTCPAddrList(opt.LocalAddrs...)
+	)
+	h.transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyWithRules(req, proxyAddr, hostnameRules)
+	}
+	h.transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dialContextWithLocalAddr(ctx, network, address, func() *net.TCPAddr {
+			return randomTCPAddr(localAddrs)
+		})
+	}
+}
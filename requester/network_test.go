@@ -0,0 +1,75 @@
+package requester_test
+
+import (
+	"context"
+	"errors"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestReqWithContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	client := requester.NewHTTPClient()
+	start := time.Now()
+	_, err := client.ReqWithContext(ctx, http.MethodGet, server.URL, nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("request not interrupted, elapsed %s", elapsed)
+	}
+}
+
+func TestSetNetworkOptions(t *testing.T) {
+	var proxied bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.Host == "example.invalid"
+		w.Write([]byte("ok"))
+	}))
+	defer proxy.Close()
+
+	client := requester.NewHTTPClient()
+	client.SetNetworkOptions(&requester.NetworkOptions{
+		Proxy:      proxy.URL,
+		LocalAddrs: []string{"127.0.0.1"},
+	})
+	body, err := client.Fetch(http.MethodGet, "http://example.invalid/test", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok" || !proxied {
+		t.Fatalf("request not sent through proxy, body: %s", body)
+	}
+
+	// 不在代理域名范围内的请求不走代理
+	client.SetNetworkOptions(&requester.NetworkOptions{
+		Proxy:          proxy.URL,
+		ProxyHostnames: "pan.baidu.com",
+	})
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct"))
+	}))
+	defer target.Close()
+	u, _ := url.Parse(target.URL)
+	body, err = client.Fetch(http.MethodGet, "http://"+u.Host, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "direct" {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}